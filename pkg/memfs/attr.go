@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// Attr represents file attribute flags changing which operations are
+// permitted on a file or a directory, similar to the ones set by chattr(1).
+type Attr uint8
+
+const (
+	// AttrImmutable marks a file which content cannot be modified or a
+	// directory to which entries cannot be added (chattr +i).
+	AttrImmutable Attr = 1 << iota
+
+	// AttrAppendOnly marks a file which content can only be appended to. The
+	// file must have the [os.O_APPEND] flag set for writes to succeed, and it
+	// cannot be truncated (chattr +a).
+	AttrAppendOnly
+)
+
+// WithFileAttr is a [File] constructor function option setting the attribute
+// flags.
+func WithFileAttr(attr Attr) func(*File) {
+	return func(fil *File) { fil.attr = attr }
+}
+
+// Attr returns the attribute flags.
+func (fil *File) Attr() Attr { return fil.attr }
+
+// SetAttr sets the attribute flags.
+func (fil *File) SetAttr(attr Attr) { fil.attr = attr }
+
+// checkAttr returns an error of the [fs.PathError] type with [syscall.EPERM]
+// when the attribute flags do not permit modifying the file content or with
+// [syscall.EROFS] when the file is read-only (see [WithReadOnly]). The op is
+// used as the operation name in the returned error.
+func (fil *File) checkAttr(op string) error {
+	if err := fil.checkReadOnly(op); err != nil {
+		return err
+	}
+	permitted := true
+	switch {
+	case fil.attr&AttrImmutable != 0:
+		permitted = false
+	case fil.attr&AttrAppendOnly != 0:
+		permitted = op != "truncate" && fil.flag&os.O_APPEND != 0
+	}
+	if !permitted {
+		return fil.hookErr(&fs.PathError{
+			Op:   op,
+			Path: fil.path(),
+			Err:  syscall.EPERM,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithFileAttr(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+
+	// --- When ---
+	WithFileAttr(AttrImmutable)(fil)
+
+	// --- Then ---
+	assert.Equal(t, AttrImmutable, fil.attr)
+}
+
+func Test_File_Attr(t *testing.T) {
+	// --- Given ---
+	fil := &File{attr: AttrAppendOnly}
+
+	// --- When ---
+	have := fil.Attr()
+
+	// --- Then ---
+	assert.Equal(t, AttrAppendOnly, have)
+}
+
+func Test_File_SetAttr(t *testing.T) {
+	// --- Given ---
+	fil := &File{attr: AttrAppendOnly}
+
+	// --- When ---
+	fil.SetAttr(AttrImmutable)
+
+	// --- Then ---
+	assert.Equal(t, AttrImmutable, fil.attr)
+}
+
+func Test_File_checkAttr(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		attr Attr
+		flag int
+		op   string
+		err  error
+	}{
+		{"no attributes", 0, 0, "write", nil},
+		{"immutable", AttrImmutable, 0, "write", syscall.EPERM},
+		{"immutable append", AttrImmutable, os.O_APPEND, "write", syscall.EPERM},
+		{"append-only", AttrAppendOnly, 0, "write", syscall.EPERM},
+		{"append-only append", AttrAppendOnly, os.O_APPEND, "write", nil},
+		{
+			"append-only truncate",
+			AttrAppendOnly,
+			os.O_APPEND,
+			"truncate",
+			syscall.EPERM,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			opts := []func(*File){WithFileFlag(tc.flag), WithFileAttr(tc.attr)}
+			fil := MustFile("file", opts...)
+
+			// --- When ---
+			err := fil.checkAttr(tc.op)
+
+			// --- Then ---
+			if tc.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var e *fs.PathError
+			assert.ErrorAs(t, &e, err)
+			assert.Equal(t, tc.op, e.Op)
+			assert.Equal(t, "file", e.Path)
+			assert.Equal(t, tc.err, e.Err)
+		})
+	}
+}
+
+func Test_File_attributes(t *testing.T) {
+	t.Run("immutable Write", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0}, WithFileAttr(AttrImmutable))
+
+		// --- When ---
+		n, err := fil.Write([]byte{1})
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, []byte{0}, fil.buf)
+	})
+
+	t.Run("immutable WriteByte", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0}, WithFileAttr(AttrImmutable))
+
+		// --- When ---
+		err := fil.WriteByte(1)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Equal(t, []byte{0}, fil.buf)
+	})
+
+	t.Run("immutable WriteAt", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0}, WithFileAttr(AttrImmutable))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte{1}, 0)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, []byte{0}, fil.buf)
+	})
+
+	t.Run("immutable ReadFrom", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0}, WithFileAttr(AttrImmutable))
+
+		// --- When ---
+		n, err := fil.ReadFrom(bytes.NewReader([]byte{1}))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Equal(t, int64(0), n)
+		assert.Equal(t, []byte{0}, fil.buf)
+	})
+
+	t.Run("immutable Truncate", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0}, WithFileAttr(AttrImmutable))
+
+		// --- When ---
+		err := fil.Truncate(0)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "truncate", e.Op)
+		assert.Equal(t, syscall.EPERM, e.Err)
+		assert.Equal(t, []byte{0}, fil.buf)
+	})
+
+	t.Run("immutable directory AddFile", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(NewDirectory("dir", WithFileAttr(AttrImmutable)))
+
+		// --- When ---
+		err := dir.AddFile(MustFile("file"))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "AddFile", e.Op)
+		assert.Equal(t, "dir/file", e.Path)
+		assert.Equal(t, syscall.EPERM, e.Err)
+		assert.Len(t, 0, dir.entries)
+	})
+
+	t.Run("append-only Write with O_APPEND", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith(
+			"file",
+			[]byte{0},
+			WithFileAppend,
+			WithFileAttr(AttrAppendOnly),
+		)
+
+		// --- When ---
+		n, err := fil.Write([]byte{1})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []byte{0, 1}, fil.buf)
+	})
+
+	t.Run("append-only Write without O_APPEND", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0}, WithFileAttr(AttrAppendOnly))
+
+		// --- When ---
+		n, err := fil.Write([]byte{1})
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("append-only directory AddFile", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(NewDirectory("dir", WithFileAttr(AttrAppendOnly)))
+
+		// --- When ---
+		err := dir.AddFile(MustFile("file"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+}
@@ -5,6 +5,7 @@ package memfs_test
 
 import (
 	"bytes"
+	"io"
 	"testing"
 
 	"github.com/ctx42/memfs/pkg/memfs"
@@ -141,3 +142,68 @@ func BenchmarkFileReadFrom(b *testing.B) {
 		bufferReadFrom = n
 	})
 }
+
+//goland:noinspection GoUnusedGlobalVariable
+var bufferCopy int64
+
+func BenchmarkFileCopy(b *testing.B) {
+	data := make([]byte, 1<<20)
+
+	b.Run("fskit", func(b *testing.B) {
+		b.ReportAllocs()
+		b.StopTimer()
+		var n int64
+		src := memfs.MustFileWith("src", data)
+
+		b.StartTimer()
+		for i := 0; i < b.N; i++ {
+			dst := &memfs.File{}
+			n, _ = io.Copy(dst, src)
+			src.SeekStart()
+		}
+		bufferCopy = n
+	})
+
+	b.Run("bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		b.StopTimer()
+		var n int64
+		src := bytes.NewReader(data)
+
+		b.StartTimer()
+		for i := 0; i < b.N; i++ {
+			dst := &bytes.Buffer{}
+			n, _ = io.Copy(dst, src)
+			src.Reset(data)
+		}
+		bufferCopy = n
+	})
+}
+
+//goland:noinspection GoUnusedGlobalVariable
+var bufferTreeWrite error
+
+func BenchmarkTreeWriteByte(b *testing.B) {
+	bench := func(b *testing.B, opts ...func(*memfs.File)) {
+		b.ReportAllocs()
+		b.StopTimer()
+		var err error
+		root := memfs.NewRoot(opts...)
+		_ = root.MkdirAll("a/b/c", 0700)
+		_ = root.WriteFile("a/b/c/file", nil, 0600)
+		f, _ := root.Open("a/b/c/file")
+		fil := f.(*memfs.File)
+
+		b.StartTimer()
+		for i := 0; i < b.N; i++ {
+			if i%(1<<20) == 0 {
+				fil.SeekStart()
+			}
+			err = fil.WriteByte(1)
+		}
+		bufferTreeWrite = err
+	}
+
+	b.Run("default", func(b *testing.B) { bench(b) })
+	b.Run("no stats", func(b *testing.B) { bench(b, memfs.WithNoStats) })
+}
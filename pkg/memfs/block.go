@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// NewBlockFile returns a new instance of [File] emulating a block device, or
+// a file opened with the O_DIRECT flag, of the given size filled with zeros.
+// The offset and the length of every write must be multiples of the block
+// size, otherwise the write fails with [syscall.EINVAL]. Writes beyond the
+// size fail with [syscall.ENOSPC], and the file can't be truncated. Reads are
+// not restricted. It returns [fs.ErrInvalid] when the block size is not
+// positive or the size is not its multiple.
+func NewBlockFile(
+	name string,
+	size, blockSize int,
+	opts ...func(*File),
+) (*File, error) {
+	if blockSize <= 0 || size < 0 || size%blockSize != 0 {
+		return nil, fs.ErrInvalid
+	}
+	fil, err := FileWith(name, make([]byte, size), opts...)
+	if err != nil {
+		return nil, err
+	}
+	fil.block = blockSize
+	return fil, nil
+}
+
+// BlockSize returns the block size of the file created with [NewBlockFile].
+// It returns zero for other files.
+func (fil *File) BlockSize() int { return fil.block }
+
+// checkBlock returns an error when writing n bytes at the offset off to the
+// file created with [NewBlockFile] is not block-aligned or doesn't fit in the
+// file. It returns nil for empty writes and other files.
+func (fil *File) checkBlock(off int64, n int) error {
+	if fil.block == 0 || n == 0 {
+		return nil
+	}
+	var err error
+	bs := int64(fil.block)
+	switch {
+	case off%bs != 0 || int64(n)%bs != 0:
+		err = syscall.EINVAL
+	case off+int64(n) > int64(fil.Len()):
+		err = syscall.ENOSPC
+	}
+	if err != nil {
+		return fil.hookErr(&fs.PathError{
+			Op:   "write",
+			Path: fil.path(),
+			Err:  err,
+		})
+	}
+	return nil
+}
+
+// writeOffset returns the offset of the next [File.Write] call.
+func (fil *File) writeOffset() int64 {
+	if fil.flag&os.O_APPEND != 0 {
+		return int64(fil.Len())
+	}
+	return int64(fil.off)
+}
+
+// readBlocks implements [File.ReadFrom] for the files created with
+// [NewBlockFile]. It reads from r one block at a time and writes every block
+// with [File.Write]. Data not filling the last block results in an error.
+func (fil *File) readBlocks(r io.Reader) (int64, error) {
+	var total int64
+	blk := make([]byte, fil.block)
+	for {
+		n, err := io.ReadFull(r, blk)
+		if n > 0 {
+			wn, wErr := fil.Write(blk[:n])
+			total += int64(wn)
+			if wErr != nil {
+				return total, wErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
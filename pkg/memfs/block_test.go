@@ -0,0 +1,364 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_NewBlockFile(t *testing.T) {
+	t.Run("block file", func(t *testing.T) {
+		// --- When ---
+		have, err := NewBlockFile("dev", 1024, 512)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "dev", have.Name())
+		assert.Equal(t, int64(1024), have.Size())
+		assert.Equal(t, make([]byte, 1024), have.buf)
+		assert.Equal(t, 512, have.BlockSize())
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		// --- When ---
+		have, err := NewBlockFile("dev", 1024, 512, WithFileOffset(512))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 512, have.Offset())
+	})
+
+	t.Run("error - invalid arguments", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			name      string
+			size      int
+			blockSize int
+		}{
+			{"zero block size", "dev", 1024, 0},
+			{"negative block size", "dev", 1024, -512},
+			{"negative size", "dev", -512, 512},
+			{"size not multiple of block size", "dev", 1000, 512},
+			{"invalid name", "a/b", 1024, 512},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- When ---
+				have, err := NewBlockFile(tc.name, tc.size, tc.blockSize)
+
+				// --- Then ---
+				assert.ErrorIs(t, fs.ErrInvalid, err)
+				assert.Nil(t, have)
+			})
+		}
+	})
+}
+
+func Test_File_BlockSize(t *testing.T) {
+	t.Run("not a block file", func(t *testing.T) {
+		// --- When ---
+		have := MustFile("file").BlockSize()
+
+		// --- Then ---
+		assert.Equal(t, 0, have)
+	})
+}
+
+func Test_File_Write_block(t *testing.T) {
+	t.Run("aligned", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4, WithFileOffset(4)))
+
+		// --- When ---
+		n, err := fil.Write([]byte("abcd"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, []byte("\x00\x00\x00\x00abcd"), fil.buf)
+		assert.Equal(t, 8, fil.Offset())
+	})
+
+	t.Run("empty write", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4, WithFileOffset(1)))
+
+		// --- When ---
+		n, err := fil.Write(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error - misaligned offset", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4, WithFileOffset(1)))
+
+		// --- When ---
+		n, err := fil.Write([]byte("abcd"))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "write", e.Op)
+		assert.Equal(t, "dev", e.Path)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, make([]byte, 8), fil.buf)
+	})
+
+	t.Run("error - misaligned length", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+
+		// --- When ---
+		n, err := fil.Write([]byte("abc"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error - beyond the end", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4, WithFileOffset(4)))
+
+		// --- When ---
+		n, err := fil.Write([]byte("abcdefgh"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, 8, fil.Len())
+	})
+
+	t.Run("error - append mode", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4, WithFileAppend))
+
+		// --- When ---
+		n, err := fil.Write([]byte("abcd"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error - append mode stripped", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4, WithFileAppend))
+		dir := NewRoot()
+		must.Nil(dir.AddFile(fil))
+		must.Nil(dir.StripContents(nil))
+
+		// --- When ---
+		n, err := fil.Write([]byte("abcd"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, 8, fil.Len())
+	})
+}
+
+func Test_File_WriteByte_block(t *testing.T) {
+	t.Run("one byte blocks", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 2, 1))
+
+		// --- When ---
+		err := fil.WriteByte('a')
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("a\x00"), fil.buf)
+	})
+
+	t.Run("error - misaligned", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+
+		// --- When ---
+		err := fil.WriteByte('a')
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		assert.Equal(t, make([]byte, 8), fil.buf)
+	})
+}
+
+func Test_File_WriteAt_block(t *testing.T) {
+	t.Run("aligned", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte("abcd"), 4)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, []byte("\x00\x00\x00\x00abcd"), fil.buf)
+		assert.Equal(t, 0, fil.Offset())
+	})
+
+	t.Run("error - misaligned offset", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte("abcd"), 2)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error - beyond the end", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte("abcd"), 8)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, 8, fil.Len())
+	})
+
+	t.Run("stripped", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+		dir := NewRoot()
+		must.Nil(dir.AddFile(fil))
+		must.Nil(dir.StripContents(nil))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte("abcd"), 4)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, []byte("\x00\x00\x00\x00abcd"), fil.buf)
+	})
+}
+
+func Test_File_ReadFrom_block(t *testing.T) {
+	t.Run("whole blocks", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+		r := iotest.OneByteReader(strings.NewReader("abcdefgh"))
+
+		// --- When ---
+		n, err := fil.ReadFrom(r)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(8), n)
+		assert.Equal(t, []byte("abcdefgh"), fil.buf)
+	})
+
+	t.Run("from file", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+		src := MustFileWith("src", []byte("abcd"))
+
+		// --- When ---
+		n, err := io.Copy(fil, src)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), n)
+		assert.Equal(t, []byte("abcd\x00\x00\x00\x00"), fil.buf)
+	})
+
+	t.Run("error - partial block", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+
+		// --- When ---
+		n, err := fil.ReadFrom(strings.NewReader("abcdef"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		assert.Equal(t, int64(4), n)
+		assert.Equal(t, []byte("abcd\x00\x00\x00\x00"), fil.buf)
+	})
+
+	t.Run("error - beyond the end", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 4, 4))
+
+		// --- When ---
+		n, err := fil.ReadFrom(strings.NewReader("abcdefgh"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, int64(4), n)
+	})
+
+	t.Run("error - reader", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+		errTst := errors.New("test error")
+		r := io.MultiReader(
+			strings.NewReader("abcd"),
+			iotest.ErrReader(errTst),
+		)
+
+		// --- When ---
+		n, err := fil.ReadFrom(r)
+
+		// --- Then ---
+		assert.ErrorIs(t, errTst, err)
+		assert.Equal(t, int64(4), n)
+	})
+}
+
+func Test_File_Truncate_block(t *testing.T) {
+	t.Run("error - block file", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+
+		// --- When ---
+		err := fil.Truncate(4)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "truncate", e.Op)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+		assert.Equal(t, 8, fil.Len())
+	})
+}
+
+func Test_File_Read_block(t *testing.T) {
+	t.Run("not restricted", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewBlockFile("dev", 8, 4))
+		must.Value(fil.WriteAt([]byte("abcd"), 0))
+		buf := make([]byte, 3)
+
+		// --- When ---
+		n, err := fil.ReadAt(buf, 1)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []byte("bcd"), buf)
+	})
+}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// Broadcast is an append-only buffer with one writer and many independent
+// readers. Every reader returned by [Broadcast.Open] reads the buffer from
+// the beginning at its own pace. Reads at the end of the buffer block until
+// more data is written or the writer is closed, the same way consumers of
+// "tail -f" wait for new lines. Broadcast is safe for concurrent use.
+//
+// Use [File.AddBroadcast] to add the broadcast to a directory tree, so the
+// code under test opens the readers with [File.Open] or the [fs.FS]
+// returned by [File.FS] or [NewSyncFS].
+type Broadcast struct {
+	name   string        // Name used in errors.
+	mu     sync.Mutex    // Guards fields below.
+	buf    []byte        // Written data.
+	closed bool          // Writer closed.
+	notify chan struct{} // Closed and replaced on every change.
+}
+
+// NewBroadcast returns a new empty [Broadcast]. The name is used in errors.
+func NewBroadcast(name string) *Broadcast {
+	return &Broadcast{name: name, notify: make(chan struct{})}
+}
+
+// AddBroadcast adds a new regular file with the given name and the default
+// permission bits (0600) to the directory, backed by a new [Broadcast], and
+// returns the broadcast, which is the only writer of the file. Every open of
+// the file with [File.Open], the [fs.FS] returned by [File.FS] or
+// [NewSyncFS] returns a new independent reader, like [Broadcast.Open] does,
+// so reading the file to the end, for example, with [fs.ReadFile], blocks
+// until the broadcast is closed. The size of the file is the number of bytes
+// written to the broadcast. The readers are not counted by
+// [File.OpenCount], as they may be closed concurrently.
+//
+// Returns the errors returned by [NewFile] and [File.AddFile].
+func (fil *File) AddBroadcast(name string) (*Broadcast, error) {
+	ent, err := NewFile(name)
+	if err != nil {
+		return nil, err
+	}
+	bc := NewBroadcast(name)
+	ent.bcast = bc
+	if err = fil.AddFile(ent); err != nil {
+		return nil, err
+	}
+	bc.name = ent.path()
+	return bc, nil
+}
+
+// Write appends p to the buffer and wakes up the blocked readers. Returns an
+// error of the [fs.PathError] type with [fs.ErrClosed] when the writer was
+// closed.
+func (bc *Broadcast) Write(p []byte) (int, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if bc.closed {
+		return 0, &fs.PathError{
+			Op:   "write",
+			Path: bc.name,
+			Err:  fs.ErrClosed,
+		}
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	bc.buf = append(bc.buf, p...)
+	bc.wake()
+	return len(p), nil
+}
+
+// Close closes the writer. The readers return [io.EOF] after reading all the
+// data. Returns an error of the [fs.PathError] type with [fs.ErrClosed] when
+// the writer was already closed.
+func (bc *Broadcast) Close() error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if bc.closed {
+		return &fs.PathError{
+			Op:   "close",
+			Path: bc.name,
+			Err:  fs.ErrClosed,
+		}
+	}
+	bc.closed = true
+	bc.wake()
+	return nil
+}
+
+// Len returns the number of bytes written.
+func (bc *Broadcast) Len() int {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return len(bc.buf)
+}
+
+// Open returns a new reader reading the buffer from the beginning. Closing
+// the reader unblocks its pending read. The reader may be opened before or
+// after the writer is closed.
+func (bc *Broadcast) Open() io.ReadCloser {
+	return &bcReader{bc: bc, done: make(chan struct{})}
+}
+
+// file returns a new reader of the broadcast file ent, see
+// [File.AddBroadcast].
+func (bc *Broadcast) file(ent *File) fs.File {
+	return bcFile{
+		bcReader: &bcReader{bc: bc, done: make(chan struct{})},
+		ent:      ent,
+	}
+}
+
+// wake wakes up the readers waiting for a change. Must be called with the
+// mutex locked.
+func (bc *Broadcast) wake() {
+	close(bc.notify)
+	bc.notify = make(chan struct{})
+}
+
+// bcReader is an independent [Broadcast] reader.
+type bcReader struct {
+	bc   *Broadcast    // Read buffer.
+	off  int           // Current offset.
+	once sync.Once     // Guards closing done.
+	done chan struct{} // Closed when the reader is closed.
+}
+
+// Read reads the next len(p) bytes from the buffer or until the end of the
+// written data. When there is no data to read, it blocks until the data is
+// written, the writer is closed, or the reader is closed. Returns [io.EOF]
+// when all data was read and the writer is closed. Returns an error of the
+// [fs.PathError] type with [fs.ErrClosed] when the reader is closed.
+func (rd *bcReader) Read(p []byte) (int, error) {
+	for {
+		select {
+		case <-rd.done:
+			return 0, rd.closedErr("read")
+		default:
+		}
+
+		rd.bc.mu.Lock()
+		if rd.off < len(rd.bc.buf) || len(p) == 0 {
+			n := copy(p, rd.bc.buf[rd.off:])
+			rd.off += n
+			rd.bc.mu.Unlock()
+			return n, nil
+		}
+		if rd.bc.closed {
+			rd.bc.mu.Unlock()
+			return 0, io.EOF
+		}
+		notify := rd.bc.notify
+		rd.bc.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-rd.done:
+			return 0, rd.closedErr("read")
+		}
+	}
+}
+
+// Close closes the reader. Returns an error of the [fs.PathError] type with
+// [fs.ErrClosed] when the reader was already closed.
+func (rd *bcReader) Close() error {
+	err := rd.closedErr("close")
+	rd.once.Do(func() {
+		close(rd.done)
+		err = nil
+	})
+	return err
+}
+
+// bcFile is an independent reader of the broadcast file, see
+// [File.AddBroadcast].
+type bcFile struct {
+	*bcReader
+	ent *File // The broadcast file.
+}
+
+// Stat implements [fs.File] interface.
+func (f bcFile) Stat() (fs.FileInfo, error) { return f.ent.Stat() }
+
+// closedErr returns the error returned by the op when the reader is closed.
+func (rd *bcReader) closedErr(op string) error {
+	return &fs.PathError{
+		Op:   op,
+		Path: rd.bc.name,
+		Err:  fs.ErrClosed,
+	}
+}
@@ -0,0 +1,383 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"sync"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_NewBroadcast(t *testing.T) {
+	// --- When ---
+	have := NewBroadcast("stream")
+
+	// --- Then ---
+	assert.Equal(t, "stream", have.name)
+	assert.Equal(t, 0, have.Len())
+	assert.False(t, have.closed)
+	assert.NotNil(t, have.notify)
+}
+
+func Test_File_AddBroadcast(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+
+		// --- When ---
+		have, err := sub.AddBroadcast("stream")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		ent := must.Value(open(dir, "sub/stream"))
+		assert.Same(t, have, ent.bcast)
+		assert.Equal(t, "sub/stream", have.name)
+		assert.Equal(t, fs.FileMode(0600), ent.Mode())
+	})
+
+	t.Run("independent readers", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		bc := must.Value(dir.AddBroadcast("stream"))
+		must.Value(bc.Write([]byte("abc")))
+		rd0 := must.Value(dir.Open("stream"))
+		must.Value(rd0.Read(make([]byte, 2)))
+
+		// --- When ---
+		rd1 := must.Value(dir.FS().Open("stream"))
+
+		// --- Then ---
+		must.Nil(bc.Close())
+		assert.Equal(t, "c", string(must.Value(io.ReadAll(rd0))))
+		assert.Equal(t, "abc", string(must.Value(io.ReadAll(rd1))))
+	})
+
+	t.Run("read blocks until written", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		bc := must.Value(dir.AddBroadcast("stream"))
+		rd := must.Value(NewSyncFS(dir).Open("stream"))
+		var have []byte
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			have, _ = io.ReadAll(rd)
+		}()
+
+		// --- When ---
+		must.Value(bc.Write([]byte("abc")))
+		must.Nil(bc.Close())
+
+		// --- Then ---
+		wg.Wait()
+		assert.Equal(t, "abc", string(have))
+	})
+
+	t.Run("ReadFile after close", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		bc := must.Value(dir.AddBroadcast("stream"))
+		must.Value(bc.Write([]byte("abc")))
+		must.Nil(bc.Close())
+
+		// --- When ---
+		have, err := fs.ReadFile(dir.FS(), "stream")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", string(have))
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		bc := must.Value(dir.AddBroadcast("stream"))
+		must.Value(bc.Write([]byte("abc")))
+		rd := must.Value(dir.Open("stream"))
+
+		// --- When ---
+		have, err := rd.Stat()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "stream", have.Name())
+		assert.Equal(t, int64(3), have.Size())
+		assert.Equal(t, 0, must.Value(open(dir, "stream")).OpenCount())
+	})
+
+	t.Run("error - exists", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.AddBroadcast("file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrExist, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - closed reader", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(dir.AddBroadcast("stream"))
+		rd := must.Value(dir.Open("stream"))
+		must.Nil(rd.Close())
+
+		// --- When ---
+		_, err := rd.Read(make([]byte, 1))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "stream", e.Path)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+	})
+}
+
+func Test_Broadcast_Write(t *testing.T) {
+	t.Run("append", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		data := []byte("abc")
+
+		// --- When ---
+		n, err := bc.Write(data)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		must.Value(bc.Write([]byte("def")))
+		data[0] = 'x'
+		assert.Equal(t, []byte("abcdef"), bc.buf)
+		assert.Equal(t, 6, bc.Len())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+
+		// --- When ---
+		n, err := bc.Write(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		must.Nil(bc.Close())
+
+		// --- When ---
+		n, err := bc.Write([]byte("abc"))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "write", e.Op)
+		assert.Equal(t, "stream", e.Path)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func Test_Broadcast_Close(t *testing.T) {
+	t.Run("close", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+
+		// --- When ---
+		err := bc.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, bc.closed)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		must.Nil(bc.Close())
+
+		// --- When ---
+		err := bc.Close()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "close", e.Op)
+		assert.Equal(t, "stream", e.Path)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+	})
+}
+
+func Test_Broadcast_Open(t *testing.T) {
+	t.Run("independent readers", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		must.Value(bc.Write([]byte("abc")))
+		rd0 := bc.Open()
+		buf := make([]byte, 2)
+		must.Value(rd0.Read(buf))
+
+		// --- When ---
+		rd1 := bc.Open()
+
+		// --- Then ---
+		must.Value(bc.Write([]byte("def")))
+		must.Nil(bc.Close())
+		assert.Equal(t, "cdef", string(must.Value(io.ReadAll(rd0))))
+		assert.Equal(t, "abcdef", string(must.Value(io.ReadAll(rd1))))
+	})
+
+	t.Run("opened after close", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		must.Value(bc.Write([]byte("abc")))
+		must.Nil(bc.Close())
+
+		// --- When ---
+		rd := bc.Open()
+
+		// --- Then ---
+		assert.Equal(t, "abc", string(must.Value(io.ReadAll(rd))))
+	})
+}
+
+func Test_bcReader_Read(t *testing.T) {
+	t.Run("blocks until written", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		rd := bc.Open()
+		go func() { must.Value(bc.Write([]byte("abc"))) }()
+		buf := make([]byte, 8)
+
+		// --- When ---
+		n, err := rd.Read(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", string(buf[:n]))
+	})
+
+	t.Run("blocks until writer is closed", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		rd := bc.Open()
+		go func() { must.Nil(bc.Close()) }()
+
+		// --- When ---
+		n, err := rd.Read(make([]byte, 8))
+
+		// --- Then ---
+		assert.ErrorIs(t, io.EOF, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("many readers stream", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		have := make([]string, 3)
+		var wg sync.WaitGroup
+		for i := range have {
+			rd := bc.Open()
+			wg.Go(func() { have[i] = string(must.Value(io.ReadAll(rd))) })
+		}
+
+		// --- When ---
+		for _, line := range []string{"line0\n", "line1\n", "line2\n"} {
+			must.Value(bc.Write([]byte(line)))
+		}
+		must.Nil(bc.Close())
+
+		// --- Then ---
+		wg.Wait()
+		want := "line0\nline1\nline2\n"
+		assert.Equal(t, []string{want, want, want}, have)
+	})
+
+	t.Run("empty buffer does not block", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		rd := bc.Open()
+
+		// --- When ---
+		n, err := rd.Read(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error - reader closed while blocked", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		rd := bc.Open()
+		go func() { must.Nil(rd.Close()) }()
+
+		// --- When ---
+		n, err := rd.Read(make([]byte, 8))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "stream", e.Path)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error - reader closed", func(t *testing.T) {
+		// --- Given ---
+		bc := NewBroadcast("stream")
+		must.Value(bc.Write([]byte("abc")))
+		rd := bc.Open()
+		must.Nil(rd.Close())
+
+		// --- When ---
+		n, err := rd.Read(make([]byte, 8))
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrClosed, err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func Test_bcReader_Close(t *testing.T) {
+	t.Run("close", func(t *testing.T) {
+		// --- Given ---
+		rd := NewBroadcast("stream").Open()
+
+		// --- When ---
+		err := rd.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		rd := NewBroadcast("stream").Open()
+		must.Nil(rd.Close())
+
+		// --- When ---
+		err := rd.Close()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "close", e.Op)
+		assert.Equal(t, "stream", e.Path)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+	})
+}
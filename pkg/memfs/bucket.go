@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Object describes an object stored in a [Bucket].
+type Object struct {
+	Key  string // Object key.
+	Size int64  // Object size in bytes.
+}
+
+// Bucket is the interface implemented by object store buckets used by
+// [FromBucket] and [WriteBack]. It can be implemented by adapters wrapping
+// real object store clients; [MemBucket] is an in-memory implementation.
+type Bucket interface {
+	// List returns all objects in the bucket.
+	List(ctx context.Context) ([]Object, error)
+
+	// Get returns the content of the object with the given key.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put creates or replaces the object with the given key. The bucket must
+	// not retain the data slice.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// MemBucket is an in-memory [Bucket]. The zero value is an empty bucket ready
+// to use. It is safe for concurrent use.
+type MemBucket struct {
+	mu   sync.Mutex
+	objs map[string][]byte
+}
+
+// List returns all objects in the bucket sorted by key.
+func (bkt *MemBucket) List(_ context.Context) ([]Object, error) {
+	bkt.mu.Lock()
+	defer bkt.mu.Unlock()
+	objs := make([]Object, 0, len(bkt.objs))
+	for key, data := range bkt.objs {
+		objs = append(objs, Object{Key: key, Size: int64(len(data))})
+	}
+	slices.SortFunc(objs, func(a, b Object) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return objs, nil
+}
+
+// Get returns a copy of the object content. Returns an error of the
+// [fs.PathError] type with [fs.ErrNotExist] when the object does not exist.
+func (bkt *MemBucket) Get(_ context.Context, key string) ([]byte, error) {
+	bkt.mu.Lock()
+	defer bkt.mu.Unlock()
+	data, ok := bkt.objs[key]
+	if !ok {
+		return nil, &fs.PathError{
+			Op:   "get",
+			Path: key,
+			Err:  fs.ErrNotExist,
+		}
+	}
+	return slices.Clone(data), nil
+}
+
+// Put creates or replaces the object with a copy of the data.
+func (bkt *MemBucket) Put(_ context.Context, key string, data []byte) error {
+	bkt.mu.Lock()
+	defer bkt.mu.Unlock()
+	if bkt.objs == nil {
+		bkt.objs = make(map[string][]byte)
+	}
+	bkt.objs[key] = slices.Clone(data)
+	return nil
+}
+
+// FromBucket returns a new root directory with the tree of the objects in
+// the bucket. The object keys are used as paths, the keys ending with a slash
+// represent directories. The options are applied to the root directory. The
+// object contents are read through to the bucket on the first read and
+// dropped on [File.Close] unless the [WithLazyCache] option was used. The
+// context is used for all the bucket calls made by the tree.
+//
+// Returns an error of the [fs.PathError] type with [fs.ErrInvalid] when an
+// object key is not a valid path. The errors returned by the bucket when
+// reading the object contents are returned by the first read.
+func FromBucket(
+	ctx context.Context,
+	bkt Bucket,
+	opts ...func(*File),
+) (*File, error) {
+	objs, err := bkt.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	root := NewRoot(opts...)
+	for _, obj := range objs {
+		name := strings.TrimSuffix(obj.Key, "/")
+		if !fs.ValidPath(name) || name == "." {
+			return nil, &fs.PathError{
+				Op:   "bucket",
+				Path: obj.Key,
+				Err:  fs.ErrInvalid,
+			}
+		}
+
+		if strings.HasSuffix(obj.Key, "/") {
+			if _, err = mkdirAll(root, name); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		dir, err := mkdirAll(root, path.Dir(name))
+		if err != nil {
+			return nil, err
+		}
+		fil := &File{
+			info: FileInfo{
+				name: path.Base(name),
+				size: obj.Size,
+				mode: 0600,
+			},
+			lazy: bucketContent(ctx, bkt, obj.Key),
+		}
+		if err = dir.AddFile(fil); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// WriteBack writes back the files created or modified in the tree to the
+// bucket using their paths relative to the root as the object keys. The files
+// which were not modified since they were read from the bucket or written
+// back are skipped. The written files are read through to the bucket again,
+// so WriteBack can be called repeatedly to write back only the new changes.
+// Directories are not written, and the objects of removed files are not
+// deleted from the bucket.
+func WriteBack(ctx context.Context, bkt Bucket, root *File) error {
+	var err error
+	root.walk("", func(pth string, ent *File) {
+		if err != nil || ent.IsDir() || ent.lazy != nil {
+			return
+		}
+		key := filepath.ToSlash(pth)
+		data := ent.mapCopy()
+		if err = bkt.Put(ctx, key, data); err != nil {
+			return
+		}
+		ent.info.size = int64(len(data))
+		ent.lazy = bucketContent(ctx, bkt, key)
+		ent.loaded = true
+	})
+	return err
+}
+
+// bucketContent returns a function reading the object from the bucket.
+func bucketContent(
+	ctx context.Context,
+	bkt Bucket,
+	key string,
+) func() ([]byte, error) {
+	return func() ([]byte, error) { return bkt.Get(ctx, key) }
+}
@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstBucket is a [Bucket] counting the calls and failing them with the
+// configured errors.
+type tstBucket struct {
+	MemBucket
+	gets    int
+	puts    []string
+	listErr error
+	getErr  error
+	putErr  error
+}
+
+func (bkt *tstBucket) List(ctx context.Context) ([]Object, error) {
+	if bkt.listErr != nil {
+		return nil, bkt.listErr
+	}
+	return bkt.MemBucket.List(ctx)
+}
+
+func (bkt *tstBucket) Get(ctx context.Context, key string) ([]byte, error) {
+	bkt.gets++
+	if bkt.getErr != nil {
+		return nil, bkt.getErr
+	}
+	return bkt.MemBucket.Get(ctx, key)
+}
+
+func (bkt *tstBucket) Put(ctx context.Context, key string, data []byte) error {
+	bkt.puts = append(bkt.puts, key)
+	if bkt.putErr != nil {
+		return bkt.putErr
+	}
+	return bkt.MemBucket.Put(ctx, key, data)
+}
+
+// tstBucketDir returns a bucket matching the structure created by tstDirMem.
+func tstBucketDir() *tstBucket {
+	bkt := &tstBucket{}
+	ctx := context.Background()
+	for _, key := range []string{
+		"file0", "file1", "file2", "sub/file3", "sub/file4",
+		"sub/sub2/file5", "sub/sub2/file6",
+	} {
+		must.Nil(bkt.MemBucket.Put(ctx, key, []byte(key[len(key)-5:])))
+	}
+	return bkt
+}
+
+func Test_MemBucket(t *testing.T) {
+	t.Run("zero value", func(t *testing.T) {
+		// --- Given ---
+		bkt := &MemBucket{}
+
+		// --- When ---
+		have, err := bkt.List(context.Background())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, have)
+	})
+
+	t.Run("put list and get", func(t *testing.T) {
+		// --- Given ---
+		ctx := context.Background()
+		bkt := &MemBucket{}
+		data := []byte("abc")
+
+		// --- When ---
+		must.Nil(bkt.Put(ctx, "b", data))
+		must.Nil(bkt.Put(ctx, "a", []byte("de")))
+
+		// --- Then ---
+		data[0] = 'x'
+		want := []Object{{Key: "a", Size: 2}, {Key: "b", Size: 3}}
+		assert.Equal(t, want, must.Value(bkt.List(ctx)))
+		have := must.Value(bkt.Get(ctx, "b"))
+		assert.Equal(t, []byte("abc"), have)
+		have[0] = 'x'
+		assert.Equal(t, []byte("abc"), must.Value(bkt.Get(ctx, "b")))
+	})
+
+	t.Run("put replaces", func(t *testing.T) {
+		// --- Given ---
+		ctx := context.Background()
+		bkt := &MemBucket{}
+		must.Nil(bkt.Put(ctx, "a", []byte("abc")))
+
+		// --- When ---
+		err := bkt.Put(ctx, "a", []byte("de"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("de"), must.Value(bkt.Get(ctx, "a")))
+	})
+
+	t.Run("error - get not existing", func(t *testing.T) {
+		// --- Given ---
+		bkt := &MemBucket{}
+
+		// --- When ---
+		have, err := bkt.Get(context.Background(), "a")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "get", e.Op)
+		assert.Equal(t, "a", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_FromBucket(t *testing.T) {
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		bkt := tstBucketDir()
+
+		// --- When ---
+		have, err := FromBucket(context.Background(), bkt)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := must.Value(tstDirMem().List())
+		assert.Equal(t, want, must.Value(have.List()))
+		assert.Equal(t, 0, bkt.gets)
+		fil := must.Value(open(have, "sub/sub2/file5"))
+		assert.Equal(t, int64(5), fil.Size())
+		assert.Equal(t, 0, bkt.gets)
+	})
+
+	t.Run("read through", func(t *testing.T) {
+		// --- Given ---
+		bkt := tstBucketDir()
+		root := must.Value(FromBucket(context.Background(), bkt))
+		fil := must.Value(open(root, "sub/file3"))
+
+		// --- When ---
+		have, err := io.ReadAll(fil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file3", string(have))
+		assert.Equal(t, 1, bkt.gets)
+		must.Nil(fil.Close())
+		must.Value(fil.Seek(0, io.SeekStart))
+		assert.Equal(t, "file3", string(must.Value(io.ReadAll(fil))))
+		assert.Equal(t, 2, bkt.gets)
+	})
+
+	t.Run("directory keys", func(t *testing.T) {
+		// --- Given ---
+		bkt := &MemBucket{}
+		must.Nil(bkt.Put(context.Background(), "dir/sub/", nil))
+
+		// --- When ---
+		have, err := FromBucket(context.Background(), bkt)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		sub := must.Value(open(have, "dir/sub"))
+		assert.True(t, sub.IsDir())
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		// --- Given ---
+		bkt := tstBucketDir()
+
+		// --- When ---
+		have, err := FromBucket(context.Background(), bkt, WithLazyCache)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.keep)
+	})
+
+	t.Run("error - list", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+		bkt := &tstBucket{listErr: errTst}
+
+		// --- When ---
+		have, err := FromBucket(context.Background(), bkt)
+
+		// --- Then ---
+		assert.Same(t, errTst, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - get", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+		bkt := tstBucketDir()
+		bkt.getErr = errTst
+		root := must.Value(FromBucket(context.Background(), bkt))
+
+		// --- When ---
+		have, err := root.ReadFile("file0")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Same(t, errTst, e.Err)
+		assert.Len(t, 0, have)
+	})
+
+	t.Run("error - invalid key", func(t *testing.T) {
+		// --- Given ---
+		bkt := &MemBucket{}
+		must.Nil(bkt.Put(context.Background(), "/file", nil))
+
+		// --- When ---
+		have, err := FromBucket(context.Background(), bkt)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "bucket", e.Op)
+		assert.Equal(t, "/file", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - file used as a directory", func(t *testing.T) {
+		// --- Given ---
+		ctx := context.Background()
+		bkt := &MemBucket{}
+		must.Nil(bkt.Put(ctx, "file", nil))
+		must.Nil(bkt.Put(ctx, "file/sub", nil))
+
+		// --- When ---
+		have, err := FromBucket(ctx, bkt)
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_WriteBack(t *testing.T) {
+	t.Run("created and modified files", func(t *testing.T) {
+		// --- Given ---
+		ctx := context.Background()
+		bkt := tstBucketDir()
+		root := must.Value(FromBucket(ctx, bkt))
+		must.Value(must.Value(open(root, "sub/file3")).Write([]byte("FILE")))
+		must.Value(root.ReadFile("file0"))
+		sub2 := must.Value(open(root, "sub/sub2"))
+		must.Nil(sub2.AddFile(must.Value(FileWith("new", []byte("abc")))))
+
+		// --- When ---
+		err := WriteBack(ctx, bkt, root)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sub/file3", "sub/sub2/new"}, bkt.puts)
+		have := must.Value(bkt.Get(ctx, "sub/file3"))
+		assert.Equal(t, "FILE3", string(have))
+		have = must.Value(bkt.Get(ctx, "sub/sub2/new"))
+		assert.Equal(t, "abc", string(have))
+	})
+
+	t.Run("only new changes", func(t *testing.T) {
+		// --- Given ---
+		ctx := context.Background()
+		bkt := tstBucketDir()
+		root := must.Value(FromBucket(ctx, bkt))
+		fil := must.Value(open(root, "file1"))
+		must.Value(fil.Write([]byte("FILE")))
+		must.Nil(WriteBack(ctx, bkt, root))
+		bkt.puts = nil
+
+		// --- When ---
+		err := WriteBack(ctx, bkt, root)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, bkt.puts)
+		must.Nil(fil.Close())
+		assert.Equal(t, 5, fil.Len())
+		assert.Equal(t, "FILE1", string(must.Value(root.ReadFile("file1"))))
+	})
+
+	t.Run("error - put", func(t *testing.T) {
+		// --- Given ---
+		ctx := context.Background()
+		errTst := errors.New("test error")
+		bkt := tstBucketDir()
+		root := must.Value(FromBucket(ctx, bkt))
+		must.Nil(root.AddFile(must.Value(FileWith("a", []byte("abc")))))
+		must.Nil(root.AddFile(must.Value(FileWith("b", []byte("def")))))
+		bkt.putErr = errTst
+
+		// --- When ---
+		err := WriteBack(ctx, bkt, root)
+
+		// --- Then ---
+		assert.Same(t, errTst, err)
+		assert.Equal(t, []string{"a"}, bkt.puts)
+		fil := must.Value(open(root, "a"))
+		assert.Nil(t, fil.lazy)
+	})
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+)
+
+// Compile time checks of the file systems returned by [File.FS] and
+// [File.DirFS]. Keep them in sync with the documentation of the methods.
+var (
+	_ fs.ReadDirFS  = fsDir{}
+	_ fs.ReadFileFS = fsDir{}
+	_ fs.StatFS     = fsDir{}
+
+	_ fs.ReadDirFS  = dirFS{}
+	_ fs.ReadFileFS = dirFS{}
+	_ fs.StatFS     = dirFS{}
+)
+
+// fsCapabilities lists the optional [io/fs] interfaces reported by
+// [Capabilities] in the name order.
+var fsCapabilities = []struct {
+	name string
+	impl func(fsys fs.FS) bool
+}{
+	{"fs.GlobFS", implements[fs.GlobFS]},
+	{"fs.ReadDirFS", implements[fs.ReadDirFS]},
+	{"fs.ReadFileFS", implements[fs.ReadFileFS]},
+	{"fs.ReadLinkFS", implements[fs.ReadLinkFS]},
+	{"fs.StatFS", implements[fs.StatFS]},
+	{"fs.SubFS", implements[fs.SubFS]},
+}
+
+// Capabilities returns the names of the optional [io/fs] interfaces, like
+// "fs.StatFS", implemented by the file system, in the name order. Use it to
+// verify that adapters wrapping the file systems returned by [File.FS] or
+// [File.DirFS] don't hide any of the interfaces they implement, for example,
+// by comparing the reports for the wrapped and the wrapping file systems.
+func Capabilities(fsys fs.FS) []string {
+	var have []string
+	for _, c := range fsCapabilities {
+		if c.impl(fsys) {
+			have = append(have, c.name)
+		}
+	}
+	return have
+}
+
+// implements returns true when the file system implements the interface T.
+func implements[T any](fsys fs.FS) bool {
+	_, ok := fsys.(T)
+	return ok
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Capabilities(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		fsys fs.FS
+		want []string
+	}{
+		{
+			"FS",
+			tstDirMem().FS(),
+			[]string{
+				"fs.ReadDirFS", "fs.ReadFileFS", "fs.ReadLinkFS", "fs.StatFS",
+			},
+		},
+		{
+			"DirFS",
+			tstDirMem().DirFS(),
+			[]string{
+				"fs.ReadDirFS", "fs.ReadFileFS", "fs.ReadLinkFS", "fs.StatFS",
+			},
+		},
+		{"File", tstDirMem(), []string{"fs.ReadFileFS", "fs.ReadLinkFS"}},
+		{"wrapped", fsOnly{tstDirMem().FS()}, nil},
+		{
+			"MapFS",
+			fstest.MapFS{},
+			[]string{
+				"fs.GlobFS", "fs.ReadDirFS", "fs.ReadFileFS", "fs.ReadLinkFS",
+				"fs.StatFS", "fs.SubFS",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have := Capabilities(tc.fsys)
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
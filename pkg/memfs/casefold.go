@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"strings"
+)
+
+// RenameEvent describes a file or a directory renamed with [File.Rename],
+// [File.Exchange] or [File.Rotate], reported to the hook set with
+// [WithRenameHook].
+type RenameEvent struct {
+	OldPath string // Path before the rename.
+	NewPath string // Path after the rename.
+
+	// Only the case of the name changed, for example, from "README.md" to
+	// "Readme.md", in the case-insensitive mode (see [WithCaseInsensitive]).
+	// In this mode, both paths name the same file.
+	CaseOnly bool
+}
+
+// WithCaseInsensitive is a [File] constructor function option making the
+// names in the directory and its whole tree case-insensitive and
+// case-preserving, like on the default file systems of macOS and Windows.
+// The names which differ only in case name the same file, so, for example,
+// opening "readme.md" opens the "README.md" file and adding the "Readme.md"
+// file fails with [fs.ErrExist]. The names keep the case they were created
+// with, and [File.Rename] can change only the case of a name. The glob
+// patterns are matched with the exact case.
+func WithCaseInsensitive(fil *File) { fil.nocase = true }
+
+// WithRenameHook is a [File] constructor function option setting a hook which
+// is called with the description of every file or directory renamed with
+// [File.Rename], [File.Exchange] or [File.Rotate], with
+// [RenameEvent.CaseOnly] telling the case-only renames apart, so the tools
+// syncing trees can be tested for handling them. The hook set on a directory
+// is used for all renames in its tree unless a directory closer to the
+// renamed file in the hierarchy has its own hook.
+func WithRenameHook(hook func(ev RenameEvent)) func(*File) {
+	return func(fil *File) { fil.renameHook = hook }
+}
+
+// caseInsensitive returns true when the [WithCaseInsensitive] option was used
+// on the instance or any of its parents.
+func (fil *File) caseInsensitive() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.nocase {
+			return true
+		}
+	}
+	return false
+}
+
+// entry returns the directory entry with the given name, ignoring the case in
+// the case-insensitive mode (see [WithCaseInsensitive]). Returns nil when
+// there is no such entry.
+func (fil *File) entry(name string) *File {
+	fold := fil.caseInsensitive()
+	for _, ent := range fil.entries {
+		if sameName(ent.Name(), name, fold) {
+			return ent
+		}
+	}
+	return nil
+}
+
+// sameName returns true when the names are the same or, when fold is true,
+// equal under Unicode case folding, like [strings.EqualFold] reports. It
+// decides whether two names refer to the same entry of a directory.
+func sameName(a, b string, fold bool) bool {
+	return a == b || (fold && strings.EqualFold(a, b))
+}
+
+// renamesHook returns the hook set with [WithRenameHook] on the instance or
+// the closest of its parents. Returns nil when no hook was set.
+func (fil *File) renamesHook() func(ev RenameEvent) {
+	for f := fil; f != nil; f = f.parent {
+		if f.renameHook != nil {
+			return f.renameHook
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstDirNoCase returns the [tstDirMem] tree in the case-insensitive mode.
+func tstDirNoCase() *File {
+	dir := tstDirMem()
+	WithCaseInsensitive(dir)
+	return dir
+}
+
+func Test_WithCaseInsensitive(t *testing.T) {
+	t.Run("open", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirNoCase()
+
+		// --- When ---
+		have, err := open(dir, "SUB/Sub2/FILE5")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file5", have.Name())
+		assert.Equal(t, "file5", have.String())
+	})
+
+	t.Run("case-sensitive by default", func(t *testing.T) {
+		// --- When ---
+		have, err := open(tstDirMem(), "SUB/file3")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("file systems", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirNoCase()
+
+		// --- When ---
+		have, err := fs.ReadFile(dir.DirFS(), "Sub/File3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file3", string(have))
+		info := must.Value(fs.Stat(dir.FS(), "FILE0"))
+		assert.Equal(t, "file0", info.Name())
+	})
+
+	t.Run("AddFile", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirNoCase()
+
+		// --- When ---
+		err := dir.AddFile(MustFile("File0"))
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrExist, err)
+		assert.Len(t, 4, dir.entries)
+	})
+
+	t.Run("WriteFile keeps the name", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirNoCase()
+
+		// --- When ---
+		err := dir.WriteFile("FILE0", []byte("abc"), 0600)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := must.Value(open(dir, "file0"))
+		assert.Equal(t, "file0", fil.Name())
+		assert.Equal(t, "abc", fil.String())
+		assert.Len(t, 4, dir.entries)
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithCaseInsensitive(must.Value(open(dir, "sub")))
+
+		// --- When ---
+		_, err := open(dir, "sub/FILE3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = open(dir, "SUB/file3")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+}
+
+func Test_File_Rename_case_only(t *testing.T) {
+	t.Run("case-insensitive", func(t *testing.T) {
+		// --- Given ---
+		var have []RenameEvent
+		dir := tstDirNoCase()
+		WithRenameHook(func(ev RenameEvent) { have = append(have, ev) })(dir)
+
+		// --- When ---
+		err := dir.Rename("sub/file3", "sub/File3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		sub := must.Value(open(dir, "sub"))
+		ents := names(must.Value(sub.ReadDir(-1)))
+		assert.Equal(t, []string{"File3", "file4", "sub2"}, ents)
+		want := []RenameEvent{
+			{OldPath: "sub/file3", NewPath: "sub/File3", CaseOnly: true},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("case-insensitive directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirNoCase()
+
+		// --- When ---
+		err := dir.Rename("sub", "SUB")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "SUB", must.Value(open(dir, "sub")).Name())
+		file5 := must.Value(open(dir, "SUB/sub2/file5"))
+		assert.Equal(t, "file5", file5.String())
+	})
+
+	t.Run("case-sensitive", func(t *testing.T) {
+		// --- Given ---
+		var have []RenameEvent
+		dir := tstDirMem()
+		WithRenameHook(func(ev RenameEvent) { have = append(have, ev) })(dir)
+
+		// --- When ---
+		err := dir.Rename("file0", "File0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []RenameEvent{{OldPath: "file0", NewPath: "File0"}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("same name", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		dir := tstDirNoCase()
+		WithRenameHook(func(RenameEvent) { calls++ })(dir)
+
+		// --- When ---
+		err := dir.Rename("FILE0", "file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("write-through", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirNoCase()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+
+		// --- When ---
+		err := dir.Rename("file0", "FILE0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file0", readOS(t, filepath.Join(dst, "FILE0")))
+		_, err = os.Stat(filepath.Join(dst, "file0"))
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirNoCase()
+		WithReadOnly(dir)
+
+		// --- When ---
+		err := dir.Rename("file0", "FILE0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Equal(t, "file0", must.Value(open(dir, "file0")).Name())
+	})
+}
+
+func Test_WithRenameHook(t *testing.T) {
+	t.Run("moved between directories", func(t *testing.T) {
+		// --- Given ---
+		var have []RenameEvent
+		dir := tstDirMem()
+		WithRenameHook(func(ev RenameEvent) { have = append(have, ev) })(dir)
+
+		// --- When ---
+		err := dir.Rename("sub/sub2/file5", "file5")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []RenameEvent{{OldPath: "sub/sub2/file5", NewPath: "file5"}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("closest hook is used", func(t *testing.T) {
+		// --- Given ---
+		var root, sub int
+		dir := tstDirMem()
+		WithRenameHook(func(RenameEvent) { root++ })(dir)
+		WithRenameHook(func(RenameEvent) { sub++ })(
+			must.Value(open(dir, "sub")),
+		)
+
+		// --- When ---
+		err := dir.Rename("sub/file3", "sub/file7")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, root)
+		assert.Equal(t, 1, sub)
+	})
+
+	t.Run("not called on error", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		dir := tstDirMem()
+		WithRenameHook(func(RenameEvent) { calls++ })(dir)
+
+		// --- When ---
+		err := dir.Rename("missing", "file7")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Equal(t, 0, calls)
+	})
+}
+
+func Test_File_entry(t *testing.T) {
+	t.Run("exact", func(t *testing.T) {
+		// --- When ---
+		have := tstDirMem().entry("file1")
+
+		// --- Then ---
+		assert.Equal(t, "file1", have.Name())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		// --- When ---
+		have := tstDirMem().entry("FILE1")
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("folded", func(t *testing.T) {
+		// --- When ---
+		have := tstDirNoCase().entry("FILE1")
+
+		// --- Then ---
+		assert.Equal(t, "file1", have.Name())
+	})
+}
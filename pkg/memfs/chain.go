@@ -0,0 +1,391 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// Compile time checks of the file systems returned by the middlewares.
+var (
+	_ fs.ReadDirFS  = mwFS{}
+	_ fs.ReadFileFS = mwFS{}
+	_ fs.ReadLinkFS = mwFS{}
+	_ fs.StatFS     = mwFS{}
+	_ WriteFS       = mwWriteFS{}
+)
+
+// Compile time checks of the files returned by [ReadOnly].
+var (
+	_ fs.ReadDirFile = roDir{}
+	_ io.ReaderAt    = roSeeker{}
+	_ io.Seeker      = roSeeker{}
+	_ io.WriterTo    = roSeeker{}
+)
+
+// WriteFS is the interface implemented by the file systems which can be
+// modified, like the ones returned by [NewSyncFS] and [WrapOS]. The names are
+// the same as in [fs.FS], and the methods work like the [File] methods or
+// the [os] package functions with the same names.
+type WriteFS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Remove(name string) error
+	RemoveAll(name string) error
+	Rename(oldpath, newpath string) error
+}
+
+// Middleware wraps a file system adding a cross-cutting behavior to it, like
+// the ones returned by [ReadOnly], [Trace], [Faults] and [Latency].
+type Middleware func(fsys fs.FS) fs.FS
+
+// Chain returns the file system wrapped with the middlewares, for example:
+//
+//	fsys := Chain(root.FS(), Trace(rec), Faults(plan), Latency(time.Second))
+//
+// The first middleware is the outermost one, so the calls go through the
+// middlewares in the given order. Any [fs.FS] can be wrapped, not only the
+// ones returned by [File.FS] and [File.DirFS]. The file systems returned by
+// the middlewares implement [fs.ReadDirFS], [fs.ReadFileFS], [fs.ReadLinkFS]
+// and [fs.StatFS] using the wrapped file system methods when it implements
+// them.
+func Chain(fsys fs.FS, mws ...Middleware) fs.FS {
+	for i := len(mws) - 1; i >= 0; i-- {
+		fsys = mws[i](fsys)
+	}
+	return fsys
+}
+
+// Event describes a file system operation recorded by the [Trace]
+// middleware.
+type Event struct {
+	Op   string // Operation name, see [Faults].
+	Name string // Name, or the old path for "rename", of the operation.
+	Err  error  // Error returned by the operation.
+}
+
+// ReadOnly returns a middleware hiding the [WriteFS] methods of the file
+// system and the write methods of the opened files, so the files cannot be
+// modified by asserting them to [io.Writer] and similar interfaces. The
+// opened directories keep the [fs.ReadDirFile] methods, and the seekable
+// files keep the [io.Seeker], [io.ReaderAt] and [io.WriterTo] methods.
+func ReadOnly() Middleware {
+	return func(fsys fs.FS) fs.FS {
+		return mwFS{fsys: fsys, file: readOnlyFile}
+	}
+}
+
+// Trace returns a middleware calling rec with every operation on the file
+// system after it's done.
+func Trace(rec func(ev Event)) Middleware {
+	return func(fsys fs.FS) fs.FS {
+		call := func(op, name string, fn func() error) error {
+			err := fn()
+			rec(Event{Op: op, Name: name, Err: err})
+			return err
+		}
+		return mwFS{fsys: fsys, call: call}.wrap()
+	}
+}
+
+// Faults returns a middleware calling the plan before every operation on the
+// file system with the operation name, "open", "stat", "readdir",
+// "readfile", "readlink" or "lstat", or for [WriteFS] "writefile", "mkdir",
+// "remove", "removeall" or "rename", and the name, or the old path for
+// "rename", passed to it. When the plan returns an error, the operation is
+// not called and an error of the [fs.PathError] type wrapping the planned
+// error is returned.
+func Faults(plan func(op, name string) error) Middleware {
+	return func(fsys fs.FS) fs.FS {
+		call := func(op, name string, fn func() error) error {
+			if err := plan(op, name); err != nil {
+				return &fs.PathError{Op: op, Path: name, Err: err}
+			}
+			return fn()
+		}
+		return mwFS{fsys: fsys, call: call}.wrap()
+	}
+}
+
+// Latency returns a middleware delaying every operation on the file system
+// by the given duration, like a slow network mount. Use [WithLatency] to
+// delay the reads and writes of the files.
+func Latency(d time.Duration) Middleware {
+	return func(fsys fs.FS) fs.FS {
+		call := func(_, _ string, fn func() error) error {
+			time.Sleep(d)
+			return fn()
+		}
+		return mwFS{fsys: fsys, call: call}.wrap()
+	}
+}
+
+// Quota returns a middleware limiting the total size of the regular files in
+// a [WriteFS] file system to the given number of bytes. The WriteFile calls
+// which would exceed the quota return an error of the [fs.PathError] type with
+// [syscall.ENOSPC]. The usage is computed by walking the file system before
+// every WriteFile call. Other file systems are returned unchanged.
+func Quota(size int64) Middleware {
+	return func(fsys fs.FS) fs.FS {
+		if _, ok := fsys.(WriteFS); !ok {
+			return fsys
+		}
+		write := func(name string, n int64) error {
+			used, err := usageFS(fsys, name)
+			if err != nil {
+				return err
+			}
+			if used+n > size {
+				return &fs.PathError{
+					Op:   "writefile",
+					Path: name,
+					Err:  syscall.ENOSPC,
+				}
+			}
+			return nil
+		}
+		return mwWriteFS{mwFS{fsys: fsys, write: write}}
+	}
+}
+
+// mwFS is the file system returned by the middlewares.
+type mwFS struct {
+	fsys fs.FS
+
+	// call intercepts the operation op on the name, which is done by
+	// calling fn. When nil, fn is called directly.
+	call func(op, name string, fn func() error) error
+
+	// file wraps the file opened with the name. When nil, the files are not
+	// wrapped.
+	file func(name string, f fs.File) fs.File
+
+	// write checks writing n bytes to the name with WriteFile before it's
+	// called. When nil, there is no check.
+	write func(name string, n int64) error
+}
+
+// wrap returns the instance as [WriteFS] when the wrapped file system
+// implements it.
+func (m mwFS) wrap() fs.FS {
+	if _, ok := m.fsys.(WriteFS); ok {
+		return mwWriteFS{m}
+	}
+	return m
+}
+
+// Open implements [fs.FS] interface.
+func (m mwFS) Open(name string) (fs.File, error) {
+	var f fs.File
+	err := m.do("open", name, func() (err error) {
+		f, err = m.fsys.Open(name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m.file != nil {
+		f = m.file(name, f)
+	}
+	return f, nil
+}
+
+// Stat implements [fs.StatFS] interface.
+func (m mwFS) Stat(name string) (fs.FileInfo, error) {
+	var info fs.FileInfo
+	err := m.do("stat", name, func() (err error) {
+		info, err = fs.Stat(m.fsys, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ReadDir implements [fs.ReadDirFS] interface.
+func (m mwFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var ets []fs.DirEntry
+	err := m.do("readdir", name, func() (err error) {
+		ets, err = fs.ReadDir(m.fsys, name)
+		return err
+	})
+	return ets, err
+}
+
+// ReadFile implements [fs.ReadFileFS] interface.
+func (m mwFS) ReadFile(name string) ([]byte, error) {
+	var data []byte
+	err := m.do("readfile", name, func() (err error) {
+		data, err = fs.ReadFile(m.fsys, name)
+		return err
+	})
+	return data, err
+}
+
+// ReadLink implements [fs.ReadLinkFS] interface.
+func (m mwFS) ReadLink(name string) (string, error) {
+	var dst string
+	err := m.do("readlink", name, func() (err error) {
+		dst, err = fs.ReadLink(m.fsys, name)
+		return err
+	})
+	return dst, err
+}
+
+// Lstat implements [fs.ReadLinkFS] interface.
+func (m mwFS) Lstat(name string) (fs.FileInfo, error) {
+	var info fs.FileInfo
+	err := m.do("lstat", name, func() (err error) {
+		info, err = fs.Lstat(m.fsys, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// do calls fn doing the operation through the call function.
+func (m mwFS) do(op, name string, fn func() error) error {
+	if m.call == nil {
+		return fn()
+	}
+	return m.call(op, name, fn)
+}
+
+// mwWriteFS is the file system returned by the middlewares for the wrapped
+// file systems implementing [WriteFS].
+type mwWriteFS struct{ mwFS }
+
+// WriteFile implements [WriteFS] interface.
+func (m mwWriteFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return m.do("writefile", name, func() error {
+		if m.write != nil {
+			if err := m.write(name, int64(len(data))); err != nil {
+				return err
+			}
+		}
+		return m.fsys.(WriteFS).WriteFile(name, data, perm)
+	})
+}
+
+// MkdirAll implements [WriteFS] interface.
+func (m mwWriteFS) MkdirAll(name string, perm fs.FileMode) error {
+	return m.do("mkdir", name, func() error {
+		return m.fsys.(WriteFS).MkdirAll(name, perm)
+	})
+}
+
+// Remove implements [WriteFS] interface.
+func (m mwWriteFS) Remove(name string) error {
+	return m.do("remove", name, func() error {
+		return m.fsys.(WriteFS).Remove(name)
+	})
+}
+
+// RemoveAll implements [WriteFS] interface.
+func (m mwWriteFS) RemoveAll(name string) error {
+	return m.do("removeall", name, func() error {
+		return m.fsys.(WriteFS).RemoveAll(name)
+	})
+}
+
+// Rename implements [WriteFS] interface.
+func (m mwWriteFS) Rename(oldpath, newpath string) error {
+	return m.do("rename", oldpath, func() error {
+		return m.fsys.(WriteFS).Rename(oldpath, newpath)
+	})
+}
+
+// usageFS returns the total size of the regular files in the file system,
+// not counting the file with the given name.
+func usageFS(fsys fs.FS, name string) (int64, error) {
+	var used int64
+	walk := func(pth string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || pth == name {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		used += info.Size()
+		return nil
+	}
+	err := fs.WalkDir(fsys, ".", walk)
+	return used, err
+}
+
+// readOnlyFile returns the file opened with the name with its write methods
+// hidden. The directories keep the [fs.ReadDirFile] methods, and the files
+// implementing [io.Seeker] keep the [io.Seeker], [io.ReaderAt] and
+// [io.WriterTo] methods, so, for example, [net/http.ServeContent] works.
+func readOnlyFile(name string, f fs.File) fs.File {
+	if d, ok := f.(fs.ReadDirFile); ok && isDir(f) {
+		return roDir{d}
+	}
+	if _, ok := f.(io.Seeker); ok {
+		return roSeeker{roFile{File: f, name: name}}
+	}
+	return roFile{File: f, name: name}
+}
+
+// roFile is a wrapper that hides all but the [fs.File] methods.
+type roFile struct {
+	fs.File
+	name string // Name passed to Open.
+}
+
+// roSeeker is a wrapper that hides all but the [fs.File] methods and the
+// read methods of the seekable files.
+type roSeeker struct{ roFile }
+
+// Seek implements [io.Seeker] interface.
+func (f roSeeker) Seek(offset int64, whence int) (int64, error) {
+	return f.File.(io.Seeker).Seek(offset, whence)
+}
+
+// ReadAt implements [io.ReaderAt] interface.
+func (f roSeeker) ReadAt(p []byte, off int64) (int, error) {
+	return readAt(f.File, f.name, p, off)
+}
+
+// WriteTo implements [io.WriterTo] interface.
+func (f roSeeker) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(f.File, w)
+}
+
+// roDir is a wrapper that hides all but the [fs.ReadDirFile] methods.
+type roDir struct{ fs.ReadDirFile }
+
+// isDir reports whether the file is a directory.
+func isDir(f fs.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.IsDir()
+}
+
+// readAt reads from the file opened with the name at the offset when it
+// implements [io.ReaderAt]. Otherwise, it returns an error of the
+// [fs.PathError] type with [errors.ErrUnsupported].
+func readAt(f fs.File, name string, p []byte, off int64) (int, error) {
+	if r, ok := f.(io.ReaderAt); ok {
+		return r.ReadAt(p, off)
+	}
+	return 0, &fs.PathError{Op: "read", Path: name, Err: errors.ErrUnsupported}
+}
+
+// writeTo writes the content of the file to w using its [io.WriterTo]
+// method when it has one, or [io.Copy] otherwise.
+func writeTo(f fs.File, w io.Writer) (int64, error) {
+	if wt, ok := f.(io.WriterTo); ok {
+		return wt.WriteTo(w)
+	}
+	return io.Copy(w, struct{ io.Reader }{f})
+}
@@ -0,0 +1,417 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_Chain(t *testing.T) {
+	t.Run("no middlewares", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirMem().FS()
+
+		// --- When ---
+		have := Chain(fsys)
+
+		// --- Then ---
+		assert.Equal(t, fsys, have)
+	})
+
+	t.Run("first middleware is the outermost", func(t *testing.T) {
+		// --- Given ---
+		var evs []Event
+		rec := func(ev Event) { evs = append(evs, ev) }
+		plan := func(op, name string) error {
+			if name == "file0" {
+				return syscall.EIO
+			}
+			return nil
+		}
+
+		// --- When ---
+		fsys := Chain(tstDirMem().FS(), Trace(rec), Faults(plan))
+
+		// --- Then ---
+		_, err := fsys.Open("file0")
+		assert.ErrorIs(t, syscall.EIO, err)
+		assert.Len(t, 1, evs)
+		assert.ErrorIs(t, syscall.EIO, evs[0].Err)
+	})
+
+	t.Run("inner middleware is not called", func(t *testing.T) {
+		// --- Given ---
+		var evs []Event
+		rec := func(ev Event) { evs = append(evs, ev) }
+		plan := func(op, name string) error { return syscall.EIO }
+
+		// --- When ---
+		fsys := Chain(tstDirMem().FS(), Faults(plan), Trace(rec))
+
+		// --- Then ---
+		_, err := fsys.Open("file0")
+		assert.ErrorIs(t, syscall.EIO, err)
+		assert.Len(t, 0, evs)
+	})
+
+	t.Run("any file system", func(t *testing.T) {
+		// --- Given ---
+		mfs := fstest.MapFS{"a/b": &fstest.MapFile{Data: []byte("abc")}}
+		rec := func(ev Event) {}
+		plan := func(op, name string) error { return nil }
+
+		// --- When ---
+		fsys := Chain(mfs, ReadOnly(), Trace(rec), Faults(plan), Latency(0))
+
+		// --- Then ---
+		assert.NoError(t, fstest.TestFS(fsys, "a/b"))
+	})
+
+	t.Run("keeps capabilities", func(t *testing.T) {
+		// --- Given ---
+		rec := func(ev Event) {}
+
+		// --- When ---
+		fsys := Chain(tstDirMem().DirFS(), ReadOnly(), Trace(rec))
+
+		// --- Then ---
+		have := Capabilities(fsys)
+		assert.Equal(t, Capabilities(tstDirMem().DirFS()), have)
+		data := must.Value(fs.ReadFile(fsys, "sub/sub2/file6"))
+		assert.Equal(t, "file6", string(data))
+	})
+
+	t.Run("ReadFile keeps the result for directories", func(t *testing.T) {
+		// --- Given ---
+		rec := func(ev Event) {}
+		fsys := Chain(tstDirMem().FS(), Trace(rec))
+
+		// --- When ---
+		have, err := fs.ReadFile(fsys, "sub")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EISDIR, err)
+		assert.NotNil(t, have)
+		assert.Len(t, 0, have)
+	})
+}
+
+func Test_ReadOnly(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(tstDirMem().FS(), ReadOnly())
+
+		// --- When ---
+		have, err := fsys.Open("file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, ok := have.(io.Writer)
+		assert.False(t, ok)
+		assert.Equal(t, "file0", string(must.Value(io.ReadAll(have))))
+	})
+
+	t.Run("file keeps the read methods", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(tstDirMem().FS(), ReadOnly())
+
+		// --- When ---
+		have := must.Value(fsys.Open("file0"))
+
+		// --- Then ---
+		_, isWA := have.(io.WriterAt)
+		assert.False(t, isWA)
+		_, isRF := have.(io.ReaderFrom)
+		assert.False(t, isRF)
+		_, isRD := have.(fs.ReadDirFile)
+		assert.False(t, isRD)
+
+		off := must.Value(have.(io.Seeker).Seek(2, io.SeekStart))
+		assert.Equal(t, int64(2), off)
+		buf := make([]byte, 3)
+		must.Value(have.(io.ReaderAt).ReadAt(buf, 1))
+		assert.Equal(t, "ile", string(buf))
+		out := &bytes.Buffer{}
+		must.Value(have.(io.WriterTo).WriteTo(out))
+		assert.Equal(t, "le0", out.String())
+	})
+
+	t.Run("http.ServeContent range request", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(tstDirMem().FS(), ReadOnly())
+		fil := must.Value(fsys.Open("file0"))
+		rs, ok := fil.(io.ReadSeeker)
+		assert.True(t, ok)
+		req := httptest.NewRequest(http.MethodGet, "/file0", nil)
+		req.Header.Set("Range", "bytes=1-2")
+		rec := httptest.NewRecorder()
+
+		// --- When ---
+		http.ServeContent(rec, req, "file0", time.Time{}, rs)
+
+		// --- Then ---
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "il", rec.Body.String())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(tstDirMem().FS(), ReadOnly())
+
+		// --- When ---
+		have, err := fsys.Open("sub")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, ok := have.(io.Writer)
+		assert.False(t, ok)
+		dir, ok := have.(fs.ReadDirFile)
+		assert.True(t, ok)
+		assert.Len(t, 3, must.Value(dir.ReadDir(-1)))
+	})
+
+	t.Run("file without ReadDir", func(t *testing.T) {
+		// --- Given ---
+		mfs := fstest.MapFS{"file": &fstest.MapFile{Data: []byte("abc")}}
+		fsys := Chain(mfs, ReadOnly())
+
+		// --- When ---
+		have, err := fsys.Open("file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, ok := have.(fs.ReadDirFile)
+		assert.False(t, ok)
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(tstDirMem().FS(), ReadOnly())
+
+		// --- When ---
+		have, err := fsys.Open("not-existing")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_Trace(t *testing.T) {
+	// --- Given ---
+	var evs []Event
+	rec := func(ev Event) { evs = append(evs, ev) }
+	fsys := Chain(tstDirMem().FS(), Trace(rec))
+
+	// --- When ---
+	_, errO := fsys.Open("file0")
+	_, errS := fs.Stat(fsys, "sub")
+	_, errD := fs.ReadDir(fsys, "sub")
+	_, errF := fs.ReadFile(fsys, "not-existing")
+
+	// --- Then ---
+	assert.NoError(t, errO)
+	assert.NoError(t, errS)
+	assert.NoError(t, errD)
+	assert.ErrorIs(t, fs.ErrNotExist, errF)
+	want := []Event{
+		{Op: "open", Name: "file0"},
+		{Op: "stat", Name: "sub"},
+		{Op: "readdir", Name: "sub"},
+		{Op: "readfile", Name: "not-existing", Err: errF},
+	}
+	assert.Equal(t, want, evs)
+}
+
+func Test_Faults(t *testing.T) {
+	t.Run("injects errors", func(t *testing.T) {
+		// --- Given ---
+		plan := func(op, name string) error {
+			if op == "readfile" && name == "sub/file3" {
+				return syscall.EIO
+			}
+			return nil
+		}
+		fsys := Chain(tstDirMem().FS(), Faults(plan))
+
+		// --- When ---
+		have, err := fs.ReadFile(fsys, "sub/file3")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "readfile", e.Op)
+		assert.Equal(t, "sub/file3", e.Path)
+		assert.Equal(t, syscall.EIO, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("other operations", func(t *testing.T) {
+		// --- Given ---
+		plan := func(op, name string) error {
+			return errors.New(op)
+		}
+		fsys := Chain(tstDirMem().FS(), Faults(plan))
+
+		// --- When ---
+		_, errO := fsys.Open("file0")
+		_, errS := fs.Stat(fsys, "file0")
+		_, errD := fs.ReadDir(fsys, "sub")
+
+		// --- Then ---
+		assert.ErrorEqual(t, "open file0: open", errO)
+		assert.ErrorEqual(t, "stat file0: stat", errS)
+		assert.ErrorEqual(t, "readdir sub: readdir", errD)
+	})
+
+	t.Run("no faults", func(t *testing.T) {
+		// --- Given ---
+		plan := func(op, name string) error { return nil }
+		fsys := Chain(tstDirMem().FS(), Faults(plan))
+
+		// --- When ---
+		have, err := fs.ReadFile(fsys, "sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file3", string(have))
+	})
+}
+
+func Test_Latency(t *testing.T) {
+	// --- Given ---
+	fsys := Chain(tstDirMem().FS(), Latency(10*time.Millisecond))
+
+	// --- When ---
+	start := time.Now()
+	_, err := fs.Stat(fsys, "file0")
+
+	// --- Then ---
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 10*time.Millisecond)
+}
+
+func Test_Quota(t *testing.T) {
+	t.Run("within quota", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(NewSyncFS(tstDirMem()), Quota(40)).(WriteFS)
+
+		// --- When ---
+		err := fsys.WriteFile("new", []byte("abcde"), 0600)
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("replaced file is not counted", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(NewSyncFS(tstDirMem()), Quota(35)).(WriteFS)
+
+		// --- When ---
+		err := fsys.WriteFile("file0", []byte("abcde"), 0600)
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("not writable file system", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirMem().DirFS()
+
+		// --- When ---
+		have := Chain(fsys, Quota(0))
+
+		// --- Then ---
+		assert.Equal(t, fsys, have)
+	})
+
+	t.Run("error - exceeded", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fsys := Chain(NewSyncFS(dir), Quota(38)).(WriteFS)
+
+		// --- When ---
+		err := fsys.WriteFile("sub/new", []byte("abcd"), 0600)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "writefile", e.Op)
+		assert.Equal(t, "sub/new", e.Path)
+		assert.Equal(t, syscall.ENOSPC, e.Err)
+		_, err = open(dir, "sub/new")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+}
+
+func Test_Chain_WriteFS(t *testing.T) {
+	t.Run("middlewares keep WriteFS", func(t *testing.T) {
+		// --- Given ---
+		var evs []Event
+		rec := func(ev Event) { evs = append(evs, ev) }
+		plan := func(op, name string) error { return nil }
+		dir := tstDirMem()
+
+		// --- When ---
+		fsys := Chain(NewSyncFS(dir), Trace(rec), Faults(plan), Latency(0))
+
+		// --- Then ---
+		wfs, ok := fsys.(WriteFS)
+		assert.True(t, ok)
+		must.Nil(wfs.MkdirAll("a", 0700))
+		must.Nil(wfs.WriteFile("a/file", nil, 0600))
+		must.Nil(wfs.Rename("a/file", "a/moved"))
+		must.Nil(wfs.Remove("a/moved"))
+		must.Nil(wfs.RemoveAll("a"))
+		want := []Event{
+			{Op: "mkdir", Name: "a"},
+			{Op: "writefile", Name: "a/file"},
+			{Op: "rename", Name: "a/file"},
+			{Op: "remove", Name: "a/moved"},
+			{Op: "removeall", Name: "a"},
+		}
+		assert.Equal(t, want, evs)
+		assert.Len(t, 4, dir.entries)
+	})
+
+	t.Run("ReadOnly hides WriteFS", func(t *testing.T) {
+		// --- When ---
+		fsys := Chain(NewSyncFS(tstDirMem()), ReadOnly())
+
+		// --- Then ---
+		_, ok := fsys.(WriteFS)
+		assert.False(t, ok)
+	})
+
+	t.Run("Faults injects write errors", func(t *testing.T) {
+		// --- Given ---
+		plan := func(op, name string) error {
+			if op == "remove" {
+				return syscall.EBUSY
+			}
+			return nil
+		}
+		dir := tstDirMem()
+		fsys := Chain(NewSyncFS(dir), Faults(plan)).(WriteFS)
+
+		// --- When ---
+		err := fsys.Remove("file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EBUSY, err)
+		must.Value(open(dir, "file0"))
+	})
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"hash/crc32"
+	"io/fs"
+)
+
+// ErrChecksum is returned when the file content does not match the checksum
+// maintained in the checksum verification mode.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// WithFileChecksum is a [File] constructor function option turning on the
+// checksum verification mode. In this mode, the file maintains a CRC-32
+// checksum of its content which is updated on every write and verified on
+// every read and on [File.Close]. It detects content changes made outside the
+// [File] methods, for example, through slices passed to [FileWith] or
+// returned by [File.Release] which are still used after the ownership
+// transfer.
+//
+// Every write and read recalculates the checksum of the whole content, so the
+// mode should be used only for debugging.
+func WithFileChecksum(fil *File) {
+	fil.csum = true
+	fil.sum = crc32.ChecksumIEEE(fil.buf)
+}
+
+// VerifyChecksum verifies the file content against the checksum. Returns nil
+// when the checksum verification mode is not turned on with the
+// [WithFileChecksum] option. Otherwise, returns an error of the [fs.PathError]
+// type with [ErrChecksum] when the content does not match the checksum.
+func (fil *File) VerifyChecksum() error { return fil.verifySum("verify") }
+
+// updateSum updates the content checksum when the checksum verification mode
+// is on.
+func (fil *File) updateSum() {
+	if fil.csum {
+		fil.sum = crc32.ChecksumIEEE(fil.buf)
+	}
+}
+
+// verifySum verifies the content checksum when the checksum verification mode
+// is on. The op is used as the operation name in the returned error.
+func (fil *File) verifySum(op string) error {
+	if !fil.csum || fil.stub {
+		return nil
+	}
+	if crc32.ChecksumIEEE(fil.buf) != fil.sum {
+		return fil.hookErr(&fs.PathError{
+			Op:   op,
+			Path: fil.path(),
+			Err:  ErrChecksum,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithFileChecksum(t *testing.T) {
+	// --- Given ---
+	fil := &File{buf: []byte{0, 1, 2}}
+
+	// --- When ---
+	WithFileChecksum(fil)
+
+	// --- Then ---
+	assert.True(t, fil.csum)
+	assert.Equal(t, crc32.ChecksumIEEE([]byte{0, 1, 2}), fil.sum)
+}
+
+func Test_File_VerifyChecksum(t *testing.T) {
+	t.Run("checksum mode off", func(t *testing.T) {
+		// --- Given ---
+		content := []byte{0, 1, 2}
+		fil := MustFileWith("file", content)
+		content[0] = 42
+
+		// --- When ---
+		err := fil.VerifyChecksum()
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2}, WithFileChecksum)
+
+		// --- When ---
+		err := fil.VerifyChecksum()
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("error - content changed outside", func(t *testing.T) {
+		// --- Given ---
+		content := []byte{0, 1, 2}
+		fil := MustFileWith("file", content, WithFileChecksum)
+		content[0] = 42
+
+		// --- When ---
+		err := fil.VerifyChecksum()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "verify", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, ErrChecksum, e.Err)
+	})
+}
+
+func Test_File_checksum(t *testing.T) {
+	t.Run("updated by writes", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithFileChecksum)
+
+		// --- When ---
+		must.Value(fil.Write([]byte{0, 1, 2}))
+		must.Nil(fil.WriteByte(3))
+		must.Value(fil.WriteAt([]byte{4}, 10))
+		must.Value(fil.ReadFrom(bytes.NewReader([]byte{5, 6})))
+		must.Nil(fil.Truncate(8))
+
+		// --- Then ---
+		assert.Equal(t, crc32.ChecksumIEEE(fil.buf), fil.sum)
+		assert.NoError(t, fil.Close())
+	})
+
+	t.Run("updated by release", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2}, WithFileChecksum)
+
+		// --- When ---
+		buf := fil.Release()
+
+		// --- Then ---
+		buf[0] = 42
+		assert.NoError(t, fil.VerifyChecksum())
+	})
+
+	t.Run("error - Read", func(t *testing.T) {
+		// --- Given ---
+		content := []byte{0, 1, 2}
+		fil := MustFileWith("file", content, WithFileChecksum)
+		content[0] = 42
+
+		// --- When ---
+		have, err := io.ReadAll(fil)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, ErrChecksum, e.Err)
+		assert.Len(t, 0, have)
+	})
+
+	t.Run("error - ReadByte", func(t *testing.T) {
+		// --- Given ---
+		content := []byte{0, 1, 2}
+		fil := MustFileWith("file", content, WithFileChecksum)
+		content[0] = 42
+
+		// --- When ---
+		_, err := fil.ReadByte()
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrChecksum, err)
+		assert.Equal(t, 0, fil.off)
+	})
+
+	t.Run("error - WriteTo", func(t *testing.T) {
+		// --- Given ---
+		content := []byte{0, 1, 2}
+		fil := MustFileWith("file", content, WithFileChecksum)
+		content[0] = 42
+
+		// --- When ---
+		n, err := fil.WriteTo(&bytes.Buffer{})
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrChecksum, err)
+		assert.Equal(t, int64(0), n)
+	})
+
+	t.Run("error - Close", func(t *testing.T) {
+		// --- Given ---
+		content := []byte{0, 1, 2}
+		fil := MustFileWith("file", content, WithFileChecksum)
+		content[0] = 42
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "close", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, ErrChecksum, e.Err)
+	})
+}
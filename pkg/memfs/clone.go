@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"slices"
+)
+
+// Clone returns a deep copy of the file or the directory with its whole tree,
+// so a pristine fixture can be built once and reused across parallel tests
+// without one test seeing the modifications made by another. The copy has
+// the same names, contents, modes, times, symbolic link destinations,
+// attribute flags and options as the original, and no parent, so it can be
+// added to another directory with [File.AddFile]. The options set on the
+// parents of the original, like [WithReadOnly], don't apply to the copy.
+//
+// The state of the open handles is not copied: the copy has no open handles
+// (see [File.OpenCount]), byte-range locks or tails, its offset is zero, its
+// I/O statistics start from zero, and it's not in the write-through mode (see
+// [File.MirrorTo]). The lazily loaded files share the content source with
+// the original, the broadcast files (see [File.AddBroadcast]) share the
+// broadcast, and the sequences set with [WithTempSeed] and
+// [WithSetTimeSkew] continue from the same point in both trees.
+func (fil *File) Clone() *File { return fil.clone(false) }
+
+// Freeze returns an immutable point-in-time view of the file or the directory
+// with its whole tree, for cheap before and after comparisons of large
+// fixtures. Like with [File.Clone], the tree structure is copied, but the
+// file contents are shared between the view and the original until either
+// of them is modified, and only then the modified content is copied. The
+// view is read-only (see [WithReadOnly]), so the changes made to the
+// original after the call are never visible in the view. The view is a
+// regular [File], so it can be read, compared with the original using [Diff]
+// or saved with [File.Snapshot].
+func (fil *File) Freeze() *File {
+	view := fil.clone(true)
+	view.rdonly = true
+	return view
+}
+
+// clone returns a deep copy of the instance and its tree. When share is
+// true, the buffers are shared with the copy until modified. The tree is
+// copied with a stack of the directories being copied instead of recursion,
+// so deep trees can't exhaust the goroutine stack.
+func (fil *File) clone(share bool) *File {
+	type pair struct {
+		src *File // The copied directory.
+		dst *File // The copy of the directory.
+	}
+	root := fil.cloneNode(share)
+	stack := []pair{{src: fil, dst: root}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, ent := range top.src.entries {
+			sub := ent.cloneNode(share)
+			sub.parent = top.dst
+			top.dst.entries = append(top.dst.entries, sub)
+			if len(ent.entries) > 0 {
+				stack = append(stack, pair{src: ent, dst: sub})
+			}
+		}
+	}
+	return root
+}
+
+// cloneNode returns a copy of the instance without its entries, see
+// [File.clone].
+func (fil *File) cloneNode(share bool) *File {
+	buf := fil.buf
+	if share {
+		fil.shared = buf != nil
+	} else {
+		buf = slices.Clone(buf)
+	}
+	cp := fil.cloneOpts()
+	cp.buf = buf
+	cp.shared = share && buf != nil
+	cp.info = fil.info
+	cp.stub = fil.stub
+	cp.sum = fil.sum
+	cp.dirty = fil.dirty
+	cp.prev = slices.Clone(fil.prev)
+	cp.lazy = fil.lazy
+	cp.loaded = fil.loaded
+	cp.link = fil.link
+	cp.atime = fil.atime
+	cp.holes = slices.Clone(fil.holes)
+	cp.bcast = fil.bcast
+	return cp
+}
+
+// cloneOpts returns a new empty file with the name, mode, flags, attribute
+// flags and options of the instance.
+func (fil *File) cloneOpts() *File {
+	cp := &File{
+		flag: fil.flag,
+		info: FileInfo{
+			name: fil.info.name,
+			mode: fil.info.mode,
+		},
+		csum:     fil.csum,
+		errHook:  fil.errHook,
+		scan:     fil.scan,
+		attr:     fil.attr,
+		keep:     fil.keep,
+		statHook: fil.statHook,
+		nostats:  fil.nostats,
+		growth:   fil.growth,
+		exact:    fil.exact,
+		block:    fil.block,
+		rdonly:   fil.rdonly,
+		once:     fil.once,
+		quota:    fil.quota,
+		latency:  fil.latency,
+		rchunk:   fil.rchunk,
+		wchunk:   fil.wchunk,
+		peek:     fil.peek,
+		lenient:  fil.lenient,
+		limits:   fil.limits,
+		hops:     fil.hops,
+		sparse:   fil.sparse,
+		holeHook: fil.holeHook,
+
+		nocase:     fil.nocase,
+		renameHook: fil.renameHook,
+		sharing:    fil.sharing,
+		progHook:   fil.progHook,
+		throt:      fil.throt,
+	}
+	if fil.rnd != nil {
+		rnd := *fil.rnd
+		cp.rnd = &rnd
+	}
+	if fil.skew != nil {
+		cp.skew = fil.skew.clone()
+	}
+	return cp
+}
@@ -0,0 +1,298 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Clone(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		tim := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+		fil := MustFileWith("file", []byte("abc"), WithFileMode(0755))
+		must.Nil(fil.Chtimes(tim, tim))
+		fil.SetAttr(AttrAppendOnly)
+
+		// --- When ---
+		have := fil.Clone()
+
+		// --- Then ---
+		assert.NotSame(t, fil, have)
+		assert.Equal(t, "file", have.Name())
+		assert.Equal(t, "abc", have.String())
+		assert.Equal(t, fs.FileMode(0755), have.Mode())
+		assert.Equal(t, tim, have.ModTime())
+		assert.Equal(t, tim, have.atime)
+		assert.Equal(t, AttrAppendOnly, have.Attr())
+		assert.Nil(t, have.parent)
+	})
+
+	t.Run("content is not shared", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"))
+		have := fil.Clone()
+
+		// --- When ---
+		must.Value(have.WriteAt([]byte("X"), 0))
+
+		// --- Then ---
+		assert.Equal(t, "abc", fil.String())
+		assert.Equal(t, "Xbc", have.String())
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub/file3", "link"))
+
+		// --- When ---
+		have := dir.Clone()
+
+		// --- Then ---
+		assert.Equal(t, must.Value(dir.List()), must.Value(have.List()))
+		tol := Tolerance{}
+		assert.Nil(t, must.Value(Diff(dir.DirFS(), have.DirFS(), tol)))
+		sub := must.Value(open(have, "sub"))
+		assert.Same(t, have, sub.parent)
+		file5 := must.Value(open(have, "sub/sub2/file5"))
+		assert.Same(t, must.Value(open(have, "sub/sub2")), file5.parent)
+		assert.NotSame(t, must.Value(open(dir, "sub/sub2/file5")), file5)
+	})
+
+	t.Run("tree is not shared", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		have := dir.Clone()
+
+		// --- When ---
+		must.Nil(have.WriteFile("sub/file3", []byte("X"), 0600))
+		must.Nil(have.RemoveAll("sub/sub2"))
+		must.Nil(have.WriteFile("new", nil, 0600))
+
+		// --- Then ---
+		assert.Equal(t, "file3", must.Value(open(dir, "sub/file3")).String())
+		assert.Len(t, 2, must.Value(open(dir, "sub/sub2")).entries)
+		_, err := open(dir, "new")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("subdirectory is detached", func(t *testing.T) {
+		// --- Given ---
+		sub := must.Value(open(tstDirMem(), "sub"))
+		root := NewRoot()
+
+		// --- When ---
+		have := sub.Clone()
+
+		// --- Then ---
+		assert.Nil(t, have.parent)
+		assert.NoError(t, root.AddFile(have))
+		file5 := must.Value(open(root, "sub/sub2/file5"))
+		assert.Equal(t, "file5", file5.String())
+	})
+
+	t.Run("options are copied", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithReadOnly)
+
+		// --- When ---
+		have := dir.Clone()
+
+		// --- Then ---
+		err := have.WriteFile("file", nil, 0600)
+		assert.ErrorIs(t, syscall.EROFS, err)
+	})
+
+	t.Run("symbolic link limit is copied", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithMaxSymlinks(1))
+		must.Nil(dir.WriteFile("file", nil, 0600))
+		must.Nil(dir.Symlink("file", "link0"))
+		must.Nil(dir.Symlink("link0", "link1"))
+
+		// --- When ---
+		have := dir.Clone()
+
+		// --- Then ---
+		_, err := have.ReadFile("link1")
+		assert.ErrorIs(t, syscall.ELOOP, err)
+	})
+
+	t.Run("read and write chunks are copied", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithMaxReadChunk(2), WithMaxWriteChunk(3))
+
+		// --- When ---
+		have := fil.Clone()
+
+		// --- Then ---
+		assert.Equal(t, 2, have.readChunk())
+		assert.Equal(t, 3, have.writeChunk())
+	})
+
+	t.Run("options of the parents are not copied", func(t *testing.T) {
+		// --- Given ---
+		dir := tstMount(WithReadOnly)
+		fil := must.Value(open(dir, "mnt/file"))
+
+		// --- When ---
+		have := fil.Clone()
+
+		// --- Then ---
+		n, err := have.Write([]byte("X"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("handle state is not copied", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file0"))
+		fil.opened()
+		must.Value(fil.Seek(2, 0))
+		must.Nil(dir.MirrorTo(t.TempDir()))
+
+		// --- When ---
+		have := dir.Clone()
+
+		// --- Then ---
+		cp := must.Value(open(have, "file0"))
+		assert.Equal(t, 0, cp.OpenCount())
+		assert.Equal(t, 0, cp.off)
+		assert.Equal(t, "", have.mirror)
+		assert.Equal(t, "", cp.mirrorPath())
+	})
+
+	t.Run("temp names continue from the same point", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithTempSeed(42))
+		must.Value(dir.MkdirTemp("", "d"))
+		have := dir.Clone()
+
+		// --- When ---
+		want := must.Value(dir.MkdirTemp("", "d"))
+		name := must.Value(have.MkdirTemp("", "d"))
+
+		// --- Then ---
+		assert.Equal(t, want, name)
+	})
+
+	t.Run("clock skew continues from the same point", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithSetTimeSkew(0, time.Hour, 42))
+		must.Nil(dir.Touch("file"))
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		must.Nil(dir.ChtimesAt("file", time.Time{}, mtime))
+		have := dir.Clone()
+
+		// --- When ---
+		must.Nil(dir.ChtimesAt("file", time.Time{}, mtime))
+		must.Nil(have.ChtimesAt("file", time.Time{}, mtime))
+
+		// --- Then ---
+		want := must.Value(open(dir, "file")).ModTime()
+		assert.Equal(t, want, must.Value(open(have, "file")).ModTime())
+		assert.NotSame(t, dir.skew, have.skew)
+	})
+
+	t.Run("lazy file", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte("abc"), &loads)
+
+		// --- When ---
+		have := fil.Clone()
+
+		// --- Then ---
+		assert.True(t, have.unloaded())
+		assert.Equal(t, "abc", string(must.Value(io.ReadAll(have))))
+		assert.Equal(t, 1, loads)
+		assert.True(t, fil.unloaded())
+	})
+}
+
+func Test_File_Freeze(t *testing.T) {
+	t.Run("shares content", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"))
+
+		// --- When ---
+		have := fil.Freeze()
+
+		// --- Then ---
+		assert.NotSame(t, fil, have)
+		assert.Equal(t, "abc", have.String())
+		assert.True(t, &fil.buf[0] == &have.buf[0])
+		assert.True(t, fil.shared)
+		assert.True(t, have.readOnly())
+	})
+
+	t.Run("write to the original copies content", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"))
+		have := fil.Freeze()
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("X"), 0))
+
+		// --- Then ---
+		assert.Equal(t, "Xbc", fil.String())
+		assert.Equal(t, "abc", have.String())
+		assert.False(t, fil.shared)
+	})
+
+	t.Run("truncate of the original copies content", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"))
+		have := fil.Freeze()
+
+		// --- When ---
+		err := fil.Truncate(1)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "a", fil.String())
+		assert.Equal(t, "abc", have.String())
+	})
+
+	t.Run("error - view is read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		have := dir.Freeze()
+
+		// --- When ---
+		err := have.WriteFile("sub/file3", []byte("X"), 0644)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Equal(t, "file3", string(must.Value(have.ReadFile("sub/file3"))))
+	})
+
+	t.Run("tree changes are not visible in the view", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		have := dir.Freeze()
+
+		// --- When ---
+		must.Nil(dir.Remove("file0"))
+		must.Nil(dir.WriteFile("sub/file3", []byte("changed"), 0644))
+		must.Nil(dir.WriteFile("new", nil, 0644))
+
+		// --- Then ---
+		diffs := must.Value(Diff(have.FS(), dir.FS(), Tolerance{}))
+		assert.Len(t, 3, diffs)
+		assert.Equal(t, "file0", diffs[0].Path)
+		assert.Equal(t, "new", diffs[1].Path)
+		assert.Equal(t, "sub/file3", diffs[2].Path)
+		assert.Equal(t, "file3", string(must.Value(have.ReadFile("sub/file3"))))
+	})
+}
@@ -5,6 +5,8 @@ package memfs
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
@@ -34,6 +36,24 @@ func Test_TstDirectory(t *testing.T) {
 	t.Run("KitDir", func(t *testing.T) { TstDirectory(t, dir, kitDir.FS()) })
 }
 
+func Test_Nlink(t *testing.T) {
+	// --- Given ---
+	osDir := must.Value(os.OpenRoot(tstDirOS(t)))
+	kitDir := tstDirMem()
+
+	for _, name := range []string{".", "sub", "sub/sub2", "file0"} {
+		t.Run(name, func(t *testing.T) {
+			// --- When ---
+			osInfo := must.Value(fs.Stat(osDir.FS(), name))
+			kitInfo := must.Value(fs.Stat(kitDir, name))
+
+			// --- Then ---
+			want := uint64(osInfo.Sys().(*syscall.Stat_t).Nlink)
+			assert.Equal(t, want, kitInfo.Sys().(*SysInfo).Nlink)
+		})
+	}
+}
+
 // TstFile performs tests on instances of a file created by the create function.
 func TstFile(t *testing.T, create fileCreator) {
 	t.Helper()
@@ -127,6 +147,38 @@ func TstFile(t *testing.T, create fileCreator) {
 		assert.Equal(t, []byte{}, must.Value(io.ReadAll(fil)))
 	})
 
+	t.Run("Write - empty with O_APPEND", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR|os.O_APPEND, []byte{0, 1, 2})
+
+		// --- When ---
+		have, err := fil.Write(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, have)
+		assert.Equal(t, int64(0), iokit.Offset(fil))
+		assert.Equal(t, []byte{0, 1, 2}, iokit.ReadAllFromStart(fil))
+	})
+
+	t.Run("Write - beyond capacity", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
+		iokit.Seek(fil, 600, io.SeekStart)
+
+		// --- When ---
+		have, err := fil.Write([]byte{3})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 1, have)
+		assert.Equal(t, int64(601), iokit.Offset(fil))
+		want := make([]byte, 601)
+		copy(want, []byte{0, 1, 2})
+		want[600] = 3
+		assert.Equal(t, want, iokit.ReadAllFromStart(fil))
+	})
+
 	t.Run("WriteAt - empty file at the beginning", func(t *testing.T) {
 		// --- Given ---
 		fil := create(t, os.O_RDWR, nil)
@@ -225,6 +277,19 @@ func TstFile(t *testing.T, create fileCreator) {
 		assert.Equal(t, []byte{0, 1, 2}, iokit.ReadAllFromStart(fil))
 	})
 
+	t.Run("WriteAt - empty beyond end", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
+
+		// --- When ---
+		have, err := fil.WriteAt(nil, 100)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, have)
+		assert.Equal(t, []byte{0, 1, 2}, iokit.ReadAllFromStart(fil))
+	})
+
 	t.Run("WriteTo - after seek", func(t *testing.T) {
 		// --- Given ---
 		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
@@ -241,6 +306,22 @@ func TstFile(t *testing.T, create fileCreator) {
 		assert.Equal(t, []byte{1, 2}, dst.Bytes())
 	})
 
+	t.Run("WriteTo - beyond end", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
+		iokit.Seek(fil, 5, io.SeekStart)
+		dst := &bytes.Buffer{}
+
+		// --- When ---
+		have, err := fil.WriteTo(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), have)
+		assert.Equal(t, int64(5), iokit.Offset(fil))
+		assert.Len(t, 0, dst.Bytes())
+	})
+
 	t.Run("WriteString - after seek", func(t *testing.T) {
 		// --- Given ---
 		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
@@ -271,6 +352,20 @@ func TstFile(t *testing.T, create fileCreator) {
 		assert.Equal(t, []byte{0, 0, 0}, dst)
 	})
 
+	t.Run("Read - empty buffer beyond end", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
+		iokit.Seek(fil, 5, io.SeekStart)
+
+		// --- When ---
+		have, err := fil.Read(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, have)
+		assert.Equal(t, int64(5), iokit.Offset(fil))
+	})
+
 	t.Run("Read - with a small buffer", func(t *testing.T) {
 		// --- Given ---
 		fil := create(t, os.O_RDWR, []byte{0, 1, 2, 3, 4})
@@ -487,6 +582,66 @@ func TstFile(t *testing.T, create fileCreator) {
 		assert.Equal(t, []byte{0, 1, 2, 3, 4}, iokit.ReadAllFromStart(fil))
 	})
 
+	t.Run("ReadFrom - empty with O_APPEND", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR|os.O_APPEND, []byte{0, 1, 2})
+
+		// --- When ---
+		have, err := fil.ReadFrom(&bytes.Buffer{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), have)
+		assert.Equal(t, int64(0), iokit.Offset(fil))
+		assert.Equal(t, []byte{0, 1, 2}, iokit.ReadAllFromStart(fil))
+	})
+
+	t.Run("ReadFrom - empty beyond end", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
+		iokit.Seek(fil, 5, io.SeekStart)
+
+		// --- When ---
+		have, err := fil.ReadFrom(&bytes.Buffer{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), have)
+		assert.Equal(t, int64(5), iokit.Offset(fil))
+		assert.Equal(t, []byte{0, 1, 2}, iokit.ReadAllFromStart(fil))
+	})
+
+	t.Run("ReadFrom - beyond end", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
+		iokit.Seek(fil, 5, io.SeekStart)
+		rdr := bytes.NewBuffer([]byte{3, 4})
+
+		// --- When ---
+		have, err := fil.ReadFrom(rdr)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), have)
+		assert.Equal(t, int64(7), iokit.Offset(fil))
+		want := []byte{0, 1, 2, 0, 0, 3, 4}
+		assert.Equal(t, want, iokit.ReadAllFromStart(fil))
+	})
+
+	t.Run("ReadFrom - overwrite and truncate", func(t *testing.T) {
+		// --- Given ---
+		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
+		must.Value(fil.ReadFrom(bytes.NewBuffer([]byte{3, 4, 5, 6})))
+
+		// --- When ---
+		err := fil.Truncate(8)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []byte{3, 4, 5, 6, 0, 0, 0, 0}
+		assert.Equal(t, want, iokit.ReadAllFromStart(fil))
+	})
+
 	t.Run("Seek - SeekCurrent zero offset after creation", func(t *testing.T) {
 		// --- Given ---
 		fil := create(t, os.O_RDWR, []byte{0, 1, 2})
@@ -838,6 +993,7 @@ func TstDirectory(t *testing.T, pth string, root fs.FS) {
 		assert.Equal(t, "read", e.Op)
 		assert.Equal(t, "sub", strings.TrimPrefix(e.Path, pth+"/"))
 		assert.Equal(t, syscall.EISDIR, e.Err)
+		assert.NotNil(t, have)
 		assert.Len(t, 0, have)
 	})
 
@@ -967,4 +1123,154 @@ func TstDirectory(t *testing.T, pth string, root fs.FS) {
 		assert.True(t, must.Value(have.Stat()).IsDir())
 		assert.Equal(t, "sub2", must.Value(have.Stat()).Name())
 	})
+
+	t.Run("Close - directory handle", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(root.Open("sub")).(fs.ReadDirFile)
+		must.Value(dir.ReadDir(1))
+
+		// --- When ---
+		err := dir.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("Close - error - closed directory handle", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(root.Open("sub")).(fs.ReadDirFile)
+		must.Nil(dir.Close())
+
+		// --- When ---
+		err := dir.Close()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "close", e.Op)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+	})
+
+	t.Run("Close - error - ReadDir after close", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(root.Open("sub")).(fs.ReadDirFile)
+		must.Value(dir.ReadDir(1))
+		must.Nil(dir.Close())
+
+		// --- When ---
+		have, err := dir.ReadDir(-1)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "readdirent", e.Op)
+		assert.Len(t, 0, have)
+	})
+
+	t.Run("Close - error - Stat after close", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(root.Open("sub"))
+		must.Nil(dir.Close())
+
+		// --- When ---
+		have, err := dir.Stat()
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrClosed, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("Close - reopened directory reads from start", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(root.Open("sub")).(fs.ReadDirFile)
+		must.Value(dir.ReadDir(2))
+		must.Nil(dir.Close())
+		dir = must.Value(root.Open("sub")).(fs.ReadDirFile)
+
+		// --- When ---
+		have, err := dir.ReadDir(-1)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 3, have)
+		must.Nil(dir.Close())
+	})
+}
+
+func Test_DirFS(t *testing.T) {
+	// --- Given ---
+	osFS := os.DirFS(tstDirOS(t))
+	kitFS := tstDirMem().DirFS()
+
+	names := []string{
+		".", "file0", "sub", "sub/sub2", "sub/sub2/file5", "missing",
+		"sub/missing", "file0/sub", "sub/file3/sub", "../file0", "/file0",
+		"sub/../file0", "sub//file3", "sub/", "file\x00",
+	}
+	ops := map[string]func(fsys fs.FS, name string) (any, error){
+		"Open": func(fsys fs.FS, name string) (any, error) {
+			f, err := fsys.Open(name)
+			if err != nil {
+				return nil, err
+			}
+			return must.Value(f.Stat()).Name(), nil
+		},
+		"Stat": func(fsys fs.FS, name string) (any, error) {
+			fi, err := fs.Stat(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			return []any{fi.Name(), fi.Size(), fi.IsDir()}, nil
+		},
+		"ReadFile": func(fsys fs.FS, name string) (any, error) {
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			return string(data), nil
+		},
+		"ReadDir": func(fsys fs.FS, name string) (any, error) {
+			ents, err := fs.ReadDir(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			var have []string
+			for _, ent := range ents {
+				have = append(have, ent.Name())
+			}
+			return have, nil
+		},
+	}
+
+	for opN, op := range ops {
+		for _, name := range names {
+			t.Run(opN+" "+name, func(t *testing.T) {
+				// --- When ---
+				have, haveErr := op(kitFS, name)
+
+				// --- Then ---
+				want, wantErr := op(osFS, name)
+				if wantErr != nil {
+					assert.ErrorEqual(t, wantErr.Error(), haveErr)
+					assert.Equal(t, errorType(wantErr), errorType(haveErr))
+				} else {
+					assert.NoError(t, haveErr)
+				}
+				if name != "." || opN == "ReadFile" || opN == "ReadDir" {
+					// Names of the root directories differ.
+					assert.Equal(t, want, have)
+				}
+			})
+		}
+	}
+}
+
+// errorType returns the description of the error type and the type of the
+// wrapped error for the [fs.PathError] errors.
+func errorType(err error) string {
+	var e *fs.PathError
+	if errors.As(err, &e) {
+		return fmt.Sprintf("%T %T", err, e.Err)
+	}
+	return fmt.Sprintf("%T", err)
 }
@@ -0,0 +1,322 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// Difference kinds reported by [Diff].
+const (
+	// DiffMissing is reported for the paths which exist only in the want
+	// file system.
+	DiffMissing = "missing"
+
+	// DiffExtra is reported for the paths which exist only in the have file
+	// system.
+	DiffExtra = "extra"
+
+	// DiffType is reported for the paths of different file types, for
+	// example, a directory and a regular file.
+	DiffType = "type"
+
+	// DiffMode is reported for the paths with different permission bits.
+	DiffMode = "mode"
+
+	// DiffModTime is reported for the paths with different modification
+	// times.
+	DiffModTime = "mtime"
+
+	// DiffContent is reported for the regular files with different contents
+	// and the symbolic links with different destinations.
+	DiffContent = "content"
+)
+
+// Tolerance relaxes the comparison done by [Diff] and [Equal]. The zero value
+// compares the trees strictly.
+type Tolerance struct {
+	// Don't compare the modification times.
+	IgnoreModTime bool
+
+	// Don't compare the permission bits.
+	IgnoreMode bool
+
+	// Compare the contents of the regular files with the "\r\n" line endings
+	// replaced with "\n".
+	NormalizeEOL bool
+
+	// Patterns in the [File.GlobEx] syntax of the paths not to compare. When
+	// a directory matches, its whole subtree is ignored.
+	Ignore []string
+
+	// Compare only the files and directories selected in the want or the
+	// have tree, for example, only the executable files. The entries of the
+	// directories which are not selected are still compared. When nil, all
+	// of them are compared.
+	Select Selector
+}
+
+// Difference describes a path which differs between two trees.
+type Difference struct {
+	Path string // Slash-separated path relative to the root.
+	Kind string // One of the Diff constants, like [DiffMissing].
+	Msg  string // Human-readable description.
+}
+
+// String implements [fmt.Stringer] interface.
+func (d Difference) String() string {
+	return d.Path + ": " + d.Kind + ": " + d.Msg
+}
+
+// Diff compares the trees in the want and have file systems, for example, a
+// golden tree returned by [os.DirFS] and the one returned by [File.DirFS],
+// and returns their differences in the path order. The roots of the file
+// systems are not compared. The subtrees of the directories which exist only
+// in one of the trees, or which are of different types, are not compared
+// either. The symbolic links are not followed; their destinations are
+// compared when both file systems implement [fs.ReadLinkFS]. Returns nil
+// when the trees are equal.
+//
+// Returns [path.ErrBadPattern] when a pattern in the tolerance is malformed
+// and the errors returned by the file systems.
+func Diff(want, have fs.FS, tol Tolerance) ([]Difference, error) {
+	ignore, err := compileGlobs(tol.Ignore...)
+	if err != nil {
+		return nil, err
+	}
+	d := &differ{want: want, have: have, tol: tol, ignore: ignore}
+	if err = d.dir("."); err != nil {
+		return nil, err
+	}
+	return d.diffs, nil
+}
+
+// Equal returns true when the trees in the want and have file systems are
+// equal within the tolerance, see [Diff] for details.
+func Equal(want, have fs.FS, tol Tolerance) (bool, error) {
+	diffs, err := Diff(want, have, tol)
+	if err != nil {
+		return false, err
+	}
+	return len(diffs) == 0, nil
+}
+
+// differ compares two trees for [Diff].
+type differ struct {
+	want   fs.FS        // The want file system.
+	have   fs.FS        // The have file system.
+	tol    Tolerance    // The comparison tolerance.
+	ignore []string     // The compiled patterns of the ignored paths.
+	diffs  []Difference // The differences found so far.
+}
+
+// add records the difference.
+func (d *differ) add(pth, kind, format string, args ...any) {
+	d.diffs = append(d.diffs, Difference{
+		Path: pth,
+		Kind: kind,
+		Msg:  fmt.Sprintf(format, args...),
+	})
+}
+
+// dir compares the entries of the directory existing in both trees.
+func (d *differ) dir(dir string) error {
+	wEnts, err := fs.ReadDir(d.want, dir)
+	if err != nil {
+		return err
+	}
+	hEnts, err := fs.ReadDir(d.have, dir)
+	if err != nil {
+		return err
+	}
+
+	// Both listings are sorted by name, so they are merged.
+	for len(wEnts) > 0 || len(hEnts) > 0 {
+		var wEnt, hEnt fs.DirEntry
+		switch {
+		case len(hEnts) == 0 ||
+			(len(wEnts) > 0 && wEnts[0].Name() < hEnts[0].Name()):
+			wEnt, wEnts = wEnts[0], wEnts[1:]
+		case len(wEnts) == 0 || hEnts[0].Name() < wEnts[0].Name():
+			hEnt, hEnts = hEnts[0], hEnts[1:]
+		default:
+			wEnt, wEnts = wEnts[0], wEnts[1:]
+			hEnt, hEnts = hEnts[0], hEnts[1:]
+		}
+
+		var pth string
+		if wEnt != nil {
+			pth = path.Join(dir, wEnt.Name())
+		} else {
+			pth = path.Join(dir, hEnt.Name())
+		}
+		if matchAny(d.ignore, pth, false) {
+			continue
+		}
+		switch {
+		case hEnt == nil:
+			ok, err := d.selected(pth, wEnt)
+			if err != nil {
+				return err
+			}
+			if ok {
+				d.add(pth, DiffMissing, "exists only in want")
+			}
+		case wEnt == nil:
+			ok, err := d.selected(pth, hEnt)
+			if err != nil {
+				return err
+			}
+			if ok {
+				d.add(pth, DiffExtra, "exists only in have")
+			}
+		default:
+			if err = d.entry(pth, wEnt, hEnt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// selected returns true when the entries are compared. The entries are
+// selected when there is no selector in the tolerance or it selects any of
+// them.
+func (d *differ) selected(pth string, ets ...fs.DirEntry) (bool, error) {
+	if d.tol.Select == nil {
+		return true, nil
+	}
+	for _, ent := range ets {
+		info, err := ent.Info()
+		if err != nil {
+			return false, err
+		}
+		if d.tol.Select(pth, info) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// entry compares the entry existing in both trees.
+func (d *differ) entry(pth string, wEnt, hEnt fs.DirEntry) error {
+	wInfo, err := wEnt.Info()
+	if err != nil {
+		return err
+	}
+	hInfo, err := hEnt.Info()
+	if err != nil {
+		return err
+	}
+
+	ok, err := d.selected(pth, wEnt, hEnt)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if wInfo.IsDir() && hInfo.IsDir() {
+			return d.dir(pth)
+		}
+		return nil
+	}
+
+	wType, hType := typeName(wInfo.Mode()), typeName(hInfo.Mode())
+	if wType != hType {
+		d.add(pth, DiffType, "have %s, want %s", hType, wType)
+		return nil
+	}
+	wPerm, hPerm := wInfo.Mode().Perm(), hInfo.Mode().Perm()
+	if !d.tol.IgnoreMode && wPerm != hPerm {
+		d.add(pth, DiffMode, "have %04o, want %04o", hPerm, wPerm)
+	}
+	wTime, hTime := wInfo.ModTime(), hInfo.ModTime()
+	if !d.tol.IgnoreModTime && !wTime.Equal(hTime) {
+		d.add(
+			pth,
+			DiffModTime,
+			"have %s, want %s",
+			hTime.Format(time.RFC3339Nano),
+			wTime.Format(time.RFC3339Nano),
+		)
+	}
+
+	switch mode := wInfo.Mode(); {
+	case mode.IsDir():
+		return d.dir(pth)
+	case mode.IsRegular():
+		return d.content(pth)
+	case mode&fs.ModeSymlink != 0:
+		return d.link(pth)
+	}
+	return nil
+}
+
+// content compares the contents of the regular file existing in both trees.
+func (d *differ) content(pth string) error {
+	wData, err := fs.ReadFile(d.want, pth)
+	if err != nil {
+		return err
+	}
+	hData, err := fs.ReadFile(d.have, pth)
+	if err != nil {
+		return err
+	}
+	if d.tol.NormalizeEOL {
+		wData = bytes.ReplaceAll(wData, []byte("\r\n"), []byte("\n"))
+		hData = bytes.ReplaceAll(hData, []byte("\r\n"), []byte("\n"))
+	}
+	if bytes.Equal(wData, hData) {
+		return nil
+	}
+	i := 0
+	for i < len(wData) && i < len(hData) && wData[i] == hData[i] {
+		i++
+	}
+	d.add(
+		pth,
+		DiffContent,
+		"differs at offset %d (have %d bytes, want %d bytes)",
+		i, len(hData), len(wData),
+	)
+	return nil
+}
+
+// link compares the destinations of the symbolic link existing in both
+// trees when both file systems implement [fs.ReadLinkFS].
+func (d *differ) link(pth string) error {
+	wFS, wOK := d.want.(fs.ReadLinkFS)
+	hFS, hOK := d.have.(fs.ReadLinkFS)
+	if !wOK || !hOK {
+		return nil
+	}
+	wDst, err := wFS.ReadLink(pth)
+	if err != nil {
+		return err
+	}
+	hDst, err := hFS.ReadLink(pth)
+	if err != nil {
+		return err
+	}
+	if wDst != hDst {
+		d.add(pth, DiffContent, "have link to %q, want %q", hDst, wDst)
+	}
+	return nil
+}
+
+// typeName returns the name of the file type of the mode.
+func typeName(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "directory"
+	case mode.IsRegular():
+		return "regular file"
+	case mode&fs.ModeSymlink != 0:
+		return "symbolic link"
+	}
+	return "special file"
+}
@@ -0,0 +1,366 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstDiffFS returns a file system for the Diff tests.
+func tstDiffFS() fstest.MapFS {
+	tim := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	return fstest.MapFS{
+		"file":      {Data: []byte("abc\n"), Mode: 0644, ModTime: tim},
+		"dir":       {Mode: fs.ModeDir | 0755, ModTime: tim},
+		"dir/file":  {Data: []byte("a\nb\n"), Mode: 0600, ModTime: tim},
+		"dir/other": {Data: []byte("xyz"), Mode: 0600, ModTime: tim},
+	}
+}
+
+func Test_Diff(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		// --- When ---
+		have, err := Diff(tstDiffFS(), tstDiffFS(), Tolerance{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("missing and extra", func(t *testing.T) {
+		// --- Given ---
+		want := tstDiffFS()
+		delete(want, "dir/other")
+		have := tstDiffFS()
+		delete(have, "file")
+		delete(have, "dir/file")
+
+		// --- When ---
+		diffs, err := Diff(want, have, Tolerance{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := []Difference{
+			{Path: "dir/file", Kind: DiffMissing, Msg: "exists only in want"},
+			{Path: "dir/other", Kind: DiffExtra, Msg: "exists only in have"},
+			{Path: "file", Kind: DiffMissing, Msg: "exists only in want"},
+		}
+		assert.Equal(t, exp, diffs)
+	})
+
+	t.Run("missing directory is reported once", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		delete(have, "dir")
+		delete(have, "dir/file")
+		delete(have, "dir/other")
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), have, Tolerance{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := []Difference{
+			{Path: "dir", Kind: DiffMissing, Msg: "exists only in want"},
+		}
+		assert.Equal(t, exp, diffs)
+	})
+
+	t.Run("type", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		have["file"] = &fstest.MapFile{Mode: fs.ModeDir | 0644}
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), have, Tolerance{IgnoreModTime: true})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := []Difference{
+			{
+				Path: "file",
+				Kind: DiffType,
+				Msg:  "have directory, want regular file",
+			},
+		}
+		assert.Equal(t, exp, diffs)
+	})
+
+	t.Run("mode and mtime", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		have["dir/file"].Mode = 0644
+		have["dir/file"].ModTime = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), have, Tolerance{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := []Difference{
+			{Path: "dir/file", Kind: DiffMode, Msg: "have 0644, want 0600"},
+			{
+				Path: "dir/file",
+				Kind: DiffModTime,
+				Msg:  "have 2001-01-01T00:00:00Z, want 2000-01-02T03:04:05Z",
+			},
+		}
+		assert.Equal(t, exp, diffs)
+	})
+
+	t.Run("content", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		have["dir/other"].Data = []byte("xyZW")
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), have, Tolerance{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := []Difference{
+			{
+				Path: "dir/other",
+				Kind: DiffContent,
+				Msg:  "differs at offset 2 (have 4 bytes, want 3 bytes)",
+			},
+		}
+		assert.Equal(t, exp, diffs)
+	})
+
+	t.Run("symbolic link", func(t *testing.T) {
+		// --- Given ---
+		want := NewRoot()
+		must.Nil(want.Symlink("a", "link"))
+		have := NewRoot()
+		must.Nil(have.Symlink("b", "link"))
+
+		// --- When ---
+		diffs, err := Diff(want.DirFS(), have.DirFS(), Tolerance{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := []Difference{
+			{
+				Path: "link",
+				Kind: DiffContent,
+				Msg:  `have link to "b", want "a"`,
+			},
+		}
+		assert.Equal(t, exp, diffs)
+	})
+
+	t.Run("tolerance", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		have["file"].ModTime = time.Now()
+		have["dir/file"].Mode = 0644
+		have["dir/file"].Data = []byte("a\r\nb\r\n")
+		have["dir/other"].Data = []byte("changed")
+		have["dir/extra"] = &fstest.MapFile{Data: []byte("extra")}
+		tol := Tolerance{
+			IgnoreModTime: true,
+			IgnoreMode:    true,
+			NormalizeEOL:  true,
+			Ignore:        []string{"dir/{other,extra}"},
+		}
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), have, tol)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, diffs)
+	})
+
+	t.Run("ignored directory", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		have["dir/other"].Data = []byte("changed")
+		tol := Tolerance{Ignore: []string{"dir"}}
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), have, tol)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, diffs)
+	})
+
+	t.Run("selected files", func(t *testing.T) {
+		// --- Given ---
+		want := tstDiffFS()
+		want["dir/file"].Mode = 0700
+		have := tstDiffFS()
+		have["file"].Data = []byte("changed")
+		have["dir/other"].Data = []byte("changed")
+		tol := Tolerance{Select: SelectPerm(0100)}
+
+		// --- When ---
+		diffs, err := Diff(want, have, tol)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := []Difference{
+			{Path: "dir/file", Kind: DiffMode, Msg: "have 0600, want 0700"},
+		}
+		assert.Equal(t, exp, diffs)
+	})
+
+	t.Run("selected missing and extra", func(t *testing.T) {
+		// --- Given ---
+		want := tstDiffFS()
+		want["dir/exe"] = &fstest.MapFile{Mode: 0755}
+		delete(want, "dir/other")
+		have := tstDiffFS()
+		delete(have, "file")
+		have["dir/script"] = &fstest.MapFile{Mode: 0700}
+		tol := Tolerance{Select: SelectAll(SelectType(0), SelectPerm(0111))}
+
+		// --- When ---
+		diffs, err := Diff(want, have, tol)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := []Difference{
+			{Path: "dir/exe", Kind: DiffMissing, Msg: "exists only in want"},
+			{Path: "dir/script", Kind: DiffExtra, Msg: "exists only in have"},
+		}
+		assert.Equal(t, exp, diffs)
+	})
+
+	t.Run("not selected directory is compared", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		have["dir"].Mode = fs.ModeDir | 0700
+		have["dir/file"].Data = []byte("changed")
+		tol := Tolerance{Select: SelectType(0)}
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), have, tol)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 1, diffs)
+		assert.Equal(t, "dir/file", diffs[0].Path)
+		assert.Equal(t, DiffContent, diffs[0].Kind)
+	})
+
+	t.Run("tree written to disk", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.WriteToDisk(dst))
+
+		// --- When ---
+		diffs, err := Diff(
+			os.DirFS(dst),
+			dir.DirFS(),
+			Tolerance{IgnoreModTime: true},
+		)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, diffs)
+	})
+
+	t.Run("error - bad pattern", func(t *testing.T) {
+		// --- Given ---
+		tol := Tolerance{Ignore: []string{"[a"}}
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), tstDiffFS(), tol)
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.Nil(t, diffs)
+	})
+
+	t.Run("error - file system", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("test error")
+		have := Chain(tstDiffFS(), Faults(func(op, name string) error {
+			if op == "readfile" && name == "dir/file" {
+				return e
+			}
+			return nil
+		}))
+
+		// --- When ---
+		diffs, err := Diff(tstDiffFS(), have, Tolerance{})
+
+		// --- Then ---
+		assert.ErrorIs(t, e, err)
+		assert.Nil(t, diffs)
+	})
+}
+
+func Test_Equal(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		// --- When ---
+		have, err := Equal(tstDiffFS(), tstDiffFS(), Tolerance{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have)
+	})
+
+	t.Run("not equal", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		have["file"].Mode = 0600
+
+		// --- When ---
+		eq, err := Equal(tstDiffFS(), have, Tolerance{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.False(t, eq)
+	})
+
+	t.Run("within tolerance", func(t *testing.T) {
+		// --- Given ---
+		have := tstDiffFS()
+		have["file"].Mode = 0600
+
+		// --- When ---
+		eq, err := Equal(tstDiffFS(), have, Tolerance{IgnoreMode: true})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, eq)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		// --- Given ---
+		tol := Tolerance{Ignore: []string{"[a"}}
+
+		// --- When ---
+		have, err := Equal(tstDiffFS(), tstDiffFS(), tol)
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.False(t, have)
+	})
+}
+
+func Test_Difference_String(t *testing.T) {
+	// --- Given ---
+	d := Difference{Path: "a/b", Kind: DiffMode, Msg: "have 0644, want 0600"}
+
+	// --- When ---
+	have := d.String()
+
+	// --- Then ---
+	assert.Equal(t, "a/b: mode: have 0644, want 0600", have)
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"syscall"
+)
+
+// ReadDirFiltered works like [File.ReadDir] but returns only the entries of
+// the given type: [fs.ModeDir] for directories or zero for regular files.
+// Other bits of the typ are ignored. It shares the cursor and the entries
+// snapshot with [File.ReadDir], and the entries of other types are skipped
+// without being counted towards n. The returned entries are the directory
+// entries themselves, not copies of their [fs.FileInfo].
+func (fil *File) ReadDirFiltered(
+	n int,
+	typ fs.FileMode,
+) ([]fs.DirEntry, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "ReadDir",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	listing := fil.snapshotEntries()
+
+	typ &= fs.ModeType
+	var ets []fs.DirEntry
+	for ; fil.cursor < len(listing); fil.cursor++ {
+		if n > 0 && len(ets) == n {
+			break
+		}
+		if ent := listing[fil.cursor]; ent.Type() == typ {
+			ets = append(ets, ent)
+		}
+	}
+	if len(ets) == 0 {
+		return nil, io.EOF
+	}
+	return ets, nil
+}
+
+// Dirs returns the subdirectories of the directory in the name order. It
+// does not change the [File.ReadDir] cursor. Returns nil when the instance
+// is not a directory.
+func (fil *File) Dirs() []fs.DirEntry { return fil.entriesOf(fs.ModeDir) }
+
+// Files returns the regular files in the directory in the name order. It
+// does not change the [File.ReadDir] cursor. Returns nil when the instance
+// is not a directory.
+func (fil *File) Files() []fs.DirEntry { return fil.entriesOf(0) }
+
+// entriesOf returns the directory entries of the given type in the name
+// order.
+func (fil *File) entriesOf(typ fs.FileMode) []fs.DirEntry {
+	fil.sortEntries()
+	var ets []fs.DirEntry
+	for _, ent := range fil.entries {
+		if ent.Type() == typ {
+			ets = append(ets, ent)
+		}
+	}
+	return ets
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstDirMixed returns a directory with interleaved files and directories.
+func tstDirMixed() *File {
+	dir := MustDirectory("dir")
+	must.Nil(dir.AddFile(MustDirectory("d3")))
+	must.Nil(dir.AddFile(MustFile("f2")))
+	must.Nil(dir.AddFile(MustDirectory("d1")))
+	must.Nil(dir.AddFile(MustFile("f0")))
+	must.Nil(dir.AddFile(MustFile("f4")))
+	return dir
+}
+
+// names returns the names of the directory entries.
+func names(ets []fs.DirEntry) []string {
+	var have []string
+	for _, ent := range ets {
+		have = append(have, ent.Name())
+	}
+	return have
+}
+
+func Test_File_ReadDirFiltered(t *testing.T) {
+	t.Run("all files", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMixed()
+
+		// --- When ---
+		have, err := dir.ReadDirFiltered(-1, 0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"f0", "f2", "f4"}, names(have))
+		assert.Same(t, must.Value(open(dir, "f0")), have[0].(*File))
+
+		// --- When ---
+		have, err = dir.ReadDirFiltered(-1, 0)
+
+		// --- Then ---
+		assert.ErrorIs(t, io.EOF, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("all directories", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMixed()
+
+		// --- When ---
+		have, err := dir.ReadDirFiltered(0, fs.ModeDir|0700)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d1", "d3"}, names(have))
+	})
+
+	t.Run("in batches", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMixed()
+
+		// --- When ---
+		have0, err0 := dir.ReadDirFiltered(2, 0)
+		have1, err1 := dir.ReadDirFiltered(2, 0)
+		have2, err2 := dir.ReadDirFiltered(2, 0)
+
+		// --- Then ---
+		assert.NoError(t, err0)
+		assert.Equal(t, []string{"f0", "f2"}, names(have0))
+		assert.NoError(t, err1)
+		assert.Equal(t, []string{"f4"}, names(have1))
+		assert.ErrorIs(t, io.EOF, err2)
+		assert.Nil(t, have2)
+	})
+
+	t.Run("shares cursor with ReadDir", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMixed()
+		must.Value(dir.ReadDir(1))
+
+		// --- When ---
+		have, err := dir.ReadDirFiltered(-1, fs.ModeDir)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d3"}, names(have))
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have, err := fil.ReadDirFiltered(-1, 0)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "ReadDir", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_File_Dirs(t *testing.T) {
+	t.Run("directories", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMixed()
+		must.Value(dir.ReadDir(1))
+
+		// --- When ---
+		have := dir.Dirs()
+
+		// --- Then ---
+		assert.Equal(t, []string{"d1", "d3"}, names(have))
+		assert.Equal(t, 1, dir.cursor)
+	})
+
+	t.Run("not a directory", func(t *testing.T) {
+		// --- When ---
+		have := MustFile("file").Dirs()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+}
+
+func Test_File_Files(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMixed()
+
+		// --- When ---
+		have := dir.Files()
+
+		// --- Then ---
+		assert.Equal(t, []string{"f0", "f2", "f4"}, names(have))
+		assert.Same(t, must.Value(open(dir, "f4")), have[2].(*File))
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		// --- When ---
+		have := MustDirectory("dir").Files()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+}
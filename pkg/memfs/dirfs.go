@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"cmp"
+	"io/fs"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// DirFS returns a file system [fs.FS] for the directory tree which reports
+// errors the same way the file system returned by [os.DirFS] does on Unix.
+// Use it when porting tests from [os.DirFS] to avoid changing their error
+// expectations. Returns nil if the file is not a directory.
+//
+// The differences from the file system returned by [File.FS] are:
+//
+//   - invalid names are reported with the operation name of the called
+//     method, for example, "stat" or "readfile",
+//   - not existing files are reported with the "open" or "stat" operation
+//     and [syscall.ENOENT], or [syscall.ENOTDIR] when the path goes through a
+//     regular file,
+//   - ReadDir reports regular files with the "open" operation and
+//     [syscall.ENOTDIR],
+//   - the Path field of the errors is always the name passed to the method,
+//   - ReadFile returns a nil slice instead of an empty one for directories.
+//
+// The result implements:
+//   - [io/fs.StatFS],
+//   - [io/fs.ReadFileFS],
+//   - [io/fs.ReadDirFS],
+//   - [io/fs.ReadLinkFS].
+func (fil *File) DirFS() fs.FS {
+	if fil.IsDir() {
+		return dirFS{dir: fil}
+	}
+	return nil
+}
+
+// dirFS implements the file system returned by [File.DirFS].
+type dirFS struct{ dir *File }
+
+// Open implements [fs.FS] interface.
+func (d dirFS) Open(name string) (fs.File, error) {
+	fil, err := d.open(name, "open", "open")
+	if err != nil {
+		return nil, err
+	}
+	name = d.dir.lenientPath(name)
+	if fil.IsDir() {
+		return newDirHandle(fil, name), nil
+	}
+	return openedAs(d.dir, fil, name), nil
+}
+
+// Stat implements [fs.StatFS] interface.
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	fil, err := d.open(name, "stat", "stat")
+	if err != nil {
+		return nil, err
+	}
+	if fil == d.dir {
+		return fil.statAs("."), nil
+	}
+	if ent, _ := lopen(d.dir, name); ent != fil {
+		return fil.statAs(name), nil
+	}
+	return fil, nil
+}
+
+// ReadLink implements [fs.ReadLinkFS] interface.
+func (d dirFS) ReadLink(name string) (string, error) {
+	return d.dir.ReadLink(name)
+}
+
+// Lstat implements [fs.ReadLinkFS] interface.
+func (d dirFS) Lstat(name string) (fs.FileInfo, error) {
+	return d.dir.Lstat(name)
+}
+
+// ReadFile implements [fs.ReadFileFS] interface.
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	fil, err := d.open(name, "readfile", "open")
+	if err != nil {
+		return nil, err
+	}
+	if fil.IsDir() {
+		return nil, d.dir.hookErr(&fs.PathError{
+			Op:   "read",
+			Path: name,
+			Err:  syscall.EISDIR,
+		})
+	}
+	if err = fil.load(); err != nil {
+		return nil, err
+	}
+	return fil.mapCopy(), nil
+}
+
+// ReadDir implements [fs.ReadDirFS] interface.
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	fil, err := d.open(name, "readdir", "open")
+	if err != nil {
+		return nil, err
+	}
+	if !fil.IsDir() {
+		return nil, d.dir.hookErr(&fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	ents := make([]fs.DirEntry, 0, len(fil.entries))
+	for _, ent := range fil.entries {
+		ents = append(ents, ent)
+	}
+	slices.SortFunc(ents, func(a, b fs.DirEntry) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+	return ents, nil
+}
+
+// open returns the file with the given name. The invOp is used as the
+// operation name when the name is invalid, and the op when the file does not
+// exist.
+func (d dirFS) open(name, invOp, op string) (*File, error) {
+	name = d.dir.lenientPath(name)
+	if !fs.ValidPath(name) || strings.IndexByte(name, 0) != -1 {
+		return nil, d.dir.hookErr(&fs.PathError{
+			Op:   invOp,
+			Path: name,
+			Err:  fs.ErrInvalid,
+		})
+	}
+
+	fil := d.dir
+	if name == "." {
+		return fil, nil
+	}
+	for _, elem := range strings.Split(name, "/") {
+		var err error
+		if !fil.IsDir() {
+			err = syscall.ENOTDIR
+		} else if fil, err = open(fil, elem); err != nil {
+			err = syscall.ENOENT
+		}
+		if err != nil {
+			return nil, d.dir.hookErr(&fs.PathError{
+				Op:   op,
+				Path: name,
+				Err:  err,
+			})
+		}
+	}
+	return fil, nil
+}
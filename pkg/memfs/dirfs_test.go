@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_DirFS(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have := dir.DirFS()
+
+		// --- Then ---
+		assert.Equal(t, dirFS{dir: dir}, have)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have := fil.DirFS()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+}
+
+func Test_dirFS_Stat(t *testing.T) {
+	t.Run("root", func(t *testing.T) {
+		// --- Given ---
+		fsys := must.Value(NewDirectory("dir")).DirFS()
+
+		// --- When ---
+		have, err := fs.Stat(fsys, ".")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, ".", have.Name())
+		assert.True(t, have.IsDir())
+	})
+
+	t.Run("file in subdirectory", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirMem().DirFS()
+
+		// --- When ---
+		have, err := fs.Stat(fsys, "sub/sub2/file5")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file5", have.Name())
+	})
+
+	t.Run("error - through a file", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirMem().DirFS()
+
+		// --- When ---
+		have, err := fs.Stat(fsys, "sub/file3/x")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "stat", e.Op)
+		assert.Equal(t, "sub/file3/x", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_dirFS_ReadFile(t *testing.T) {
+	t.Run("returns copy", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fsys := dir.DirFS()
+
+		// --- When ---
+		have, err := fs.ReadFile(fsys, "sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file3", string(have))
+		have[0] = 'x'
+		assert.Equal(t, "file3", must.Value(open(dir, "sub/file3")).String())
+	})
+
+	t.Run("lazily loaded file", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		dir := NewRoot()
+		must.Nil(dir.AddFile(lazyFile([]byte("abc"), &loads)))
+
+		// --- When ---
+		have, err := fs.ReadFile(dir.DirFS(), "file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", string(have))
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- Given ---
+		osHave, osErr := fs.ReadFile(os.DirFS(tstDirOS(t)), "sub")
+
+		// --- When ---
+		have, err := fs.ReadFile(tstDirMem().DirFS(), "sub")
+
+		// --- Then ---
+		assert.Equal(t, osErr.Error(), err.Error())
+		assert.ErrorIs(t, syscall.EISDIR, err)
+		assert.Nil(t, osHave)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - load", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+		dir := NewRoot()
+		must.Nil(dir.AddFile(&File{
+			info: FileInfo{name: "file", size: 3, mode: 0600},
+			lazy: func() ([]byte, error) { return nil, errTst },
+		}))
+
+		// --- When ---
+		have, err := fs.ReadFile(dir.DirFS(), "file")
+
+		// --- Then ---
+		assert.ErrorIs(t, errTst, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_dirFS_ReadDir(t *testing.T) {
+	t.Run("repeated calls", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirMem().DirFS()
+		must.Value(fs.ReadDir(fsys, "sub"))
+
+		// --- When ---
+		have, err := fs.ReadDir(fsys, "sub")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 3, have)
+		assert.Equal(t, "file3", have[0].Name())
+		assert.Equal(t, "sub2", have[2].Name())
+	})
+}
+
+func Test_dirFS_errorHook(t *testing.T) {
+	// --- Given ---
+	hook := func(err error) error { return fmt.Errorf("hooked: %w", err) }
+	dir := NewRoot(WithErrorHook(hook))
+	fsys := dir.DirFS()
+
+	// --- When ---
+	have, err := fsys.Open("missing")
+
+	// --- Then ---
+	assert.ErrorEqual(t, "hooked: open missing: no such file or directory", err)
+	assert.Nil(t, have)
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"syscall"
+)
+
+// Compile time checks.
+var _ fs.ReadDirFile = &dirHandle{}
+
+// dirHandle is the directory handle returned by the Open methods of the file
+// systems returned by [File.FS] and [File.DirFS]. Unlike the directory
+// itself, every handle has its own [fs.ReadDirFile.ReadDir] cursor and
+// entries snapshot, so the handles paginate independently, and the stdlib
+// helpers relying on it, like [fs.WalkDir] or [net/http.FileServer], work
+// when the directory is opened more than once.
+type dirHandle struct {
+	dir     *File
+	name    string  // Name passed to Open.
+	listing []*File // Snapshot of the directory entries.
+	cursor  int     // Directory entries already read.
+	closed  bool    // The handle was closed.
+}
+
+// newDirHandle returns a new handle for the directory opened with the given
+// name.
+func newDirHandle(dir *File, name string) *dirHandle {
+	dir.opened()
+	return &dirHandle{dir: dir, name: name}
+}
+
+// Stat implements [fs.File] interface. The name of the returned
+// [fs.FileInfo] is the last element of the name passed to Open, so it's "."
+// for the root of the file system, like for [os.DirFS].
+func (h *dirHandle) Stat() (fs.FileInfo, error) {
+	if err := h.check("stat"); err != nil {
+		return nil, err
+	}
+	return h.dir.statAs(h.name), nil
+}
+
+// Read implements [fs.File] interface. Always returns an error of the
+// [fs.PathError] type with [syscall.EISDIR].
+func (h *dirHandle) Read([]byte) (int, error) {
+	if err := h.check("read"); err != nil {
+		return 0, err
+	}
+	return 0, h.err("read", syscall.EISDIR)
+}
+
+// ReadDir implements [fs.ReadDirFile] interface. The first call takes a
+// snapshot of the directory entries in the name order.
+func (h *dirHandle) ReadDir(n int) ([]fs.DirEntry, error) {
+	if err := h.check("readdirent"); err != nil {
+		return nil, err
+	}
+	if h.listing == nil {
+		h.dir.sortEntries()
+		h.listing = append([]*File{}, h.dir.entries...)
+	}
+	left := h.listing[h.cursor:]
+	if n > 0 {
+		if len(left) == 0 {
+			return nil, io.EOF
+		}
+		left = left[:min(n, len(left))]
+	}
+	ets := make([]fs.DirEntry, 0, len(left))
+	for _, ent := range left {
+		info, err := ent.Stat()
+		if err != nil {
+			return nil, err
+		}
+		ets = append(ets, fs.FileInfoToDirEntry(info))
+	}
+	h.cursor += len(left)
+	return ets, nil
+}
+
+// Close implements [fs.File] interface. It releases the entries snapshot and
+// resets the cursor. Like with [os.File], the closed handle can't be reused,
+// all its methods return an error of the [fs.PathError] type with
+// [fs.ErrClosed], so open the directory again to read it from the beginning.
+func (h *dirHandle) Close() error {
+	if err := h.check("close"); err != nil {
+		return err
+	}
+	h.closed = true
+	h.listing, h.cursor = nil, 0
+	if h.dir.refs > 0 {
+		h.dir.refs--
+	}
+	return nil
+}
+
+// check returns an error of the [fs.PathError] type with [fs.ErrClosed] when
+// the handle was closed.
+func (h *dirHandle) check(op string) error {
+	if h.closed {
+		return h.err(op, fs.ErrClosed)
+	}
+	return nil
+}
+
+// err returns an error of the [fs.PathError] type for the handle.
+func (h *dirHandle) err(op string, err error) error {
+	return h.dir.hookErr(&fs.PathError{Op: op, Path: h.name, Err: err})
+}
+
+// statAs returns the [fs.FileInfo] of the instance with the name set to the
+// last element of the given path, for example, "." for the root of a file
+// system, whatever the name of the directory is.
+func (fil *File) statAs(pth string) fs.FileInfo {
+	info := fil.info
+	info.name = path.Base(pth)
+	info.size = fil.Size()
+	info.sys = fil.Sys()
+	return info
+}
@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_dirHandle(t *testing.T) {
+	t.Run("passes fstest", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirMem().DirFS()
+
+		// --- When ---
+		err := fstest.TestFS(fsys, "file0", "sub/file3", "sub/sub2/file6")
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("walk through Open", func(t *testing.T) {
+		// --- Given ---
+		fsys := fsOnly{tstDirMem().FS()}
+		var have []string
+		walk := func(pth string, _ fs.DirEntry, err error) error {
+			have = append(have, pth)
+			return err
+		}
+
+		// --- When ---
+		err := fs.WalkDir(fsys, ".", walk)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{
+			".", "file0", "file1", "file2", "sub", "sub/file3",
+			"sub/file4", "sub/sub2", "sub/sub2/file5", "sub/sub2/file6",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("file server", func(t *testing.T) {
+		// --- Given ---
+		srv := httptest.NewServer(http.FileServerFS(tstDirMem().FS()))
+		t.Cleanup(srv.Close)
+
+		// --- When ---
+		res := must.Value(http.Get(srv.URL + "/"))
+
+		// --- Then ---
+		defer func() { _ = res.Body.Close() }()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		body := string(must.Value(io.ReadAll(res.Body)))
+		assert.True(t, strings.Contains(body, `<a href="sub/">sub/</a>`))
+		assert.True(t, strings.Contains(body, `<a href="file2">file2</a>`))
+	})
+}
+
+func Test_dirHandle_Stat(t *testing.T) {
+	t.Run("root", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(NewDirectory("dir", WithFileMode(0750)))
+		h := must.Value(dir.FS().Open("."))
+
+		// --- When ---
+		have, err := h.Stat()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, ".", have.Name())
+		assert.Equal(t, fs.ModeDir|0750, have.Mode())
+		assert.Equal(t, int64(4096), have.Size())
+		assert.True(t, have.IsDir())
+	})
+
+	t.Run("root with lenient paths", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(NewDirectory("dir", WithLenientPaths))
+		h := must.Value(dir.DirFS().Open("./"))
+
+		// --- When ---
+		have, err := h.Stat()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, ".", have.Name())
+	})
+
+	t.Run("subdirectory", func(t *testing.T) {
+		// --- Given ---
+		h := must.Value(tstDirMem().DirFS().Open("sub/sub2"))
+
+		// --- When ---
+		have, err := h.Stat()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "sub2", have.Name())
+		assert.Equal(t, &SysInfo{Nlink: 2}, have.Sys())
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		h := must.Value(tstDirMem().FS().Open("."))
+		must.Nil(h.Close())
+
+		// --- When ---
+		have, err := h.Stat()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "stat", e.Op)
+		assert.Equal(t, ".", e.Path)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_dirHandle_Read(t *testing.T) {
+	// --- Given ---
+	h := must.Value(tstDirMem().FS().Open("sub"))
+
+	// --- When ---
+	n, err := h.Read(make([]byte, 1))
+
+	// --- Then ---
+	var e *fs.PathError
+	assert.ErrorAs(t, &e, err)
+	assert.Equal(t, "read", e.Op)
+	assert.Equal(t, "sub", e.Path)
+	assert.Equal(t, syscall.EISDIR, e.Err)
+	assert.Equal(t, 0, n)
+}
+
+func Test_dirHandle_ReadDir(t *testing.T) {
+	t.Run("handles paginate independently", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirMem().FS()
+		h0 := must.Value(fsys.Open(".")).(fs.ReadDirFile)
+		h1 := must.Value(fsys.Open(".")).(fs.ReadDirFile)
+		must.Value(h0.ReadDir(2))
+
+		// --- When ---
+		have1, err1 := h1.ReadDir(-1)
+		have0, err0 := h0.ReadDir(-1)
+
+		// --- Then ---
+		assert.NoError(t, err1)
+		want := []string{"file0", "file1", "file2", "sub"}
+		assert.Equal(t, want, names(have1))
+		assert.NoError(t, err0)
+		assert.Equal(t, []string{"file2", "sub"}, names(have0))
+	})
+
+	t.Run("directory cursor not used", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(dir.ReadDir(-1))
+		h := must.Value(dir.FS().Open(".")).(fs.ReadDirFile)
+
+		// --- When ---
+		have, err := h.ReadDir(-1)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 4, have)
+	})
+
+	t.Run("at the end", func(t *testing.T) {
+		// --- Given ---
+		h := must.Value(tstDirMem().FS().Open(".")).(fs.ReadDirFile)
+		must.Value(h.ReadDir(-1))
+
+		// --- When ---
+		haveAll, errAll := h.ReadDir(-1)
+		haveN, errN := h.ReadDir(1)
+
+		// --- Then ---
+		assert.NoError(t, errAll)
+		assert.NotNil(t, haveAll)
+		assert.Len(t, 0, haveAll)
+		assert.ErrorIs(t, io.EOF, errN)
+		assert.Nil(t, haveN)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		h := must.Value(tstDirMem().FS().Open(".")).(fs.ReadDirFile)
+		must.Nil(h.Close())
+
+		// --- When ---
+		have, err := h.ReadDir(-1)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrClosed, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_dirHandle_Close(t *testing.T) {
+	t.Run("close", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.FS().Open("."))
+
+		// --- When ---
+		err := h.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, dir.OpenCount())
+	})
+
+	t.Run("releases snapshot", func(t *testing.T) {
+		// --- Given ---
+		h := must.Value(tstDirMem().FS().Open("sub")).(*dirHandle)
+		must.Value(h.ReadDir(1))
+
+		// --- When ---
+		err := h.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, h.listing)
+		assert.Equal(t, 0, h.cursor)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		h := must.Value(tstDirMem().FS().Open("."))
+		must.Nil(h.Close())
+
+		// --- When ---
+		err := h.Close()
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrClosed, err)
+	})
+}
+
+func Test_File_statAs(t *testing.T) {
+	// --- Given ---
+	dir := tstDirMem()
+	sub := must.Value(open(dir, "sub"))
+
+	// --- When ---
+	have := sub.statAs(".")
+
+	// --- Then ---
+	assert.Equal(t, ".", have.Name())
+	assert.Equal(t, fs.ModeDir|0700, have.Mode())
+	assert.Equal(t, &SysInfo{Nlink: 3}, have.Sys())
+}
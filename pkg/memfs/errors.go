@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+// WithErrorHook is a [File] constructor function option setting a hook which
+// is called with every error produced by the [File] methods and the [fs.FS]
+// returned by [File.FS]. The error returned by the hook is returned to the
+// caller instead of the original one. The hook may change the Op or Err
+// fields of the [fs.PathError] errors, or wrap the errors, for example, to
+// mimic error messages of a specific platform.
+//
+// The hook set on a directory is used for all files and directories in its
+// tree unless a file or a directory closer to them in the hierarchy has its
+// own hook. Errors returned by the [io.Reader] or [io.Writer] instances passed
+// to the [File] methods are not passed to the hook.
+func WithErrorHook(hook func(err error) error) func(*File) {
+	return func(fil *File) { fil.errHook = hook }
+}
+
+// hookErr passes the error through the error hook set with [WithErrorHook] on
+// the instance or the closest of its parents. Returns the error unchanged when
+// no hook was set.
+func (fil *File) hookErr(err error) error {
+	for f := fil; f != nil; f = f.parent {
+		if f.errHook != nil {
+			return f.errHook(err)
+		}
+	}
+	return err
+}
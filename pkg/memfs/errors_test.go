@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithErrorHook(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+	hook := func(err error) error { return fmt.Errorf("hooked: %w", err) }
+
+	// --- When ---
+	WithErrorHook(hook)(fil)
+
+	// --- Then ---
+	assert.NotNil(t, fil.errHook)
+	assert.ErrorEqual(t, "hooked: abc", fil.errHook(errors.New("abc")))
+}
+
+func Test_File_hookErr(t *testing.T) {
+	hook := func(prefix string) func(error) error {
+		return func(err error) error {
+			return fmt.Errorf("%s: %w", prefix, err)
+		}
+	}
+
+	t.Run("no hook", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.hookErr(fs.ErrInvalid)
+
+		// --- Then ---
+		assert.Same(t, fs.ErrInvalid, err)
+	})
+
+	t.Run("instance hook", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithErrorHook(hook("file")))
+
+		// --- When ---
+		err := fil.hookErr(fs.ErrInvalid)
+
+		// --- Then ---
+		assert.ErrorEqual(t, "file: invalid argument", err)
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+	})
+
+	t.Run("parent hook", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithErrorHook(hook("root")))
+		sub := MustDirectory("sub")
+		fil := MustFile("file")
+		must.Nil(sub.AddFile(fil))
+		must.Nil(root.AddFile(sub))
+
+		// --- When ---
+		err := fil.hookErr(fs.ErrInvalid)
+
+		// --- Then ---
+		assert.ErrorEqual(t, "root: invalid argument", err)
+	})
+
+	t.Run("the closest hook wins", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithErrorHook(hook("root")))
+		sub := must.Value(NewDirectory("sub", WithErrorHook(hook("sub"))))
+		fil := MustFile("file")
+		must.Nil(sub.AddFile(fil))
+		must.Nil(root.AddFile(sub))
+
+		// --- When ---
+		err := fil.hookErr(fs.ErrInvalid)
+
+		// --- Then ---
+		assert.ErrorEqual(t, "sub: invalid argument", err)
+	})
+}
+
+func Test_File_errorHook(t *testing.T) {
+	// renameOp returns a hook changing the Op of [fs.PathError] errors.
+	renameOp := func(op string) func(error) error {
+		return func(err error) error {
+			var e *fs.PathError
+			if errors.As(err, &e) {
+				e.Op = op
+			}
+			return err
+		}
+	}
+
+	t.Run("file method", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithErrorHook(renameOp("CreateFile")))
+
+		// --- When ---
+		_, err := root.Write([]byte{0})
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "CreateFile", e.Op)
+		assert.Equal(t, syscall.EISDIR, e.Err)
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithErrorHook(renameOp("CreateFile")))
+
+		// --- When ---
+		have, err := root.Open("not-existing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "CreateFile", e.Op)
+		assert.Nil(t, have)
+	})
+
+	t.Run("FS Open", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithErrorHook(renameOp("CreateFile")))
+
+		// --- When ---
+		have, err := root.FS().Open("not-existing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "CreateFile", e.Op)
+		assert.Equal(t, syscall.ENOENT, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("FS Stat", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithErrorHook(renameOp("GetFileAttributesEx")))
+
+		// --- When ---
+		have, err := fs.Stat(root.FS(), "not-existing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "GetFileAttributesEx", e.Op)
+		assert.Nil(t, have)
+	})
+
+	t.Run("FS ReadDir", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithErrorHook(renameOp("FindFirstFile")))
+		must.Nil(root.AddFile(MustFile("file")))
+
+		// --- When ---
+		have, err := fs.ReadDir(root.FS(), "file")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "FindFirstFile", e.Op)
+		assert.Nil(t, have)
+	})
+
+	t.Run("AddFile", func(t *testing.T) {
+		// --- Given ---
+		hook := func(err error) error { return fmt.Errorf("hooked: %w", err) }
+		root := NewRoot(WithErrorHook(hook))
+		must.Nil(root.AddFile(MustFile("file")))
+
+		// --- When ---
+		err := root.AddFile(MustFile("file"))
+
+		// --- Then ---
+		assert.ErrorEqual(t, "hooked: file already exists", err)
+		assert.ErrorIs(t, fs.ErrExist, err)
+	})
+}
@@ -68,3 +68,15 @@ func ExampleNewDirectory() {
 	// -rw------- 1 0001-01-01 00:00:00 file0
 	// -rw------- 1 0001-01-01 00:00:00 file1
 }
+
+func ExampleMkFS() {
+	root, _ := memfs.MkFS(map[string][]byte{
+		"dir/sub/file1": []byte("content 1"),
+		"dir/file0":     []byte("content 0"),
+	})
+
+	data, _ := fs.ReadFile(root.FS(), "dir/sub/file1")
+	fmt.Println(string(data))
+
+	// Output: content 1
+}
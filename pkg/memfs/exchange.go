@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"slices"
+	"syscall"
+)
+
+// Exchange atomically exchanges the files or directories with the given
+// names in the directory or its subdirectories, like renameat2(2) with the
+// RENAME_EXCHANGE flag. Both must exist, but they do not have to be of the
+// same type. After the call, each name refers to the file previously found
+// under the other one, so at no point either of the names is missing.
+// Exchanging a file with itself does nothing. The symbolic links (see
+// [File.Symlink]) are exchanged, not the files they point to. Both moves are
+// reported to the hook set with [WithRenameHook].
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.ENOTDIR] when the instance is not a directory,
+//   - [fs.ErrInvalid] when any of the names is not valid,
+//   - [fs.ErrNotExist] when any of the files does not exist,
+//   - [syscall.EINVAL] when one of the files is an ancestor of the other,
+//   - [syscall.EPERM] when any of the files or their parent directories has
+//     attribute flags (see [Attr]) set,
+//   - [syscall.EROFS] when any of the files is read-only (see
+//     [WithReadOnly]),
+//   - [syscall.EBUSY] when any of the files is open and the sharing
+//     violations are turned on (see [WithSharingViolations]),
+//   - [syscall.ENOSPC] when moving any of the files to the place of the
+//     other exceeds any of the quotas (see [WithQuota]).
+func (fil *File) Exchange(a, b string) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "exchange",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	ea, err := fil.exchangeEntry(a)
+	if err != nil {
+		return err
+	}
+	eb, err := fil.exchangeEntry(b)
+	if err != nil {
+		return err
+	}
+	if ea == eb {
+		return nil
+	}
+	if ea.isAncestorOf(eb) || eb.isAncestorOf(ea) {
+		return fil.hookErr(&fs.PathError{
+			Op:   "exchange",
+			Path: a,
+			Err:  syscall.EINVAL,
+		})
+	}
+	for _, f := range []*File{ea, eb} {
+		if err = f.checkReadOnly("exchange"); err != nil {
+			return err
+		}
+		if err = f.checkSharing("exchange"); err != nil {
+			return err
+		}
+	}
+	for _, f := range []*File{ea, eb, ea.parent, eb.parent} {
+		if f.attr != 0 {
+			return fil.hookErr(&fs.PathError{
+				Op:   "exchange",
+				Path: f.path(),
+				Err:  syscall.EPERM,
+			})
+		}
+	}
+	pa, pb := ea.parent, eb.parent
+	var full string
+	switch {
+	case !pb.moveFits(ea, eb):
+		full = b
+	case !pa.moveFits(eb, ea):
+		full = a
+	}
+	if full != "" {
+		return fil.hookErr(&fs.PathError{
+			Op:   "exchange",
+			Path: full,
+			Err:  syscall.ENOSPC,
+		})
+	}
+
+	ma, mb := ea.mirrorPath(), eb.mirrorPath()
+	oa, ob := ea.path(), eb.path()
+	pa.entries[slices.Index(pa.entries, ea)] = eb
+	pb.entries[slices.Index(pb.entries, eb)] = ea
+	ea.parent, eb.parent = pb, pa
+	ea.info.name, eb.info.name = eb.info.name, ea.info.name
+	if hook := ea.renamesHook(); hook != nil {
+		hook(RenameEvent{OldPath: oa, NewPath: ea.path()})
+	}
+	if hook := eb.renamesHook(); hook != nil {
+		hook(RenameEvent{OldPath: ob, NewPath: eb.path()})
+	}
+	return mirrorExchange(ma, mb)
+}
+
+// exchangeEntry returns the file with the given name for [File.Exchange]
+// without following the symbolic link named by the last element of the name.
+func (fil *File) exchangeEntry(name string) (*File, error) {
+	ent, err := lopen(fil, name)
+	if err != nil {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "exchange",
+			Path: name,
+			Err:  unwrapPathErr(err),
+		})
+	}
+	return ent, nil
+}
+
+// IsAncestor returns true when the directory a is one of the parents of the
+// file b, so moving a into b, or into any directory in its tree, would make
+// a cycle. Returns false when a and b are the same file or any of them is
+// nil. [File.Rename], [File.Exchange] and [File.AddFile] use it to reject
+// such moves.
+func IsAncestor(a, b *File) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	return a.isAncestorOf(b)
+}
+
+// isAncestorOf returns true when the instance is one of the parents of the
+// given file.
+func (fil *File) isAncestorOf(file *File) bool {
+	for f := file.parent; f != nil; f = f.parent {
+		if f == fil {
+			return true
+		}
+	}
+	return false
+}
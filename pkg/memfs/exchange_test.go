@@ -0,0 +1,324 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Exchange(t *testing.T) {
+	t.Run("files in the same directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Exchange("file0", "file1")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file1", string(must.Value(dir.ReadFile("file0"))))
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("file1"))))
+		fil := must.Value(open(dir, "file0"))
+		assert.Equal(t, "file0", fil.Name())
+		assert.Same(t, dir, fil.parent)
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Value(mkdirAll(dir, "blue/app"))
+		must.Value(mkdirAll(dir, "green/app"))
+		must.Value(mkdirAll(dir, "live"))
+		green := must.Value(open(dir, "green"))
+
+		// --- When ---
+		err := dir.Exchange("green", "live")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, green, must.Value(open(dir, "live")))
+		assert.Equal(t, "live", green.Name())
+		assert.Equal(t, "live/app", must.Value(open(dir, "live/app")).path())
+		assert.Len(t, 0, must.Value(open(dir, "green")).entries)
+	})
+
+	t.Run("different directories and types", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub2 := must.Value(open(dir, "sub/sub2"))
+		fil := must.Value(open(dir, "file2"))
+
+		// --- When ---
+		err := dir.Exchange("sub/sub2", "file2")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, sub2, must.Value(open(dir, "file2")))
+		assert.Same(t, fil, must.Value(open(dir, "sub/sub2")))
+		assert.Same(t, dir, sub2.parent)
+		assert.Equal(t, "sub/sub2", fil.path())
+		want := "file6"
+		assert.Equal(t, want, string(must.Value(dir.ReadFile("file2/file6"))))
+		assert.Equal(t, "file2", string(must.Value(dir.ReadFile("sub/sub2"))))
+	})
+
+	t.Run("same file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Exchange("sub/file3", "sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := "file3"
+		assert.Equal(t, want, string(must.Value(dir.ReadFile("sub/file3"))))
+	})
+
+	t.Run("symbolic link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("file0", "link"))
+		link := must.Value(lopen(dir, "link"))
+
+		// --- When ---
+		err := dir.Exchange("link", "file1")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, link, must.Value(lopen(dir, "file1")))
+		assert.Equal(t, "file1", string(must.Value(dir.ReadFile("link"))))
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("file0"))))
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("file1"))))
+	})
+
+	t.Run("renames reported", func(t *testing.T) {
+		// --- Given ---
+		var have []RenameEvent
+		dir := tstDirMem()
+		WithRenameHook(func(ev RenameEvent) { have = append(have, ev) })(dir)
+
+		// --- When ---
+		err := dir.Exchange("file0", "sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []RenameEvent{
+			{OldPath: "file0", NewPath: "sub/file3"},
+			{OldPath: "sub/file3", NewPath: "file0"},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.Exchange("a", "b")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "exchange", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Exchange("file0", "sub/missing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "exchange", e.Op)
+		assert.Equal(t, "sub/missing", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+		want := "file0"
+		assert.Equal(t, want, string(must.Value(dir.ReadFile("file0"))))
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Exchange("../file0", "file1")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "exchange", e.Op)
+		assert.Equal(t, "../file0", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+	})
+
+	t.Run("error - ancestor", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			a, b string
+		}{
+			{"parent", "sub", "sub/sub2/file5"},
+			{"child", "sub/sub2", "sub"},
+			{"directory itself", ".", "file0"},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				dir := tstDirMem()
+
+				// --- When ---
+				err := dir.Exchange(tc.a, tc.b)
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "exchange", e.Op)
+				assert.Equal(t, tc.a, e.Path)
+				assert.Equal(t, syscall.EINVAL, e.Err)
+			})
+		}
+	})
+
+	t.Run("error - attributes", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			pth  string
+			attr Attr
+		}{
+			{"immutable file", "sub/file3", AttrImmutable},
+			{"append only file", "file0", AttrAppendOnly},
+			{"immutable parent", "sub", AttrImmutable},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				dir := tstDirMem()
+				must.Value(open(dir, tc.pth)).SetAttr(tc.attr)
+
+				// --- When ---
+				err := dir.Exchange("file0", "sub/file3")
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "exchange", e.Op)
+				assert.Equal(t, tc.pth, e.Path)
+				assert.Equal(t, syscall.EPERM, e.Err)
+				have := string(must.Value(dir.ReadFile("file0")))
+				assert.Equal(t, "file0", have)
+			})
+		}
+	})
+
+	t.Run("quota - same size", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithQuota(3))
+		must.Nil(root.WriteFile("file", []byte("def"), 0600))
+
+		// --- When ---
+		err := root.Exchange("file", "mnt/file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "def", string(must.Value(root.ReadFile("mnt/file"))))
+	})
+
+	t.Run("error - quota", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithQuota(5))
+		must.Nil(root.WriteFile("big", []byte("abcdef"), 0600))
+
+		// --- When ---
+		err := root.Exchange("big", "mnt/file")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "exchange", e.Op)
+		assert.Equal(t, "mnt/file", e.Path)
+		assert.Equal(t, syscall.ENOSPC, e.Err)
+		assert.Equal(t, "abc", string(must.Value(root.ReadFile("mnt/file"))))
+	})
+
+	t.Run("error - quota other way", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithQuota(5))
+		must.Nil(root.WriteFile("big", []byte("abcdef"), 0600))
+
+		// --- When ---
+		err := root.Exchange("mnt/file", "big")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, "mnt/file", err.(*fs.PathError).Path)
+	})
+
+	t.Run("error - sharing violation", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		must.Value(dir.OpenFile("sub/file3", os.O_RDONLY, 0))
+
+		// --- When ---
+		err := dir.Exchange("file0", "sub/file3")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "exchange", e.Op)
+		assert.Equal(t, "sub/file3", e.Path)
+		assert.Equal(t, syscall.EBUSY, e.Err)
+		have := string(must.Value(dir.ReadFile("file0")))
+		assert.Equal(t, "file0", have)
+	})
+}
+
+func Test_IsAncestor(t *testing.T) {
+	dir := tstDirMem()
+	sub := must.Value(open(dir, "sub"))
+	sub2 := must.Value(open(dir, "sub/sub2"))
+	file5 := must.Value(open(dir, "sub/sub2/file5"))
+
+	tt := []struct {
+		testN string
+
+		a    *File
+		b    *File
+		want bool
+	}{
+		{"parent", sub2, file5, true},
+		{"grandparent", sub, file5, true},
+		{"root", dir, file5, true},
+		{"same", sub, sub, false},
+		{"descendant", file5, sub, false},
+		{"sibling", must.Value(open(dir, "file0")), sub, false},
+		{"a nil", nil, sub, false},
+		{"b nil", sub, nil, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have := IsAncestor(tc.a, tc.b)
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
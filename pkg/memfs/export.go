@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/tar"
+	"cmp"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// Export writes the files and directories in the directory tree matching any
+// of the patterns to w in the given format, so big trees can produce small,
+// targeted golden files. The patterns have the [File.GlobEx] syntax and are
+// matched against the slash-separated paths relative to the directory. The
+// parent directories of the matching paths are exported too, so the archives
+// keep their permission bits. When no patterns are given, the whole tree is
+// exported. The formats are:
+//
+//   - [FormatTar] - a tar archive, see [File.WriteTar],
+//   - [FormatZip] - a zip archive, see [File.WriteZip],
+//   - [FormatTxtar] - the txtar format, see [File.Txtar],
+//   - [FormatCSV] and [FormatJSON] - the metadata records, see
+//     [File.ExportMetadata].
+//
+// Returns [path.ErrBadPattern] when a pattern is malformed and an error of
+// the [fs.PathError] type with [syscall.ENOTDIR] when the instance is not a
+// directory and with [fs.ErrInvalid] when the format is not supported.
+func (fil *File) Export(w io.Writer, format Format, patterns ...string) error {
+	return fil.ExportSelected(w, format, nil, patterns...)
+}
+
+// ExportSelected works like [File.Export] but exports only the files and
+// directories matching any of the patterns which are also selected by the
+// selector, for example, to audit the permissions set by the provisioning
+// tools:
+//
+//	exe := memfs.SelectAll(memfs.SelectType(0), memfs.SelectPerm(0111))
+//	err := root.ExportSelected(w, memfs.FormatCSV, exe)
+//
+// The selector is called with the slash-separated path relative to the
+// directory and the [fs.FileInfo] of every file and directory in the tree;
+// the symbolic links are not followed. The parent directories of the
+// selected paths are exported too. When the selector is nil, it works
+// exactly like [File.Export].
+func (fil *File) ExportSelected(
+	w io.Writer,
+	format Format,
+	sel Selector,
+	patterns ...string,
+) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "export",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	keep, err := fil.exportMatching(sel, patterns)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatTar:
+		return fil.writeTar(w, keep, nil)
+	case FormatZip:
+		return fil.writeZip(w, keep, nil)
+	case FormatTxtar:
+		out, err := fil.txtar(keep, nil)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, out)
+		return err
+	case FormatCSV, FormatJSON:
+		return fil.exportMetadata(w, format, keep)
+	default:
+		return fil.hookErr(&fs.PathError{
+			Op:   "export",
+			Path: fil.path(),
+			Err:  fs.ErrInvalid,
+		})
+	}
+}
+
+// exportMatching returns the function reporting the paths to export for
+// [File.ExportSelected]. Returns nil when there are no patterns and the
+// selector is nil.
+func (fil *File) exportMatching(
+	sel Selector,
+	patterns []string,
+) (func(string) bool, error) {
+	if len(patterns) == 0 && sel == nil {
+		return nil, nil
+	}
+	var pts []string
+	for _, pattern := range patterns {
+		exp, err := expandBraces(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, pt := range exp {
+			if err = validGlob(pt); err != nil {
+				return nil, err
+			}
+		}
+		pts = append(pts, exp...)
+	}
+
+	kept := make(map[string]bool)
+	fil.walk("", func(pth string, ent *File) {
+		pth = filepath.ToSlash(pth)
+		if len(pts) > 0 && !matchAny(pts, pth, false) {
+			return
+		}
+		if sel != nil {
+			info, _ := ent.Stat()
+			if !sel(pth, info) {
+				return
+			}
+		}
+		for ; pth != "."; pth = path.Dir(pth) {
+			kept[pth] = true
+		}
+	})
+	return func(pth string) bool { return kept[pth] }, nil
+}
+
+// WriteTar writes all files and directories in the directory tree to w as a
+// tar archive. Paths in the archive are relative to the directory, and the
+// file contents pass through the redaction rules in the given order. The
+// entries are written in the path order, and the modification times are not
+// set, so exporting the same tree always produces the same archive. The
+// symbolic links (see [File.Symlink]) are written as links.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory.
+func (fil *File) WriteTar(w io.Writer, rules ...Redaction) error {
+	return fil.writeTar(w, nil, rules)
+}
+
+// writeTar writes the files and directories in the directory tree for which
+// keep returns true to w as a tar archive. When keep is nil, all of them are
+// written. See [File.WriteTar] for details.
+func (fil *File) writeTar(
+	w io.Writer,
+	keep func(pth string) bool,
+	rules []Redaction,
+) error {
+	tw := tar.NewWriter(w)
+	add := func(pth string, ent *File, data []byte) error {
+		hdr := tarHeader(filepath.ToSlash(pth), ent, len(data))
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := fil.exportKept("tar", keep, rules, add); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// tarHeader returns the tar header of the file with the given
+// slash-separated path and content size.
+func tarHeader(pth string, ent *File, size int) *tar.Header {
+	hdr := &tar.Header{
+		Name:     pth,
+		Mode:     int64(ent.Mode().Perm()),
+		Size:     int64(size),
+		Typeflag: tar.TypeReg,
+	}
+	switch {
+	case ent.IsDir():
+		hdr.Name += "/"
+		hdr.Typeflag = tar.TypeDir
+	case ent.isSymlink():
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Linkname = ent.link
+	}
+	return hdr
+}
+
+// Txtar returns all files in the directory tree in the txtar format (see
+// golang.org/x/tools/txtar), which is convenient for golden files. Paths are
+// relative to the directory, and the file contents pass through the redaction
+// rules in the given order. The files are listed in the path order, and a
+// newline is added to the contents which don't end with one. The symbolic
+// links (see [File.Symlink]) are skipped.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory.
+func (fil *File) Txtar(rules ...Redaction) (string, error) {
+	return fil.txtar(nil, rules)
+}
+
+// txtar returns the files in the directory tree for which keep returns true
+// in the txtar format. When keep is nil, all of them are returned. See
+// [File.Txtar] for details.
+func (fil *File) txtar(
+	keep func(pth string) bool,
+	rules []Redaction,
+) (string, error) {
+	var out strings.Builder
+	add := func(pth string, ent *File, data []byte) error {
+		if ent.IsDir() || ent.isSymlink() {
+			return nil
+		}
+		out.WriteString("-- " + filepath.ToSlash(pth) + " --\n")
+		out.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			out.WriteByte('\n')
+		}
+		return nil
+	}
+	if err := fil.exportKept("txtar", keep, rules, add); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// export calls fn in the path order for every file and directory in the
+// directory tree with the file content passed through the redaction rules.
+// The content is nil for directories. The op is used as the operation name
+// in the returned errors.
+func (fil *File) export(
+	op string,
+	rules []Redaction,
+	fn func(pth string, ent *File, data []byte) error,
+) error {
+	return fil.exportKept(op, nil, rules, fn)
+}
+
+// exportKept works like [File.export] but skips the files and directories
+// for which keep returns false. The content of the skipped files is not
+// loaded. When keep is nil, nothing is skipped.
+func (fil *File) exportKept(
+	op string,
+	keep func(pth string) bool,
+	rules []Redaction,
+	fn func(pth string, ent *File, data []byte) error,
+) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   op,
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+
+	type entry struct {
+		pth string
+		ent *File
+	}
+	var ents []entry
+	fil.walk("", func(pth string, ent *File) {
+		if keep == nil || keep(filepath.ToSlash(pth)) {
+			ents = append(ents, entry{pth, ent})
+		}
+	})
+	slices.SortFunc(ents, func(a, b entry) int {
+		return cmp.Compare(a.pth, b.pth)
+	})
+
+	for _, e := range ents {
+		var data []byte
+		if !e.ent.IsDir() {
+			if err := e.ent.load(); err != nil {
+				return err
+			}
+			data = e.ent.mapCopy()
+			for _, rule := range rules {
+				var err error
+				if data, err = rule(filepath.ToSlash(e.pth), data); err != nil {
+					return err
+				}
+			}
+		}
+		if err := fn(e.pth, e.ent, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
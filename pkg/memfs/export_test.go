@@ -0,0 +1,473 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// errWriter is an [io.Writer] always failing with the error.
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func Test_File_WriteTar(t *testing.T) {
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteTar(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(FromTar(buf))
+		assert.Equal(t, must.Value(dir.List()), must.Value(have.List()))
+		content := must.Value(have.ReadFile("sub/sub2/file5"))
+		assert.Equal(t, "file5", string(content))
+	})
+
+	t.Run("entries in path order", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteTar(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		var have []string
+		tr := tar.NewReader(buf)
+		for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+			have = append(have, hdr.Name)
+		}
+		want := []string{
+			"file0", "file1", "file2", "sub/", "sub/file3", "sub/file4",
+			"sub/sub2/", "sub/sub2/file5", "sub/sub2/file6",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("same tree same archive", func(t *testing.T) {
+		// --- Given ---
+		buf0, buf1 := &bytes.Buffer{}, &bytes.Buffer{}
+
+		// --- When ---
+		must.Nil(tstDirMem().WriteTar(buf0))
+		must.Nil(tstDirMem().WriteTar(buf1))
+
+		// --- Then ---
+		assert.Equal(t, buf0.Bytes(), buf1.Bytes())
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteTar(buf, RedactPaths("sub/**/file5", "***"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(FromTar(buf))
+		content := must.Value(have.ReadFile("sub/sub2/file5"))
+		assert.Equal(t, "***", string(content))
+		content = must.Value(have.ReadFile("sub/sub2/file6"))
+		assert.Equal(t, "file6", string(content))
+		fil := must.Value(open(dir, "sub/sub2/file5"))
+		assert.Equal(t, "file5", fil.String())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.WriteTar(io.Discard)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "tar", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - writer", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		errTst := errors.New("test error")
+
+		// --- When ---
+		err := dir.WriteTar(errWriter{errTst})
+
+		// --- Then ---
+		assert.ErrorIs(t, errTst, err)
+	})
+}
+
+func Test_File_Txtar(t *testing.T) {
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.Txtar()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := "" +
+			"-- file0 --\nfile0\n" +
+			"-- file1 --\nfile1\n" +
+			"-- file2 --\nfile2\n" +
+			"-- sub/file3 --\nfile3\n" +
+			"-- sub/file4 --\nfile4\n" +
+			"-- sub/sub2/file5 --\nfile5\n" +
+			"-- sub/sub2/file6 --\nfile6\n"
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("content ending with newline and empty file", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.AddFile(MustFileWith("a", []byte("abc\n"))))
+		must.Nil(dir.AddFile(MustFileWith("b", nil)))
+
+		// --- When ---
+		have, err := dir.Txtar()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "-- a --\nabc\n-- b --\n", have)
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		rules := []Redaction{
+			RedactPaths("**/file{0,1,3,4,5,6}", "***"),
+			RedactPaths("**/file2", "secret"),
+			RedactPaths("**/file2", "***"),
+		}
+
+		// --- When ---
+		have, err := dir.Txtar(rules...)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := "" +
+			"-- file0 --\n***\n" +
+			"-- file1 --\n***\n" +
+			"-- file2 --\n***\n" +
+			"-- sub/file3 --\n***\n" +
+			"-- sub/file4 --\n***\n" +
+			"-- sub/sub2/file5 --\n***\n" +
+			"-- sub/sub2/file6 --\n***\n"
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("stripped and lazily loaded files", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		dir := NewRoot()
+		must.Nil(dir.AddFile(lazyFile([]byte("abc"), &loads)))
+		must.Nil(dir.AddFile(MustFileWith("stub", []byte{1, 2})))
+		must.Nil(dir.StripContents(func(pth string, _ *File) bool {
+			return pth == "stub"
+		}))
+
+		// --- When ---
+		have, err := dir.Txtar()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "-- file --\nabc\n-- stub --\n\x00\x00\n", have)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have, err := fil.Txtar()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "txtar", e.Op)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Equal(t, "", have)
+	})
+
+	t.Run("error - redaction", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.Txtar(RedactPaths("[", "***"))
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.Equal(t, "", have)
+	})
+
+	t.Run("error - load", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+		dir := NewRoot()
+		must.Nil(dir.AddFile(&File{
+			info: FileInfo{name: "file", size: 3, mode: 0600},
+			lazy: func() ([]byte, error) { return nil, errTst },
+		}))
+
+		// --- When ---
+		have, err := dir.Txtar()
+
+		// --- Then ---
+		assert.ErrorIs(t, errTst, err)
+		assert.Equal(t, "", have)
+	})
+}
+
+func Test_File_Export(t *testing.T) {
+	t.Run("tar", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().Export(buf, FormatTar, "sub/**/file5", "file1")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		var have []string
+		tr := tar.NewReader(buf)
+		for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+			have = append(have, hdr.Name)
+		}
+		want := []string{"file1", "sub/", "sub/sub2/", "sub/sub2/file5"}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().Export(buf, FormatZip, "sub/file{3,4}")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		r := bytes.NewReader(buf.Bytes())
+		have := must.Value(LazyFromZip(r, r.Size()))
+		want := ".\nsub\nsub/file3\nsub/file4\n"
+		assert.Equal(t, want, must.Value(have.List()))
+	})
+
+	t.Run("txtar", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().Export(buf, FormatTxtar, "**/file[56]")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := "" +
+			"-- sub/sub2/file5 --\nfile5\n" +
+			"-- sub/sub2/file6 --\nfile6\n"
+		assert.Equal(t, want, buf.String())
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().Export(buf, FormatJSON, "file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(LoadSkeleton(buf, FormatJSON))
+		assert.Equal(t, []string{"file0"}, names(must.Value(have.ReadDir(-1))))
+	})
+
+	t.Run("directory pattern", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().Export(buf, FormatTxtar, "sub/sub2")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("no patterns", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().Export(buf, FormatTxtar)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, must.Value(tstDirMem().Txtar()), buf.String())
+	})
+
+	t.Run("lazy files not matching are not loaded", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		dir := tstDirMem()
+		must.Nil(dir.AddFile(lazyFile([]byte("abc"), &loads)))
+
+		// --- When ---
+		err := dir.Export(io.Discard, FormatTar, "file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, loads)
+	})
+
+	t.Run("error - bad pattern", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().Export(io.Discard, FormatTar, "[")
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+	})
+
+	t.Run("error - not supported format", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().Export(io.Discard, Format(42))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "export", e.Op)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").Export(io.Discard, FormatTar)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "export", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - writer", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+
+		// --- When ---
+		err := tstDirMem().Export(errWriter{errTst}, FormatTxtar)
+
+		// --- Then ---
+		assert.ErrorIs(t, errTst, err)
+	})
+}
+
+func Test_File_ExportSelected(t *testing.T) {
+	t.Run("executable files", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.WriteFile("sub/sub2/run", []byte("run"), 0755))
+		must.Nil(dir.WriteFile("tool", []byte("tool"), 0700))
+		buf := &bytes.Buffer{}
+		sel := SelectAll(SelectType(0), SelectPerm(0111))
+
+		// --- When ---
+		err := dir.ExportSelected(buf, FormatTar, sel)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		var have []string
+		tr := tar.NewReader(buf)
+		for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+			have = append(have, hdr.Name)
+		}
+		want := []string{"sub/", "sub/sub2/", "sub/sub2/run", "tool"}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("selector and patterns", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.WriteFile("sub/run", []byte("run"), 0755))
+		must.Nil(dir.WriteFile("tool", []byte("tool"), 0755))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.ExportSelected(buf, FormatTxtar, SelectPerm(0111), "sub/*")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "-- sub/run --\nrun\n", buf.String())
+	})
+
+	t.Run("attribute", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(open(dir, "sub/file4")).SetAttr(AttrImmutable)
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.ExportSelected(buf, FormatTxtar, SelectAttr(AttrImmutable))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "-- sub/file4 --\nfile4\n", buf.String())
+	})
+
+	t.Run("nothing selected", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().ExportSelected(buf, FormatTxtar, SelectAny())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("nil selector", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().ExportSelected(buf, FormatTxtar, nil, "file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "-- file0 --\nfile0\n", buf.String())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").ExportSelected(io.Discard, FormatTar, nil)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+}
@@ -0,0 +1,267 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// Compile time checks of the files returned by [FaultInjector.Middleware].
+var (
+	_ fs.ReadDirFile = faultDir{}
+	_ io.ReaderAt    = faultSeeker{}
+	_ io.Seeker      = faultSeeker{}
+	_ io.WriterTo    = faultSeeker{}
+	_ io.ReaderFrom  = faultWriter{}
+	_ io.Writer      = faultWriter{}
+)
+
+// FaultInjector is a deterministic fault plan for testing the error handling
+// paths, for example, the cleanup done when the third write fails:
+//
+//	fi := NewFaultInjector().FailNth("write", 3, syscall.EIO)
+//	fsys := Chain(root.DirFS(), fi.Middleware())
+//
+// It counts the calls of every operation and fails the ones matching its
+// rules. The operation names are the ones listed in [Faults] and, for the
+// files opened with [FaultInjector.Middleware], "read", "write" and
+// "readdir". The rules are checked in the order they were added, and the
+// first matching one decides the error. It's safe for concurrent use.
+type FaultInjector struct {
+	mu    sync.Mutex
+	rules []faultRule    // The rules in the order they were added.
+	calls map[string]int // Number of calls of every operation.
+}
+
+// faultRule is a rule of [FaultInjector].
+type faultRule struct {
+	op   string                        // Operation name, empty for any.
+	err  error                         // Error returned by failed calls.
+	fail func(n int, name string) bool // Reports the failed calls.
+}
+
+// NewFaultInjector returns a new instance of [FaultInjector] without rules.
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{calls: make(map[string]int)}
+}
+
+// FailNth makes the nth call, counting from one, of the operation fail with
+// the given error. It panics when n is less than one.
+func (fi *FaultInjector) FailNth(
+	op string,
+	n int,
+	err error,
+) *FaultInjector {
+	if n < 1 {
+		panic("memfs.FaultInjector.FailNth: n less than one")
+	}
+	fail := func(cnt int, _ string) bool { return cnt == n }
+	return fi.add(faultRule{op: op, err: err, fail: fail})
+}
+
+// FailAfter makes all calls of the operation after the first n calls fail
+// with the given error, like a disk which fills up. When n is zero, all
+// calls fail. It panics when n is negative.
+func (fi *FaultInjector) FailAfter(
+	op string,
+	n int,
+	err error,
+) *FaultInjector {
+	if n < 0 {
+		panic("memfs.FaultInjector.FailAfter: negative n")
+	}
+	fail := func(cnt int, _ string) bool { return cnt > n }
+	return fi.add(faultRule{op: op, err: err, fail: fail})
+}
+
+// FailPath makes the calls of the operation on the names matching the
+// pattern fail with the given error. The pattern has the [File.GlobEx]
+// syntax, and the empty op matches all operations. It panics when the
+// pattern is malformed.
+func (fi *FaultInjector) FailPath(
+	op string,
+	pattern string,
+	err error,
+) *FaultInjector {
+	pts, e := compileGlobs(pattern)
+	if e != nil {
+		panic("memfs.FaultInjector.FailPath: " + e.Error())
+	}
+	fail := func(_ int, name string) bool {
+		return matchAny(pts, name, false)
+	}
+	return fi.add(faultRule{op: op, err: err, fail: fail})
+}
+
+// add adds the rule.
+func (fi *FaultInjector) add(rule faultRule) *FaultInjector {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.rules = append(fi.rules, rule)
+	return fi
+}
+
+// Calls returns the number of calls of the operation seen so far, including
+// the failed ones.
+func (fi *FaultInjector) Calls(op string) int {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	return fi.calls[op]
+}
+
+// Plan counts the call of the operation on the name and returns the error of
+// the first matching rule or nil. It has the signature of the plan taken by
+// [Faults], so the rules can be used without wrapping the opened files.
+func (fi *FaultInjector) Plan(op, name string) error {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.calls[op]++
+	for _, rule := range fi.rules {
+		if rule.op != "" && rule.op != op {
+			continue
+		}
+		if rule.fail(fi.calls[op], name) {
+			return rule.err
+		}
+	}
+	return nil
+}
+
+// Middleware returns a middleware working like [Faults] with the plan, which
+// also wraps the opened files, so the Read, ReadAt and WriteTo calls on them
+// are counted and can fail as the "read" operation, the Write and ReadFrom
+// calls as the "write" operation, and the ReadDir calls as the "readdir"
+// operation on the name they were opened with. The failed calls return an
+// error of the [fs.PathError] type wrapping the planned error. The wrapped
+// files implement [fs.File], [fs.ReadDirFile] for the directories, and
+// [io.Seeker], [io.ReaderAt] and [io.WriterTo] for the seekable files, so
+// the code under test takes the same paths as without the faults. The
+// writable files also implement [io.Writer] and [io.ReaderFrom]; other
+// methods are hidden.
+func (fi *FaultInjector) Middleware() Middleware {
+	return func(fsys fs.FS) fs.FS {
+		call := func(op, name string, fn func() error) error {
+			if err := fi.Plan(op, name); err != nil {
+				return &fs.PathError{Op: op, Path: name, Err: err}
+			}
+			return fn()
+		}
+		return mwFS{fsys: fsys, call: call, file: fi.wrap}.wrap()
+	}
+}
+
+// wrap returns the file opened with the name with the read, write and
+// ReadDir calls going through the plan.
+func (fi *FaultInjector) wrap(name string, f fs.File) fs.File {
+	ff := faultFile{File: f, fi: fi, name: name}
+	if d, ok := f.(fs.ReadDirFile); ok && isDir(f) {
+		return faultDir{faultFile: ff, dir: d}
+	}
+	if w, ok := f.(io.Writer); ok {
+		return faultWriter{faultSeeker: faultSeeker{ff}, w: w}
+	}
+	if _, ok := f.(io.Seeker); ok {
+		return faultSeeker{ff}
+	}
+	return ff
+}
+
+// faultFile is the file returned by [FaultInjector.Middleware].
+type faultFile struct {
+	fs.File
+	fi   *FaultInjector
+	name string // Name passed to Open.
+}
+
+// Read implements [fs.File] interface.
+func (f faultFile) Read(p []byte) (int, error) {
+	if err := f.plan("read"); err != nil {
+		return 0, err
+	}
+	return f.File.Read(p)
+}
+
+// plan returns an error of the [fs.PathError] type wrapping the planned
+// error for the operation or nil.
+func (f faultFile) plan(op string) error {
+	if err := f.fi.Plan(op, f.name); err != nil {
+		return &fs.PathError{Op: op, Path: f.name, Err: err}
+	}
+	return nil
+}
+
+// faultDir is the directory returned by [FaultInjector.Middleware].
+type faultDir struct {
+	faultFile
+	dir fs.ReadDirFile
+}
+
+// ReadDir implements [fs.ReadDirFile] interface.
+func (f faultDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if err := f.plan("readdir"); err != nil {
+		return nil, err
+	}
+	return f.dir.ReadDir(n)
+}
+
+// faultSeeker is the seekable file returned by [FaultInjector.Middleware].
+type faultSeeker struct{ faultFile }
+
+// Seek implements [io.Seeker] interface. It returns an error of the
+// [fs.PathError] type with [errors.ErrUnsupported] when the file is not
+// seekable.
+func (f faultSeeker) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := f.File.(io.Seeker); ok {
+		return s.Seek(offset, whence)
+	}
+	return 0, &fs.PathError{
+		Op:   "seek",
+		Path: f.name,
+		Err:  errors.ErrUnsupported,
+	}
+}
+
+// ReadAt implements [io.ReaderAt] interface.
+func (f faultSeeker) ReadAt(p []byte, off int64) (int, error) {
+	if err := f.plan("read"); err != nil {
+		return 0, err
+	}
+	return readAt(f.File, f.name, p, off)
+}
+
+// WriteTo implements [io.WriterTo] interface.
+func (f faultSeeker) WriteTo(w io.Writer) (int64, error) {
+	if err := f.plan("read"); err != nil {
+		return 0, err
+	}
+	return writeTo(f.File, w)
+}
+
+// faultWriter is the writable file returned by [FaultInjector.Middleware].
+type faultWriter struct {
+	faultSeeker
+	w io.Writer
+}
+
+// Write implements [io.Writer] interface.
+func (f faultWriter) Write(p []byte) (int, error) {
+	if err := f.plan("write"); err != nil {
+		return 0, err
+	}
+	return f.w.Write(p)
+}
+
+// ReadFrom implements [io.ReaderFrom] interface.
+func (f faultWriter) ReadFrom(r io.Reader) (int64, error) {
+	if err := f.plan("write"); err != nil {
+		return 0, err
+	}
+	if rf, ok := f.w.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(struct{ io.Writer }{f.w}, r)
+}
@@ -0,0 +1,369 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_FaultInjector_FailNth(t *testing.T) {
+	t.Run("fails only the nth call", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("open", 2, syscall.EIO)
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+
+		// --- When ---
+		_, err1 := fsys.Open("file0")
+		_, err2 := fsys.Open("file1")
+		_, err3 := fsys.Open("file2")
+
+		// --- Then ---
+		assert.NoError(t, err1)
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err2)
+		assert.Equal(t, "open", e.Op)
+		assert.Equal(t, "file1", e.Path)
+		assert.Equal(t, syscall.EIO, e.Err)
+		assert.NoError(t, err3)
+		assert.Equal(t, 3, fi.Calls("open"))
+	})
+
+	t.Run("other operations are counted separately", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("stat", 1, syscall.EIO)
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+		must.Value(fsys.Open("file0"))
+
+		// --- When ---
+		_, err := fs.Stat(fsys, "file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EIO, err)
+		assert.Equal(t, 1, fi.Calls("open"))
+		assert.Equal(t, 1, fi.Calls("stat"))
+	})
+
+	t.Run("panic - n less than one", func(t *testing.T) {
+		// --- Given ---
+		fn := func() { NewFaultInjector().FailNth("open", 0, syscall.EIO) }
+
+		// --- When ---
+		msg := assert.PanicMsg(t, fn)
+
+		// --- Then ---
+		assert.Equal(t, "memfs.FaultInjector.FailNth: n less than one", *msg)
+	})
+}
+
+func Test_FaultInjector_FailAfter(t *testing.T) {
+	t.Run("fails all calls after n", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailAfter("writefile", 2, syscall.ENOSPC)
+		fsys := Chain(NewSyncFS(tstDirMem()), fi.Middleware()).(WriteFS)
+
+		// --- When ---
+		err1 := fsys.WriteFile("a", []byte("a"), 0644)
+		err2 := fsys.WriteFile("b", []byte("b"), 0644)
+		err3 := fsys.WriteFile("c", []byte("c"), 0644)
+		err4 := fsys.WriteFile("d", []byte("d"), 0644)
+
+		// --- Then ---
+		assert.NoError(t, err1)
+		assert.NoError(t, err2)
+		assert.ErrorIs(t, syscall.ENOSPC, err3)
+		assert.ErrorIs(t, syscall.ENOSPC, err4)
+		_, err := fs.Stat(fsys, "c")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("zero fails all calls", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailAfter("readdir", 0, syscall.EIO)
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+
+		// --- When ---
+		_, err := fs.ReadDir(fsys, ".")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EIO, err)
+	})
+
+	t.Run("panic - negative n", func(t *testing.T) {
+		// --- Given ---
+		fn := func() { NewFaultInjector().FailAfter("open", -1, syscall.EIO) }
+
+		// --- When ---
+		msg := assert.PanicMsg(t, fn)
+
+		// --- Then ---
+		assert.Equal(t, "memfs.FaultInjector.FailAfter: negative n", *msg)
+	})
+}
+
+func Test_FaultInjector_FailPath(t *testing.T) {
+	t.Run("matching path", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailPath("readfile", "sub/**", syscall.EACCES)
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+
+		// --- When ---
+		_, errSub := fs.ReadFile(fsys, "sub/sub2/file5")
+		have, err := fs.ReadFile(fsys, "file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EACCES, errSub)
+		assert.NoError(t, err)
+		assert.Equal(t, "file0", string(have))
+	})
+
+	t.Run("any operation", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailPath("", "file{0,1}", syscall.EIO)
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+
+		// --- When ---
+		_, errO := fsys.Open("file0")
+		_, errS := fs.Stat(fsys, "file1")
+		_, err := fs.Stat(fsys, "file2")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EIO, errO)
+		assert.ErrorIs(t, syscall.EIO, errS)
+		assert.NoError(t, err)
+	})
+
+	t.Run("panic - bad pattern", func(t *testing.T) {
+		// --- Given ---
+		fn := func() { NewFaultInjector().FailPath("open", "[", syscall.EIO) }
+
+		// --- When ---
+		msg := assert.PanicMsg(t, fn)
+
+		// --- Then ---
+		want := "memfs.FaultInjector.FailPath: syntax error in pattern"
+		assert.Equal(t, want, *msg)
+	})
+}
+
+func Test_FaultInjector_Plan(t *testing.T) {
+	t.Run("first matching rule wins", func(t *testing.T) {
+		// --- Given ---
+		e1, e2 := errors.New("e1"), errors.New("e2")
+		fi := NewFaultInjector().
+			FailPath("open", "file0", e1).
+			FailAfter("open", 0, e2)
+
+		// --- When ---
+		err0 := fi.Plan("open", "file0")
+		err1 := fi.Plan("open", "file1")
+
+		// --- Then ---
+		assert.Same(t, e1, err0)
+		assert.Same(t, e2, err1)
+	})
+
+	t.Run("with Faults", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("stat", 1, syscall.EIO)
+		fsys := Chain(tstDirMem().FS(), Faults(fi.Plan))
+
+		// --- When ---
+		_, err1 := fs.Stat(fsys, "file0")
+		_, err2 := fs.Stat(fsys, "file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EIO, err1)
+		assert.NoError(t, err2)
+	})
+
+	t.Run("concurrent calls", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("open", 50, syscall.EIO)
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var failed int
+
+		// --- When ---
+		for range 100 {
+			wg.Go(func() {
+				if fi.Plan("open", "file") != nil {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+
+		// --- Then ---
+		assert.Equal(t, 1, failed)
+		assert.Equal(t, 100, fi.Calls("open"))
+	})
+}
+
+func Test_FaultInjector_Middleware(t *testing.T) {
+	t.Run("read", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("read", 2, syscall.EIO)
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+		f := must.Value(fsys.Open("sub/file3"))
+		buf := make([]byte, 2)
+
+		// --- When ---
+		n1, err1 := f.Read(buf)
+		n2, err2 := f.Read(buf)
+
+		// --- Then ---
+		assert.NoError(t, err1)
+		assert.Equal(t, 2, n1)
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err2)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "sub/file3", e.Path)
+		assert.Equal(t, syscall.EIO, e.Err)
+		assert.Equal(t, 0, n2)
+	})
+
+	t.Run("write", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailAfter("write", 1, syscall.ENOSPC)
+		dir := tstDirMem()
+		fsys := Chain(dir.DirFS(), fi.Middleware())
+		f := must.Value(fsys.Open("file0"))
+		w, ok := f.(io.Writer)
+		assert.True(t, ok)
+
+		// --- When ---
+		_, err1 := w.Write([]byte("A"))
+		_, err2 := w.Write([]byte("B"))
+
+		// --- Then ---
+		assert.NoError(t, err1)
+		assert.ErrorIs(t, syscall.ENOSPC, err2)
+		must.Nil(f.Close())
+		assert.Equal(t, "Aile0", string(must.Value(dir.ReadFile("file0"))))
+	})
+
+	t.Run("ReadAt and WriteTo", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("read", 2, syscall.EIO)
+		fsys := Chain(tstDirMem().FS(), ReadOnly(), fi.Middleware())
+		f := must.Value(fsys.Open("sub/file3"))
+		buf := make([]byte, 2)
+		out := &bytes.Buffer{}
+
+		// --- When ---
+		n1, err1 := f.(io.ReaderAt).ReadAt(buf, 1)
+		n2, err2 := f.(io.WriterTo).WriteTo(out)
+
+		// --- Then ---
+		assert.NoError(t, err1)
+		assert.Equal(t, 2, n1)
+		assert.Equal(t, "il", string(buf))
+		assert.ErrorIs(t, syscall.EIO, err2)
+		assert.Equal(t, int64(0), n2)
+		assert.Equal(t, 2, fi.Calls("read"))
+	})
+
+	t.Run("Seek", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector()
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+		f := must.Value(fsys.Open("sub/file3"))
+
+		// --- When ---
+		off, err := f.(io.Seeker).Seek(3, io.SeekStart)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), off)
+		assert.Equal(t, "e3", string(must.Value(io.ReadAll(f))))
+	})
+
+	t.Run("ReadFrom", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("write", 1, syscall.ENOSPC)
+		fsys := Chain(tstDirMem().DirFS(), fi.Middleware())
+		f := must.Value(fsys.Open("file0"))
+		rf, ok := f.(io.ReaderFrom)
+		assert.True(t, ok)
+
+		// --- When ---
+		n1, err1 := rf.ReadFrom(strings.NewReader("A"))
+		n2, err2 := rf.ReadFrom(strings.NewReader("B"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err1)
+		assert.Equal(t, int64(0), n1)
+		assert.NoError(t, err2)
+		assert.Equal(t, int64(1), n2)
+	})
+
+	t.Run("readdir", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("readdir", 1, syscall.EIO)
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+		f := must.Value(fsys.Open("sub"))
+		d, ok := f.(fs.ReadDirFile)
+		assert.True(t, ok)
+
+		// --- When ---
+		_, err1 := d.ReadDir(-1)
+		have, err2 := d.ReadDir(-1)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EIO, err1)
+		assert.NoError(t, err2)
+		assert.Equal(t, []string{"file3", "file4", "sub2"}, names(have))
+	})
+
+	t.Run("regular file is not a directory", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector()
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+
+		// --- When ---
+		f := must.Value(fsys.Open("file0"))
+
+		// --- Then ---
+		_, ok := f.(fs.ReadDirFile)
+		assert.False(t, ok)
+	})
+
+	t.Run("read only file system", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector()
+		fsys := Chain(tstDirMem().FS(), ReadOnly(), fi.Middleware())
+
+		// --- When ---
+		f := must.Value(fsys.Open("file0"))
+
+		// --- Then ---
+		_, ok := f.(io.Writer)
+		assert.False(t, ok)
+	})
+
+	t.Run("error - open", func(t *testing.T) {
+		// --- Given ---
+		fi := NewFaultInjector().FailNth("open", 1, syscall.EMFILE)
+		fsys := Chain(tstDirMem().FS(), fi.Middleware())
+
+		// --- When ---
+		f, err := fsys.Open("file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EMFILE, err)
+		assert.Nil(t, f)
+	})
+}
@@ -0,0 +1,255 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrHTTPStatus is returned by [Fetch] when the server responds with a status
+// code other than 2xx.
+var ErrHTTPStatus = errors.New("unexpected HTTP status")
+
+// Fetch downloads the archive from the URL and returns a new root directory
+// with the tree stored in it. The options are applied to the root directory.
+// Supported are tar, gzip compressed tar and zip archives. The archive format
+// is detected from the URL path extension (".tar", ".tar.gz", ".tgz" or
+// ".zip") or given in the "archive" query parameter as "tar", "tar.gz", "tgz"
+// or "zip".
+//
+// The archive is verified against the checksum given in the "checksum" query
+// parameter in the "type:value" format, where the type is one of "md5",
+// "sha1", "sha256" or "sha512" and the value is the hex-encoded checksum, for
+// example, "https://example.com/fixtures.zip?checksum=sha256:2c26b46b...".
+// The "archive" and "checksum" query parameters are not sent to the server.
+//
+// Returns an error of the [fs.PathError] type with [fs.ErrInvalid] when the
+// archive format is not supported or the checksum parameter is invalid,
+// [ErrHTTPStatus] when the server responds with a status code other than 2xx
+// and [ErrChecksum] when the archive does not match the checksum. The archive
+// entries are handled the same way as in [LazyFromZip], except the file
+// contents are loaded before Fetch returns and the tree is not read-only.
+func Fetch(
+	ctx context.Context,
+	rawURL string,
+	opts ...func(*File),
+) (*File, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	format := archiveFormat(u.Path, query.Get("archive"))
+	sum, want, sumErr := newChecksum(query.Get("checksum"))
+	if query.Has("archive") || query.Has("checksum") {
+		query.Del("archive")
+		query.Del("checksum")
+		u.RawQuery = query.Encode()
+	}
+	loc := u.String()
+	if format == "" || sumErr != nil {
+		return nil, &fs.PathError{
+			Op:   "fetch",
+			Path: loc,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loc, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, &fs.PathError{
+			Op:   "fetch",
+			Path: loc,
+			Err:  fmt.Errorf("%w: %s", ErrHTTPStatus, res.Status),
+		}
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if sum != nil {
+		sum.Write(data)
+		if !bytes.Equal(want, sum.Sum(nil)) {
+			return nil, &fs.PathError{
+				Op:   "fetch",
+				Path: loc,
+				Err:  ErrChecksum,
+			}
+		}
+	}
+
+	switch format {
+	case "zip":
+		return fetchZip(data, opts...)
+	case "tar":
+		return FromTar(bytes.NewReader(data), opts...)
+	default:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return FromTar(zr, opts...)
+	}
+}
+
+// archiveFormat returns the archive format given explicitly or detected from
+// the URL path extension. Returns an empty string when the format is not
+// supported.
+func archiveFormat(pth, format string) string {
+	if format == "" {
+		for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+			if strings.HasSuffix(pth, ext) {
+				return ext[1:]
+			}
+		}
+	}
+	switch format {
+	case "tar", "tar.gz", "tgz", "zip":
+		return format
+	}
+	return ""
+}
+
+// newChecksum returns the hash and the expected checksum described by the
+// "type:value" specification. Returns nil values when the specification is
+// empty, and [fs.ErrInvalid] when it is not valid.
+func newChecksum(spec string) (hash.Hash, []byte, error) {
+	if spec == "" {
+		return nil, nil, nil
+	}
+	typ, val, _ := strings.Cut(spec, ":")
+	var sum hash.Hash
+	switch typ {
+	case "md5":
+		sum = md5.New()
+	case "sha1":
+		sum = sha1.New()
+	case "sha256":
+		sum = sha256.New()
+	case "sha512":
+		sum = sha512.New()
+	default:
+		return nil, nil, fs.ErrInvalid
+	}
+	want, err := hex.DecodeString(val)
+	if err != nil || len(want) != sum.Size() {
+		return nil, nil, fs.ErrInvalid
+	}
+	return sum, want, nil
+}
+
+// fetchZip returns a new root directory with the tree stored in the zip
+// archive data. The options are applied to the root directory.
+func fetchZip(data []byte, opts ...func(*File)) (*File, error) {
+	root, err := fromZip(bytes.NewReader(data), int64(len(data)), opts...)
+	if err != nil {
+		return nil, err
+	}
+	root.walk("", func(_ string, ent *File) {
+		if err == nil {
+			err = ent.own()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// FromTar returns a new root directory with the tree stored in the
+// uncompressed tar archive read from r, for example, one written with
+// [File.WriteTar]. The permission bits of the files and directories are
+// kept, so the round trip preserves executables. The options are applied to
+// the root directory.
+//
+// Returns an error of the [fs.PathError] type with [fs.ErrInvalid] when the
+// archive has an entry with an invalid name (see [File.ImportTar]) or of a
+// type other than a directory or a regular file, wrapping [ErrImportLimit]
+// when the archive exceeds the limits (see [WithImportLimits]), and the
+// errors of the [archive/tar] package.
+func FromTar(r io.Reader, opts ...func(*File)) (*File, error) {
+	root := NewRoot(opts...)
+	lim := newImportLimiter(root, "tar")
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		name := path.Clean(hdr.Name)
+		mode := hdr.FileInfo().Mode()
+		if !validArchivePath(name) || !(mode.IsDir() || mode.IsRegular()) {
+			return nil, &fs.PathError{
+				Op:   "tar",
+				Path: hdr.Name,
+				Err:  fs.ErrInvalid,
+			}
+		}
+		if err = lim.add(hdr.Name, hdr.Size, -1); err != nil {
+			return nil, err
+		}
+
+		if mode.IsDir() {
+			dir, err := mkdirAll(root, name)
+			if err != nil {
+				return nil, err
+			}
+			dir.info.mode = fs.ModeDir | mode.Perm()
+			continue
+		}
+
+		dir, err := mkdirAll(root, path.Dir(name))
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		fil, err := FileWith(path.Base(name), content)
+		if err != nil {
+			return nil, &fs.PathError{
+				Op:   "tar",
+				Path: hdr.Name,
+				Err:  err,
+			}
+		}
+		fil.info.mode = mode.Perm()
+		if err = dir.AddFile(fil); err != nil {
+			return nil, err
+		}
+	}
+}
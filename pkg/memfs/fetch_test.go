@@ -0,0 +1,400 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstTar returns a tar archive with the given entries, gzip compressed when
+// gz is true.
+func tstTar(gz bool, entries ...arcEntry) []byte {
+	buf := &bytes.Buffer{}
+	var w io.Writer = buf
+	var zw *gzip.Writer
+	if gz {
+		zw = gzip.NewWriter(buf)
+		w = zw
+	}
+	tw := tar.NewWriter(w)
+	for _, ent := range entries {
+		hdr := &tar.Header{
+			Name:     ent.name,
+			Mode:     int64(ent.mode.Perm()),
+			Size:     int64(len(ent.content)),
+			Typeflag: tar.TypeReg,
+		}
+		switch {
+		case ent.mode.IsDir():
+			hdr.Typeflag = tar.TypeDir
+		case ent.mode&fs.ModeSymlink != 0:
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = ent.content
+			hdr.Size = 0
+		}
+		must.Nil(tw.WriteHeader(hdr))
+		if hdr.Typeflag == tar.TypeReg {
+			must.Value(tw.Write([]byte(ent.content)))
+		}
+	}
+	must.Nil(tw.Close())
+	if zw != nil {
+		must.Nil(zw.Close())
+	}
+	return buf.Bytes()
+}
+
+// tstTarDir returns a tar archive matching the structure created by
+// tstDirMem, gzip compressed when gz is true.
+func tstTarDir(gz bool) []byte {
+	return tstTar(
+		gz,
+		arcEntry{"./", fs.ModeDir | 0700, ""},
+		arcEntry{"./file0", 0600, "file0"},
+		arcEntry{"./file1", 0600, "file1"},
+		arcEntry{"./file2", 0600, "file2"},
+		arcEntry{"./sub/", fs.ModeDir | 0700, ""},
+		arcEntry{"./sub/file3", 0600, "file3"},
+		arcEntry{"./sub/file4", 0600, "file4"},
+		arcEntry{"./sub/sub2/file5", 0600, "file5"},
+		arcEntry{"./sub/sub2/file6", 0600, "file6"},
+	)
+}
+
+// tstServer returns a test server responding with the data for the paths
+// found in the map and with the 404 status code for other paths. It records
+// the requested URLs.
+func tstServer(
+	t *testing.T,
+	files map[string][]byte,
+) (*httptest.Server, *[]string) {
+	var reqs []string
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			reqs = append(reqs, r.URL.String())
+			data, ok := files[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(data)
+		},
+	))
+	t.Cleanup(srv.Close)
+	return srv, &reqs
+}
+
+// sha256Hex returns the hex-encoded SHA-256 checksum of the data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func Test_Fetch(t *testing.T) {
+	zipData := must.Value(io.ReadAll(tstZipDir()))
+	tarData := tstTarDir(false)
+	tgzData := tstTarDir(true)
+	srv, reqs := tstServer(t, map[string][]byte{
+		"/fixtures.zip":    zipData,
+		"/fixtures.tar":    tarData,
+		"/fixtures.tar.gz": tgzData,
+		"/fixtures.tgz":    tgzData,
+		"/fixtures":        zipData,
+		"/fixtures.bad":    []byte("abc"),
+	})
+	want := must.Value(tstDirMem().List())
+
+	tt := []struct {
+		testN string
+
+		pth string
+	}{
+		{"zip", "/fixtures.zip"},
+		{"tar", "/fixtures.tar"},
+		{"tar.gz", "/fixtures.tar.gz"},
+		{"tgz", "/fixtures.tgz"},
+		{"archive parameter", "/fixtures?archive=zip"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have, err := Fetch(context.Background(), srv.URL+tc.pth)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, want, must.Value(have.List()))
+			content := must.Value(have.ReadFile("sub/sub2/file5"))
+			assert.Equal(t, "file5", string(content))
+			sub := must.Value(open(have, "sub"))
+			assert.Equal(t, fs.ModeDir|0700, sub.Mode())
+		})
+	}
+
+	t.Run("content is loaded and writable", func(t *testing.T) {
+		// --- When ---
+		have, err := Fetch(context.Background(), srv.URL+"/fixtures.zip")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := must.Value(open(have, "sub/file3"))
+		assert.Nil(t, fil.lazy)
+		assert.Equal(t, []byte("file3"), fil.buf)
+		assert.Equal(t, Attr(0), fil.Attr())
+		must.Value(fil.Write([]byte("FILE")))
+		assert.Equal(t, "FILE3", string(fil.buf))
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		// --- When ---
+		have, err := Fetch(
+			context.Background(),
+			srv.URL+"/fixtures.tar",
+			WithFileAttr(AttrAppendOnly),
+		)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, AttrAppendOnly, have.Attr())
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		// --- Given ---
+		*reqs = nil
+		pth := "/fixtures.zip?a=1&checksum=sha256:" + sha256Hex(zipData)
+
+		// --- When ---
+		have, err := Fetch(context.Background(), srv.URL+pth)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, want, must.Value(have.List()))
+		assert.Equal(t, []string{"/fixtures.zip?a=1"}, *reqs)
+	})
+
+	t.Run("error - checksum mismatch", func(t *testing.T) {
+		// --- Given ---
+		pth := "/fixtures.zip?checksum=sha256:" + sha256Hex(tarData)
+
+		// --- When ---
+		have, err := Fetch(context.Background(), srv.URL+pth)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "fetch", e.Op)
+		assert.Equal(t, srv.URL+"/fixtures.zip", e.Path)
+		assert.Equal(t, ErrChecksum, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - invalid checksum", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			sum string
+		}{
+			{"unknown type", "crc32:00000000"},
+			{"no type", sha256Hex(zipData)},
+			{"not hex", "sha256:xyz"},
+			{"wrong length", "sha256:0000"},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				*reqs = nil
+				pth := "/fixtures.zip?checksum=" + tc.sum
+
+				// --- When ---
+				have, err := Fetch(context.Background(), srv.URL+pth)
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "fetch", e.Op)
+				assert.Equal(t, srv.URL+"/fixtures.zip", e.Path)
+				assert.Equal(t, fs.ErrInvalid, e.Err)
+				assert.Nil(t, have)
+				assert.Len(t, 0, *reqs)
+			})
+		}
+	})
+
+	t.Run("error - unknown archive format", func(t *testing.T) {
+		// --- When ---
+		have, err := Fetch(context.Background(), srv.URL+"/fixtures.bad")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "fetch", e.Op)
+		assert.Equal(t, srv.URL+"/fixtures.bad", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - unknown archive parameter", func(t *testing.T) {
+		// --- When ---
+		have, err := Fetch(context.Background(), srv.URL+"/fixtures?archive=7z")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - status", func(t *testing.T) {
+		// --- When ---
+		have, err := Fetch(context.Background(), srv.URL+"/missing.zip")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "fetch", e.Op)
+		assert.Equal(t, srv.URL+"/missing.zip", e.Path)
+		assert.ErrorIs(t, ErrHTTPStatus, err)
+		assert.ErrorContain(t, "404 Not Found", err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - canceled context", func(t *testing.T) {
+		// --- Given ---
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// --- When ---
+		have, err := Fetch(ctx, srv.URL+"/fixtures.zip")
+
+		// --- Then ---
+		assert.ErrorIs(t, context.Canceled, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - invalid URL", func(t *testing.T) {
+		// --- When ---
+		have, err := Fetch(context.Background(), "http://[::1")
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - not a zip archive", func(t *testing.T) {
+		// --- Given ---
+		srv, _ := tstServer(t, map[string][]byte{"/bad.zip": []byte("abc")})
+
+		// --- When ---
+		have, err := Fetch(context.Background(), srv.URL+"/bad.zip")
+
+		// --- Then ---
+		assert.ErrorIs(t, zip.ErrFormat, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - not a gzip archive", func(t *testing.T) {
+		// --- Given ---
+		data := []byte("not a gzip compressed archive")
+		srv, _ := tstServer(t, map[string][]byte{"/bad.tgz": data})
+
+		// --- When ---
+		have, err := Fetch(context.Background(), srv.URL+"/bad.tgz")
+
+		// --- Then ---
+		assert.ErrorIs(t, gzip.ErrHeader, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_FromTar(t *testing.T) {
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		data := tstTar(
+			false,
+			arcEntry{"dir/file", 0640, "abc"},
+			arcEntry{"dir/", fs.ModeDir | 0750, ""},
+		)
+
+		// --- When ---
+		have, err := FromTar(bytes.NewReader(data))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := must.Value(open(have, "dir/file"))
+		assert.Equal(t, fs.FileMode(0640), fil.Mode())
+		assert.Equal(t, "abc", string(fil.buf))
+		dir := must.Value(open(have, "dir"))
+		assert.Equal(t, fs.ModeDir|0750, dir.Mode())
+	})
+
+	t.Run("error - invalid path", func(t *testing.T) {
+		// --- Given ---
+		data := tstTar(false, arcEntry{"../file", 0600, "abc"})
+
+		// --- When ---
+		have, err := FromTar(bytes.NewReader(data))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "tar", e.Op)
+		assert.Equal(t, "../file", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - symbolic link", func(t *testing.T) {
+		// --- Given ---
+		data := tstTar(false, arcEntry{"link", fs.ModeSymlink, "file"})
+
+		// --- When ---
+		have, err := FromTar(bytes.NewReader(data))
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - file used as a directory", func(t *testing.T) {
+		// --- Given ---
+		data := tstTar(
+			false,
+			arcEntry{"file", 0600, "abc"},
+			arcEntry{"file/sub", 0600, "abc"},
+		)
+
+		// --- When ---
+		have, err := FromTar(bytes.NewReader(data))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - truncated", func(t *testing.T) {
+		// --- Given ---
+		data := tstTar(false, arcEntry{"file", 0600, "abc"})
+
+		// --- When ---
+		have, err := FromTar(bytes.NewReader(data[:514]))
+
+		// --- Then ---
+		assert.ErrorIs(t, io.ErrUnexpectedEOF, err)
+		assert.Nil(t, have)
+	})
+}
@@ -9,10 +9,14 @@ import (
 	"errors"
 	"io"
 	"io/fs"
+	"math/rand/v2"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -45,6 +49,12 @@ func WithFileOffset(off int) func(*File) {
 // [File].
 func WithFileAppend(fil *File) { fil.flag |= os.O_APPEND }
 
+// WithNonConsumingString is a [File] constructor function option making the
+// [File.String] method not advance the offset, so it can be used, for
+// example, for debugging without affecting the following reads. When used on
+// a directory, it applies to all files in its tree.
+func WithNonConsumingString(fil *File) { fil.peek = true }
+
 // WithFileFlag is a [File] constructor function option setting flags. Flags
 // are the same as for [os.OpenFile].
 //
@@ -71,6 +81,7 @@ var (
 	_ fs.FS          = &File{}
 	_ fs.ReadDirFile = &File{}
 	_ fs.ReadFileFS  = &File{}
+	_ fs.ReadLinkFS  = &File{}
 )
 
 // A File is a variable-sized buffer of bytes representing a file or directory.
@@ -82,6 +93,77 @@ type File struct {
 	parent  *File    // Parent directory (nil for the root directory).
 	cursor  int      // Used as [File.ReadDir] cursor.
 	entries []*File  // Entries when the file represents a directory.
+	listing []*File  // Entries snapshot used by [File.ReadDir].
+
+	locks []rangeLock // Advisory byte-range locks.
+	stub  bool        // Content stripped, the size is kept in info.size.
+	csum  bool        // Checksum verification mode.
+	sum   uint32      // Content checksum in the checksum verification mode.
+
+	errHook func(error) error // Error hook, see [WithErrorHook].
+
+	scan  func(pth string, data []byte) error // See [WithContentScanner].
+	dirty bool                                // Modified since last scan.
+	wrote bool                                // Modified since last close.
+	prev  []byte                              // Content before modification.
+
+	attr Attr // Attribute flags.
+
+	lazy   func() ([]byte, error) // Lazily loaded content source.
+	loaded bool                   // Lazily loaded content is in memory.
+	keep   bool                   // See [WithLazyCache].
+
+	tailMu sync.Mutex   // Guards tails.
+	tails  []*tail      // Subscriptions created by [File.Tail].
+	ntail  atomic.Int32 // Number of tails, checked without locking.
+
+	refs     int   // Number of open handles, see [File.OpenCount].
+	shareDel int   // Open handles with the [OpenShareDelete] flag.
+	sharing  bool  // See [WithSharingViolations].
+	reserved int64 // Bytes reserved with [File.Reserve].
+
+	statHook func(pth string, st Stats) // See [WithStatsHook].
+	stats    Stats                      // Statistics since open or close.
+	nostats  bool                       // See [WithNoStats].
+	start    time.Time                  // Start of the statistics.
+
+	mirror string // See [File.MirrorTo].
+
+	growth float64 // See [WithGrowthFactor].
+	exact  bool    // See [WithExactAlloc].
+
+	block int // Block size, see [NewBlockFile].
+
+	rdonly  bool          // See [WithReadOnly].
+	once    bool          // See [WithWriteOnce].
+	quota   int64         // See [WithQuota].
+	latency time.Duration // See [WithLatency].
+	nodelay bool          // Latency already applied by a [SyncFS] handle.
+	rchunk  int           // See [WithMaxReadChunk].
+	wchunk  int           // See [WithMaxWriteChunk].
+
+	peek    bool // See [WithNonConsumingString].
+	lenient bool // See [WithLenientPaths].
+
+	limits *ImportLimits // See [WithImportLimits].
+	rnd    *rand.PCG     // See [WithTempSeed].
+	link   string        // Symbolic link destination, see [File.Symlink].
+	hops   int           // See [WithMaxSymlinks].
+	atime  time.Time     // Access time, see [File.Chtimes].
+	skew   *timeSkew     // See [WithSetTimeSkew].
+
+	sparse   bool                           // See [WithSparse].
+	holes    []Hole                         // Holes in the sparse mode.
+	holeHook func(pth string, ev HoleEvent) // See [WithHoleHook].
+
+	nocase     bool                 // See [WithCaseInsensitive].
+	renameHook func(ev RenameEvent) // See [WithRenameHook].
+
+	progHook func(pth string, done, total int64) // See [WithProgress].
+	throt    *Throttle                           // See [WithThrottle].
+	bcast    *Broadcast                          // See [File.AddBroadcast].
+
+	shared bool // Buffer shared with a view, see [File.Freeze].
 }
 
 // NewFile returns a new instance of [File] with an initial capacity of
@@ -121,21 +203,28 @@ func FileWith(name string, content []byte, opts ...func(*File)) (*File, error) {
 }
 
 // NewDirectory returns a new instance of [File] representing a directory.
-func NewDirectory(name string) (*File, error) {
+func NewDirectory(name string, opts ...func(*File)) (*File, error) {
 	dir, err := FileWith(name, nil)
 	if err != nil {
 		return nil, err
 	}
 	dir.info.size = 4096
 	dir.info.mode = 0700 | os.ModeDir
+	for _, opt := range opts {
+		opt(dir)
+	}
 	return dir, nil
 }
 
 // NewRoot returns a new instance of [File] representing the root directory.
 // The root directory is a nameless special directory that contains all other
 // files and directories.
-func NewRoot() *File {
-	return &File{info: FileInfo{size: 4096, mode: 0700 | os.ModeDir}}
+func NewRoot(opts ...func(*File)) *File {
+	root := &File{info: FileInfo{size: 4096, mode: 0700 | os.ModeDir}}
+	for _, opt := range opts {
+		opt(root)
+	}
+	return root
 }
 
 // NewBuffer returns a new instance of [File] with the name "memfile" and
@@ -153,79 +242,112 @@ func NewBuffer(content []byte) *File {
 }
 
 // AddFile adds a file to the directory. Returns [fs.ErrExist] if the file by
-// that name already exists, [fs.ErrInvalid] if the file is not a regular file
-// or a directory. Returns [fs.ErrInvalid] if the file name is a path.
+// that name already exists, [fs.ErrInvalid] if the file is not a regular file,
+// a directory or a symbolic link (see [File.Symlink]). Returns [fs.ErrInvalid]
+// if the file name is a path. Returns an error of the [fs.PathError] type with
+// [syscall.EPERM] when the directory is immutable (see [Attr]), [syscall.EROFS]
+// when it's read-only (see [WithReadOnly]) and [syscall.ENOSPC] when the file
+// would exceed the quota (see [WithQuota]). Returns an error of the
+// [fs.PathError] type with [syscall.EINVAL] when the file is the directory
+// or one of its parents (see [IsAncestor]). In the write-through mode (see
+// [File.MirrorTo]), it returns the errors of the [os] package when the file
+// cannot be written.
 func (fil *File) AddFile(file *File) error {
 	if !fil.IsDir() {
-		return &fs.PathError{
+		return fil.hookErr(&fs.PathError{
 			Op:   "open",
 			Path: filepath.Join(fil.info.name, file.info.Name()),
 			Err:  syscall.ENOTDIR,
-		}
+		})
 	}
 
 	if file.parent != nil {
-		return &fs.PathError{
+		return fil.hookErr(&fs.PathError{
 			Op:   "AddFile",
 			Path: file.path(),
 			Err:  ErrHasParent,
-		}
+		})
+	}
+
+	if file == fil || file.isAncestorOf(fil) {
+		return fil.hookErr(&fs.PathError{
+			Op:   "AddFile",
+			Path: file.path(),
+			Err:  syscall.EINVAL,
+		})
 	}
 
 	switch file.Type() {
-	case fs.ModeDir, fs.FileMode(0):
+	case fs.ModeDir, fs.ModeSymlink, fs.FileMode(0):
 	default:
-		return fs.ErrInvalid
+		return fil.hookErr(fs.ErrInvalid)
 	}
 
-	var found *File
-	for _, f := range fil.entries {
-		if f.Name() == file.Name() {
-			found = f
-			break
-		}
+	var errno syscall.Errno
+	left, limited := fil.quotaLeft()
+	switch {
+	case fil.readOnly():
+		errno = syscall.EROFS
+	case fil.attr&AttrImmutable != 0:
+		errno = syscall.EPERM
+	case limited && file.usage() > left:
+		errno = syscall.ENOSPC
+	}
+	if errno != 0 {
+		return fil.hookErr(&fs.PathError{
+			Op:   "AddFile",
+			Path: filepath.Join(fil.path(), file.Name()),
+			Err:  errno,
+		})
 	}
-	if found != nil {
-		return fs.ErrExist
+
+	if fil.entry(file.Name()) != nil {
+		return fil.hookErr(fs.ErrExist)
 	}
 	fil.entries = append(fil.entries, file)
 	file.parent = fil
-	return nil
+	return file.mirrorAdd()
 }
 
 // ReadDir implements [fs.ReadDirFile] interface.
+//
+// The first call takes a snapshot of the directory entries in the name
+// order, and the following calls return the entries from the snapshot. The
+// entries added to the directory after the first call are not returned, and
+// the removed ones are still returned, so listing a directory while it's
+// being modified never returns an entry twice or skips an entry present
+// before the listing started. The snapshot and the cursor are reset
+// by [File.Close]. The instance is not safe for concurrent use, concurrent
+// listing and modification must be synchronized by the caller.
 func (fil *File) ReadDir(n int) ([]fs.DirEntry, error) {
 	if !fil.IsDir() {
-		return nil, &fs.PathError{
+		return nil, fil.hookErr(&fs.PathError{
 			Op:   "ReadDir",
 			Path: fil.path(),
 			Err:  syscall.ENOTDIR,
-		}
+		})
 	}
-
-	slices.SortFunc(fil.entries, func(a, b *File) int {
-		return cmp.Compare(a.Name(), b.Name())
-	})
+	listing := fil.snapshotEntries()
 
 	// If n <= 0, return all remaining entries.
 	if n <= 0 {
-		n = len(fil.entries) - fil.cursor
+		n = len(listing) - fil.cursor
 	}
 
 	// Check if we've reached the end.
-	if fil.cursor >= len(fil.entries) {
+	if fil.cursor >= len(listing) {
 		return nil, io.EOF
 	}
 
 	// Calculate how many entries to return.
 	end := fil.cursor + n
-	if end > len(fil.entries) {
-		end = len(fil.entries)
+	if end > len(listing) {
+		end = len(listing)
 	}
 
 	ets := make([]fs.DirEntry, 0, end-fil.cursor)
 	for i := fil.cursor; i < end; i++ {
-		file := fil.entries[i]
+		file := listing[i]
 		info, err := file.Stat()
 		if err != nil {
 			return nil, err
@@ -236,14 +358,35 @@ func (fil *File) ReadDir(n int) ([]fs.DirEntry, error) {
 	return ets, nil
 }
 
-// ReadFile implements [fs.ReadFileFS] interface.
+// snapshotEntries returns the snapshot of the directory entries used by
+// [File.ReadDir] taking it when there is none.
+func (fil *File) snapshotEntries() []*File {
+	if fil.listing == nil {
+		fil.sortEntries()
+		fil.listing = append([]*File{}, fil.entries...)
+	}
+	return fil.listing
+}
+
+// sortEntries sorts the directory entries by name.
+func (fil *File) sortEntries() {
+	slices.SortFunc(fil.entries, func(a, b *File) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+}
+
+// ReadFile implements [fs.ReadFileFS] interface. Reading a directory returns
+// the same results as [os.ReadFile] does: an empty, non-nil slice and an
+// error of the [fs.PathError] type with the "read" operation and
+// [syscall.EISDIR]. The file system returned by [File.DirFS] returns a nil
+// slice instead, like the one returned by [os.DirFS].
 func (fil *File) ReadFile(name string) ([]byte, error) {
 	if !fil.IsDir() {
-		return nil, &fs.PathError{
+		return nil, fil.hookErr(&fs.PathError{
 			Op:   "ReadFile",
 			Path: fil.path(),
 			Err:  syscall.ENOTDIR,
-		}
+		})
 	}
 	return fs.ReadFile(fsOnly{fil}, name)
 }
@@ -258,23 +401,32 @@ func (fil *File) IsDir() bool { return fil.info.IsDir() }
 func (fil *File) Type() fs.FileMode { return fil.info.Type() }
 
 // Stat returns information about the in-memory file, the size is the length of
-// the underlying buffer, modification time is always zero value time and
-// [fs.FileInfo.Sys] always returns nil.
+// the underlying buffer, modification time is the one set with [File.Chtimes]
+// and [fs.FileInfo.Sys] returns an instance of [SysInfo].
 func (fil *File) Stat() (fs.FileInfo, error) {
 	info := fil.info
 	info.size = fil.Size()
+	info.sys = fil.Sys()
 	return info, nil
 }
 
 // Info returns information about the in-memory file, the size is the length of
-// the underlying buffer, modification time is always zero value time and
-// [fs.FileInfo.Sys] always returns nil.
+// the underlying buffer, modification time is the one set with [File.Chtimes]
+// and [fs.FileInfo.Sys] returns an instance of [SysInfo].
 func (fil *File) Info() (fs.FileInfo, error) { return fil.Stat() }
 
-// Size implements [fs.FileInfo] interface. Always returns 4096 for directories.
+// Size implements [fs.FileInfo] interface. Always returns 4096 for directories,
+// the length of the destination for symbolic links, and the number of bytes
+// written to the broadcast for the broadcast files (see [File.AddBroadcast]).
 func (fil *File) Size() int64 {
+	if fil.isSymlink() {
+		return int64(len(fil.link))
+	}
+	if fil.bcast != nil {
+		return int64(fil.bcast.Len())
+	}
 	if !fil.IsDir() {
-		return int64(len(fil.buf))
+		return int64(fil.Len())
 	}
 	return fil.info.size
 }
@@ -282,22 +434,49 @@ func (fil *File) Size() int64 {
 // Mode implements [fs.FileInfo] interface.
 func (fil *File) Mode() fs.FileMode { return fil.info.mode }
 
-// ModTime implements [fs.FileInfo] interface - always returns zero value time.
+// ModTime implements [fs.FileInfo] interface. Returns the modification time
+// set with [File.Chtimes], the modification times are not updated by writes,
+// so it's zero value time by default.
 func (fil *File) ModTime() time.Time { return fil.info.ModTime() }
 
-// Sys implements [fs.FileInfo] interface - always returns nil.
-func (fil *File) Sys() any { return fil.info.Sys() }
+// Sys implements [fs.FileInfo] interface - always returns an instance of
+// [*SysInfo].
+func (fil *File) Sys() any {
+	nlink := uint64(1)
+	if fil.IsDir() {
+		nlink = 2
+		for _, ent := range fil.entries {
+			if ent.IsDir() {
+				nlink++
+			}
+		}
+	}
+	return &SysInfo{Nlink: nlink, Atime: fil.atime, Attr: fil.attr}
+}
 
 // Open implements [fs.FS] interface.
-func (fil *File) Open(name string) (fs.File, error) { return open(fil, name) }
+func (fil *File) Open(name string) (fs.File, error) {
+	f, err := open(fil, name)
+	if err != nil {
+		return nil, fil.hookErr(err)
+	}
+	if f.bcast != nil {
+		return f.bcast.file(f), nil
+	}
+	f.opened()
+	return f, nil
+}
 
-// FS returns a file system [fs.FS] for the list of files in the directory.
-// Returns nil if the file is not a directory.
+// FS returns a file system [fs.FS] for the directory tree which passes the
+// [testing/fstest.TestFS] conformance tests. The files opened through the
+// symbolic links report the names of the links, and ReadDir returns all
+// entries on every call. Returns nil if the file is not a directory.
 //
 // The result implements:
 //   - [io/fs.StatFS],
 //   - [io/fs.ReadFileFS],
-//   - [io/fs.ReadDirFS].
+//   - [io/fs.ReadDirFS],
+//   - [io/fs.ReadLinkFS].
 func (fil *File) FS() fs.FS {
 	if fil.IsDir() {
 		return fsDir{dir: fil}
@@ -306,58 +485,149 @@ func (fil *File) FS() fs.FS {
 }
 
 // Release releases ownership of the underlying buffer, the caller should not
-// use this instance after this call.
+// use this instance after this call. When the file has open handles (see
+// [File.OpenCount]), the ownership is not released; the file keeps its
+// content, and Release returns its copy, so the handles never share the
+// buffer with the caller. Returns nil when the content of a lazily loaded
+// file cannot be loaded.
 func (fil *File) Release() []byte {
+	if fil.refs > 0 {
+		if fil.load() != nil {
+			return nil
+		}
+		return fil.mapCopy()
+	}
+	if fil.own() != nil {
+		return nil
+	}
+	fil.materialize()
 	buf := fil.buf
 	fil.off = 0
 	fil.buf = nil
+	fil.holes = nil
+	fil.updateSum()
+	fil.notifyTails()
 	return buf
 }
 
+// ReleaseCopy returns a copy of the content of the file, leaving the file
+// usable, unlike [File.Release]. The offset is not changed. Returns nil when
+// the content of a lazily loaded file cannot be loaded.
+func (fil *File) ReleaseCopy() []byte {
+	if fil.load() != nil {
+		return nil
+	}
+	return fil.mapCopy()
+}
+
+// TakeString works like [File.Release] but returns the content as a string.
+// Converting the buffer to a string copies it once, the package doesn't use
+// [unsafe] to avoid it, but the released buffer is not kept by the file, so
+// it's garbage collected after the call. Returns an empty string when the
+// content of a lazily loaded file cannot be loaded.
+func (fil *File) TakeString() string {
+	return string(fil.Release())
+}
+
 // Write writes the contents of p to the underlying buffer at the current
 // offset, growing the buffer as needed. The return value n is the length of p;
-// returns an error when the file represents a directory.
+// returns an error when the file represents a directory. See
+// [WithMaxWriteChunk] for the short writes.
 func (fil *File) Write(p []byte) (n int, err error) {
 	if fil.IsDir() {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "write",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
+	}
+	if err := fil.checkAttr("write"); err != nil {
+		return 0, err
+	}
+	if err := fil.own(); err != nil {
+		return 0, err
+	}
+	var short bool
+	if c := fil.writeChunk(); c > 0 && len(p) > c {
+		p, short = p[:c], true
+	}
+	if err := fil.checkBlock(fil.writeOffset(), len(p)); err != nil {
+		return 0, err
+	}
+	grow := fil.growsBy(fil.writeOffset(), len(p))
+	if err := fil.checkQuota("write", grow); err != nil {
+		return 0, err
+	}
+	n = fil.write(p)
+	fil.countWrite(n)
+	if err = fil.mirrorContent(); err == nil && short {
+		err = fil.hookErr(io.ErrShortWrite)
 	}
-	return fil.write(p), nil
+	return n, err
 }
 
 // WriteByte writes a byte b to the underlying buffer at the current offset.
 // Returns an error when the file represents a directory.
 func (fil *File) WriteByte(b byte) error {
 	if fil.IsDir() {
-		return &fs.PathError{
+		return fil.hookErr(&fs.PathError{
 			Op:   "write",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
 	}
-	fil.write([]byte{b})
-	return nil
+	if err := fil.checkAttr("write"); err != nil {
+		return err
+	}
+	if err := fil.own(); err != nil {
+		return err
+	}
+	if err := fil.checkBlock(fil.writeOffset(), 1); err != nil {
+		return err
+	}
+	grow := fil.growsBy(fil.writeOffset(), 1)
+	if err := fil.checkQuota("write", grow); err != nil {
+		return err
+	}
+	fil.countWrite(fil.write([]byte{b}))
+	return fil.mirrorContent()
 }
 
 // WriteAt writes len(p) bytes to the underlying buffer starting at the current
 // offset. It returns the number of bytes written; err is returned only when
-// the file was opened with an [os.O_APPEND] flag or the file represents a
-// directory. It does not change the offset.
+// the file was opened with an [os.O_APPEND] flag, the file represents a
+// directory or its attribute flags (see [Attr]) do not permit writes. It does
+// not change the offset.
 func (fil *File) WriteAt(p []byte, off int64) (n int, err error) {
 	if fil.IsDir() {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "write",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
+	}
+	if err := fil.checkAttr("write"); err != nil {
+		return 0, err
+	}
+	if err := fil.own(); err != nil {
+		return 0, err
 	}
 
 	if fil.flag&os.O_APPEND != 0 {
-		return 0, errWriteAtInAppendMode
+		return 0, fil.hookErr(errWriteAtInAppendMode)
+	}
+	if err := fil.checkBlock(off, len(p)); err != nil {
+		return 0, err
 	}
+	if err := fil.checkQuota("write", fil.growsBy(off, len(p))); err != nil {
+		return 0, err
+	}
+	if len(p) == 0 {
+		fil.countWrite(0)
+		return 0, nil
+	}
+	fil.snapshot()
+	fil.materialize()
 
 	prev := fil.off
 	c := cap(fil.buf)
@@ -365,7 +635,8 @@ func (fil *File) WriteAt(p []byte, off int64) (n int, err error) {
 
 	// Handle writing beyond capacity.
 	if int(off)+pl > c {
-		fil.off = c // So tryGrowByReslice returns false.
+		fil.gap(len(fil.buf), int(off))
+		fil.off = len(fil.buf) // So tryGrowByReslice returns false.
 		fil.grow(int(off) + pl - len(fil.buf))
 		fil.buf = fil.buf[:int(off)+pl]
 	}
@@ -373,7 +644,8 @@ func (fil *File) WriteAt(p []byte, off int64) (n int, err error) {
 	fil.off = int(off)
 	n = fil.write(p)
 	fil.off = prev
-	return n, nil
+	fil.countWrite(n)
+	return n, fil.mirrorContent()
 }
 
 // WriteTo writes data to w starting at the current offset until there are no
@@ -382,14 +654,30 @@ func (fil *File) WriteAt(p []byte, off int64) (n int, err error) {
 // also returned.
 func (fil *File) WriteTo(w io.Writer) (int64, error) {
 	if fil.IsDir() {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "write",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
+	}
+	if err := fil.load(); err != nil {
+		return 0, err
+	}
+	if fil.stub {
+		n, err := fil.writeStubTo(w)
+		fil.countRead(int(n))
+		return n, err
+	}
+	if err := fil.verifySum("read"); err != nil {
+		return 0, err
+	}
+	if fil.off >= len(fil.buf) {
+		fil.countRead(0)
+		return 0, nil
 	}
 	n, err := w.Write(fil.buf[fil.off:])
 	fil.off += n
+	fil.countRead(n)
 	return int64(n), err
 }
 
@@ -400,38 +688,77 @@ func (fil *File) WriteString(s string) (int, error) {
 
 // write writes p at the current offset.
 func (fil *File) write(p []byte) int {
+	if len(p) == 0 {
+		// Like with os.File, an empty write doesn't change the offset even
+		// in the append mode.
+		return 0
+	}
+	fil.snapshot()
+	fil.materialize()
 	if fil.flag&os.O_APPEND != 0 {
 		fil.off = len(fil.buf)
 	}
+	fil.gap(len(fil.buf), fil.off)
+	fil.extend(fil.off)
 	l := len(fil.buf)
 	fil.grow(len(p))
 	n := copy(fil.buf[fil.off:], p)
+	fil.fill(fil.off, n)
 	fil.off += n
 	if fil.off > l {
 		l = fil.off
 	}
 	fil.buf = fil.buf[:l]
+	fil.updateSum()
+	fil.notifyTails()
 	return n
 }
 
 // Read reads the next len(p) bytes from the buffer at the current offset or
 // until the buffer is drained. The return value is the number of bytes read.
 // If the buffer has no data to return, err is [io.EOF] (unless len(p) is zero)
-// or if the file represents a directory; otherwise it is nil.
+// or if the file represents a directory; otherwise it is nil. See
+// [WithMaxReadChunk] for the short reads.
 func (fil *File) Read(p []byte) (int, error) {
+	if c := fil.readChunk(); c > 0 && len(p) > c {
+		p = p[:c]
+	}
+	return fil.read(p)
+}
+
+// read implements [File.Read] without the limit set with [WithMaxReadChunk].
+func (fil *File) read(p []byte) (int, error) {
 	if fil.IsDir() {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "read",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
+	}
+	if err := fil.load(); err != nil {
+		return 0, err
 	}
 	// Nothing more to read.
-	if len(p) > 0 && fil.off >= len(fil.buf) {
-		return 0, io.EOF
+	if fil.off >= fil.Len() {
+		fil.countRead(0)
+		if len(p) > 0 {
+			return 0, io.EOF
+		}
+		return 0, nil
+	}
+	if fil.stub {
+		n := min(len(p), max(fil.Len()-fil.off, 0))
+		zeroOutSlice(p[:n])
+		fil.off += n
+		fil.countRead(n)
+		return n, nil
+	}
+	if err := fil.verifySum("read"); err != nil {
+		return 0, err
 	}
 	n := copy(p, fil.buf[fil.off:])
 	fil.off += n
+	fil.countRead(n)
 	return n, nil
 }
 
@@ -440,18 +767,31 @@ func (fil *File) Read(p []byte) (int, error) {
 // consumed, and the returned byte value is undefined.
 func (fil *File) ReadByte() (byte, error) {
 	if fil.IsDir() {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "read",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
+	}
+	if err := fil.load(); err != nil {
+		return 0, err
 	}
 	// Nothing more to read.
-	if fil.off >= len(fil.buf) {
+	if fil.off >= fil.Len() {
+		fil.countRead(0)
 		return 0, io.EOF
 	}
+	if fil.stub {
+		fil.off++
+		fil.countRead(1)
+		return 0, nil
+	}
+	if err := fil.verifySum("read"); err != nil {
+		return 0, err
+	}
 	v := fil.buf[fil.off]
 	fil.off++
+	fil.countRead(1)
 	return v, nil
 }
 
@@ -461,16 +801,16 @@ func (fil *File) ReadByte() (byte, error) {
 // does not change the offset.
 func (fil *File) ReadAt(p []byte, off int64) (int, error) {
 	if fil.IsDir() {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "read",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
 	}
 	prev := fil.off
 	defer func() { fil.off = prev }()
 	fil.off = int(off)
-	n, err := fil.Read(p)
+	n, err := fil.read(p)
 	if err != nil {
 		return n, err
 	}
@@ -484,21 +824,40 @@ func (fil *File) ReadAt(p []byte, off int64) (int, error) {
 // current offset, growing the buffer as needed. The return value is the number
 // of bytes read. Any error except [io.EOF] encountered during the read is also
 // returned. If the buffer becomes too large, ReadFrom will panic with
-// [bytes.ErrTooLarge].
+// [bytes.ErrTooLarge]. The files created with [NewBlockFile] are written one
+// block at a time.
 func (fil *File) ReadFrom(r io.Reader) (int64, error) {
 	var err error
 	var n, total int
 
 	if fil.IsDir() {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "write",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
+	}
+	if err := fil.checkAttr("write"); err != nil {
+		return 0, err
 	}
+	if err := fil.own(); err != nil {
+		return 0, err
+	}
+	if fil.block != 0 {
+		return fil.readBlocks(r)
+	}
+	left, limited := fil.quotaLeft()
+	fil.snapshot()
+	fil.materialize()
+	prev, size := fil.off, len(fil.buf)
 	if fil.flag&os.O_APPEND != 0 {
 		fil.off = len(fil.buf)
 	}
+	fil.extend(fil.off)
+	start := fil.off
+
+	// The size of the data is not known, so the exact allocation is not used.
+	factor, _ := fil.growthPolicy()
 
 	for {
 
@@ -507,7 +866,7 @@ func (fil *File) ReadFrom(r io.Reader) (int64, error) {
 
 		// Make sure we can fit [bytes.MinRead] between the current offset and
 		// the new buffer length.
-		fil.grow(bytes.MinRead)
+		fil.growBy(bytes.MinRead, factor, false)
 
 		// We will use bytes between l and cap(fil.buf) as a temporary scratch
 		// space for reading from r and then slide read bytes to place. We have
@@ -521,11 +880,9 @@ func (fil *File) ReadFrom(r io.Reader) (int64, error) {
 		if l != fil.off {
 			// Move bytes from temporary area to correct place.
 			copy(fil.buf[fil.off:], tmp[:n])
-			if n < len(tmp) {
-				// Clean up any garbage the reader might put in there. We want
-				// to keep all bytes between len and cap as zeros.
-				zeroOutSlice(tmp[n:])
-			}
+			// Clean up any garbage the reader might put in there. We want
+			// to keep all bytes between len and cap as zeros.
+			zeroOutSlice(fil.buf[max(l, fil.off+n):cap(fil.buf)])
 		}
 
 		fil.off += n
@@ -538,26 +895,72 @@ func (fil *File) ReadFrom(r io.Reader) (int64, error) {
 		// Set proper buffer length.
 		fil.buf = fil.buf[:l]
 
+		if limit := size + int(left); limited && l > limit {
+			// Drop the bytes exceeding the quota.
+			zeroOutSlice(fil.buf[limit:l])
+			fil.buf = fil.buf[:limit]
+			fil.off -= l - limit
+			total -= l - limit
+			err = fil.hookErr(&fs.PathError{
+				Op:   "write",
+				Path: fil.path(),
+				Err:  syscall.ENOSPC,
+			})
+		}
+
 		if err != nil {
 			break
 		}
 	}
 
+	if total == 0 {
+		// Nothing was written, so neither the offset nor the size change
+		// even in the append mode or when the offset is beyond the end.
+		zeroOutSlice(fil.buf[size:])
+		fil.buf = fil.buf[:size]
+		fil.off = prev
+	} else {
+		fil.gap(size, start)
+		fil.fill(start, total)
+	}
+
+	fil.updateSum()
+	fil.notifyTails()
+	fil.countWrite(total)
+
 	// The [io.EOF] is not an error.
 	if err == io.EOF {
 		err = nil
 	}
+	if err == nil {
+		err = fil.mirrorContent()
+	}
 
 	return int64(total), err
 }
 
 // String returns string representation of the buffer starting at the current
 // offset. Calling this method is considered as reading the buffer and advances
-// offset to the end of the buffer. When the file represents a directory, it
-// returns an empty string.
+// offset to the end of the buffer unless the [WithNonConsumingString] option
+// was used. When the file represents a directory, it returns an empty string.
 func (fil *File) String() string {
-	s := string(fil.buf[fil.off:])
-	fil.off = len(fil.buf)
+	if fil.load() != nil {
+		return ""
+	}
+	var s string
+	off := fil.off
+	switch {
+	case fil.stub:
+		s = strings.Repeat("\x00", max(fil.Len()-fil.off, 0))
+		off = fil.Len()
+	case fil.off < len(fil.buf):
+		s = string(fil.buf[fil.off:])
+		off = len(fil.buf)
+	}
+	if !fil.nonConsuming() {
+		fil.off = off
+	}
+	fil.countRead(len(s))
 	return s
 }
 
@@ -568,11 +971,11 @@ func (fil *File) String() string {
 // Returns a non-nil error of the [fs.PathError] type.
 func (fil *File) Seek(offset int64, whence int) (int64, error) {
 	if fil.IsDir() {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "seek",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
 	}
 
 	var off int
@@ -582,15 +985,15 @@ func (fil *File) Seek(offset int64, whence int) (int64, error) {
 	case io.SeekCurrent:
 		off = fil.off + int(offset)
 	case io.SeekEnd:
-		off = len(fil.buf) + int(offset)
+		off = fil.Len() + int(offset)
 	}
 
 	if off < 0 {
-		return 0, &fs.PathError{
+		return 0, fil.hookErr(&fs.PathError{
 			Op:   "seek",
 			Path: fil.path(),
 			Err:  syscall.EINVAL,
-		}
+		})
 	}
 	fil.off = off
 
@@ -609,7 +1012,7 @@ func (fil *File) SeekStart() int64 {
 // length and returning the value it had before the method was called.
 func (fil *File) SeekEnd() int64 {
 	prev := fil.off
-	fil.off = len(fil.buf)
+	fil.off = fil.Len()
 	return int64(prev)
 }
 
@@ -620,19 +1023,43 @@ func (fil *File) SeekEnd() int64 {
 // type.
 func (fil *File) Truncate(size int64) error {
 	if fil.IsDir() {
-		return &fs.PathError{
+		return fil.hookErr(&fs.PathError{
 			Op:   "truncate",
 			Path: fil.path(),
 			Err:  syscall.EISDIR,
-		}
+		})
 	}
 
 	if size < 0 {
-		return &os.PathError{
+		return fil.hookErr(&os.PathError{
 			Op:   "truncate",
 			Path: fil.path(),
 			Err:  syscall.EINVAL,
-		}
+		})
+	}
+	if err := fil.checkAttr("truncate"); err != nil {
+		return err
+	}
+	if fil.block != 0 {
+		return fil.hookErr(&fs.PathError{
+			Op:   "truncate",
+			Path: fil.path(),
+			Err:  syscall.EINVAL,
+		})
+	}
+	if err := fil.checkQuota("truncate", size-int64(fil.Len())); err != nil {
+		return err
+	}
+	if err := fil.own(); err != nil {
+		return err
+	}
+
+	fil.snapshot()
+	if fil.stub {
+		// The stub content is all zeros, so it's enough to change its size.
+		fil.info.size = size
+		fil.notifyTails()
+		return fil.mirrorContent()
 	}
 
 	prev := fil.off
@@ -653,7 +1080,7 @@ func (fil *File) Truncate(size int64) error {
 
 	case int(size) > c:
 		// Truncate beyond the cap.
-		fil.off = c // So tryGrowByReslice returns false.
+		fil.off = l // So tryGrowByReslice returns false.
 		fil.grow(int(size) - l)
 		fil.buf = fil.buf[:int(size)]
 
@@ -662,10 +1089,14 @@ func (fil *File) Truncate(size int64) error {
 		zeroOutSlice(fil.buf[size:])
 		fil.buf = fil.buf[:size]
 	}
+	fil.cut(int(size))
+	fil.gap(l, int(size))
 
 	fil.off = prev
+	fil.updateSum()
+	fil.notifyTails()
 
-	return nil
+	return fil.mirrorContent()
 }
 
 // Grow grows the buffer's capacity, if necessary, to guarantee space for
@@ -676,9 +1107,10 @@ func (fil *File) Grow(n int) {
 	if n < 0 {
 		panic("memfs.File.Grow: negative count")
 	}
-	if fil.IsDir() {
+	if fil.IsDir() || fil.own() != nil {
 		return
 	}
+	fil.materialize()
 
 	l := len(fil.buf)
 	if l+n <= cap(fil.buf) {
@@ -698,20 +1130,52 @@ func (fil *File) Grow(n int) {
 // len(b.buf) changes. If the buffer can't grow, it will panic with
 // [bytes.ErrTooLarge].
 func (fil *File) grow(n int) {
+	factor, exact := fil.growthPolicy()
+	fil.growBy(n, factor, exact)
+}
+
+// growBy works like [File.grow] but uses the given growth policy, see
+// [WithGrowthFactor] and [WithExactAlloc].
+func (fil *File) growBy(n int, factor float64, exact bool) {
 	// Try to grow by a reslice.
 	if ok := fil.tryGrowByReslice(n); ok {
 		return
 	}
+	if exact {
+		tmp := makeSlice(fil.off + n)
+		copy(tmp, fil.buf)
+		fil.buf = tmp
+		return
+	}
 	if fil.buf == nil && n <= smallBufferSize {
 		fil.buf = make([]byte, n, smallBufferSize)
 		return
 	}
 	// Allocate bigger buffer.
-	tmp := makeSlice(cap(fil.buf)*2 + n) // cap(b.buf) may be zero.
+	tmp := makeSlice(int(float64(cap(fil.buf))*factor) + n) // Cap may be zero.
 	copy(tmp, fil.buf)
 	fil.buf = tmp
 }
 
+// extend extends the buffer with zeros to the size. It does nothing when
+// the buffer is already longer than the size.
+func (fil *File) extend(size int) {
+	l := len(fil.buf)
+	if size <= l {
+		return
+	}
+	if size <= cap(fil.buf) {
+		// Bytes between length and capacity are always zeros.
+		fil.buf = fil.buf[:size]
+		return
+	}
+	prev := fil.off
+	fil.off = l // So tryGrowByReslice returns false.
+	fil.grow(size - l)
+	fil.buf = fil.buf[:size]
+	fil.off = prev
+}
+
 // tryGrowByReslice is an inlineable version of [File.grow] for the fast-case
 // where the internal buffer only needs to be resliced. It returns whether it
 // succeeded.
@@ -732,43 +1196,123 @@ func (fil *File) tryGrowByReslice(n int) bool {
 // Offset returns the current offset.
 func (fil *File) Offset() int { return fil.off }
 
-// Len returns the buffer length.
-func (fil *File) Len() int { return len(fil.buf) }
+// Len returns the buffer length. For files with content stripped by
+// [File.StripContents] or not yet loaded lazily loaded files, it returns the
+// preserved size.
+func (fil *File) Len() int {
+	if fil.stub || fil.unloaded() {
+		return int(fil.info.size)
+	}
+	return len(fil.buf)
+}
 
 // Cap returns the buffer capacity, that is, the total space allocated for the
 // buffer's data.
 func (fil *File) Cap() int { return cap(fil.buf) }
 
+// OpenCount returns the number of handles to the file which were opened with
+// [File.Open] or the Open method of the file systems returned by [File.FS]
+// and [File.DirFS] and not closed yet.
+func (fil *File) OpenCount() int { return fil.refs }
+
+// nonConsuming returns true when the [WithNonConsumingString] option was
+// used on the instance or any of its parents.
+func (fil *File) nonConsuming() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.peek {
+			return true
+		}
+	}
+	return false
+}
+
 // path returns the full path of the instance, including the parent's path if
 // it's not the root.
 func (fil *File) path() string {
-	pth := fil.Name()
-	if fil.parent != nil {
-		if par := fil.parent.path(); par != "" {
-			return filepath.Join(par, pth)
-		}
+	var elems []string
+	for f := fil; f != nil; f = f.parent {
+		elems = append(elems, f.Name())
 	}
-	return pth
+	slices.Reverse(elems)
+	return filepath.Join(elems...)
 }
 
-// Close sets offset to zero. It always returns nil error.
+// Close sets offset to zero, decreases the number of open handles (see
+// [File.OpenCount]), releases the byte-range locks placed with
+// [File.LockRange] and reports the I/O statistics to the hook set with
+// [WithStatsHook]. It returns a non-nil error only when the content scanner
+// set with [WithContentScanner] rejects the modifications or in the checksum
+// verification mode when the content does not match the checksum. For lazily
+// loaded files, it drops the loaded content when the last handle is closed
+// unless the [WithLazyCache] option was used. With the [WithWriteOnce]
+// option, the regular file modified since it was last closed becomes
+// read-only when successfully closed.
+//
+// For directories, it resets the [File.ReadDir] cursor and drops the entries
+// snapshot, so the next ReadDir call starts from the first entry and sees
+// the entries added or removed in the meantime.
 func (fil *File) Close() error {
 	if fil == nil {
 		return nil
 	}
-	fil.off = 0
+	fil.resetState()
+	fil.unlockAll(fil)
+	wrote := fil.wrote
+	fil.wrote = false
+	return fil.release(wrote)
+}
+
+// release decreases the number of open handles and runs the checks done by
+// [File.Close]. When seal is true, it seals the write-once file (see
+// [WithWriteOnce]) after the successful checks.
+func (fil *File) release(seal bool) error {
+	if fil.refs > 0 {
+		fil.refs--
+	}
+	fil.reportStats()
+	if err := fil.scanContent(); err != nil {
+		return err
+	}
+	if err := fil.verifySum("close"); err != nil {
+		return err
+	}
+	if fil.refs == 0 {
+		fil.unload()
+	}
+	if seal {
+		fil.seal()
+	}
 	return nil
 }
 
+// ResetState resets the offset of the instance and, when it's a directory,
+// the [File.ReadDir] cursors and offsets of all files in its tree, like
+// [File.Close] does, without touching the content. Use it to reuse a fixture
+// across subtests so it behaves the same way in each of them.
+func (fil *File) ResetState() {
+	fil.resetState()
+	if fil.IsDir() {
+		fil.walk("", func(_ string, ent *File) { ent.resetState() })
+	}
+}
+
+// resetState resets the offset, the [File.ReadDir] cursor and the entries
+// snapshot of the instance.
+func (fil *File) resetState() {
+	fil.off = 0
+	fil.cursor = 0
+	fil.listing = nil
+}
+
 // List recursively lists the directory and returns a string with one entry per
 // line. If the instance is not a directory, it returns an error.
 func (fil *File) List() (string, error) {
 	if !fil.IsDir() {
-		return "", &fs.PathError{
+		return "", fil.hookErr(&fs.PathError{
 			Op:   "list",
 			Path: fil.path(),
 			Err:  syscall.ENOTDIR,
-		}
+		})
 	}
 	return list(fil)
 }
@@ -808,7 +1352,8 @@ func makeSlice(n int) []byte {
 //
 //   - [io/fs.StatFS],
 //   - [io/fs.ReadFileFS],
-//   - [io/fs.ReadDirFS].
+//   - [io/fs.ReadDirFS],
+//   - [io/fs.ReadLinkFS].
 type fsDir struct{ dir *File }
 
 // ReadDir implements [fs.ReadDirFS] interface.
@@ -819,17 +1364,40 @@ func (f fsDir) ReadDir(name string) ([]fs.DirEntry, error) {
 	}
 
 	if !fil.IsDir() {
-		return nil, &fs.PathError{
+		return nil, f.dir.hookErr(&fs.PathError{
 			Op:   "readdirent",
 			Path: filepath.Join(f.dir.info.name, name),
 			Err:  syscall.ENOTDIR,
-		}
+		})
 	}
-	return fil.ReadDir(-1)
+	ets := make([]fs.DirEntry, 0, len(fil.entries))
+	for _, ent := range fil.listEntries() {
+		ets = append(ets, ent)
+	}
+	return ets, nil
 }
 
-// Open implements [fs.FS] interface.
-func (f fsDir) Open(name string) (fs.File, error) { return f.open(name) }
+// ReadFile implements [fs.ReadFileFS] interface.
+func (f fsDir) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(fsOnly{f}, name)
+}
+
+// Open implements [fs.FS] interface. The directories are returned as
+// handles paginating independently of each other and of the directory.
+func (f fsDir) Open(name string) (fs.File, error) {
+	fil, err := f.open(name)
+	if err != nil {
+		return nil, err
+	}
+	name = f.dir.lenientPath(name)
+	if fil.IsDir() {
+		return newDirHandle(fil, name), nil
+	}
+	if fil.bcast != nil {
+		return fil.bcast.file(fil), nil
+	}
+	return openedAs(f.dir, fil, name), nil
+}
 
 // open opens the file with the given name and handles errors in a way that
 // matches the behavior of [os.Open] and [os.OpenFile].
@@ -843,20 +1411,41 @@ func (f fsDir) open(name string) (*File, error) {
 				e.Op = "openat"
 				e.Err = syscall.ENOENT
 			default:
-				return nil, e
+				return nil, f.dir.hookErr(e)
 			}
 		}
-		return nil, err
+		return nil, f.dir.hookErr(err)
 	}
 	return fil, nil
 }
 
+// ReadLink implements [fs.ReadLinkFS] interface.
+func (f fsDir) ReadLink(name string) (string, error) {
+	return f.dir.ReadLink(name)
+}
+
+// Lstat implements [fs.ReadLinkFS] interface.
+func (f fsDir) Lstat(name string) (fs.FileInfo, error) {
+	return f.dir.Lstat(name)
+}
+
 // Stat implements [fs.StatFS] interface.
 func (f fsDir) Stat(name string) (fs.FileInfo, error) {
-	for _, fil := range f.dir.entries {
-		if fil.Name() == name {
+	name = f.dir.lenientPath(name)
+	if name == "." {
+		return f.dir.statAs(name), nil
+	}
+	if fil, err := lopen(f.dir, name); err == nil && fil != f.dir {
+		if !fil.isSymlink() {
 			return fil, nil
 		}
+		if dst, err := open(f.dir, name); err == nil {
+			return dst.statAs(path.Base(name)), nil
+		}
 	}
-	return nil, &fs.PathError{Op: "statat", Path: name, Err: syscall.ENOENT}
+	return nil, f.dir.hookErr(&fs.PathError{
+		Op:   "statat",
+		Path: name,
+		Err:  syscall.ENOENT,
+	})
 }
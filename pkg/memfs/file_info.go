@@ -17,16 +17,32 @@ var (
 
 // FileInfo implements [fs.FileInfo] interface.
 type FileInfo struct {
-	name string
-	size int64
-	mode fs.FileMode
+	name  string
+	size  int64
+	mode  fs.FileMode
+	mtime time.Time // See [File.Chtimes].
+	sys   any
+}
+
+// SysInfo is the underlying data source returned by [FileInfo.Sys] and
+// [File.Sys] methods.
+type SysInfo struct {
+	// Number of hard links. It is 1 for regular files and 2 plus the number
+	// of subdirectories for directories.
+	Nlink uint64
+
+	// Last access time set with [File.Chtimes], zero value by default.
+	Atime time.Time
+
+	// Attribute flags set with [File.SetAttr], zero value by default.
+	Attr Attr
 }
 
 func (fi FileInfo) Name() string               { return filepath.Base(fi.name) }
 func (fi FileInfo) Size() int64                { return fi.size }
 func (fi FileInfo) Mode() fs.FileMode          { return fi.mode }
-func (fi FileInfo) ModTime() time.Time         { return time.Time{} }
+func (fi FileInfo) ModTime() time.Time         { return fi.mtime }
 func (fi FileInfo) IsDir() bool                { return fi.mode&fs.ModeDir != 0 }
-func (fi FileInfo) Sys() any                   { return nil }
+func (fi FileInfo) Sys() any                   { return fi.sys }
 func (fi FileInfo) Type() fs.FileMode          { return fi.Mode().Type() }
 func (fi FileInfo) Info() (fs.FileInfo, error) { return fi, nil }
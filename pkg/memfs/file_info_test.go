@@ -79,14 +79,27 @@ func Test_FileInfo_IsDir(t *testing.T) {
 }
 
 func Test_FileInfo_Sys(t *testing.T) {
-	// --- Given ---
-	fi := FileInfo{}
+	t.Run("nil", func(t *testing.T) {
+		// --- Given ---
+		fi := FileInfo{}
 
-	// --- When ---
-	have := fi.Sys()
+		// --- When ---
+		have := fi.Sys()
 
-	// --- Then ---
-	assert.Nil(t, have)
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		// --- Given ---
+		fi := FileInfo{sys: &SysInfo{Nlink: 2}}
+
+		// --- When ---
+		have := fi.Sys()
+
+		// --- Then ---
+		assert.Equal(t, &SysInfo{Nlink: 2}, have)
+	})
 }
 
 func Test_FileInfo_Type(t *testing.T) {
@@ -8,8 +8,10 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"strings"
 	"syscall"
 	"testing"
+	"testing/fstest"
 
 	"github.com/ctx42/testing/pkg/assert"
 	"github.com/ctx42/testing/pkg/must"
@@ -37,6 +39,17 @@ func Test_WithFileAppend(t *testing.T) {
 	assert.Equal(t, fil.flag&os.O_APPEND, os.O_APPEND)
 }
 
+func Test_WithNonConsumingString(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+
+	// --- When ---
+	WithNonConsumingString(fil)
+
+	// --- Then ---
+	assert.True(t, fil.peek)
+}
+
 func Test_WithFileFlag(t *testing.T) {
 	// --- Given ---
 	fil := &File{}
@@ -188,6 +201,19 @@ func Test_NewDirectory(t *testing.T) {
 		assert.Nil(t, have.entries)
 	})
 
+	t.Run("with options", func(t *testing.T) {
+		// --- Given ---
+		opt := func(fil *File) { fil.flag = 42 }
+
+		// --- When ---
+		have, err := NewDirectory("dir", opt)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 42, have.flag)
+		assert.Equal(t, 0700|fs.ModeDir, have.info.mode)
+	})
+
 	t.Run("error - invalid name", func(t *testing.T) {
 		// --- When ---
 		have, err := NewDirectory("/dir")
@@ -199,14 +225,28 @@ func Test_NewDirectory(t *testing.T) {
 }
 
 func Test_NewRoot(t *testing.T) {
-	// --- When ---
-	have := NewRoot()
+	t.Run("without options", func(t *testing.T) {
+		// --- When ---
+		have := NewRoot()
 
-	// --- Then ---
-	assert.Equal(t, "", have.info.name)
-	assert.Equal(t, int64(4096), have.info.size)
-	assert.Equal(t, 0700|fs.ModeDir, have.info.mode)
-	assert.Nil(t, have.entries)
+		// --- Then ---
+		assert.Equal(t, "", have.info.name)
+		assert.Equal(t, int64(4096), have.info.size)
+		assert.Equal(t, 0700|fs.ModeDir, have.info.mode)
+		assert.Nil(t, have.entries)
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		// --- Given ---
+		opt := func(fil *File) { fil.flag = 42 }
+
+		// --- When ---
+		have := NewRoot(opt)
+
+		// --- Then ---
+		assert.Equal(t, 42, have.flag)
+		assert.Equal(t, 0700|fs.ModeDir, have.info.mode)
+	})
 }
 
 func Test_NewBuffer(t *testing.T) {
@@ -327,7 +367,7 @@ func Test_File_AddFile(t *testing.T) {
 		// --- Given ---
 		dir := MustDirectory("dir")
 		fil := MustFile("file")
-		fil.info.mode = fs.ModeSymlink
+		fil.info.mode = fs.ModeNamedPipe
 
 		// --- When ---
 		err := dir.AddFile(fil)
@@ -369,6 +409,37 @@ func Test_File_AddFile(t *testing.T) {
 		assert.Equal(t, "dir0/file", e.Path)
 		assert.Equal(t, ErrHasParent, e.Err)
 	})
+
+	t.Run("error - directory to itself", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("dir")
+
+		// --- When ---
+		err := dir.AddFile(dir)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "AddFile", e.Op)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+		assert.Len(t, 0, dir.entries)
+	})
+
+	t.Run("error - root to its deep descendant", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot()
+		deep := strings.Repeat("d/", 50) + "d"
+		must.Nil(root.MkdirAll(deep, 0700))
+		leaf := must.Value(open(root, deep))
+
+		// --- When ---
+		err := leaf.AddFile(root)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		assert.Nil(t, root.parent)
+		assert.Len(t, 0, leaf.entries)
+	})
 }
 
 func Test_File_ReadDir(t *testing.T) {
@@ -511,6 +582,43 @@ func Test_File_ReadDir(t *testing.T) {
 		assert.Nil(t, have)
 	})
 
+	t.Run("entries added while listing are not returned", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("dir")
+		must.Nil(dir.AddFile(MustFile("file1")))
+		must.Nil(dir.AddFile(MustFile("file3")))
+		must.Value(dir.ReadDir(1))
+		must.Nil(dir.AddFile(MustFile("file0")))
+		must.Nil(dir.AddFile(MustFile("file2")))
+
+		// --- When ---
+		have, err := dir.ReadDir(-1)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 1, have)
+		assert.Equal(t, "file3", have[0].Name())
+		assert.Len(t, 4, dir.entries)
+	})
+
+	t.Run("close resets the snapshot", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("dir")
+		must.Nil(dir.AddFile(MustFile("file1")))
+		must.Value(dir.ReadDir(-1))
+		must.Nil(dir.AddFile(MustFile("file0")))
+		must.Nil(dir.Close())
+
+		// --- When ---
+		have, err := dir.ReadDir(-1)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 2, have)
+		assert.Equal(t, "file0", have[0].Name())
+		assert.Equal(t, "file1", have[1].Name())
+	})
+
 	t.Run("error - not a directory", func(t *testing.T) {
 		// --- Given ---
 		fil := MustFile("file")
@@ -664,7 +772,7 @@ func Test_File_Stat(t *testing.T) {
 		assert.Equal(t, fs.FileMode(0600), have.Mode())
 		assert.Zero(t, have.ModTime())
 		assert.False(t, have.IsDir())
-		assert.Nil(t, have.Sys())
+		assert.Equal(t, &SysInfo{Nlink: 1}, have.Sys())
 	})
 
 	t.Run("file with contents", func(t *testing.T) {
@@ -706,7 +814,7 @@ func Test_File_Stat(t *testing.T) {
 		assert.Equal(t, fs.FileMode(0700)|fs.ModeDir, have.Mode())
 		assert.Zero(t, have.ModTime())
 		assert.True(t, have.IsDir())
-		assert.Nil(t, have.Sys())
+		assert.Equal(t, &SysInfo{Nlink: 2}, have.Sys())
 	})
 }
 
@@ -725,7 +833,7 @@ func Test_File_Info(t *testing.T) {
 		assert.Equal(t, fs.FileMode(0600), have.Mode())
 		assert.Zero(t, have.ModTime())
 		assert.False(t, have.IsDir())
-		assert.Nil(t, have.Sys())
+		assert.Equal(t, &SysInfo{Nlink: 1}, have.Sys())
 	})
 
 	t.Run("directory", func(t *testing.T) {
@@ -743,7 +851,7 @@ func Test_File_Info(t *testing.T) {
 		assert.Equal(t, fs.FileMode(0700)|fs.ModeDir, have.Mode())
 		assert.Zero(t, have.ModTime())
 		assert.True(t, have.IsDir())
-		assert.Nil(t, have.Sys())
+		assert.Equal(t, &SysInfo{Nlink: 2}, have.Sys())
 	})
 }
 
@@ -819,14 +927,39 @@ func Test_File_ModTime(t *testing.T) {
 }
 
 func Test_File_Sys(t *testing.T) {
-	// --- Given ---
-	fil := MustFileWith("file", []byte{0, 1, 2, 3})
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2, 3})
 
-	// --- When ---
-	have := fil.Sys()
+		// --- When ---
+		have := fil.Sys()
 
-	// --- Then ---
-	assert.Nil(t, have)
+		// --- Then ---
+		assert.Equal(t, &SysInfo{Nlink: 1}, have)
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("dir")
+
+		// --- When ---
+		have := dir.Sys()
+
+		// --- Then ---
+		assert.Equal(t, &SysInfo{Nlink: 2}, have)
+	})
+
+	t.Run("directory with subdirectories", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.AddFile(MustDirectory("sub3")))
+
+		// --- When ---
+		have := dir.Sys()
+
+		// --- Then ---
+		assert.Equal(t, &SysInfo{Nlink: 4}, have)
+	})
 }
 
 func Test_File_Open(t *testing.T) {
@@ -893,6 +1026,37 @@ func Test_File_FS(t *testing.T) {
 		assert.Equal(t, "file6", string(must.Value(io.ReadAll(fil))))
 	})
 
+	t.Run("passes fstest", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub/file3", "link"))
+		must.Nil(dir.Symlink("sub", "dlink"))
+		must.Nil(dir.MkdirAll("empty", 0700))
+
+		// --- When ---
+		have := dir.FS()
+
+		// --- Then ---
+		exp := []string{"file0", "sub/sub2/file6", "link", "dlink", "empty"}
+		assert.NoError(t, fstest.TestFS(have, exp...))
+	})
+
+	t.Run("open through symbolic link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub/file3", "link"))
+
+		// --- When ---
+		fil, err := dir.FS().Open("link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		info := must.Value(fil.Stat())
+		assert.Equal(t, "link", info.Name())
+		assert.Equal(t, "file3", string(must.Value(io.ReadAll(fil))))
+		assert.NoError(t, fil.Close())
+	})
+
 	t.Run("file", func(t *testing.T) {
 		// --- Given ---
 		dir := MustFile("file")
@@ -906,16 +1070,161 @@ func Test_File_FS(t *testing.T) {
 }
 
 func Test_File_Release(t *testing.T) {
-	// --- Given ---
-	fil := MustFileWith("file", []byte{0, 1, 2, 3}, WithFileOffset(1))
+	t.Run("release", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2, 3}, WithFileOffset(1))
 
-	// --- When ---
-	have := fil.Release()
+		// --- When ---
+		have := fil.Release()
 
-	// --- Then ---
-	assert.Equal(t, []byte{0, 1, 2, 3}, have)
-	assert.Equal(t, 0, fil.off)
-	assert.Nil(t, fil.buf)
+		// --- Then ---
+		assert.Equal(t, []byte{0, 1, 2, 3}, have)
+		assert.Equal(t, 0, fil.off)
+		assert.Nil(t, fil.buf)
+	})
+
+	t.Run("open handles get a copy", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.AddFile(MustFileWith("file", []byte{0, 1, 2, 3})))
+		fil := must.Value(dir.FS().Open("file")).(*File)
+		must.Value(fil.Seek(1, io.SeekStart))
+
+		// --- When ---
+		have := fil.Release()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 1, 2, 3}, have)
+		have[0] = 9
+		assert.Equal(t, []byte{0, 1, 2, 3}, fil.buf)
+		assert.Equal(t, 1, fil.off)
+		assert.Equal(t, 1, fil.OpenCount())
+	})
+
+	t.Run("after all handles are closed", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.AddFile(MustFileWith("file", []byte{0, 1, 2, 3})))
+		fil := must.Value(dir.FS().Open("file")).(*File)
+		must.Nil(fil.Close())
+
+		// --- When ---
+		have := fil.Release()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 1, 2, 3}, have)
+		assert.Nil(t, fil.buf)
+	})
+
+	t.Run("lazy file with open handles", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2, 3}, &loads)
+		dir := NewRoot()
+		must.Nil(dir.AddFile(fil))
+		must.Value(dir.Open("file"))
+
+		// --- When ---
+		have := fil.Release()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 1, 2, 3}, have)
+		assert.Equal(t, 1, loads)
+		assert.Equal(t, []byte{0, 1, 2, 3}, fil.buf)
+	})
+}
+
+func Test_File_ReleaseCopy(t *testing.T) {
+	t.Run("copy", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2, 3}, WithFileOffset(1))
+
+		// --- When ---
+		have := fil.ReleaseCopy()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 1, 2, 3}, have)
+		have[0] = 9
+		assert.Equal(t, []byte{0, 1, 2, 3}, fil.buf)
+		assert.Equal(t, 1, fil.off)
+	})
+
+	t.Run("file stays usable", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"))
+		fil.ReleaseCopy()
+
+		// --- When ---
+		must.Value(fil.WriteString("def"))
+
+		// --- Then ---
+		assert.Equal(t, []byte("def"), fil.buf)
+		assert.Equal(t, []byte("def"), fil.ReleaseCopy())
+	})
+
+	t.Run("lazy file", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2, 3}, &loads)
+
+		// --- When ---
+		have := fil.ReleaseCopy()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 1, 2, 3}, have)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("stripped file", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		fil := MustFileWith("file", []byte{1, 2, 3})
+		must.Nil(dir.AddFile(fil))
+		must.Nil(dir.StripContents(nil))
+
+		// --- When ---
+		have := fil.ReleaseCopy()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 0, 0}, have)
+	})
+}
+
+func Test_File_TakeString(t *testing.T) {
+	t.Run("take", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithFileOffset(1))
+
+		// --- When ---
+		have := fil.TakeString()
+
+		// --- Then ---
+		assert.Equal(t, "abc", have)
+		assert.Equal(t, 0, fil.off)
+		assert.Nil(t, fil.buf)
+	})
+
+	t.Run("open handles keep content", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.AddFile(MustFileWith("file", []byte("abc"))))
+		fil := must.Value(dir.FS().Open("file")).(*File)
+
+		// --- When ---
+		have := fil.TakeString()
+
+		// --- Then ---
+		assert.Equal(t, "abc", have)
+		assert.Equal(t, []byte("abc"), fil.buf)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		// --- When ---
+		have := MustFile("file").TakeString()
+
+		// --- Then ---
+		assert.Equal(t, "", have)
+	})
 }
 
 func Test_File_Write(t *testing.T) {
@@ -1907,6 +2216,55 @@ func Test_File_ReadFrom(t *testing.T) {
 		assert.Equal(t, syscall.EISDIR, e.Err)
 		assert.Equal(t, int64(0), have)
 	})
+
+	t.Run("from file", func(t *testing.T) {
+		// --- Given ---
+		src := MustFileWith("src", []byte{0, 1, 2, 3}, WithFileOffset(1))
+		dst := MustFileWith("dst", []byte{4, 5}, WithFileAppend)
+
+		// --- When ---
+		have, err := dst.ReadFrom(src)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), have)
+		assert.Equal(t, []byte{4, 5, 1, 2, 3}, dst.buf)
+		assert.Equal(t, 5, dst.off)
+		assert.Equal(t, 4, src.off)
+		assert.Equal(t, Stats{Writes: 1, BytesWritten: 3}, dst.stats)
+	})
+
+	t.Run("io.Copy between files", func(t *testing.T) {
+		// --- Given ---
+		data := bytes.Repeat([]byte{1, 2, 3}, 1<<12)
+		src := MustFileWith("src", data)
+		dst := MustFile("dst")
+
+		// --- When ---
+		have, err := io.Copy(dst, src)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(data)), have)
+		assert.Equal(t, data, dst.buf)
+		assert.Equal(t, Stats{Reads: 1, BytesRead: 12288}, src.stats)
+		want := Stats{Writes: 1, BytesWritten: 12288}
+		assert.Equal(t, want, dst.stats)
+	})
+
+	t.Run("error - from directory", func(t *testing.T) {
+		// --- Given ---
+		src := MustDirectory("dir")
+		dst := MustFile("dst")
+
+		// --- When ---
+		have, err := dst.ReadFrom(src)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EISDIR, err)
+		assert.Equal(t, int64(0), have)
+		assert.Len(t, 0, dst.buf)
+	})
 }
 
 func Test_File_ReadFrom_tabular(t *testing.T) {
@@ -2017,6 +2375,53 @@ func Test_File_String(t *testing.T) {
 		assert.Equal(t, 3, fil.Offset())
 	})
 
+	t.Run("non consuming", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith(
+			"file",
+			[]byte{'A', 'B', 'C'},
+			WithFileOffset(1),
+			WithNonConsumingString,
+		)
+
+		// --- When ---
+		have := fil.String()
+
+		// --- Then ---
+		assert.Equal(t, "BC", have)
+		assert.Equal(t, 1, fil.Offset())
+		assert.Equal(t, "BC", fil.String())
+	})
+
+	t.Run("non consuming inherited from directory", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithNonConsumingString)
+		fil := MustFileWith("file", []byte{'A', 'B', 'C'})
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		have := fil.String()
+
+		// --- Then ---
+		assert.Equal(t, "ABC", have)
+		assert.Equal(t, 0, fil.Offset())
+	})
+
+	t.Run("non consuming stub", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithNonConsumingString)
+		fil := MustFileWith("file", []byte{'A', 'B', 'C'})
+		must.Nil(root.AddFile(fil))
+		must.Nil(root.StripContents(nil))
+
+		// --- When ---
+		have := fil.String()
+
+		// --- Then ---
+		assert.Equal(t, "\x00\x00\x00", have)
+		assert.Equal(t, 0, fil.Offset())
+	})
+
 	t.Run("directory", func(t *testing.T) {
 		// --- Given ---
 		dir := MustDirectory("dir")
@@ -2622,6 +3027,64 @@ func Test_File_Cap(t *testing.T) {
 	assert.Equal(t, 44, have)
 }
 
+func Test_File_OpenCount(t *testing.T) {
+	t.Run("not opened", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have := fil.OpenCount()
+
+		// --- Then ---
+		assert.Equal(t, 0, have)
+	})
+
+	t.Run("open and close", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "sub/file3"))
+
+		// --- When ---
+		h0 := must.Value(dir.Open("sub/file3"))
+		h1 := must.Value(dir.FS().Open("sub/file3"))
+		h2 := must.Value(dir.DirFS().Open("sub/file3"))
+
+		// --- Then ---
+		assert.Equal(t, 3, fil.OpenCount())
+		assert.NoError(t, h0.Close())
+		assert.NoError(t, h1.Close())
+		assert.NoError(t, h2.Close())
+		assert.Equal(t, 0, fil.OpenCount())
+		assert.NoError(t, fil.Close())
+		assert.Equal(t, 0, fil.OpenCount())
+	})
+
+	t.Run("failed open", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		_, err := dir.FS().Open("not-existing")
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.Equal(t, 0, dir.OpenCount())
+	})
+
+	t.Run("read file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file0"))
+
+		// --- When ---
+		have := must.Value(fs.ReadFile(dir.FS(), "file0"))
+
+		// --- Then ---
+		assert.Equal(t, "file0", string(have))
+		assert.Equal(t, 0, fil.OpenCount())
+	})
+}
+
 func Test_File_path(t *testing.T) {
 	t.Run("level 1 file", func(t *testing.T) {
 		// --- Given ---
@@ -2670,6 +3133,25 @@ func Test_File_path(t *testing.T) {
 		// --- Then ---
 		assert.Equal(t, "sub/sub2", have)
 	})
+
+	t.Run("very deep file", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot()
+		cur := root
+		for range 1000 {
+			sub := MustDirectory("d")
+			must.Nil(cur.AddFile(sub))
+			cur = sub
+		}
+		fil := MustFile("file")
+		must.Nil(cur.AddFile(fil))
+
+		// --- When ---
+		have := fil.path()
+
+		// --- Then ---
+		assert.Equal(t, strings.Repeat("d/", 1000)+"file", have)
+	})
 }
 
 func Test_File_Close(t *testing.T) {
@@ -2704,6 +3186,83 @@ func Test_File_Close(t *testing.T) {
 		assert.Cap(t, 10, fil.buf)
 		assert.Equal(t, []byte{0, 1, 2, 3}, fil.buf)
 	})
+
+	t.Run("lazy content kept for other handles", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		dir := NewRoot()
+		must.Nil(dir.AddFile(lazyFile([]byte{0, 1, 2, 3}, &loads)))
+		h0 := must.Value(dir.Open("file"))
+		h1 := must.Value(dir.Open("file"))
+		must.Value(io.ReadAll(h0))
+
+		// --- When ---
+		err := h0.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := h1.(*File)
+		assert.True(t, have.loaded)
+		assert.NoError(t, h1.Close())
+		assert.False(t, have.loaded)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(dir.ReadDir(2))
+		must.Nil(dir.WriteFile("added", nil, 0600))
+
+		// --- When ---
+		err := dir.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, dir.cursor)
+		assert.Nil(t, dir.listing)
+		have := must.Value(dir.ReadDir(-1))
+		assert.Equal(t, "added", have[0].Name())
+		assert.Len(t, 5, have)
+	})
+}
+
+func Test_File_ResetState(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(dir.ReadDir(2))
+		sub := must.Value(open(dir, "sub"))
+		must.Value(sub.ReadDir(-1))
+		fil := must.Value(open(dir, "sub/sub2/file5"))
+		must.Value(fil.Read(make([]byte, 2)))
+
+		// --- When ---
+		dir.ResetState()
+
+		// --- Then ---
+		assert.Equal(t, 0, dir.cursor)
+		assert.Nil(t, dir.listing)
+		assert.Equal(t, 0, sub.cursor)
+		assert.Equal(t, 0, fil.Offset())
+		assert.Equal(t, "file5", fil.String())
+		have, err := dir.ReadDir(-1)
+		assert.NoError(t, err)
+		assert.Len(t, 4, have)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"))
+		must.Value(fil.Read(make([]byte, 2)))
+
+		// --- When ---
+		fil.ResetState()
+
+		// --- Then ---
+		assert.Equal(t, 0, fil.Offset())
+		assert.Equal(t, "abc", fil.String())
+	})
 }
 
 func Test_File_List(t *testing.T) {
@@ -2812,6 +3371,31 @@ func Test_fsDir_ReadDir(t *testing.T) {
 		assert.Equal(t, "file6", have[1].Name())
 	})
 
+	t.Run("all entries on every call", func(t *testing.T) {
+		// --- Given ---
+		dir := fsDir{tstDirMem()}
+		must.Value(dir.ReadDir("sub"))
+
+		// --- When ---
+		have, err := dir.ReadDir("sub")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"file3", "file4", "sub2"}, names(have))
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		// --- Given ---
+		dir := fsDir{MustDirectory("dir")}
+
+		// --- When ---
+		have, err := dir.ReadDir(".")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, have)
+	})
+
 	t.Run("error - reading a file", func(t *testing.T) {
 		// --- Given ---
 		dir := fsDir{tstDirMem()}
@@ -2845,6 +3429,38 @@ func Test_fsDir_ReadDir(t *testing.T) {
 	})
 }
 
+func Test_fsDir_ReadFile(t *testing.T) {
+	t.Run("nested file", func(t *testing.T) {
+		// --- Given ---
+		dir := fsDir{tstDirMem()}
+
+		// --- When ---
+		have, err := dir.ReadFile("sub/sub2/file5")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file5", string(have))
+		fil := must.Value(open(dir.dir, "sub/sub2/file5"))
+		assert.Equal(t, 0, fil.OpenCount())
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- Given ---
+		dir := fsDir{tstDirMem()}
+
+		// --- When ---
+		have, err := dir.ReadFile("not-existing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "openat", e.Op)
+		assert.Equal(t, "not-existing", e.Path)
+		assert.Equal(t, syscall.ENOENT, e.Err)
+		assert.Len(t, 0, have)
+	})
+}
+
 func Test_fsDir_Open(t *testing.T) {
 	t.Run("open file", func(t *testing.T) {
 		// --- Given ---
@@ -2892,6 +3508,19 @@ func Test_fsDir_Open(t *testing.T) {
 }
 
 func Test_fdDir_Stat(t *testing.T) {
+	t.Run("root", func(t *testing.T) {
+		// --- Given ---
+		dir := fsDir{must.Value(NewDirectory("dir"))}
+
+		// --- When ---
+		have, err := dir.Stat(".")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, ".", have.Name())
+		assert.True(t, have.IsDir())
+	})
+
 	t.Run("file", func(t *testing.T) {
 		// --- Given ---
 		dir := fsDir{tstDirMem()}
@@ -2906,7 +3535,20 @@ func Test_fdDir_Stat(t *testing.T) {
 		assert.Equal(t, fs.FileMode(0600), have.Mode())
 		assert.Zero(t, have.ModTime())
 		assert.False(t, have.IsDir())
-		assert.Nil(t, have.Sys())
+		assert.Equal(t, &SysInfo{Nlink: 1}, have.Sys())
+	})
+
+	t.Run("file in subdirectory", func(t *testing.T) {
+		// --- Given ---
+		dir := fsDir{tstDirMem()}
+
+		// --- When ---
+		have, err := dir.Stat("sub/sub2/file6")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file6", have.Name())
+		assert.Equal(t, int64(5), have.Size())
 	})
 
 	t.Run("directory", func(t *testing.T) {
@@ -2923,7 +3565,7 @@ func Test_fdDir_Stat(t *testing.T) {
 		assert.Equal(t, fs.FileMode(0700)|fs.ModeDir, have.Mode())
 		assert.Zero(t, have.ModTime())
 		assert.True(t, have.IsDir())
-		assert.Nil(t, have.Sys())
+		assert.Equal(t, &SysInfo{Nlink: 3}, have.Sys())
 	})
 
 	t.Run("error - not existing", func(t *testing.T) {
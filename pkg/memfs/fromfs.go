@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path"
+)
+
+// FromFS returns a new root directory with a copy of the tree in the file
+// system, for example, an [embed.FS] or the one returned by [os.DirFS], so
+// the fixtures can be modified without touching the original. The contents
+// and the permission bits of the files and directories are copied, and the
+// symbolic links are recreated with the same destinations when the file
+// system implements [fs.ReadLinkFS]. The options are applied to the root
+// directory after the tree is copied, so options like [WithReadOnly] can be
+// used, but the function set with the [WithProgress] option is called during
+// the copy, with the total size of the regular files in the file system, and
+// the throttle set with the [WithThrottle] option shapes the copy.
+//
+// Returns an error of the [fs.PathError] type with [fs.ErrInvalid] when the
+// file system has a file of a type other than a directory, a regular file or
+// a symbolic link, an error of the [fs.PathError] type with [ErrInterrupted]
+// when the copy was interrupted with [Throttle.Interrupt], and the errors
+// returned by the file system.
+func FromFS(fsys fs.FS, opts ...func(*File)) (*File, error) {
+	root := NewRoot()
+	// Find the progress function without applying the options to the root.
+	var probe File
+	for _, opt := range opts {
+		opt(&probe)
+	}
+	var prog *progress
+	if probe.progHook != nil {
+		total, err := fsSize(fsys)
+		if err != nil {
+			return nil, err
+		}
+		prog = &progress{fn: probe.progHook, total: total}
+	}
+	th := probe.throt
+	th.begin()
+	walk := func(pth string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		mode := info.Mode()
+		switch {
+		case pth == ".":
+			root.info.mode = fs.ModeDir | mode.Perm()
+			return nil
+
+		case mode.IsDir():
+			dir, err := mkdirAll(root, pth)
+			if err != nil {
+				return err
+			}
+			dir.info.mode = fs.ModeDir | mode.Perm()
+			return nil
+
+		case mode&fs.ModeSymlink != 0:
+			if err = th.wait(0); err != nil {
+				return &fs.PathError{Op: "fromfs", Path: pth, Err: err}
+			}
+			dst, err := fs.ReadLink(fsys, pth)
+			if err != nil {
+				return err
+			}
+			return root.Symlink(dst, pth)
+
+		case !mode.IsRegular():
+			return &fs.PathError{Op: "fromfs", Path: pth, Err: fs.ErrInvalid}
+		}
+
+		if err = th.wait(info.Size()); err != nil {
+			return &fs.PathError{Op: "fromfs", Path: pth, Err: err}
+		}
+		content, err := fs.ReadFile(fsys, pth)
+		if err != nil {
+			return err
+		}
+		dir, err := open(root, path.Dir(pth))
+		if err != nil {
+			return err
+		}
+		fil, err := FileWith(path.Base(pth), content, WithFileMode(mode))
+		if err != nil {
+			return err
+		}
+		if err = dir.AddFile(fil); err != nil {
+			return err
+		}
+		prog.add(pth, int64(len(content)))
+		return nil
+	}
+	if err := fs.WalkDir(fsys, ".", walk); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(root)
+	}
+	return root, nil
+}
+
+// CopyFromDisk returns a new root directory with a copy of the tree in the
+// directory dir on the OS file system, see [FromFS] for details.
+func CopyFromDisk(dir string, opts ...func(*File)) (*File, error) {
+	return FromFS(os.DirFS(dir), opts...)
+}
+
+// fsSize returns the total size of the regular files in the file system.
+func fsSize(fsys fs.FS) (int64, error) {
+	var size int64
+	walk := func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	}
+	if err := fs.WalkDir(fsys, ".", walk); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
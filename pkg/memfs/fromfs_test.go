@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_FromFS(t *testing.T) {
+	t.Run("map file system", func(t *testing.T) {
+		// --- Given ---
+		fsys := fstest.MapFS{
+			"file":        {Data: []byte("abc"), Mode: 0644},
+			"dir":         {Mode: fs.ModeDir | 0750},
+			"dir/sub/exe": {Data: []byte("#!"), Mode: 0755},
+		}
+
+		// --- When ---
+		have, err := FromFS(fsys, WithReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.rdonly)
+		wantList := ".\ndir\ndir/sub\ndir/sub/exe\nfile\n"
+		assert.Equal(t, wantList, must.Value(have.List()))
+		fil := must.Value(open(have, "file"))
+		assert.Equal(t, "abc", fil.String())
+		assert.Equal(t, fs.FileMode(0644), fil.Mode())
+		dir := must.Value(open(have, "dir"))
+		assert.Equal(t, fs.ModeDir|0750, dir.Mode())
+		exe := must.Value(open(have, "dir/sub/exe"))
+		assert.Equal(t, fs.FileMode(0755), exe.Mode())
+	})
+
+	t.Run("contents are copied", func(t *testing.T) {
+		// --- Given ---
+		fsys := fstest.MapFS{"file": {Data: []byte("abc")}}
+		have := must.Value(FromFS(fsys))
+
+		// --- When ---
+		must.Nil(have.WriteFile("file", []byte("xyz"), 0600))
+
+		// --- Then ---
+		assert.Equal(t, "abc", string(fsys["file"].Data))
+	})
+
+	t.Run("memfs round trip", func(t *testing.T) {
+		// --- When ---
+		have, err := FromFS(tstDirMem().DirFS())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := must.Value(tstDirMem().List())
+		assert.Equal(t, want, must.Value(have.List()))
+		data := must.Value(have.ReadFile("sub/sub2/file6"))
+		assert.Equal(t, "file6", string(data))
+	})
+
+	t.Run("symbolic links", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("../file0", "sub/link"))
+
+		// --- When ---
+		have, err := FromFS(dir.DirFS())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "../file0", must.Value(have.ReadLink("sub/link")))
+		data := must.Value(have.ReadFile("sub/link"))
+		assert.Equal(t, "file0", string(data))
+	})
+
+	t.Run("error - unsupported file type", func(t *testing.T) {
+		// --- Given ---
+		fsys := fstest.MapFS{"pipe": {Mode: fs.ModeNamedPipe}}
+
+		// --- When ---
+		have, err := FromFS(fsys)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "fromfs", e.Op)
+		assert.Equal(t, "pipe", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_CopyFromDisk(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirOS(t)
+		must.Nil(os.Chmod(filepath.Join(dir, "file1"), 0755))
+		must.Nil(os.Symlink("file0", filepath.Join(dir, "link")))
+
+		// --- When ---
+		have, err := CopyFromDisk(dir)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		data := must.Value(have.ReadFile("sub/sub2/file5"))
+		assert.Equal(t, "file5", string(data))
+		fil := must.Value(open(have, "file1"))
+		assert.Equal(t, fs.FileMode(0755), fil.Mode())
+		assert.Equal(t, "file0", must.Value(have.ReadLink("link")))
+	})
+
+	t.Run("disk is not modified", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirOS(t)
+		have := must.Value(CopyFromDisk(dir))
+
+		// --- When ---
+		must.Nil(have.RemoveAll("sub"))
+
+		// --- Then ---
+		assert.Equal(t, "file3", readOS(t, filepath.Join(dir, "sub/file3")))
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- When ---
+		have, err := CopyFromDisk(filepath.Join(t.TempDir(), "missing"))
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, have)
+	})
+}
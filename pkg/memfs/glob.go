@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// GlobEx returns the sorted paths of all files and directories in the
+// directory tree matching the pattern. Paths are relative to the directory.
+//
+// The pattern syntax is the same as in [path.Match] with two extensions:
+//
+//   - the "**" path element matches zero or more path elements,
+//   - the "{a,b}" matches any of the comma-separated alternatives, which may
+//     contain patterns and nested alternatives.
+//
+// Returns [path.ErrBadPattern] when the pattern is malformed and an error of
+// the [fs.PathError] type with [syscall.ENOTDIR] when the instance is not a
+// directory.
+func (fil *File) GlobEx(pattern string) ([]string, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "glob",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	pts, err := expandBraces(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, pt := range pts {
+		if err = validGlob(pt); err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []string
+	fil.walk("", func(pth string, _ *File) {
+		for _, pt := range pts {
+			if ok, _ := matchGlob(pt, pth); ok {
+				matches = append(matches, pth)
+				return
+			}
+		}
+	})
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// walk calls fn for every file and directory in the directory tree. The pth is
+// the path of the directory relative to the walk root. The entries of every
+// directory are visited in the name order, each directory before its
+// entries, so the order doesn't depend on the order the entries were added.
+// The tree is walked without recursion, so the very deep trees don't grow the
+// goroutine stack.
+func (fil *File) walk(pth string, fn func(pth string, ent *File)) {
+	type frame struct {
+		pth  string  // Path of the directory.
+		ents []*File // Entries of the directory not visited yet.
+	}
+	fil.sortEntries()
+	stack := []*frame{{pth: pth, ents: fil.entries}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if len(top.ents) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		ent := top.ents[0]
+		top.ents = top.ents[1:]
+		entPth := filepath.Join(top.pth, ent.Name())
+		fn(entPth, ent)
+		if ent.IsDir() {
+			ent.sortEntries()
+			stack = append(stack, &frame{pth: entPth, ents: ent.entries})
+		}
+	}
+}
+
+// matchGlob reports whether the name matches the pattern. The pattern syntax
+// is the same as in [path.Match] with the "**" path element matching zero or
+// more path elements. The brace alternatives must be expanded with
+// [expandBraces] before calling this function.
+func matchGlob(pattern, name string) (bool, error) {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchElems reports whether the name elements match the pattern elements.
+func matchElems(pts, names []string) (bool, error) {
+	for len(pts) > 0 {
+		if pts[0] == "**" {
+			// Collapse consecutive "**" elements.
+			for len(pts) > 0 && pts[0] == "**" {
+				pts = pts[1:]
+			}
+			if len(pts) == 0 {
+				return true, nil
+			}
+			for i := range names {
+				ok, err := matchElems(pts, names[i:])
+				if ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(names) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pts[0], names[0])
+		if !ok || err != nil {
+			return false, err
+		}
+		pts, names = pts[1:], names[1:]
+	}
+	return len(names) == 0, nil
+}
+
+// validGlob returns [path.ErrBadPattern] when any of the pattern elements is
+// malformed.
+func validGlob(pattern string) error {
+	for _, pt := range strings.Split(pattern, "/") {
+		if _, err := path.Match(pt, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandBraces expands the "{a,b}" alternatives in the pattern and returns the
+// list of patterns without alternatives. Braces escaped with backslash are not
+// expanded. Returns [path.ErrBadPattern] when the braces are not balanced.
+func expandBraces(pattern string) ([]string, error) {
+	start, end := -1, -1
+	depth := 0
+	var commas []int
+
+loop:
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			if depth == 0 {
+				start = i
+				commas = commas[:0]
+			}
+			depth++
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		case '}':
+			if depth == 0 {
+				return nil, path.ErrBadPattern
+			}
+			depth--
+			if depth == 0 {
+				end = i
+				break loop
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, path.ErrBadPattern
+	}
+	if start == -1 {
+		return []string{pattern}, nil
+	}
+
+	pre, post := pattern[:start], pattern[end+1:]
+	bounds := append(append([]int{start}, commas...), end)
+
+	var pts []string
+	for i := 0; i < len(bounds)-1; i++ {
+		alt := pattern[bounds[i]+1 : bounds[i+1]]
+		exp, err := expandBraces(pre + alt + post)
+		if err != nil {
+			return nil, err
+		}
+		for _, pt := range exp {
+			if !slices.Contains(pts, pt) {
+				pts = append(pts, pt)
+			}
+		}
+	}
+	return pts, nil
+}
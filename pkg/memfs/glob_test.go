@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"path"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_GlobEx(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pattern string
+		want    []string
+	}{
+		{"no wildcards", "file0", []string{"file0"}},
+		{"star", "file*", []string{"file0", "file1", "file2"}},
+		{"star in directory", "sub/*", []string{
+			"sub/file3", "sub/file4", "sub/sub2",
+		}},
+		{"double star", "**", []string{
+			"file0", "file1", "file2",
+			"sub", "sub/file3", "sub/file4",
+			"sub/sub2", "sub/sub2/file5", "sub/sub2/file6",
+		}},
+		{"double star prefix", "**/file[35]", []string{
+			"sub/file3", "sub/sub2/file5",
+		}},
+		{"double star matches zero elements", "**/file0", []string{"file0"}},
+		{"double star inside", "sub/**/file6", []string{"sub/sub2/file6"}},
+		{"braces", "{file0,sub/file4}", []string{"file0", "sub/file4"}},
+		{"braces with patterns", "**/file{[02],6}", []string{
+			"file0", "file2", "sub/sub2/file6",
+		}},
+		{"nested braces", "sub/{file{3,4},sub2}", []string{
+			"sub/file3", "sub/file4", "sub/sub2",
+		}},
+		{"no matches", "**/file9", nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			dir := tstDirMem()
+
+			// --- When ---
+			have, err := dir.GlobEx(tc.pattern)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+
+	t.Run("error - bad pattern", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.GlobEx("sub/[")
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have, err := fil.GlobEx("*")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "glob", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_File_walk(t *testing.T) {
+	t.Run("walk", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(open(tstDirMem(), "sub"))
+		var have []string
+
+		// --- When ---
+		dir.walk("sub", func(pth string, ent *File) {
+			have = append(have, pth+":"+ent.Name())
+		})
+
+		// --- Then ---
+		want := []string{
+			"sub/file3:file3",
+			"sub/file4:file4",
+			"sub/sub2:sub2",
+			"sub/sub2/file5:file5",
+			"sub/sub2/file6:file6",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("name order", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMixed()
+		must.Nil(must.Value(open(dir, "d3")).AddFile(MustFile("f1")))
+		var have []string
+
+		// --- When ---
+		dir.walk("", func(pth string, _ *File) { have = append(have, pth) })
+
+		// --- Then ---
+		want := []string{"d1", "d3", "d3/f1", "f0", "f2", "f4"}
+		assert.Equal(t, want, have)
+	})
+}
+
+func Test_matchGlob(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"exact", "a/b", "a/b", true},
+		{"not matching", "a/b", "a/c", false},
+		{"too short name", "a/b", "a", false},
+		{"too long name", "a", "a/b", false},
+		{"double star alone", "**", "a/b/c", true},
+		{"double star zero elements", "a/**/b", "a/b", true},
+		{"double star many elements", "a/**/b", "a/x/y/b", true},
+		{"double star not matching", "a/**/b", "a/x/y/c", false},
+		{"consecutive double stars", "**/**/c", "a/b/c", true},
+		{"trailing double star", "a/**", "a/b/c", true},
+		{"star does not cross elements", "a/*", "a/b/c", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have, err := matchGlob(tc.pattern, tc.name)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func Test_validGlob(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		// --- When ---
+		err := validGlob("**/a/[ab]*")
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		// --- When ---
+		err := validGlob("a/[")
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+	})
+}
+
+func Test_expandBraces(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pattern string
+		want    []string
+	}{
+		{"no braces", "a/b", []string{"a/b"}},
+		{"one alternative", "a/{b}", []string{"a/b"}},
+		{"two alternatives", "a/{b,c}", []string{"a/b", "a/c"}},
+		{"empty alternative", "a{,b}", []string{"a", "ab"}},
+		{"two groups", "{a,b}{c,d}", []string{"ac", "ad", "bc", "bd"}},
+		{"nested", "{a,b{c,d}}", []string{"a", "bc", "bd"}},
+		{"duplicates", "{a,a}", []string{"a"}},
+		{"escaped", `\{a,b\}`, []string{`\{a,b\}`}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have, err := expandBraces(tc.pattern)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+
+	t.Run("error - not closed", func(t *testing.T) {
+		// --- When ---
+		have, err := expandBraces("{a,b")
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - not opened", func(t *testing.T) {
+		// --- When ---
+		have, err := expandBraces("a,b}")
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.Nil(t, have)
+	})
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+// defaultGrowth is the default buffer growth factor.
+const defaultGrowth = 2
+
+// WithGrowthFactor is a [File] constructor function option setting the buffer
+// growth policy. When a write doesn't fit in the buffer, a new buffer with the
+// capacity of cap*factor+n is allocated, where n is the number of bytes which
+// don't fit. The default factor is 2. Smaller factors, like 1.5, waste less
+// memory for large files at the cost of more frequent allocations. When used
+// on a directory, it applies to all files in its tree which don't set their
+// own growth policy. It panics when the factor is less than one.
+//
+// Use [File.Len] and [File.Cap] to see how much of the allocated memory is
+// used.
+func WithGrowthFactor(factor float64) func(*File) {
+	if factor < 1 {
+		panic("memfs.WithGrowthFactor: factor less than one")
+	}
+	return func(fil *File) { fil.growth, fil.exact = factor, false }
+}
+
+// WithExactAlloc is a [File] constructor function option setting the buffer
+// growth policy to allocate exactly as much memory as a write needs. It's
+// useful for files written once with a few big writes, like fixtures, but
+// makes every write beyond the buffer capacity reallocate it. The
+// [File.ReadFrom] method can't know how much data it will read, so it still
+// grows the buffer with the default factor. When used on a directory, it
+// applies to all files in its tree which don't set their own growth policy.
+func WithExactAlloc(fil *File) { fil.growth, fil.exact = 0, true }
+
+// growthPolicy returns the buffer growth factor and true when the exact
+// allocation is used, as set on the instance or the nearest of its parents.
+func (fil *File) growthPolicy() (float64, bool) {
+	for f := fil; f != nil; f = f.parent {
+		if f.exact {
+			return defaultGrowth, true
+		}
+		if f.growth != 0 {
+			return f.growth, false
+		}
+	}
+	return defaultGrowth, false
+}
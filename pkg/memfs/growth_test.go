@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithGrowthFactor(t *testing.T) {
+	t.Run("write beyond capacity", func(t *testing.T) {
+		// --- Given ---
+		buf := make([]byte, 0, 100)
+		fil := must.Value(FileWith("file", buf, WithGrowthFactor(1.5)))
+
+		// --- When ---
+		n, err := fil.Write(bytes.Repeat([]byte{'a'}, 101))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 101, n)
+		assert.Equal(t, 101, fil.Len())
+		assert.Equal(t, 251, fil.Cap())
+	})
+
+	t.Run("default", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(FileWith("file", make([]byte, 0, 100)))
+
+		// --- When ---
+		must.Value(fil.Write(bytes.Repeat([]byte{'a'}, 101)))
+
+		// --- Then ---
+		assert.Equal(t, 301, fil.Cap())
+	})
+
+	t.Run("inherited from directory", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithGrowthFactor(1.5))
+		dir := MustDirectory("dir")
+		fil := must.Value(FileWith("file", make([]byte, 0, 100)))
+		must.Nil(dir.AddFile(fil))
+		must.Nil(root.AddFile(dir))
+
+		// --- When ---
+		must.Value(fil.Write(bytes.Repeat([]byte{'a'}, 101)))
+
+		// --- Then ---
+		assert.Equal(t, 251, fil.Cap())
+	})
+
+	t.Run("file overrides directory", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithExactAlloc)
+		buf := make([]byte, 0, 100)
+		fil := must.Value(FileWith("file", buf, WithGrowthFactor(1)))
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		must.Value(fil.Write(bytes.Repeat([]byte{'a'}, 101)))
+
+		// --- Then ---
+		assert.Equal(t, 201, fil.Cap())
+	})
+
+	t.Run("panic - factor less than one", func(t *testing.T) {
+		// --- Given ---
+		fn := func() { WithGrowthFactor(0.5) }
+
+		// --- When ---
+		msg := assert.PanicMsg(t, fn)
+
+		// --- Then ---
+		assert.Equal(t, "memfs.WithGrowthFactor: factor less than one", *msg)
+	})
+}
+
+func Test_WithExactAlloc(t *testing.T) {
+	t.Run("write", func(t *testing.T) {
+		// --- Given ---
+		buf := make([]byte, 0, 100)
+		fil := must.Value(FileWith("file", buf, WithExactAlloc))
+
+		// --- When ---
+		n, err := fil.Write(bytes.Repeat([]byte{'a'}, 101))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 101, n)
+		assert.Equal(t, 101, fil.Len())
+		assert.Equal(t, 101, fil.Cap())
+	})
+
+	t.Run("small write to empty file", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(FileWith("file", nil, WithExactAlloc))
+
+		// --- When ---
+		must.Value(fil.WriteString("abc"))
+
+		// --- Then ---
+		assert.Equal(t, []byte("abc"), fil.buf)
+		assert.Equal(t, 3, fil.Cap())
+	})
+
+	t.Run("WriteAt", func(t *testing.T) {
+		// --- Given ---
+		buf := make([]byte, 10, 100)
+		fil := must.Value(FileWith("file", buf, WithExactAlloc))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte("abc"), 200)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, 203, fil.Len())
+		assert.Equal(t, 203, fil.Cap())
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		// --- Given ---
+		buf := make([]byte, 10, 100)
+		fil := must.Value(FileWith("file", buf, WithExactAlloc))
+
+		// --- When ---
+		err := fil.Truncate(300)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 300, fil.Len())
+		assert.Equal(t, 300, fil.Cap())
+	})
+
+	t.Run("ReadFrom uses default factor", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(FileWith("file", nil, WithExactAlloc))
+		def := must.Value(FileWith("file", nil))
+		must.Value(def.ReadFrom(strings.NewReader("abc")))
+
+		// --- When ---
+		n, err := fil.ReadFrom(strings.NewReader("abc"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, []byte("abc"), fil.buf)
+		assert.Equal(t, def.Cap(), fil.Cap())
+	})
+}
+
+func Test_File_growthPolicy(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		// --- When ---
+		factor, exact := MustFile("file").growthPolicy()
+
+		// --- Then ---
+		assert.Equal(t, 2.0, factor)
+		assert.False(t, exact)
+	})
+
+	t.Run("nearest parent", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithExactAlloc)
+		dir := must.Value(NewDirectory("dir", WithGrowthFactor(1.25)))
+		fil := MustFile("file")
+		must.Nil(dir.AddFile(fil))
+		must.Nil(root.AddFile(dir))
+
+		// --- When ---
+		factor, exact := fil.growthPolicy()
+
+		// --- Then ---
+		assert.Equal(t, 1.25, factor)
+		assert.False(t, exact)
+	})
+}
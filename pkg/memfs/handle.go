@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// Compile time checks.
+var (
+	_ fs.ReadDirFile  = &Handle{}
+	_ io.ReaderAt     = &Handle{}
+	_ io.ReaderFrom   = &Handle{}
+	_ io.Seeker       = &Handle{}
+	_ io.StringWriter = &Handle{}
+	_ io.WriterAt     = &Handle{}
+	_ io.WriterTo     = &Handle{}
+)
+
+// Handle is a file or directory in the directory tree opened with
+// [File.OpenFile] or [File.CreateTemp], like [os.File] is for the files on
+// disk. Every handle has its own offset, [Handle.ReadDir] cursor and flags,
+// so the handles of the same file don't affect each other, and the access
+// mode is enforced: the write methods of the handles opened with
+// [os.O_RDONLY] and the read methods of the handles opened with
+// [os.O_WRONLY] return an error of the [fs.PathError] type with
+// [syscall.EBADF]. The methods work like the [File] methods with the same
+// names, and the closed handle can't be reused.
+type Handle struct {
+	fil     *File
+	name    string  // Name passed to OpenFile.
+	flag    int     // Flags passed to OpenFile.
+	off     int     // Offset of the handle.
+	cursor  int     // Directory entries already read.
+	listing []*File // Snapshot of the directory entries.
+	wrote   bool    // The file was modified through the handle.
+	closed  bool    // The handle was closed.
+}
+
+// File returns the file in the directory tree the handle is for.
+func (h *Handle) File() *File { return h.fil }
+
+// Name returns the name passed to [File.OpenFile].
+func (h *Handle) Name() string { return h.name }
+
+// Stat implements [fs.File] interface.
+func (h *Handle) Stat() (fs.FileInfo, error) {
+	if err := h.check("stat"); err != nil {
+		return nil, err
+	}
+	return h.fil.Stat()
+}
+
+// Read implements [fs.File] interface.
+func (h *Handle) Read(p []byte) (n int, err error) {
+	if err = h.access("read", os.O_RDONLY); err != nil {
+		return 0, err
+	}
+	h.do(func() { n, err = h.fil.Read(p) })
+	return n, err
+}
+
+// ReadAt implements [io.ReaderAt] interface.
+func (h *Handle) ReadAt(p []byte, off int64) (n int, err error) {
+	if err = h.access("read", os.O_RDONLY); err != nil {
+		return 0, err
+	}
+	h.do(func() { n, err = h.fil.ReadAt(p, off) })
+	return n, err
+}
+
+// WriteTo implements [io.WriterTo] interface.
+func (h *Handle) WriteTo(w io.Writer) (n int64, err error) {
+	if err = h.access("read", os.O_RDONLY); err != nil {
+		return 0, err
+	}
+	h.do(func() { n, err = h.fil.WriteTo(w) })
+	return n, err
+}
+
+// ReadDir implements [fs.ReadDirFile] interface.
+func (h *Handle) ReadDir(n int) (ets []fs.DirEntry, err error) {
+	if err = h.check("readdirent"); err != nil {
+		return nil, err
+	}
+	h.do(func() { ets, err = h.fil.ReadDir(n) })
+	return ets, err
+}
+
+// Write implements [io.Writer] interface.
+func (h *Handle) Write(p []byte) (n int, err error) {
+	if err = h.access("write", os.O_WRONLY); err != nil {
+		return 0, err
+	}
+	h.do(func() { n, err = h.fil.Write(p) })
+	return n, err
+}
+
+// WriteString implements [io.StringWriter] interface.
+func (h *Handle) WriteString(s string) (n int, err error) {
+	if err = h.access("write", os.O_WRONLY); err != nil {
+		return 0, err
+	}
+	h.do(func() { n, err = h.fil.WriteString(s) })
+	return n, err
+}
+
+// WriteAt implements [io.WriterAt] interface.
+func (h *Handle) WriteAt(p []byte, off int64) (n int, err error) {
+	if err = h.access("write", os.O_WRONLY); err != nil {
+		return 0, err
+	}
+	h.do(func() { n, err = h.fil.WriteAt(p, off) })
+	return n, err
+}
+
+// ReadFrom implements [io.ReaderFrom] interface.
+func (h *Handle) ReadFrom(r io.Reader) (n int64, err error) {
+	if err = h.access("write", os.O_WRONLY); err != nil {
+		return 0, err
+	}
+	h.do(func() { n, err = h.fil.ReadFrom(r) })
+	return n, err
+}
+
+// Truncate changes the size of the file, see [File.Truncate].
+func (h *Handle) Truncate(size int64) (err error) {
+	if err = h.access("truncate", os.O_WRONLY); err != nil {
+		return err
+	}
+	h.do(func() { err = h.fil.Truncate(size) })
+	return err
+}
+
+// Seek implements [io.Seeker] interface.
+func (h *Handle) Seek(offset int64, whence int) (off int64, err error) {
+	if err = h.check("seek"); err != nil {
+		return 0, err
+	}
+	h.do(func() { off, err = h.fil.Seek(offset, whence) })
+	return off, err
+}
+
+// LockRange places an advisory byte-range lock owned by the handle, see
+// [File.LockRange] for details. The locks of the handle conflict with the
+// locks of other handles and of the file, so the lock ordering of the code
+// using many handles can be tested. The locks are released by
+// [Handle.UnlockRange] or [Handle.Close].
+func (h *Handle) LockRange(off, n int64, exclusive bool) error {
+	if err := h.check("lock"); err != nil {
+		return err
+	}
+	if err := h.fil.lockRange(h, off, n, exclusive); err != nil {
+		return h.err("lock", err)
+	}
+	return nil
+}
+
+// UnlockRange releases the lock placed with [Handle.LockRange] for the same
+// range, see [File.UnlockRange] for details.
+func (h *Handle) UnlockRange(off, n int64) error {
+	if err := h.check("unlock"); err != nil {
+		return err
+	}
+	if err := h.fil.unlockRange(h, off, n); err != nil {
+		return h.err("unlock", err)
+	}
+	return nil
+}
+
+// Close implements [fs.File] interface. It releases the file like
+// [File.Close] does, without resetting its offset, releases the byte-range
+// locks of the handle (see [Handle.LockRange]), and, when the file was
+// modified through the handle, seals the write-once files (see
+// [WithWriteOnce]).
+func (h *Handle) Close() error {
+	if err := h.check("close"); err != nil {
+		return err
+	}
+	h.closed = true
+	h.listing, h.cursor = nil, 0
+	h.fil.unlockAll(h)
+	if h.flag&OpenShareDelete != 0 && h.fil.shareDel > 0 {
+		h.fil.shareDel--
+	}
+	return h.fil.release(h.wrote)
+}
+
+// do calls fn with the offset, the [File.ReadDir] state and the flags of the
+// file replaced by the ones of the handle, and restores them afterward.
+func (h *Handle) do(fn func()) {
+	fil := h.fil
+	off, cursor, listing := fil.off, fil.cursor, fil.listing
+	flag, wrote := fil.flag, fil.wrote
+	fil.off, fil.cursor, fil.listing = h.off, h.cursor, h.listing
+	fil.flag, fil.wrote = h.flag, false
+
+	fn()
+
+	h.off, h.cursor, h.listing = fil.off, fil.cursor, fil.listing
+	h.wrote = h.wrote || fil.wrote
+	fil.off, fil.cursor, fil.listing = off, cursor, listing
+	fil.flag, fil.wrote = flag, wrote
+}
+
+// access returns an error of the [fs.PathError] type with [syscall.EBADF]
+// when the access mode of the handle doesn't allow the operation. The mode
+// is [os.O_RDONLY] for the read and [os.O_WRONLY] for the write operations.
+func (h *Handle) access(op string, mode int) error {
+	if err := h.check(op); err != nil {
+		return err
+	}
+	acc := h.flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR)
+	if acc == os.O_RDWR || acc == mode {
+		return nil
+	}
+	return h.err(op, syscall.EBADF)
+}
+
+// check returns an error of the [fs.PathError] type with [fs.ErrClosed] when
+// the handle was closed.
+func (h *Handle) check(op string) error {
+	if h.closed {
+		return h.err(op, fs.ErrClosed)
+	}
+	return nil
+}
+
+// err returns an error of the [fs.PathError] type for the handle.
+func (h *Handle) err(op string, err error) error {
+	return h.fil.hookErr(&fs.PathError{Op: op, Path: h.name, Err: err})
+}
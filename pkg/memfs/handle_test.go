@@ -0,0 +1,346 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_Handle_Read(t *testing.T) {
+	t.Run("independent offsets", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.WriteFile("file", []byte("abc"), 0600))
+		h0 := must.Value(dir.OpenFile("file", os.O_RDONLY, 0))
+		must.Value(h0.Read(make([]byte, 2)))
+
+		// --- When ---
+		h1 := must.Value(dir.OpenFile("file", os.O_RDONLY, 0))
+
+		// --- Then ---
+		assert.Equal(t, "c", string(must.Value(io.ReadAll(h0))))
+		assert.Equal(t, "abc", string(must.Value(io.ReadAll(h1))))
+		assert.Equal(t, 0, h0.File().Offset())
+	})
+
+	t.Run("error - write only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_WRONLY, 0))
+
+		// --- When ---
+		n, err := h.Read(make([]byte, 2))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EBADF, e.Err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+		must.Nil(h.Close())
+
+		// --- When ---
+		n, err := h.Read(make([]byte, 2))
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrClosed, err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func Test_Handle_Write(t *testing.T) {
+	t.Run("independent offsets", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		h1 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Value(h0.Seek(2, io.SeekStart))
+
+		// --- When ---
+		n0, err0 := h0.Write([]byte("X"))
+		n1, err1 := h1.Write([]byte("Y"))
+
+		// --- Then ---
+		assert.NoError(t, err0)
+		assert.Equal(t, 1, n0)
+		assert.NoError(t, err1)
+		assert.Equal(t, 1, n1)
+		assert.Equal(t, "YiXe0", string(h0.File().buf))
+	})
+
+	t.Run("append flag does not leak", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile("file0", os.O_WRONLY|os.O_APPEND, 0))
+		h1 := must.Value(dir.OpenFile("file0", os.O_WRONLY, 0))
+
+		// --- When ---
+		must.Value(h0.Write([]byte("A")))
+		must.Value(h1.Write([]byte("B")))
+
+		// --- Then ---
+		assert.Equal(t, "Bile0A", string(h0.File().buf))
+	})
+
+	t.Run("error - read only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+
+		// --- When ---
+		n, err := h.Write([]byte("X"))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "write", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EBADF, e.Err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, "file0", string(h.File().buf))
+	})
+
+	t.Run("error - read only write methods", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+
+		// --- When ---
+		_, err0 := h.WriteString("X")
+		_, err1 := h.WriteAt([]byte("X"), 0)
+		_, err2 := h.ReadFrom(bytes.NewReader([]byte("X")))
+		err3 := h.Truncate(0)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EBADF, err0)
+		assert.ErrorIs(t, syscall.EBADF, err1)
+		assert.ErrorIs(t, syscall.EBADF, err2)
+		assert.ErrorIs(t, syscall.EBADF, err3)
+		assert.Equal(t, "file0", string(h.File().buf))
+	})
+}
+
+func Test_Handle_ReadDir(t *testing.T) {
+	t.Run("independent cursors", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile(".", os.O_RDONLY, 0))
+		h1 := must.Value(dir.OpenFile(".", os.O_RDONLY, 0))
+		must.Value(h0.ReadDir(3))
+
+		// --- When ---
+		have, err := h1.ReadDir(-1)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 4, have)
+		assert.Len(t, 1, must.Value(h0.ReadDir(-1)))
+	})
+}
+
+func Test_Handle_Close(t *testing.T) {
+	t.Run("close", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+		h1 := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+		must.Value(h1.Read(make([]byte, 2)))
+
+		// --- When ---
+		err := h0.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 1, h0.File().OpenCount())
+		assert.Equal(t, "le0", string(must.Value(io.ReadAll(h1))))
+	})
+
+	t.Run("error - closed twice", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+		must.Nil(h.Close())
+
+		// --- When ---
+		err := h.Close()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "close", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+	})
+}
+
+func Test_Handle_LockRange(t *testing.T) {
+	t.Run("lock", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+
+		// --- When ---
+		err := h.LockRange(0, 2, true)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := h.File()
+		want := []rangeLock{{off: 0, end: 2, excl: true, owner: h}}
+		assert.Equal(t, want, fil.locks)
+	})
+
+	t.Run("shared locks of handles", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+		h1 := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+		must.Nil(h0.LockRange(0, 5, false))
+
+		// --- When ---
+		err := h1.LockRange(2, 5, false)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 2, h0.File().locks)
+	})
+
+	t.Run("released by Close", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		h1 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h0.LockRange(0, 0, true))
+
+		// --- When ---
+		must.Nil(h0.Close())
+
+		// --- Then ---
+		assert.NoError(t, h1.LockRange(0, 0, true))
+		assert.Len(t, 1, h1.File().locks)
+	})
+
+	t.Run("Close keeps locks of other handles", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		h1 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h0.LockRange(0, 2, true))
+		must.Nil(h1.LockRange(2, 2, true))
+
+		// --- When ---
+		must.Nil(h0.Close())
+
+		// --- Then ---
+		want := []rangeLock{{off: 2, end: 4, excl: true, owner: h1}}
+		assert.Equal(t, want, h1.File().locks)
+	})
+
+	t.Run("error - conflict with other handle", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		h1 := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+		must.Nil(h0.LockRange(0, 5, true))
+
+		// --- When ---
+		err := h1.LockRange(4, 1, false)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "lock", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EAGAIN, e.Err)
+	})
+
+	t.Run("error - conflict with file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h.File().LockRange(0, 5, false))
+
+		// --- When ---
+		err := h.LockRange(0, 5, true)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EAGAIN, err)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h.Close())
+
+		// --- When ---
+		err := h.LockRange(0, 5, true)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrClosed, err)
+		assert.Len(t, 0, h.File().locks)
+	})
+}
+
+func Test_Handle_UnlockRange(t *testing.T) {
+	t.Run("unlock", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h.LockRange(0, 5, true))
+
+		// --- When ---
+		err := h.UnlockRange(0, 5)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, h.File().locks)
+	})
+
+	t.Run("error - lock of other handle", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h0 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		h1 := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h0.LockRange(0, 5, true))
+
+		// --- When ---
+		err := h1.UnlockRange(0, 5)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "unlock", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.ENOLCK, e.Err)
+		assert.Len(t, 1, h0.File().locks)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h.Close())
+
+		// --- When ---
+		err := h.UnlockRange(0, 5)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrClosed, err)
+	})
+}
@@ -5,33 +5,104 @@ package memfs
 
 import (
 	"io/fs"
-	"path/filepath"
 	"strings"
+	"syscall"
 )
 
-// open opens files in a given directory or its subdirectories.
+// open opens files in a given directory or its subdirectories. The symbolic
+// links (see [File.Symlink]) are followed.
 func open(dir *File, name string) (*File, error) {
+	return lookup(dir, name, true)
+}
+
+// lopen works like [open] but doesn't follow the symbolic link named by the
+// last element of the name.
+func lopen(dir *File, name string) (*File, error) {
+	return lookup(dir, name, false)
+}
+
+// lookup returns the file with the given name in a given directory or its
+// subdirectories. The symbolic links are followed, but the one named by the
+// last element of the name only when follow is true. Returns an error of the
+// [fs.PathError] type with [syscall.ELOOP] when more links were followed than
+// allowed in the directory (see [WithMaxSymlinks]).
+func lookup(dir *File, name string, follow bool) (*File, error) {
+	name = dir.lenientPath(name)
 	if !fs.ValidPath(name) {
 		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
 	}
 
+	cur, hops, limit := dir, 0, dir.symlinkLimit()
+	elems := strings.Split(name, "/")
+	for len(elems) > 0 {
+		elem := elems[0]
+		elems = elems[1:]
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if cur.parent != nil {
+				cur = cur.parent
+			}
+			continue
+		}
+
+		ent := cur.entry(elem)
+		if ent == nil {
+			return nil, &fs.PathError{
+				Op:   "open",
+				Path: elem,
+				Err:  fs.ErrNotExist,
+			}
+		}
+		if ent.isSymlink() && (follow || len(elems) > 0) {
+			if hops++; hops > limit {
+				return nil, &fs.PathError{
+					Op:   "open",
+					Path: name,
+					Err:  syscall.ELOOP,
+				}
+			}
+			if strings.HasPrefix(ent.link, "/") {
+				for cur.parent != nil {
+					cur = cur.parent
+				}
+			}
+			elems = append(strings.Split(ent.link, "/"), elems...)
+			continue
+		}
+		cur = ent
+	}
+	return cur, nil
+}
+
+// mkdirAll returns the directory with the given name in a given directory or
+// its subdirectories, creating it along with any missing parents. The missing
+// directories are created with the given options. Returns an error of the
+// [fs.PathError] type with [syscall.ENOTDIR] when any path element exists and
+// is not a directory.
+func mkdirAll(dir *File, name string, opts ...func(*File)) (*File, error) {
 	if name == "." {
 		return dir, nil
 	}
-
-	parts := strings.Split(name, string(filepath.Separator))
-	if len(parts) > 1 {
-		d, err := open(dir, parts[0])
+	for _, elem := range strings.Split(name, "/") {
+		sub, err := open(dir, elem)
 		if err != nil {
-			return nil, err
+			if sub, err = NewDirectory(elem, opts...); err != nil {
+				return nil, err
+			}
+			if err = dir.AddFile(sub); err != nil {
+				return nil, err
+			}
 		}
-		return open(d, filepath.Join(parts[1:]...))
-	}
-
-	for _, fil := range dir.entries {
-		if fil.Name() == name {
-			return fil, nil
+		if !sub.IsDir() {
+			return nil, &fs.PathError{
+				Op:   "mkdir",
+				Path: sub.path(),
+				Err:  syscall.ENOTDIR,
+			}
 		}
+		dir = sub
 	}
-	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	return dir, nil
 }
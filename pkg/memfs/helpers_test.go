@@ -6,6 +6,7 @@ package memfs
 import (
 	"io"
 	"io/fs"
+	"syscall"
 	"testing"
 
 	"github.com/ctx42/testing/pkg/assert"
@@ -114,3 +115,60 @@ func Test_open(t *testing.T) {
 		assert.Nil(t, have)
 	})
 }
+
+func Test_mkdirAll(t *testing.T) {
+	t.Run("create nested directories", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot()
+
+		// --- When ---
+		have, err := mkdirAll(root, "a/b/c")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "c", have.Name())
+		assert.True(t, have.IsDir())
+		assert.Same(t, have, must.Value(open(root, "a/b/c")))
+	})
+
+	t.Run("existing directory", func(t *testing.T) {
+		// --- Given ---
+		root := tstDirMem()
+		sub := must.Value(open(root, "sub/sub2"))
+
+		// --- When ---
+		have, err := mkdirAll(root, "sub/sub2")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, sub, have)
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot()
+
+		// --- When ---
+		have, err := mkdirAll(root, ".")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, root, have)
+	})
+
+	t.Run("error - file in the path", func(t *testing.T) {
+		// --- Given ---
+		root := tstDirMem()
+
+		// --- When ---
+		have, err := mkdirAll(root, "sub/file3/dir")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "mkdir", e.Op)
+		assert.Equal(t, "sub/file3", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+}
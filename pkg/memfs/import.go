@@ -0,0 +1,346 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"syscall"
+)
+
+// ConflictPolicy decides what the importers, like [File.ImportTar], do when
+// an archive entry conflicts with a file already in the tree. A regular file
+// entry conflicts with any existing file or directory with the same path,
+// and a directory entry conflicts with an existing regular file. Directory
+// entries matching existing directories are merged.
+type ConflictPolicy int
+
+const (
+	// ConflictError makes the import fail with an error of the
+	// [fs.PathError] type with [syscall.EEXIST].
+	ConflictError ConflictPolicy = iota
+
+	// ConflictSkip keeps the existing file and skips the archive entry.
+	ConflictSkip
+
+	// ConflictOverwrite replaces the existing file, or directory with its
+	// subtree, with the archive entry. The permission bits of the merged
+	// directories, other than the one imported to, are set from the
+	// archive too.
+	ConflictOverwrite
+)
+
+// ImportTar merges the tree stored in the uncompressed tar archive read from
+// r into the directory, like unpacking an archive over an existing
+// directory, resolving the conflicts with the files already in the tree
+// according to the policy. The permission bits of the entries are kept. The
+// entries imported before an error stay in the tree.
+//
+// The entry names are cleaned with [path.Clean] and must be valid [io/fs]
+// paths without backslashes, so the absolute paths, the paths escaping the
+// directory with ".." elements, and the Windows paths are rejected. The
+// archive must not exceed the limits set with [WithImportLimits] on the
+// directory, or any of its parents, or the [DefaultImportLimits].
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, with [fs.ErrInvalid] when the archive has an
+// entry with an invalid name or of a type other than a directory or a
+// regular file, wrapping [ErrImportLimit] when the archive exceeds the
+// limits, with [syscall.EEXIST] for the conflicts with the [ConflictError]
+// policy, the errors returned by [File.AddFile] and [File.RemoveAll], and
+// the errors of the [archive/tar] package.
+//
+// The progress is reported to the function set with [WithProgress], with
+// the total of -1, as the archive is read as a stream. The skipped regular
+// files are reported too.
+func (fil *File) ImportTar(r io.Reader, policy ConflictPolicy) error {
+	imp, err := fil.importer("tar", policy)
+	if err != nil {
+		return err
+	}
+	imp.prog = fil.progress(func() int64 { return -1 })
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		mode := hdr.FileInfo().Mode()
+		content := func() ([]byte, error) { return io.ReadAll(tr) }
+		err = imp.add(hdr.Name, mode, hdr.Size, -1, content)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ImportZip merges the tree stored in the zip archive read from r, which has
+// the given size, into the directory. The file contents are decompressed
+// during the import. It works like [File.ImportTar] otherwise, and returns
+// the errors of the [archive/zip] package instead of the [archive/tar] ones.
+// The total reported to the function set with [WithProgress] is the sum of
+// the uncompressed sizes of the regular files in the archive.
+func (fil *File) ImportZip(
+	r io.ReaderAt,
+	size int64,
+	policy ConflictPolicy,
+) error {
+	imp, err := fil.importer("zip", policy)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return err
+	}
+	imp.prog = fil.progress(func() int64 {
+		var total int64
+		for _, zf := range zr.File {
+			if zf.Mode().IsRegular() {
+				total += zipSize(zf)
+			}
+		}
+		return total
+	})
+	for _, zf := range zr.File {
+		err = imp.add(
+			zf.Name,
+			zf.Mode(),
+			zipSize(zf),
+			int64(zf.CompressedSize64),
+			zipContent(zf),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportTxtar merges the files stored in the txtar format (see
+// golang.org/x/tools/txtar), for example, returned by [File.Txtar], into the
+// directory. The comment before the first file is ignored. The files get the
+// default permission bits (0600), and the missing parent directories are
+// created. It works like [File.ImportTar] otherwise, but the total reported
+// to the function set with [WithProgress] is the size of all files.
+func (fil *File) ImportTxtar(data string, policy ConflictPolicy) error {
+	imp, err := fil.importer("txtar", policy)
+	if err != nil {
+		return err
+	}
+	files := parseTxtar(data)
+	imp.prog = fil.progress(func() int64 {
+		var total int64
+		for _, tf := range files {
+			total += int64(len(tf.data))
+		}
+		return total
+	})
+	for _, tf := range files {
+		content := func() ([]byte, error) { return []byte(tf.data), nil }
+		size := int64(len(tf.data))
+		if err = imp.add(tf.name, 0600, size, -1, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importer returns the importer merging archive entries into the directory.
+func (fil *File) importer(op string, policy ConflictPolicy) (importer, error) {
+	if !fil.IsDir() {
+		return importer{}, fil.hookErr(&fs.PathError{
+			Op:   op,
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	imp := importer{
+		dir:    fil,
+		op:     op,
+		policy: policy,
+		lim:    newImportLimiter(fil, op),
+	}
+	return imp, nil
+}
+
+// importer merges archive entries into a directory.
+type importer struct {
+	dir    *File          // The directory to import to.
+	op     string         // Operation name used in errors.
+	policy ConflictPolicy // Conflict resolution policy.
+	lim    *importLimiter // Import limits.
+	prog   *progress      // See [WithProgress].
+}
+
+// add adds the archive entry with the given name, mode and size, compressed
+// in the archive to the given number of bytes or -1, to the directory. The
+// content is called only for the regular files which are added. The regular
+// files are reported to the progress, even when skipped.
+func (imp importer) add(
+	name string,
+	mode fs.FileMode,
+	size, compressed int64,
+	content func() ([]byte, error),
+) error {
+	pth := path.Clean(name)
+	if !validArchivePath(pth) || (pth == "." && !mode.IsDir()) ||
+		!(mode.IsDir() || mode.IsRegular()) {
+		return imp.dir.hookErr(&fs.PathError{
+			Op:   imp.op,
+			Path: name,
+			Err:  fs.ErrInvalid,
+		})
+	}
+	if err := imp.lim.add(name, size, compressed); err != nil {
+		return imp.dir.hookErr(err)
+	}
+
+	if mode.IsDir() {
+		_, err := imp.mkdir(pth, mode.Perm())
+		return err
+	}
+	if err := imp.addFile(pth, mode, content); err != nil {
+		return err
+	}
+	imp.prog.add(pth, size)
+	return nil
+}
+
+// addFile adds the regular file with the given cleaned path and mode to the
+// directory, for [importer.add].
+func (imp importer) addFile(
+	pth string,
+	mode fs.FileMode,
+	content func() ([]byte, error),
+) error {
+	dir, err := imp.mkdir(path.Dir(pth), 0)
+	if dir == nil || err != nil {
+		return err
+	}
+	if ent, err := open(dir, path.Base(pth)); err == nil {
+		ok, err := imp.resolve(pth, ent)
+		if !ok || err != nil {
+			return err
+		}
+	}
+	data, err := content()
+	if err != nil {
+		return err
+	}
+	fil, err := FileWith(path.Base(pth), data, WithFileMode(mode.Perm()))
+	if err != nil {
+		return err
+	}
+	return dir.AddFile(fil)
+}
+
+// mkdir returns the directory with the given path, creating it and its
+// missing parents with the permission bits of the perm, or 0700 when the
+// perm is zero. Returns nil when the path conflicts with a regular file and
+// the policy is [ConflictSkip].
+func (imp importer) mkdir(pth string, perm fs.FileMode) (*File, error) {
+	dir := imp.dir
+	if pth == "." {
+		return dir, nil
+	}
+	elems := strings.Split(pth, "/")
+	for i, elem := range elems {
+		sub, err := open(dir, elem)
+		if err == nil && !sub.IsDir() {
+			ok, err := imp.resolve(path.Join(elems[:i+1]...), sub)
+			if !ok || err != nil {
+				return nil, err
+			}
+			sub = nil
+		}
+		last := i == len(elems)-1
+		if sub == nil || err != nil {
+			if sub, err = NewDirectory(elem); err != nil {
+				return nil, err
+			}
+			if last && perm != 0 {
+				WithFileMode(perm)(sub)
+			}
+			if err = dir.AddFile(sub); err != nil {
+				return nil, err
+			}
+		} else if last && perm != 0 && imp.policy == ConflictOverwrite {
+			WithFileMode(perm)(sub)
+		}
+		dir = sub
+	}
+	return dir, nil
+}
+
+// resolve resolves the conflict of the archive entry with the given path
+// with the existing file according to the policy. Returns true when the
+// entry should be imported, in which case the existing file was removed.
+func (imp importer) resolve(pth string, ent *File) (bool, error) {
+	switch imp.policy {
+	case ConflictSkip:
+		return false, nil
+	case ConflictOverwrite:
+		if err := ent.parent.removeAll(ent.Name(), false); err != nil {
+			return false, err
+		}
+		return true, nil
+	default:
+		return false, imp.dir.hookErr(&fs.PathError{
+			Op:   imp.op,
+			Path: pth,
+			Err:  syscall.EEXIST,
+		})
+	}
+}
+
+// txtarFile represents a file in the txtar format.
+type txtarFile struct {
+	name string
+	data string
+}
+
+// parseTxtar returns the files stored in the txtar format.
+func parseTxtar(data string) []txtarFile {
+	var tfs []txtarFile
+	var cur *txtarFile
+	for len(data) > 0 {
+		line, rest, _ := strings.Cut(data, "\n")
+		name, ok := txtarMarker(line)
+		switch {
+		case ok:
+			tfs = append(tfs, txtarFile{name: name})
+			cur = &tfs[len(tfs)-1]
+		case cur != nil:
+			cur.data += data[:len(data)-len(rest)]
+		}
+		data = rest
+	}
+	return tfs
+}
+
+// txtarMarker returns the file name and true when the line is a txtar file
+// marker, for example, "-- name --".
+func txtarMarker(line string) (string, bool) {
+	name, ok := strings.CutPrefix(line, "-- ")
+	if !ok {
+		return "", false
+	}
+	if name, ok = strings.CutSuffix(name, " --"); !ok {
+		return "", false
+	}
+	name = strings.TrimSpace(name)
+	return name, name != ""
+}
@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_ImportTar(t *testing.T) {
+	t.Run("into empty directory", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(tstTarDir(false)), ConflictError)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := must.Value(tstDirMem().List())
+		assert.Equal(t, want, must.Value(dir.List()))
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := tstTar(
+			false,
+			arcEntry{"sub/", fs.ModeDir | 0755, ""},
+			arcEntry{"sub/new", 0755, "new"},
+			arcEntry{"opt/bin/tool", 0700, "tool"},
+		)
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictError)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "new", string(must.Value(dir.ReadFile("sub/new"))))
+		have := string(must.Value(dir.ReadFile("sub/sub2/file5")))
+		assert.Equal(t, "file5", have)
+		fil := must.Value(open(dir, "sub/new"))
+		assert.Equal(t, fs.FileMode(0755), fil.Mode())
+		sub := must.Value(open(dir, "sub"))
+		assert.Equal(t, fs.ModeDir|0700, sub.Mode())
+		opt := must.Value(open(dir, "opt/bin"))
+		assert.Equal(t, fs.ModeDir|0700, opt.Mode())
+	})
+
+	t.Run("conflict skip", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := tstTar(
+			false,
+			arcEntry{"file0", 0600, "new0"},
+			arcEntry{"file1/", fs.ModeDir | 0700, ""},
+			arcEntry{"file2/file", 0600, "new"},
+			arcEntry{"sub", 0600, "new"},
+			arcEntry{"new", 0600, "new"},
+		)
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictSkip)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := tstDirMem()
+		must.Nil(want.WriteFile("new", []byte("new"), 0600))
+		assert.Equal(t, must.Value(want.List()), must.Value(dir.List()))
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("file0"))))
+	})
+
+	t.Run("conflict overwrite", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := tstTar(
+			false,
+			arcEntry{"file0", 0700, "new0"},
+			arcEntry{"file1/", fs.ModeDir | 0755, ""},
+			arcEntry{"file2/file", 0600, "new"},
+			arcEntry{"sub/sub2", 0600, "new"},
+			arcEntry{"sub/", fs.ModeDir | 0755, ""},
+		)
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictOverwrite)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "new0", string(must.Value(dir.ReadFile("file0"))))
+		fil := must.Value(open(dir, "file0"))
+		assert.Equal(t, fs.FileMode(0700), fil.Mode())
+		assert.Equal(t, fs.ModeDir|0755, must.Value(open(dir, "file1")).Mode())
+		assert.Equal(t, "new", string(must.Value(dir.ReadFile("file2/file"))))
+		assert.Equal(t, "new", string(must.Value(dir.ReadFile("sub/sub2"))))
+		assert.Equal(t, fs.ModeDir|0755, must.Value(open(dir, "sub")).Mode())
+		assert.Equal(t, "file3", string(must.Value(dir.ReadFile("sub/file3"))))
+	})
+
+	t.Run("error - conflict", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := tstTar(
+			false,
+			arcEntry{"new", 0600, "new"},
+			arcEntry{"sub/file3", 0600, "new"},
+			arcEntry{"other", 0600, "other"},
+		)
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictError)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "tar", e.Op)
+		assert.Equal(t, "sub/file3", e.Path)
+		assert.Equal(t, syscall.EEXIST, e.Err)
+		assert.Equal(t, "file3", string(must.Value(dir.ReadFile("sub/file3"))))
+		must.Value(open(dir, "new"))
+		_, err = open(dir, "other")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("error - conflict with path element", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := tstTar(false, arcEntry{"file0/file", 0600, "new"})
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictError)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EEXIST, e.Err)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").ImportTar(
+			bytes.NewReader(tstTarDir(false)),
+			ConflictError,
+		)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "tar", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := tstTar(false, arcEntry{"../file", 0600, "abc"})
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictError)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "tar", e.Op)
+		assert.Equal(t, "../file", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+	})
+
+	t.Run("error - not supported type", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := tstTar(false, arcEntry{"link", fs.ModeSymlink, "file0"})
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictError)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithReadOnly(dir)
+		data := tstTar(false, arcEntry{"new", 0600, "new"})
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictError)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Len(t, 4, dir.entries)
+	})
+}
+
+func Test_File_ImportZip(t *testing.T) {
+	t.Run("merge", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		zr := tstZip(
+			arcEntry{"file0", 0600, "new0"},
+			arcEntry{"sub/new", 0755, "new"},
+		)
+
+		// --- When ---
+		err := dir.ImportZip(zr, zr.Size(), ConflictSkip)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("file0"))))
+		assert.Equal(t, "new", string(must.Value(dir.ReadFile("sub/new"))))
+		fil := must.Value(open(dir, "sub/new"))
+		assert.Equal(t, fs.FileMode(0755), fil.Mode())
+		assert.Nil(t, fil.lazy)
+	})
+
+	t.Run("error - conflict", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZipDir()
+
+		// --- When ---
+		err := tstDirMem().ImportZip(zr, zr.Size(), ConflictError)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "zip", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EEXIST, e.Err)
+	})
+
+	t.Run("error - invalid archive", func(t *testing.T) {
+		// --- Given ---
+		zr := bytes.NewReader([]byte("abc"))
+
+		// --- When ---
+		err := NewRoot().ImportZip(zr, zr.Size(), ConflictError)
+
+		// --- Then ---
+		assert.Error(t, err)
+	})
+}
+
+func Test_File_ImportTxtar(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// --- Given ---
+		data := must.Value(tstDirMem().Txtar())
+		dir := NewRoot()
+
+		// --- When ---
+		err := dir.ImportTxtar(data, ConflictError)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, data, must.Value(dir.Txtar()))
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := "comment\n-- file0 --\nnew0\n-- sub/new --\nline1\nline2\n"
+
+		// --- When ---
+		err := dir.ImportTxtar(data, ConflictOverwrite)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "new0\n", string(must.Value(dir.ReadFile("file0"))))
+		have := string(must.Value(dir.ReadFile("sub/new")))
+		assert.Equal(t, "line1\nline2\n", have)
+	})
+
+	t.Run("error - conflict", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().ImportTxtar("-- file0 --\nabc\n", ConflictError)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "txtar", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EEXIST, e.Err)
+	})
+}
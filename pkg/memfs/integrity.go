@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Integrity issue kinds reported by [File.CheckIntegrity].
+const (
+	// IssueNil is reported for the nil directory entries.
+	IssueNil = "nil"
+
+	// IssueParent is reported for the entries with the parent pointer not
+	// pointing to the directory they are in, and for the instance not being
+	// an entry of its parent.
+	IssueParent = "parent"
+
+	// IssueShared is reported for the entries which are reachable by more
+	// than one path, including the ones creating cycles.
+	IssueShared = "shared"
+
+	// IssueDuplicate is reported for the entries with the same name as
+	// another entry in the directory.
+	IssueDuplicate = "duplicate"
+
+	// IssueName is reported for the entries with invalid names.
+	IssueName = "name"
+
+	// IssueNotDir is reported for the files with entries which are not
+	// directories.
+	IssueNotDir = "notdir"
+)
+
+// IntegrityIssue describes a broken invariant of the directory tree found by
+// [File.CheckIntegrity].
+type IntegrityIssue struct {
+	Path string // Slash-separated path relative to the instance.
+	Kind string // One of the Issue constants, like [IssueParent].
+	Msg  string // Human-readable description.
+}
+
+// String implements [fmt.Stringer] interface.
+func (i IntegrityIssue) String() string {
+	return i.Path + ": " + i.Kind + ": " + i.Msg
+}
+
+// CheckIntegrity scans the file or the directory tree for the broken
+// invariants of the tree and returns the issues found, guarding against the
+// subtle bugs in the code mutating the trees, like the code moving the
+// entries between directories without [File.Rename]. The tree is checked
+// for nil entries, entries with stale parent pointers, entries reachable by
+// more than one path, duplicate and invalid names, and non-directories with
+// entries. The issues are returned in the order of the depth-first traversal
+// in the name order. Returns nil when the tree is consistent. The tree is not
+// modified.
+func (fil *File) CheckIntegrity() []IntegrityIssue {
+	ck := &integrity{seen: make(map[*File]string)}
+	if par := fil.parent; par != nil && !slices.Contains(par.entries, fil) {
+		ck.report(".", IssueParent, "not an entry of its parent")
+	}
+	ck.seen[fil] = "."
+	ck.check(fil, ".")
+	return ck.issues
+}
+
+// integrity is the state of the [File.CheckIntegrity] scan.
+type integrity struct {
+	seen   map[*File]string // Paths of the visited files.
+	issues []IntegrityIssue // Issues found so far.
+}
+
+// integrityDir is a directory on the stack of the [integrity.check] walk.
+type integrityDir struct {
+	fil   *File    // The directory.
+	pth   string   // Path of the directory.
+	fold  bool     // Case-insensitive names, see [WithCaseInsensitive].
+	ents  []*File  // Entries in the name order not checked yet.
+	names []string // Names of the entries already checked.
+}
+
+// check checks the entries of the file with the given path and their trees,
+// depth-first, with a stack of the directories being checked instead of
+// recursion, so deep or cyclic trees can't exhaust the goroutine stack.
+func (ck *integrity) check(fil *File, pth string) {
+	stack := []*integrityDir{ck.dir(fil, pth)}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if len(top.ents) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		ent := top.ents[0]
+		top.ents = top.ents[1:]
+
+		name := ent.Name()
+		entPth := name
+		if top.pth != "." {
+			entPth = top.pth + "/" + name
+		}
+		dup := slices.ContainsFunc(top.names, func(n string) bool {
+			return sameName(n, name, top.fold)
+		})
+		if dup {
+			ck.report(entPth, IssueDuplicate, "duplicate name")
+		}
+		top.names = append(top.names, name)
+
+		if name == "" || name == "." || name == ".." ||
+			strings.Contains(name, "/") {
+			msg := fmt.Sprintf("invalid name %q", name)
+			ck.report(entPth, IssueName, msg)
+		}
+		if ent.parent != top.fil {
+			ck.report(entPth, IssueParent, "stale parent pointer")
+		}
+		if other, ok := ck.seen[ent]; ok {
+			msg := fmt.Sprintf("also reachable as %q", other)
+			ck.report(entPth, IssueShared, msg)
+			continue
+		}
+		ck.seen[ent] = entPth
+		stack = append(stack, ck.dir(ent, entPth))
+	}
+}
+
+// dir reports the issues of the file with the given path which are not
+// related to its entries and returns it prepared for the [integrity.check]
+// walk.
+func (ck *integrity) dir(fil *File, pth string) *integrityDir {
+	if !fil.IsDir() && len(fil.entries) > 0 {
+		n := len(fil.entries)
+		msg := fmt.Sprintf("not a directory, number of entries: %d", n)
+		ck.report(pth, IssueNotDir, msg)
+	}
+
+	ets := make([]*File, 0, len(fil.entries))
+	for _, ent := range fil.entries {
+		if ent == nil {
+			ck.report(pth, IssueNil, "nil entry")
+			continue
+		}
+		ets = append(ets, ent)
+	}
+	slices.SortStableFunc(ets, func(a, b *File) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+	return &integrityDir{
+		fil:  fil,
+		pth:  pth,
+		fold: fil.caseInsensitive(),
+		ents: ets,
+	}
+}
+
+// report adds the issue.
+func (ck *integrity) report(pth, kind, msg string) {
+	issue := IntegrityIssue{Path: pth, Kind: kind, Msg: msg}
+	ck.issues = append(ck.issues, issue)
+}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_IntegrityIssue_String(t *testing.T) {
+	// --- Given ---
+	issue := IntegrityIssue{Path: "a/b", Kind: IssueParent, Msg: "message"}
+
+	// --- When ---
+	have := issue.String()
+
+	// --- Then ---
+	assert.Equal(t, "a/b: parent: message", have)
+}
+
+func Test_File_CheckIntegrity(t *testing.T) {
+	t.Run("consistent tree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Rename("sub/sub2", "moved"))
+		must.Nil(dir.Remove("file0"))
+		must.Nil(dir.Symlink("moved/file5", "link"))
+
+		// --- When ---
+		have := dir.CheckIntegrity()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		// --- When ---
+		have := MustFile("file").CheckIntegrity()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("stale parent pointer", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+		fil := must.Value(open(dir, "file0"))
+		sub.entries = append(sub.entries, fil)
+		dir.entries = slices.DeleteFunc(dir.entries, func(f *File) bool {
+			return f == fil
+		})
+
+		// --- When ---
+		have := dir.CheckIntegrity()
+
+		// --- Then ---
+		exp := []IntegrityIssue{
+			{Path: "sub/file0", Kind: IssueParent, Msg: "stale parent pointer"},
+		}
+		assert.Equal(t, exp, have)
+	})
+
+	t.Run("not an entry of its parent", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+		dir.entries = slices.DeleteFunc(dir.entries, func(f *File) bool {
+			return f == sub
+		})
+
+		// --- When ---
+		have := sub.CheckIntegrity()
+
+		// --- Then ---
+		exp := []IntegrityIssue{
+			{Path: ".", Kind: IssueParent, Msg: "not an entry of its parent"},
+		}
+		assert.Equal(t, exp, have)
+	})
+
+	t.Run("shared entry and cycle", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+		sub2 := must.Value(open(dir, "sub/sub2"))
+		sub2.entries = append(sub2.entries, sub)
+
+		// --- When ---
+		have := dir.CheckIntegrity()
+
+		// --- Then ---
+		assert.Len(t, 2, have)
+		assert.Equal(t, "sub/sub2/sub", have[0].Path)
+		assert.Equal(t, IssueParent, have[0].Kind)
+		assert.Equal(t, "sub/sub2/sub", have[1].Path)
+		assert.Equal(t, IssueShared, have[1].Kind)
+		assert.Equal(t, `also reachable as "sub"`, have[1].Msg)
+	})
+
+	t.Run("nil entry", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+		sub.entries = append(sub.entries, nil)
+
+		// --- When ---
+		have := dir.CheckIntegrity()
+
+		// --- Then ---
+		exp := []IntegrityIssue{
+			{Path: "sub", Kind: IssueNil, Msg: "nil entry"},
+		}
+		assert.Equal(t, exp, have)
+	})
+
+	t.Run("duplicate and invalid names", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file1"))
+		fil.info.name = "file0"
+		sub := must.Value(open(dir, "sub"))
+		sub.info.name = ".."
+
+		// --- When ---
+		have := dir.CheckIntegrity()
+
+		// --- Then ---
+		exp := []IntegrityIssue{
+			{Path: "..", Kind: IssueName, Msg: `invalid name ".."`},
+			{Path: "file0", Kind: IssueDuplicate, Msg: "duplicate name"},
+		}
+		assert.Equal(t, exp, have)
+	})
+
+	t.Run("duplicate names in case-insensitive mode", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithCaseInsensitive(dir)
+		fil := must.Value(open(dir, "file1"))
+		fil.info.name = "FILE0"
+
+		// --- When ---
+		have := dir.CheckIntegrity()
+
+		// --- Then ---
+		exp := []IntegrityIssue{
+			{Path: "file0", Kind: IssueDuplicate, Msg: "duplicate name"},
+		}
+		assert.Equal(t, exp, have)
+	})
+
+	t.Run("duplicate names use the lookup case folding", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithCaseInsensitive)
+		must.Nil(dir.AddFile(MustFile("s")))
+		fil := MustFile("t")
+		must.Nil(dir.AddFile(fil))
+		fil.info.name = "\u017f" // Latin small letter long s.
+
+		// --- When ---
+		have := dir.CheckIntegrity()
+
+		// --- Then ---
+		exp := []IntegrityIssue{
+			{Path: "\u017f", Kind: IssueDuplicate, Msg: "duplicate name"},
+		}
+		assert.Equal(t, exp, have)
+		assert.Equal(t, "s", dir.entry("\u017f").Name())
+	})
+
+	t.Run("not a directory with entries", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file0"))
+		fil.entries = []*File{MustFile("orphan")}
+
+		// --- When ---
+		have := dir.CheckIntegrity()
+
+		// --- Then ---
+		assert.Len(t, 2, have)
+		assert.Equal(t, "file0", have[0].Path)
+		assert.Equal(t, IssueNotDir, have[0].Kind)
+		assert.Equal(t, "not a directory, number of entries: 1", have[0].Msg)
+		assert.Equal(t, IssueParent, have[1].Kind)
+		assert.Equal(t, "file0/orphan", have[1].Path)
+	})
+}
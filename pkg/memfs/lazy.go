@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"slices"
+)
+
+// WithLazyCache is a [File] constructor function option turning on caching of
+// lazily loaded content for the instance and all its descendants. By default,
+// the content of a lazily loaded file (see [LazyFromZip]) is loaded on the
+// first read and dropped on [File.Close], so it's loaded again on the next
+// read. With caching, the content is kept in memory once loaded.
+func WithLazyCache(fil *File) { fil.keep = true }
+
+// lazyCache returns true when the [WithLazyCache] option was used on the
+// instance or any of its parents.
+func (fil *File) lazyCache() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.keep {
+			return true
+		}
+	}
+	return false
+}
+
+// unloaded returns true when the content of a lazily loaded file is not in
+// memory.
+func (fil *File) unloaded() bool { return fil.lazy != nil && !fil.loaded }
+
+// load loads the content of a lazily loaded file. It does nothing for other
+// files or when the content is already loaded. Returns an error of the
+// [fs.PathError] type when the content cannot be loaded.
+func (fil *File) load() error {
+	if !fil.unloaded() {
+		return nil
+	}
+	buf, err := fil.lazy()
+	if err != nil {
+		return fil.hookErr(&fs.PathError{
+			Op:   "read",
+			Path: fil.path(),
+			Err:  err,
+		})
+	}
+	fil.buf = buf
+	fil.loaded = true
+	return nil
+}
+
+// own loads the content of a lazily loaded file and detaches it from its
+// source, so it's never dropped. The buffer shared with a view (see
+// [File.Freeze]) is copied. It must be called before the content is
+// modified.
+func (fil *File) own() error {
+	if err := fil.load(); err != nil {
+		return err
+	}
+	fil.lazy = nil
+	fil.loaded = false
+	if fil.shared {
+		fil.buf = slices.Clone(fil.buf)
+		fil.shared = false
+	}
+	return nil
+}
+
+// unload drops loaded content of a lazily loaded file unless caching is on.
+func (fil *File) unload() {
+	if fil.lazy == nil || !fil.loaded || fil.lazyCache() {
+		return
+	}
+	fil.buf = nil
+	fil.loaded = false
+}
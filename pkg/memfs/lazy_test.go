@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// lazyFile returns a lazily loaded file with the content which counts how
+// many times the content was loaded.
+func lazyFile(content []byte, loads *int) *File {
+	fil := &File{
+		info: FileInfo{name: "file", size: int64(len(content)), mode: 0600},
+		lazy: func() ([]byte, error) {
+			*loads++
+			return bytes.Clone(content), nil
+		},
+	}
+	return fil
+}
+
+func Test_WithLazyCache(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+
+	// --- When ---
+	WithLazyCache(fil)
+
+	// --- Then ---
+	assert.True(t, fil.keep)
+}
+
+func Test_File_lazyCache(t *testing.T) {
+	t.Run("not set", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have := fil.lazyCache()
+
+		// --- Then ---
+		assert.False(t, have)
+	})
+
+	t.Run("set on instance", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithLazyCache)
+
+		// --- When ---
+		have := fil.lazyCache()
+
+		// --- Then ---
+		assert.True(t, have)
+	})
+
+	t.Run("set on parent", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithLazyCache)
+		dir := MustDirectory("dir")
+		fil := MustFile("file")
+		must.Nil(root.AddFile(dir))
+		must.Nil(dir.AddFile(fil))
+
+		// --- When ---
+		have := fil.lazyCache()
+
+		// --- Then ---
+		assert.True(t, have)
+	})
+}
+
+func Test_File_lazy(t *testing.T) {
+	t.Run("Stat does not load", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+
+		// --- When ---
+		have, err := fil.Stat()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), have.Size())
+		assert.Equal(t, 3, fil.Len())
+		assert.Equal(t, 0, loads)
+	})
+
+	t.Run("Read loads", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+
+		// --- When ---
+		have, err := io.ReadAll(fil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2}, have)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("ReadAt loads", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+		buf := make([]byte, 2)
+
+		// --- When ---
+		n, err := fil.ReadAt(buf, 1)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []byte{1, 2}, buf)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("ReadByte loads", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{1}, &loads)
+
+		// --- When ---
+		have, err := fil.ReadByte()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, byte(1), have)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("WriteTo loads", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+		dst := &bytes.Buffer{}
+
+		// --- When ---
+		n, err := fil.WriteTo(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, []byte{0, 1, 2}, dst.Bytes())
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("String loads", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte("abc"), &loads)
+
+		// --- When ---
+		have := fil.String()
+
+		// --- Then ---
+		assert.Equal(t, "abc", have)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("Mmap loads", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+
+		// --- When ---
+		mem, _, err := fil.Mmap(MapPrivate)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2}, mem)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("loaded once", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+		must.Value(io.ReadAll(fil))
+		must.Value(fil.Seek(0, io.SeekStart))
+
+		// --- When ---
+		have, err := io.ReadAll(fil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2}, have)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("Close drops content", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+		must.Value(io.ReadAll(fil))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, fil.unloaded())
+		assert.Nil(t, fil.buf)
+		assert.Equal(t, 3, fil.Len())
+		assert.Equal(t, []byte{0, 1, 2}, must.Value(io.ReadAll(fil)))
+		assert.Equal(t, 2, loads)
+	})
+
+	t.Run("Close keeps content with cache", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+		WithLazyCache(fil)
+		must.Value(io.ReadAll(fil))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.False(t, fil.unloaded())
+		assert.Equal(t, []byte{0, 1, 2}, must.Value(io.ReadAll(fil)))
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("Write owns content", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+
+		// --- When ---
+		n, err := fil.Write([]byte{3})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Nil(t, fil.lazy)
+		must.Nil(fil.Close())
+		assert.Equal(t, []byte{3, 1, 2}, fil.buf)
+		assert.Equal(t, 1, loads)
+	})
+
+	t.Run("Truncate owns content", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+
+		// --- When ---
+		err := fil.Truncate(2)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, fil.lazy)
+		assert.Equal(t, []byte{0, 1}, fil.buf)
+	})
+
+	t.Run("Release owns content", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile([]byte{0, 1, 2}, &loads)
+
+		// --- When ---
+		have := fil.Release()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 1, 2}, have)
+		assert.Nil(t, fil.lazy)
+		assert.Equal(t, 0, fil.Len())
+	})
+
+	t.Run("error - load", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+		fil := &File{
+			info: FileInfo{name: "file", size: 3, mode: 0600},
+			lazy: func() ([]byte, error) { return nil, errTst },
+		}
+
+		// --- When ---
+		n, err := fil.Read(make([]byte, 3))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.ErrorIs(t, errTst, err)
+		assert.Equal(t, 0, n)
+		assert.True(t, fil.unloaded())
+
+		_, err = fil.Write([]byte{0})
+		assert.ErrorIs(t, errTst, err)
+		assert.Equal(t, "", fil.String())
+		assert.Nil(t, fil.Release())
+	})
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"strings"
+)
+
+// WithLenientPaths is a [File] constructor function option making the
+// directory and all directories in its tree accept names with the "./"
+// prefixes and the trailing slashes, which are not valid in [io/fs], for
+// example, "./dir/file" or "dir/". Such names are normalized before use by
+// the Open method of the directory, the file systems returned by [File.FS]
+// and [File.DirFS], and the methods taking names, like [File.WriteFile]. The
+// "./" name refers to the directory itself. The names are strict by default.
+func WithLenientPaths(fil *File) { fil.lenient = true }
+
+// lenientPath returns the name with the "./" prefixes and the trailing
+// slashes removed when the [WithLenientPaths] option was used on the
+// instance or any of its parents. Otherwise, it returns the name unchanged.
+func (fil *File) lenientPath(name string) string {
+	if !fil.lenientPaths() {
+		return name
+	}
+	orig := name
+	for strings.HasPrefix(name, "./") {
+		name = name[2:]
+	}
+	if trimmed := strings.TrimRight(name, "/"); trimmed != "" {
+		name = trimmed
+	}
+	if name == "" && orig != "" {
+		name = "."
+	}
+	return name
+}
+
+// lenientPaths returns true when the [WithLenientPaths] option was used on
+// the instance or any of its parents.
+func (fil *File) lenientPaths() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.lenient {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstDirLenient returns the [tstDirMem] directory with lenient paths.
+func tstDirLenient() *File {
+	dir := tstDirMem()
+	WithLenientPaths(dir)
+	return dir
+}
+
+func Test_WithLenientPaths(t *testing.T) {
+	t.Run("Open", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirLenient()
+
+		// --- When ---
+		have, err := dir.Open("./sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, must.Value(open(dir, "sub/file3")), have.(*File))
+	})
+
+	t.Run("Open directory with trailing slash", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirLenient()
+
+		// --- When ---
+		have, err := dir.Open("sub/sub2/")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "sub2", must.Value(have.Stat()).Name())
+	})
+
+	t.Run("inherited by subdirectories", func(t *testing.T) {
+		// --- Given ---
+		sub := must.Value(open(tstDirLenient(), "sub"))
+
+		// --- When ---
+		have, err := sub.Open("./file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file3", must.Value(have.Stat()).Name())
+	})
+
+	t.Run("FS", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirLenient().FS()
+
+		// --- When ---
+		data, err := fs.ReadFile(fsys, "./sub/sub2/file5")
+		info, sErr := fs.Stat(fsys, "./file0")
+		ents, dErr := fs.ReadDir(fsys, "./sub/")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file5", string(data))
+		assert.NoError(t, sErr)
+		assert.Equal(t, "file0", info.Name())
+		assert.NoError(t, dErr)
+		assert.Len(t, 3, ents)
+	})
+
+	t.Run("DirFS", func(t *testing.T) {
+		// --- Given ---
+		fsys := tstDirLenient().DirFS()
+
+		// --- When ---
+		data, err := fs.ReadFile(fsys, "./sub/file4")
+		info, sErr := fs.Stat(fsys, "sub/sub2/")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file4", string(data))
+		assert.NoError(t, sErr)
+		assert.Equal(t, "sub2", info.Name())
+	})
+
+	t.Run("WriteFile", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirLenient()
+
+		// --- When ---
+		err := dir.WriteFile("./sub/new", []byte("abc"), 0600)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", string(must.Value(dir.ReadFile("sub/new"))))
+	})
+
+	t.Run("error - strict by default", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.Open("./sub/file3")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_File_lenientPath(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		name string
+		want string
+	}{
+		{"valid", "a/b", "a/b"},
+		{"dot prefix", "./a/b", "a/b"},
+		{"many dot prefixes", "././a", "a"},
+		{"trailing slash", "a/b/", "a/b"},
+		{"many trailing slashes", "a//", "a"},
+		{"dot prefix and trailing slash", "./a/", "a"},
+		{"dot slash", "./", "."},
+		{"dot", ".", "."},
+		{"empty", "", ""},
+		{"absolute stays invalid", "/a", "/a"},
+		{"root stays invalid", "/", "/"},
+		{"parent stays invalid", "./../a", "../a"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			dir := NewRoot(WithLenientPaths)
+
+			// --- When ---
+			have := dir.lenientPath(tc.name)
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		// --- When ---
+		have := NewRoot().lenientPath("./a/")
+
+		// --- Then ---
+		assert.Equal(t, "./a/", have)
+	})
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// ErrImportLimit is returned by the archive importers, like [FromTar],
+// [LazyFromZip] and [File.ImportTar], when the archive exceeds the limits
+// (see [ImportLimits]).
+var ErrImportLimit = errors.New("archive import limit exceeded")
+
+// DefaultImportLimits are the limits used by the archive importers when the
+// [WithImportLimits] option was not used.
+var DefaultImportLimits = ImportLimits{
+	MaxEntries: 100_000,
+	MaxSize:    1 << 30,
+	MaxRatio:   100,
+}
+
+// ImportLimits protect the archive importers, like [FromTar], [LazyFromZip]
+// and [File.ImportTar], against archive bombs. The zero value of a field
+// means no limit.
+type ImportLimits struct {
+	// MaxEntries is the maximum number of files and directories.
+	MaxEntries int
+
+	// MaxSize is the maximum total size of the files in bytes.
+	MaxSize int64
+
+	// MaxRatio is the maximum ratio of the uncompressed size of a zip
+	// archive file to its compressed size. The tar archives are read
+	// uncompressed, so MaxSize limits the gzip compressed ones.
+	MaxRatio float64
+}
+
+// WithImportLimits is a [File] constructor function option setting the
+// limits for the archive importers creating the directory, like [FromTar],
+// or importing to the directory, or any directory in its tree, like
+// [File.ImportTar]. Use the zero value of [ImportLimits] to turn the limits
+// off. The [DefaultImportLimits] are used by default.
+func WithImportLimits(lim ImportLimits) func(*File) {
+	return func(fil *File) { fil.limits = &lim }
+}
+
+// importLimits returns the limits set with the [WithImportLimits] option on
+// the instance or the closest of its parents, or [DefaultImportLimits] when
+// the option was not used.
+func (fil *File) importLimits() ImportLimits {
+	for f := fil; f != nil; f = f.parent {
+		if f.limits != nil {
+			return *f.limits
+		}
+	}
+	return DefaultImportLimits
+}
+
+// importLimiter enforces the import limits on the archive entries.
+type importLimiter struct {
+	lim     ImportLimits // Enforced limits.
+	op      string       // Operation name used in errors.
+	entries int          // Number of entries so far.
+	size    int64        // Total size of the files so far.
+}
+
+// newImportLimiter returns a new limiter enforcing the limits for the
+// directory with the given operation name used in errors.
+func newImportLimiter(dir *File, op string) *importLimiter {
+	return &importLimiter{lim: dir.importLimits(), op: op}
+}
+
+// add accounts for the archive entry with the given name and size, which is
+// stored in the archive compressed to the given number of bytes, or -1 when
+// it's not compressed. Returns an error of the [fs.PathError] type wrapping
+// [ErrImportLimit] when the entry exceeds the limits.
+func (l *importLimiter) add(name string, size, compressed int64) error {
+	l.entries++
+	l.size += size
+	var msg string
+	switch {
+	case l.lim.MaxEntries > 0 && l.entries > l.lim.MaxEntries:
+		msg = fmt.Sprintf("entries limit %d", l.lim.MaxEntries)
+	case l.lim.MaxSize > 0 && l.size > l.lim.MaxSize:
+		msg = fmt.Sprintf("size limit %d bytes", l.lim.MaxSize)
+	case l.lim.MaxRatio > 0 && compressed >= 0 &&
+		float64(size) > l.lim.MaxRatio*float64(max(compressed, 1)):
+		msg = fmt.Sprintf("compression ratio limit %g", l.lim.MaxRatio)
+	default:
+		return nil
+	}
+	return &fs.PathError{
+		Op:   l.op,
+		Path: name,
+		Err:  fmt.Errorf("%w: %s", ErrImportLimit, msg),
+	}
+}
+
+// validArchivePath returns true when the archive entry path is a valid
+// [io/fs] path, so it's not absolute and has no ".." elements, and it has no
+// backslashes, which are path separators on Windows, so the entry cannot
+// escape the directory it's unpacked to, for example, when the tree is
+// mirrored to the OS file system (see [File.MirrorTo]).
+func validArchivePath(pth string) bool {
+	return fs.ValidPath(pth) && !strings.Contains(pth, `\`)
+}
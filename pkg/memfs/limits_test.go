@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithImportLimits(t *testing.T) {
+	// --- Given ---
+	lim := ImportLimits{MaxEntries: 1, MaxSize: 2, MaxRatio: 3}
+	fil := &File{}
+
+	// --- When ---
+	WithImportLimits(lim)(fil)
+
+	// --- Then ---
+	assert.Equal(t, lim, *fil.limits)
+}
+
+func Test_File_importLimits(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		// --- When ---
+		have := tstDirMem().importLimits()
+
+		// --- Then ---
+		assert.Equal(t, DefaultImportLimits, have)
+	})
+
+	t.Run("inherited", func(t *testing.T) {
+		// --- Given ---
+		lim := ImportLimits{MaxEntries: 1}
+		dir := tstDirMem()
+		WithImportLimits(lim)(dir)
+		sub := must.Value(open(dir, "sub/sub2"))
+
+		// --- When ---
+		have := sub.importLimits()
+
+		// --- Then ---
+		assert.Equal(t, lim, have)
+	})
+
+	t.Run("turned off", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithImportLimits(ImportLimits{}))
+
+		// --- When ---
+		have := dir.importLimits()
+
+		// --- Then ---
+		assert.Equal(t, ImportLimits{}, have)
+	})
+}
+
+func Test_importLimiter_add(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		lim        ImportLimits
+		size       int64
+		compressed int64
+		err        string
+	}{
+		{"no limits", ImportLimits{}, 1 << 40, 1, ""},
+		{"within", DefaultImportLimits, 100, 1, ""},
+		{"entries", ImportLimits{MaxEntries: 1}, 1, -1, "entries limit 1"},
+		{"size", ImportLimits{MaxSize: 20}, 21, -1, "size limit 20 bytes"},
+		{"ratio", ImportLimits{MaxRatio: 10}, 101, 10, "ratio limit 10"},
+		{"ratio not compressed", ImportLimits{MaxRatio: 10}, 101, -1, ""},
+		{"ratio empty", ImportLimits{MaxRatio: 10}, 11, 0, "ratio limit 10"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			l := &importLimiter{lim: tc.lim, op: "zip"}
+			must.Nil(l.add("first", 0, -1))
+
+			// --- When ---
+			err := l.add("second", tc.size, tc.compressed)
+
+			// --- Then ---
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var e *fs.PathError
+			assert.ErrorAs(t, &e, err)
+			assert.Equal(t, "zip", e.Op)
+			assert.Equal(t, "second", e.Path)
+			assert.ErrorIs(t, ErrImportLimit, err)
+			assert.ErrorContain(t, tc.err, err)
+		})
+	}
+}
+
+func Test_validArchivePath(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pth  string
+		want bool
+	}{
+		{"file", "file", true},
+		{"nested", "dir/file", true},
+		{"dot", ".", true},
+		{"absolute", "/etc/passwd", false},
+		{"parent", "../file", false},
+		{"backslash", `..\file`, false},
+		{"backslash nested", `dir\file`, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have := validArchivePath(tc.pth)
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func Test_ImportLimits_archives(t *testing.T) {
+	t.Run("FromTar entries", func(t *testing.T) {
+		// --- Given ---
+		data := tstTarDir(false)
+		opt := WithImportLimits(ImportLimits{MaxEntries: 3})
+
+		// --- When ---
+		have, err := FromTar(bytes.NewReader(data), opt)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "tar", e.Op)
+		assert.Equal(t, "./file2", e.Path)
+		assert.ErrorIs(t, ErrImportLimit, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("FromTar backslash", func(t *testing.T) {
+		// --- Given ---
+		data := tstTar(false, arcEntry{`..\file`, 0600, "abc"})
+
+		// --- When ---
+		have, err := FromTar(bytes.NewReader(data))
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("LazyFromZip ratio", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZip(arcEntry{"bomb", 0600, strings.Repeat("0", 1<<20)})
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "zip", e.Op)
+		assert.Equal(t, "bomb", e.Path)
+		assert.ErrorIs(t, ErrImportLimit, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("LazyFromZip limits off", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZip(arcEntry{"bomb", 0600, strings.Repeat("0", 1<<20)})
+		opt := WithImportLimits(ImportLimits{})
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size(), opt)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1<<20), must.Value(open(have, "bomb")).Size())
+	})
+
+	t.Run("ImportTar size", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithImportLimits(ImportLimits{MaxSize: 12}))
+		data := tstTarDir(false)
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictError)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "./file2", e.Path)
+		assert.ErrorIs(t, ErrImportLimit, err)
+		assert.Equal(t, ".\nfile0\nfile1\n", must.Value(dir.List()))
+	})
+
+	t.Run("ImportTxtar entries", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithImportLimits(ImportLimits{MaxEntries: 1}))
+
+		// --- When ---
+		err := dir.ImportTxtar("-- a --\n-- b --\n", ConflictError)
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrImportLimit, err)
+	})
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// LinkFarmStore is the name of the content-addressable store directory in the
+// tree generated by [GenLinkFarm].
+const LinkFarmStore = ".store"
+
+// GenLinkFarm returns a new root directory with a content-addressable store
+// and a tree of symbolic links to it, laid out like the Nix or pnpm stores,
+// for testing the tools which have to traverse link-heavy trees. For every
+// distinct content in the map of paths to file contents, the store directory
+// [LinkFarmStore] has one read-only (0444) file named after the hex-encoded
+// SHA-256 checksum of the content. Every path in the map is a symbolic link
+// to the store file with its content, relative to the link, for example,
+// "../.store/<sum>" for the "a/b.txt" path, so the tree stays valid when
+// written to disk with [File.WriteToDisk]. The intermediate directories are
+// created automatically. The contents are copied. The options are applied to
+// the root directory after the tree is built.
+//
+// Returns an error of the [fs.PathError] type with [fs.ErrInvalid] when a
+// path is not valid or is in the store directory, and with [syscall.ENOTDIR]
+// when a path goes through another path.
+func GenLinkFarm(
+	files map[string][]byte,
+	opts ...func(*File),
+) (*File, error) {
+	root := NewRoot()
+	store, err := mkdirAll(root, LinkFarmStore)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if !fs.ValidPath(name) || name == "." ||
+			name == LinkFarmStore ||
+			strings.HasPrefix(name, LinkFarmStore+"/") {
+			return nil, &fs.PathError{
+				Op:   "linkfarm",
+				Path: name,
+				Err:  fs.ErrInvalid,
+			}
+		}
+
+		sum := sha256.Sum256(files[name])
+		obj := hex.EncodeToString(sum[:])
+		if _, err = lopen(store, obj); err != nil {
+			fil, err := FileWith(
+				obj,
+				slices.Clone(files[name]),
+				WithFileMode(0444),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if err = store.AddFile(fil); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err = mkdirAll(root, path.Dir(name)); err != nil {
+			return nil, err
+		}
+		up := strings.Repeat("../", strings.Count(name, "/"))
+		dst := up + LinkFarmStore + "/" + obj
+		if err = root.Symlink(dst, name); err != nil {
+			return nil, err
+		}
+	}
+	for _, opt := range opts {
+		opt(root)
+	}
+	return root, nil
+}
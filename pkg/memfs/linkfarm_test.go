@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_GenLinkFarm(t *testing.T) {
+	// SHA-256 checksums of the contents used in the tests.
+	const sumA = "ca978112ca1bbdcafac231b39a23dc4d" +
+		"a786eff8147c4e72b9807785afee48bb"
+	const sumB = "3e23e8160039594a33894f6564e1b134" +
+		"8bbd7a0088d42c4acb73eeaed59c009d"
+
+	t.Run("deduplicated store", func(t *testing.T) {
+		// --- Given ---
+		files := map[string][]byte{
+			"a.txt":     []byte("a"),
+			"x/a.txt":   []byte("a"),
+			"x/y/b.txt": []byte("b"),
+		}
+
+		// --- When ---
+		root, err := GenLinkFarm(files)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		store := must.Value(open(root, LinkFarmStore))
+		ents := names(must.Value(store.ReadDir(-1)))
+		assert.Equal(t, []string{sumB, sumA}, ents)
+		obj := must.Value(open(store, sumA))
+		assert.Equal(t, "a", obj.String())
+		assert.Equal(t, fs.FileMode(0444), obj.Mode())
+
+		have := must.Value(root.ReadLink("a.txt"))
+		assert.Equal(t, ".store/"+sumA, have)
+		have = must.Value(root.ReadLink("x/a.txt"))
+		assert.Equal(t, "../.store/"+sumA, have)
+		have = must.Value(root.ReadLink("x/y/b.txt"))
+		assert.Equal(t, "../../.store/"+sumB, have)
+		data := must.Value(fs.ReadFile(root.DirFS(), "x/y/b.txt"))
+		assert.Equal(t, "b", string(data))
+	})
+
+	t.Run("written to disk", func(t *testing.T) {
+		// --- Given ---
+		files := map[string][]byte{"x/y/b.txt": []byte("b")}
+		root := must.Value(GenLinkFarm(files))
+		dst := t.TempDir()
+
+		// --- When ---
+		err := root.WriteToDisk(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(os.ReadFile(filepath.Join(dst, "x/y/b.txt")))
+		assert.Equal(t, "b", string(have))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		// --- When ---
+		root, err := GenLinkFarm(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := names(must.Value(root.ReadDir(-1)))
+		assert.Equal(t, []string{LinkFarmStore}, have)
+	})
+
+	t.Run("options are applied", func(t *testing.T) {
+		// --- Given ---
+		files := map[string][]byte{"a.txt": []byte("a")}
+
+		// --- When ---
+		root, err := GenLinkFarm(files, WithReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		err = root.WriteFile("b.txt", nil, 0600)
+		assert.ErrorIs(t, syscall.EROFS, err)
+	})
+
+	t.Run("error - invalid path", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			pth string
+		}{
+			{"not valid", "../a"},
+			{"dot", "."},
+			{"store", LinkFarmStore},
+			{"in store", LinkFarmStore + "/a"},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				files := map[string][]byte{tc.pth: []byte("a")}
+
+				// --- When ---
+				root, err := GenLinkFarm(files)
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "linkfarm", e.Op)
+				assert.Equal(t, tc.pth, e.Path)
+				assert.Equal(t, fs.ErrInvalid, e.Err)
+				assert.Nil(t, root)
+			})
+		}
+	})
+
+	t.Run("error - path through a link", func(t *testing.T) {
+		// --- Given ---
+		files := map[string][]byte{"a": []byte("a"), "a/b": []byte("b")}
+
+		// --- When ---
+		root, err := GenLinkFarm(files)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Nil(t, root)
+	})
+}
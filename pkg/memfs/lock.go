@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"math"
+	"slices"
+	"syscall"
+)
+
+// rangeLock represents an advisory byte-range lock.
+type rangeLock struct {
+	off   int64 // The first locked byte.
+	end   int64 // The byte after the last locked byte.
+	excl  bool  // Exclusive (write) lock.
+	owner any   // The [File] or the [Handle] which placed the lock.
+}
+
+// overlaps returns true if the lock overlaps with the range [off, end).
+func (lck rangeLock) overlaps(off, end int64) bool {
+	return lck.off < end && off < lck.end
+}
+
+// LockRange places an advisory byte-range lock on n bytes starting at the
+// offset off. When n is zero, the lock extends to the end of the file and
+// beyond, the same way fcntl(2) F_SETLK does. Shared locks may overlap each
+// other while an exclusive lock conflicts with any other lock overlapping its
+// range, no matter who placed it. Every successful call places a separate
+// lock which must be released with [File.UnlockRange] called with the same
+// range. The locks are owned by the instance, so they are released by
+// [File.Close] and the locks placed with [Handle.LockRange] can't be
+// released with it. Use the handles returned by [File.OpenFile] to test the
+// locks of independent owners.
+//
+// Returns an error of the [fs.PathError] type with [syscall.EAGAIN] when the
+// lock conflicts with an already held lock, [syscall.EINVAL] when off or n is
+// negative and [syscall.EISDIR] when the file represents a directory.
+func (fil *File) LockRange(off, n int64, exclusive bool) error {
+	if err := fil.lockRange(fil, off, n, exclusive); err != nil {
+		return fil.hookErr(&fs.PathError{
+			Op:   "lock",
+			Path: fil.path(),
+			Err:  err,
+		})
+	}
+	return nil
+}
+
+// UnlockRange releases the lock placed with [File.LockRange] for the same
+// range. Returns an error of the [fs.PathError] type with [syscall.ENOLCK]
+// when there is no lock for the given range and [syscall.EINVAL] when off or n
+// is negative.
+func (fil *File) UnlockRange(off, n int64) error {
+	if err := fil.unlockRange(fil, off, n); err != nil {
+		return fil.hookErr(&fs.PathError{
+			Op:   "unlock",
+			Path: fil.path(),
+			Err:  err,
+		})
+	}
+	return nil
+}
+
+// lockRange places the lock for the owner, see [File.LockRange].
+func (fil *File) lockRange(owner any, off, n int64, exclusive bool) error {
+	if fil.IsDir() {
+		return syscall.EISDIR
+	}
+	end, err := lockEnd(off, n)
+	if err != nil {
+		return err
+	}
+	for _, lck := range fil.locks {
+		if lck.overlaps(off, end) && (lck.excl || exclusive) {
+			return syscall.EAGAIN
+		}
+	}
+	lck := rangeLock{off: off, end: end, excl: exclusive, owner: owner}
+	fil.locks = append(fil.locks, lck)
+	return nil
+}
+
+// unlockRange releases the lock of the owner, see [File.UnlockRange].
+func (fil *File) unlockRange(owner any, off, n int64) error {
+	end, err := lockEnd(off, n)
+	if err != nil {
+		return err
+	}
+	for i, lck := range fil.locks {
+		if lck.owner == owner && lck.off == off && lck.end == end {
+			fil.locks = append(fil.locks[:i], fil.locks[i+1:]...)
+			return nil
+		}
+	}
+	return syscall.ENOLCK
+}
+
+// unlockAll releases all locks of the owner.
+func (fil *File) unlockAll(owner any) {
+	fil.locks = slices.DeleteFunc(fil.locks, func(lck rangeLock) bool {
+		return lck.owner == owner
+	})
+}
+
+// lockEnd returns the end of the lock range starting at the offset off and
+// spanning n bytes. The zero n means the range extends to infinity.
+func lockEnd(off, n int64) (int64, error) {
+	if off < 0 || n < 0 {
+		return 0, syscall.EINVAL
+	}
+	if n == 0 || off > math.MaxInt64-n {
+		return math.MaxInt64, nil
+	}
+	return off + n, nil
+}
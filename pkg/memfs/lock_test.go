@@ -0,0 +1,293 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"math"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_rangeLock_overlaps(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		off  int64
+		end  int64
+		want bool
+	}{
+		{"before", 0, 10, false},
+		{"touching start", 5, 10, false},
+		{"overlapping start", 5, 11, true},
+		{"inside", 12, 15, true},
+		{"covering", 0, 100, true},
+		{"overlapping end", 19, 25, true},
+		{"touching end", 20, 25, false},
+		{"after", 21, 25, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			lck := rangeLock{off: 10, end: 20}
+
+			// --- When ---
+			have := lck.overlaps(tc.off, tc.end)
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func Test_File_LockRange(t *testing.T) {
+	t.Run("exclusive lock", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.LockRange(10, 5, true)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []rangeLock{{off: 10, end: 15, excl: true, owner: fil}}
+		assert.Equal(t, want, fil.locks)
+	})
+
+	t.Run("zero length locks to infinity", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.LockRange(10, 0, false)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []rangeLock{{off: 10, end: math.MaxInt64, owner: fil}}
+		assert.Equal(t, want, fil.locks)
+	})
+
+	t.Run("overlapping shared locks", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		must.Nil(fil.LockRange(0, 10, false))
+
+		// --- When ---
+		err := fil.LockRange(5, 10, false)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 2, fil.locks)
+	})
+
+	t.Run("not overlapping exclusive locks", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		must.Nil(fil.LockRange(0, 10, true))
+
+		// --- When ---
+		err := fil.LockRange(10, 10, true)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 2, fil.locks)
+	})
+
+	t.Run("released by Close", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file0"))
+		must.Nil(fil.LockRange(0, 5, true))
+		h := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h.LockRange(5, 5, true))
+
+		// --- When ---
+		must.Nil(fil.Close())
+
+		// --- Then ---
+		want := []rangeLock{{off: 5, end: 10, excl: true, owner: h}}
+		assert.Equal(t, want, fil.locks)
+	})
+
+	t.Run("error - exclusive lock overlapping shared lock", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		must.Nil(fil.LockRange(0, 10, false))
+
+		// --- When ---
+		err := fil.LockRange(9, 10, true)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "lock", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.EAGAIN, e.Err)
+		assert.Len(t, 1, fil.locks)
+	})
+
+	t.Run("error - shared lock overlapping exclusive lock", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		must.Nil(fil.LockRange(5, 0, true))
+
+		// --- When ---
+		err := fil.LockRange(100, 1, false)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, syscall.EAGAIN, e.Err)
+	})
+
+	t.Run("error - negative offset", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.LockRange(-1, 1, true)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "lock", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+	})
+
+	t.Run("error - negative length", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.LockRange(1, -1, true)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("dir")
+
+		// --- When ---
+		err := dir.LockRange(0, 1, true)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "lock", e.Op)
+		assert.Equal(t, "dir", e.Path)
+		assert.Equal(t, syscall.EISDIR, e.Err)
+	})
+}
+
+func Test_File_UnlockRange(t *testing.T) {
+	t.Run("unlock", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		must.Nil(fil.LockRange(0, 10, true))
+
+		// --- When ---
+		err := fil.UnlockRange(0, 10)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, fil.locks)
+		assert.NoError(t, fil.LockRange(0, 10, true))
+	})
+
+	t.Run("unlock one of shared locks", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		must.Nil(fil.LockRange(0, 10, false))
+		must.Nil(fil.LockRange(0, 10, false))
+
+		// --- When ---
+		err := fil.UnlockRange(0, 10)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 1, fil.locks)
+	})
+
+	t.Run("error - not locked range", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		must.Nil(fil.LockRange(0, 10, true))
+
+		// --- When ---
+		err := fil.UnlockRange(0, 5)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "unlock", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOLCK, e.Err)
+		assert.Len(t, 1, fil.locks)
+	})
+
+	t.Run("error - lock of handle", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		h := must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+		must.Nil(h.LockRange(0, 5, true))
+
+		// --- When ---
+		err := h.File().UnlockRange(0, 5)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOLCK, err)
+		assert.Len(t, 1, h.File().locks)
+	})
+
+	t.Run("error - negative offset", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.UnlockRange(-1, 5)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+	})
+}
+
+func Test_lockEnd(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		off  int64
+		n    int64
+		want int64
+		err  error
+	}{
+		{"range", 10, 5, 15, nil},
+		{"zero length", 10, 0, math.MaxInt64, nil},
+		{"overflow", 10, math.MaxInt64, math.MaxInt64, nil},
+		{"negative offset", -1, 5, 0, syscall.EINVAL},
+		{"negative length", 1, -5, 0, syscall.EINVAL},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have, err := lockEnd(tc.off, tc.n)
+
+			// --- Then ---
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// NewLog returns a new instance of [File] representing an append-only log
+// file. The file has the [os.O_APPEND] flag and the [AttrAppendOnly]
+// attribute set, so its content can only be appended to. Use [File.Rotate] to
+// rotate it.
+func NewLog(name string, opts ...func(*File)) (*File, error) {
+	opts = append([]func(*File){
+		WithFileAppend,
+		WithFileAttr(AttrAppendOnly),
+	}, opts...)
+	return NewFile(name, opts...)
+}
+
+// Rotate rotates the file when its size reaches maxSize bytes. The existing
+// "name.N" siblings are renamed to "name.N+1" starting from the highest one,
+// the file itself is renamed to "name.1" and a new empty file with the same
+// name, mode, flags, attributes and options is added to the directory with
+// [File.AddFile]. Returns the file which is current after the call - the new
+// file when the file was rotated, or the receiver otherwise. The renames are
+// reported to the hook set with [WithRenameHook]. In the write-through mode
+// (see [File.MirrorTo]), the files are also renamed and added in the OS file
+// system. When adding the new file fails, the renames are reverted, so the
+// directory is never left without the file.
+//
+// The same as with rename(2) based rotation, the rotated instance keeps its
+// content and flags, so writers still holding it continue writing to "name.1"
+// until they open the file again. It makes it possible to test how the code
+// handles the rotation race window.
+//
+// Returns an error of the [fs.PathError] type with [syscall.EISDIR] when the
+// file represents a directory, [syscall.EINVAL] when the file has no parent
+// directory or maxSize is negative, [syscall.EPERM] when the parent
+// directory is immutable, [syscall.EROFS] when the file, any of its rotated
+// siblings or the directory is read-only (see [WithReadOnly]), and
+// [syscall.EBUSY] when any of them is open and the sharing violations are
+// turned on (see [WithSharingViolations]). Returns the errors returned by
+// [File.AddFile] and the errors of the [os] package in the write-through
+// mode.
+func (fil *File) Rotate(maxSize int64) (*File, error) {
+	var err error
+	switch {
+	case fil.IsDir():
+		err = syscall.EISDIR
+	case fil.parent == nil || maxSize < 0:
+		err = syscall.EINVAL
+	case fil.parent.attr&AttrImmutable != 0:
+		err = syscall.EPERM
+	}
+	if err != nil {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "rotate",
+			Path: fil.path(),
+			Err:  err,
+		})
+	}
+	if int64(fil.Len()) < maxSize {
+		return fil, nil
+	}
+
+	dir := fil.parent
+	name := fil.info.name
+	segs := []*File{fil}
+	for {
+		seg, err := open(dir, segName(name, len(segs)))
+		if err != nil {
+			break
+		}
+		segs = append(segs, seg)
+	}
+	if err = dir.checkReadOnly("rotate"); err != nil {
+		return nil, err
+	}
+	for _, seg := range segs {
+		if err = seg.checkReadOnly("rotate"); err != nil {
+			return nil, err
+		}
+		if err = seg.checkSharing("rotate"); err != nil {
+			return nil, err
+		}
+	}
+
+	cur := fil.cloneOpts()
+	cur.buf = make([]byte, 0, bytes.MinRead)
+	cur.updateSum()
+
+	hook := dir.renamesHook()
+	for i := len(segs) - 1; i >= 0; i-- {
+		seg := segs[i]
+		src, oldPth := seg.mirrorPath(), seg.path()
+		seg.info.name = segName(name, i+1)
+		if hook != nil {
+			hook(RenameEvent{OldPath: oldPth, NewPath: seg.path()})
+		}
+		if err = mirrorRename(seg, src); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = dir.AddFile(cur); err != nil {
+		unrotate(dir, cur, segs)
+		return nil, err
+	}
+	return cur, nil
+}
+
+// unrotate reverts the [File.Rotate] of the segments in the directory after
+// adding the new file cur failed. It removes cur when it was added and gives
+// the segments back their names. In the write-through mode, the OS file
+// system is reverted on the best-effort basis.
+func unrotate(dir, cur *File, segs []*File) {
+	if cur.parent != nil {
+		pth := cur.mirrorPath()
+		cur.unlink()
+		if pth != "" {
+			_ = os.Remove(pth)
+		}
+	}
+	hook := dir.renamesHook()
+	for i, seg := range segs {
+		src, oldPth := seg.mirrorPath(), seg.path()
+		seg.info.name = cur.info.name
+		if i > 0 {
+			seg.info.name = segName(cur.info.name, i)
+		}
+		if hook != nil {
+			hook(RenameEvent{OldPath: oldPth, NewPath: seg.path()})
+		}
+		_ = mirrorRename(seg, src)
+	}
+}
+
+// segName returns the name of the n-th rotated segment of the file.
+func segName(name string, n int) string {
+	return name + "." + strconv.Itoa(n)
+}
@@ -0,0 +1,376 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_NewLog(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		// --- When ---
+		fil, err := NewLog("app.log")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "app.log", fil.Name())
+		assert.Equal(t, os.O_APPEND, fil.flag)
+		assert.Equal(t, AttrAppendOnly, fil.attr)
+		assert.Equal(t, 0, fil.Len())
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		// --- When ---
+		fil, err := NewLog("app.log", WithFileChecksum)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, fil.csum)
+		assert.Equal(t, AttrAppendOnly, fil.attr)
+	})
+
+	t.Run("append only", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewLog("app.log"))
+		must.Value(fil.WriteString("abc"))
+
+		// --- When ---
+		err := fil.Truncate(0)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Equal(t, "abc", string(fil.buf))
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		// --- When ---
+		fil, err := NewLog("dir/app.log")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+		assert.Nil(t, fil)
+	})
+}
+
+func Test_File_Rotate(t *testing.T) {
+	t.Run("size below max", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		must.Value(fil.WriteString("abc"))
+
+		// --- When ---
+		have, err := fil.Rotate(4)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, fil, have)
+		assert.Equal(t, "app.log", fil.Name())
+		assert.Len(t, 1, dir.entries)
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		must.Value(fil.WriteString("abc"))
+
+		// --- When ---
+		have, err := fil.Rotate(3)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.NotSame(t, fil, have)
+		assert.Equal(t, "app.log.1", fil.Name())
+		assert.Equal(t, "app.log", have.Name())
+		assert.Equal(t, os.O_APPEND, have.flag)
+		assert.Equal(t, AttrAppendOnly, have.attr)
+		assert.Equal(t, fil.Mode(), have.Mode())
+		assert.Same(t, dir, have.parent)
+		assert.Equal(t, 0, have.Len())
+		assert.Equal(t, "abc", string(must.Value(open(dir, "app.log.1")).buf))
+		assert.Equal(t, "", string(must.Value(open(dir, "app.log")).buf))
+	})
+
+	t.Run("shifts existing segments", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		must.Nil(dir.AddFile(MustFileWith("app.log.1", []byte("1"))))
+		must.Nil(dir.AddFile(MustFileWith("app.log.2", []byte("2"))))
+		must.Nil(dir.AddFile(MustFileWith("app.log.4", []byte("4"))))
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		must.Value(fil.WriteString("0"))
+
+		// --- When ---
+		have, err := fil.Rotate(0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.NotSame(t, fil, have)
+		assert.Equal(t, "0", string(must.Value(open(dir, "app.log.1")).buf))
+		assert.Equal(t, "1", string(must.Value(open(dir, "app.log.2")).buf))
+		assert.Equal(t, "2", string(must.Value(open(dir, "app.log.3")).buf))
+		assert.Equal(t, "4", string(must.Value(open(dir, "app.log.4")).buf))
+		assert.Len(t, 5, dir.entries)
+	})
+
+	t.Run("old instance writes to rotated segment", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		must.Value(fil.WriteString("abc"))
+		cur := must.Value(fil.Rotate(1))
+
+		// --- When ---
+		must.Value(fil.WriteString("def"))
+
+		// --- Then ---
+		seg := must.Value(open(dir, "app.log.1"))
+		assert.Equal(t, "abcdef", string(seg.buf))
+		assert.Equal(t, 0, cur.Len())
+	})
+
+	t.Run("options are kept", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		fil := must.Value(NewLog(
+			"app.log",
+			WithWriteOnce,
+			WithGrowthFactor(3),
+			WithLatency(time.Nanosecond),
+			WithMaxWriteChunk(2),
+			WithStatsHook(func(string, Stats) {}),
+		))
+		must.Nil(dir.AddFile(fil))
+
+		// --- When ---
+		have, err := fil.Rotate(0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.once)
+		assert.Equal(t, 3.0, have.growth)
+		assert.Equal(t, time.Nanosecond, have.latency)
+		assert.Equal(t, 2, have.wchunk)
+		assert.NotNil(t, have.statHook)
+	})
+
+	t.Run("renames are reported", func(t *testing.T) {
+		// --- Given ---
+		var have []RenameEvent
+		hook := func(ev RenameEvent) { have = append(have, ev) }
+		dir := NewRoot(WithRenameHook(hook))
+		must.Nil(dir.AddFile(MustFileWith("app.log.1", []byte("1"))))
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+
+		// --- When ---
+		_, err := fil.Rotate(0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []RenameEvent{
+			{OldPath: "app.log.1", NewPath: "app.log.2"},
+			{OldPath: "app.log", NewPath: "app.log.1"},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("write-through mode", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.AddFile(MustFileWith("app.log.1", []byte("1"))))
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		must.Value(fil.WriteString("0"))
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+
+		// --- When ---
+		_, err := fil.Rotate(0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have0 := must.Value(os.ReadFile(filepath.Join(dst, "app.log")))
+		have1 := must.Value(os.ReadFile(filepath.Join(dst, "app.log.1")))
+		have2 := must.Value(os.ReadFile(filepath.Join(dst, "app.log.2")))
+		assert.Equal(t, "", string(have0))
+		assert.Equal(t, "0", string(have1))
+		assert.Equal(t, "1", string(have2))
+	})
+
+	t.Run("error - not a file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+
+		// --- When ---
+		have, err := sub.Rotate(0)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "rotate", e.Op)
+		assert.Equal(t, "sub", e.Path)
+		assert.Equal(t, syscall.EISDIR, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - no parent", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewLog("app.log"))
+
+		// --- When ---
+		have, err := fil.Rotate(0)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - negative size", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+
+		// --- When ---
+		have, err := fil.Rotate(-1)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - immutable directory", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		dir.SetAttr(AttrImmutable)
+
+		// --- When ---
+		have, err := fil.Rotate(0)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Nil(t, have)
+		assert.Equal(t, "app.log", fil.Name())
+	})
+	t.Run("error - read-only directory", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		WithReadOnly(dir)
+
+		// --- When ---
+		have, err := fil.Rotate(0)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "rotate", e.Op)
+		assert.Equal(t, syscall.EROFS, e.Err)
+		assert.Nil(t, have)
+		assert.Equal(t, "app.log", fil.Name())
+		assert.Len(t, 1, dir.entries)
+	})
+
+	t.Run("error - read-only segment", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("logs")
+		seg := MustFileWith("app.log.1", []byte("1"))
+		WithReadOnly(seg)
+		must.Nil(dir.AddFile(seg))
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+
+		// --- When ---
+		have, err := fil.Rotate(0)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Nil(t, have)
+		assert.Equal(t, "app.log", fil.Name())
+		assert.Equal(t, "app.log.1", seg.Name())
+	})
+
+	t.Run("error - sharing violation", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithSharingViolations)
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		must.Value(dir.Open("app.log"))
+
+		// --- When ---
+		have, err := fil.Rotate(0)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EBUSY, err)
+		assert.Nil(t, have)
+		assert.Equal(t, "app.log", fil.Name())
+	})
+}
+
+func Test_unrotate(t *testing.T) {
+	t.Run("reverts rotation", func(t *testing.T) {
+		// --- Given ---
+		var have []RenameEvent
+		hook := func(ev RenameEvent) { have = append(have, ev) }
+		dir := NewRoot()
+		seg := MustFileWith("app.log.1", []byte("1"))
+		must.Nil(dir.AddFile(seg))
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		cur := must.Value(fil.Rotate(0))
+		WithRenameHook(hook)(dir)
+
+		// --- When ---
+		unrotate(dir, cur, []*File{fil, seg})
+
+		// --- Then ---
+		assert.Nil(t, cur.parent)
+		assert.Len(t, 2, dir.entries)
+		assert.Same(t, fil, must.Value(open(dir, "app.log")))
+		assert.Same(t, seg, must.Value(open(dir, "app.log.1")))
+		want := []RenameEvent{
+			{OldPath: "app.log.1", NewPath: "app.log"},
+			{OldPath: "app.log.2", NewPath: "app.log.1"},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("write-through mode", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		fil := must.Value(NewLog("app.log"))
+		must.Nil(dir.AddFile(fil))
+		must.Value(fil.WriteString("0"))
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+		cur := must.Value(fil.Rotate(0))
+
+		// --- When ---
+		unrotate(dir, cur, []*File{fil})
+
+		// --- Then ---
+		have := must.Value(os.ReadFile(filepath.Join(dst, "app.log")))
+		assert.Equal(t, "0", string(have))
+		_, err := os.Stat(filepath.Join(dst, "app.log.1"))
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+}
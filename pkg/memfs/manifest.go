@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// ErrManifest is returned by [File.VerifyManifest] when the manifest is
+// malformed.
+var ErrManifest = errors.New("invalid manifest")
+
+// manifestAlgos maps the BSD-style manifest tags to the hash functions.
+var manifestAlgos = map[string]crypto.Hash{
+	"MD5":    crypto.MD5,
+	"SHA1":   crypto.SHA1,
+	"SHA256": crypto.SHA256,
+	"SHA512": crypto.SHA512,
+}
+
+// WriteManifest writes the checksums of all files in the directory tree to w
+// in the format produced by the sha256sum family of tools, for example:
+//
+//	2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae  file
+//
+// Paths are relative to the directory, and the files are listed in the path
+// order. The algo must be one of [crypto.MD5], [crypto.SHA1],
+// [crypto.SHA256] or [crypto.SHA512].
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory and with [fs.ErrInvalid] when the algorithm is
+// not supported.
+func (fil *File) WriteManifest(w io.Writer, algo crypto.Hash) error {
+	if !manifestAlgo(algo) {
+		return fil.hookErr(&fs.PathError{
+			Op:   "manifest",
+			Path: fil.path(),
+			Err:  fs.ErrInvalid,
+		})
+	}
+	add := func(pth string, ent *File, data []byte) error {
+		if ent.IsDir() {
+			return nil
+		}
+		h := algo.New()
+		h.Write(data)
+		line := hex.EncodeToString(h.Sum(nil)) + "  " + filepath.ToSlash(pth)
+		if strings.ContainsAny(pth, "\\\n") {
+			line = "\\" + manifestEscape.Replace(line)
+		}
+		_, err := io.WriteString(w, line+"\n")
+		return err
+	}
+	return fil.export("manifest", nil, add)
+}
+
+// VerifyManifest verifies the files in the directory tree against the
+// checksums in the manifest read from r. It accepts the format produced by
+// the sha256sum family of tools, in the text and the binary mode, and the
+// BSD-style format produced with the --tag option:
+//
+//	MD5 (sub/file) = acbd18db4cc2f85cedef654fccc4a4d8
+//
+// The algorithm of the lines without a tag is detected from the checksum
+// length. Paths are relative to the directory. Files not listed in the
+// manifest are not verified. Empty lines are ignored.
+//
+// All listed files are verified, and the errors for all failing files are
+// returned joined. The error for a file is of the [fs.PathError] type with
+// [ErrChecksum] when the content does not match the checksum, and the error
+// returned by [File.Open] when the file cannot be opened. Returns an error
+// wrapping [ErrManifest] when the manifest is malformed, and an error of the
+// [fs.PathError] type with [syscall.ENOTDIR] when the instance is not a
+// directory.
+func (fil *File) VerifyManifest(r io.Reader) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "verify",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+
+	var errs []error
+	scn := bufio.NewScanner(r)
+	for num := 1; scn.Scan(); num++ {
+		line := scn.Text()
+		if line == "" {
+			continue
+		}
+		algo, want, name, ok := parseManifestLine(line)
+		if !ok {
+			return fmt.Errorf("%w: line %d", ErrManifest, num)
+		}
+		ent, err := open(fil, name)
+		if err == nil && ent.IsDir() {
+			err = &fs.PathError{
+				Op:   "verify",
+				Path: ent.path(),
+				Err:  syscall.EISDIR,
+			}
+		}
+		if err == nil {
+			err = ent.load()
+		}
+		if err != nil {
+			errs = append(errs, fil.hookErr(err))
+			continue
+		}
+		h := algo.New()
+		h.Write(ent.mapCopy())
+		if !bytes.Equal(want, h.Sum(nil)) {
+			errs = append(errs, fil.hookErr(&fs.PathError{
+				Op:   "verify",
+				Path: ent.path(),
+				Err:  ErrChecksum,
+			}))
+		}
+	}
+	if err := scn.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// manifestEscape escapes the file names in manifest lines.
+var manifestEscape = strings.NewReplacer("\\", "\\\\", "\n", "\\n")
+
+// manifestUnescape unescapes the file names in manifest lines.
+var manifestUnescape = strings.NewReplacer("\\\\", "\\", "\\n", "\n")
+
+// manifestAlgo returns true when the hash function is supported in
+// manifests.
+func manifestAlgo(algo crypto.Hash) bool {
+	for _, h := range manifestAlgos {
+		if h == algo {
+			return true
+		}
+	}
+	return false
+}
+
+// parseManifestLine parses the manifest line. Returns false when the line is
+// malformed.
+func parseManifestLine(line string) (crypto.Hash, []byte, string, bool) {
+	escaped := strings.HasPrefix(line, "\\")
+	if escaped {
+		line = line[1:]
+	}
+
+	var algo crypto.Hash
+	var sum, name string
+	if tag, rest, ok := strings.Cut(line, " ("); ok && manifestAlgos[tag] != 0 {
+		algo = manifestAlgos[tag]
+		idx := strings.LastIndex(rest, ") = ")
+		if idx == -1 {
+			return 0, nil, "", false
+		}
+		name, sum = rest[:idx], rest[idx+4:]
+	} else {
+		var found bool
+		if sum, name, found = strings.Cut(line, " "); !found {
+			return 0, nil, "", false
+		}
+		if name, found = strings.CutPrefix(name, " "); !found {
+			if name, found = strings.CutPrefix(name, "*"); !found {
+				return 0, nil, "", false
+			}
+		}
+		for _, h := range manifestAlgos {
+			if len(sum) == 2*h.Size() {
+				algo = h
+			}
+		}
+	}
+
+	want, err := hex.DecodeString(sum)
+	if algo == 0 || err != nil || len(want) != algo.Size() || name == "" {
+		return 0, nil, "", false
+	}
+	if escaped {
+		name = manifestUnescape.Replace(name)
+	}
+	return algo, want, name, true
+}
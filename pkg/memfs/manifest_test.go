@@ -0,0 +1,278 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// Checksums of the "file0" content.
+const (
+	md5File0    = "b22170b6e2c9a33f45b90c7fd7260d2f"
+	sha256File0 = "56f3fd843f7ae959a8409e0ae7c067a0" +
+		"e862a6faa7a22bad147ee90ee5992bd7"
+)
+
+func Test_File_WriteManifest(t *testing.T) {
+	t.Run("sha256", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteManifest(buf, crypto.SHA256)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		lines := strings.Split(buf.String(), "\n")
+		assert.Len(t, 8, lines)
+		assert.Equal(t, sha256File0+"  file0", lines[0])
+		assert.True(t, strings.HasSuffix(lines[3], "  sub/file3"))
+		assert.True(t, strings.HasSuffix(lines[6], "  sub/sub2/file6"))
+		assert.Equal(t, "", lines[7])
+	})
+
+	t.Run("md5", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.AddFile(MustFileWith("file0", []byte("file0"))))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteManifest(buf, crypto.MD5)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, md5File0+"  file0\n", buf.String())
+	})
+
+	t.Run("escaped name", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.AddFile(MustFileWith("a\\b\nc", []byte("file0"))))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteManifest(buf, crypto.MD5)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "\\"+md5File0+"  a\\\\b\\nc\n", buf.String())
+		assert.NoError(t, dir.VerifyManifest(buf))
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := NewRoot().WriteManifest(buf, crypto.SHA256)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("error - not supported algorithm", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.WriteManifest(&bytes.Buffer{}, crypto.SHA3_256)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "manifest", e.Op)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.WriteManifest(&bytes.Buffer{}, crypto.SHA256)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "manifest", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - writer", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+
+		// --- When ---
+		err := tstDirMem().WriteManifest(errWriter{errTst}, crypto.SHA256)
+
+		// --- Then ---
+		assert.ErrorIs(t, errTst, err)
+	})
+}
+
+func Test_File_VerifyManifest(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		for _, algo := range []crypto.Hash{
+			crypto.MD5, crypto.SHA1, crypto.SHA256, crypto.SHA512,
+		} {
+			t.Run(algo.String(), func(t *testing.T) {
+				// --- Given ---
+				dir := tstDirMem()
+				buf := &bytes.Buffer{}
+				must.Nil(dir.WriteManifest(buf, algo))
+
+				// --- When ---
+				err := dir.VerifyManifest(buf)
+
+				// --- Then ---
+				assert.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			line string
+		}{
+			{"text mode", sha256File0 + "  file0"},
+			{"binary mode", sha256File0 + " *file0"},
+			{"tag", "SHA256 (file0) = " + sha256File0},
+			{"tag md5", "MD5 (file0) = " + md5File0},
+			{"tag name with parentheses", "MD5 (a) = b) = " + md5File0},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				dir := tstDirMem()
+				fil := MustFileWith("a) = b", []byte("file0"))
+				must.Nil(dir.AddFile(fil))
+
+				// --- When ---
+				err := dir.VerifyManifest(strings.NewReader(tc.line))
+
+				// --- Then ---
+				assert.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("not listed files are not verified", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		r := strings.NewReader("\n" + md5File0 + "  file0\n\n")
+
+		// --- When ---
+		err := dir.VerifyManifest(r)
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("error - all failing files", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		buf := &bytes.Buffer{}
+		must.Nil(dir.WriteManifest(buf, crypto.SHA256))
+		must.Value(must.Value(open(dir, "file1")).Write([]byte{0}))
+		must.Value(must.Value(open(dir, "sub/file4")).Write([]byte{0}))
+
+		// --- When ---
+		err := dir.VerifyManifest(buf)
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrChecksum, err)
+		want := "verify file1: checksum mismatch\n" +
+			"verify sub/file4: checksum mismatch"
+		assert.ErrorEqual(t, want, err)
+	})
+
+	t.Run("error - missing file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		r := strings.NewReader(md5File0 + "  sub/missing\n")
+
+		// --- When ---
+		err := dir.VerifyManifest(r)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		r := strings.NewReader(md5File0 + "  sub\n")
+
+		// --- When ---
+		err := dir.VerifyManifest(r)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "verify", e.Op)
+		assert.Equal(t, "sub", e.Path)
+		assert.Equal(t, syscall.EISDIR, e.Err)
+	})
+
+	t.Run("error - malformed", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			line string
+		}{
+			{"no name", md5File0},
+			{"no separator", md5File0 + " file0"},
+			{"empty name", md5File0 + "  "},
+			{"not hex", strings.Repeat("x", 32) + "  file0"},
+			{"unknown length", "abcd  file0"},
+			{"tag without checksum", "MD5 (file0)"},
+			{"tag with wrong length", "SHA256 (file0) = " + md5File0},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				dir := tstDirMem()
+				r := strings.NewReader(md5File0 + "  file0\n" + tc.line)
+
+				// --- When ---
+				err := dir.VerifyManifest(r)
+
+				// --- Then ---
+				assert.ErrorIs(t, ErrManifest, err)
+				assert.ErrorEqual(t, "invalid manifest: line 2", err)
+			})
+		}
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.VerifyManifest(strings.NewReader(""))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "verify", e.Op)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+}
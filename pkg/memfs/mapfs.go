@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"path/filepath"
+	"syscall"
+	"testing/fstest"
+)
+
+// FromMapFS returns a new root directory with a copy of the tree in the
+// [fstest.MapFS], so the tests using the MapFS fixtures can be migrated to
+// memfs. It works like [FromFS] but also copies the modification times. The
+// parent directories missing in the map are created like the MapFS
+// synthesizes them.
+func FromMapFS(m fstest.MapFS, opts ...func(*File)) (*File, error) {
+	root, err := FromFS(m)
+	if err != nil {
+		return nil, err
+	}
+	for name, mf := range m {
+		if mf == nil {
+			continue
+		}
+		if ent, err := lopen(root, name); err == nil {
+			ent.info.mtime = mf.ModTime
+		}
+	}
+	for _, opt := range opts {
+		opt(root)
+	}
+	return root, nil
+}
+
+// ToMapFS returns the directory tree as a [fstest.MapFS] with all the files,
+// directories and symbolic links in the tree, and their contents, modes and
+// modification times. The contents are copied. The data of the symbolic
+// links are their destinations, as expected by the MapFS.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory and an error of the [fs.PathError] type when
+// the content of a lazily loaded file cannot be loaded.
+func (fil *File) ToMapFS() (fstest.MapFS, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "tomapfs",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	m := make(fstest.MapFS)
+	var err error
+	fil.walk("", func(pth string, ent *File) {
+		if err != nil {
+			return
+		}
+		mf := &fstest.MapFile{Mode: ent.Mode(), ModTime: ent.ModTime()}
+		switch {
+		case ent.isSymlink():
+			mf.Data = []byte(ent.link)
+		case !ent.IsDir():
+			mf.Data, err = ent.diskContent()
+		}
+		m[filepath.ToSlash(pth)] = mf
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
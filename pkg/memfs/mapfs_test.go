@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstMapFS returns a map file system for the MapFS conversion tests.
+func tstMapFS() fstest.MapFS {
+	tim := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	return fstest.MapFS{
+		"file":      {Data: []byte("abc"), Mode: 0644, ModTime: tim},
+		"dir":       {Mode: fs.ModeDir | 0750, ModTime: tim},
+		"dir/file":  {Data: []byte("def"), Mode: 0600, ModTime: tim},
+		"dir/link":  {Data: []byte("file"), Mode: fs.ModeSymlink | 0777},
+		"empty":     {Mode: fs.ModeDir | 0700, ModTime: tim},
+		"implied/f": {Data: []byte("ghi"), Mode: 0600, ModTime: tim},
+	}
+}
+
+func Test_FromMapFS(t *testing.T) {
+	t.Run("copy", func(t *testing.T) {
+		// --- Given ---
+		m := tstMapFS()
+
+		// --- When ---
+		have, err := FromMapFS(m, WithReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.readOnly())
+		fil := must.Value(open(have, "dir/file"))
+		assert.Equal(t, "def", fil.String())
+		assert.Equal(t, fs.FileMode(0600), fil.Mode())
+		assert.Equal(t, m["dir/file"].ModTime, fil.ModTime())
+		dir := must.Value(open(have, "dir"))
+		assert.Equal(t, fs.ModeDir|0750, dir.Mode())
+		assert.Equal(t, m["dir"].ModTime, dir.ModTime())
+		assert.Equal(t, "file", must.Value(have.ReadLink("dir/link")))
+		implied := must.Value(open(have, "implied"))
+		assert.True(t, implied.IsDir())
+	})
+
+	t.Run("passes fstest", func(t *testing.T) {
+		// --- Given ---
+		have := must.Value(FromMapFS(tstMapFS()))
+
+		// --- When ---
+		err := fstest.TestFS(have.FS(), "file", "dir/file", "implied/f")
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+}
+
+func Test_File_ToMapFS(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// --- Given ---
+		want := tstMapFS()
+		want["implied"] = &fstest.MapFile{Mode: fs.ModeDir | 0700}
+		dir := must.Value(FromMapFS(want))
+
+		// --- When ---
+		have, err := dir.ToMapFS()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("content is copied", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		have := must.Value(dir.ToMapFS())
+
+		// --- When ---
+		have["file0"].Data[0] = 'X'
+
+		// --- Then ---
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("file0"))))
+	})
+
+	t.Run("lazily loaded file", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		dir := NewRoot()
+		must.Nil(dir.AddFile(lazyFile([]byte("abc"), &loads)))
+
+		// --- When ---
+		have, err := dir.ToMapFS()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 1, loads)
+		assert.Equal(t, "abc", string(have["file"].Data))
+		assert.True(t, dir.entries[0].unloaded())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have, err := fil.ToMapFS()
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Nil(t, have)
+	})
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Format represents a data interchange format.
+type Format int
+
+const (
+	// FormatCSV is the CSV format described in RFC 4180 with a header
+	// record.
+	FormatCSV Format = iota
+
+	// FormatJSON is the JSON Lines format with one JSON object per line.
+	FormatJSON
+
+	// FormatTar is the tar archive format, see [File.WriteTar].
+	FormatTar
+
+	// FormatZip is the zip archive format, see [File.WriteZip].
+	FormatZip
+
+	// FormatTxtar is the txtar format, see [File.Txtar].
+	FormatTxtar
+)
+
+// metadataHeader is the header record of the [File.ExportMetadata] output in
+// the CSV format, the same names are used as JSON object keys.
+var metadataHeader = []string{"path", "type", "size", "mode", "mtime", "hash"}
+
+// metadata represents the [File.ExportMetadata] record.
+type metadata struct {
+	Path  string `json:"path"`
+	Type  string `json:"type"`
+	Size  int64  `json:"size"`
+	Mode  string `json:"mode"`
+	MTime string `json:"mtime"`
+	Hash  string `json:"hash"`
+}
+
+// record returns the metadata as a CSV record.
+func (md metadata) record() []string {
+	return []string{
+		md.Path,
+		md.Type,
+		strconv.FormatInt(md.Size, 10),
+		md.Mode,
+		md.MTime,
+		md.Hash,
+	}
+}
+
+// ExportMetadata writes one record for every file and directory in the
+// directory tree to w in the given format, so the tree inventory can be
+// consumed by external tools and spreadsheets. The records are written in the
+// path order and have the following fields:
+//
+//   - path - the slash-separated path relative to the directory,
+//   - type - "file" or "dir",
+//   - size - the size in bytes,
+//   - mode - the permission bits as an octal number, for example, "0644",
+//   - mtime - the modification time in the [time.RFC3339Nano] format,
+//   - hash - the hex-encoded SHA-256 checksum of the file content, empty for
+//     directories.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory and with [fs.ErrInvalid] when the format is not
+// supported.
+func (fil *File) ExportMetadata(w io.Writer, format Format) error {
+	return fil.exportMetadata(w, format, nil)
+}
+
+// exportMetadata writes the records for the files and directories in the
+// directory tree for which keep returns true to w in the given format. When
+// keep is nil, the records for all of them are written. See
+// [File.ExportMetadata] for details.
+func (fil *File) exportMetadata(
+	w io.Writer,
+	format Format,
+	keep func(pth string) bool,
+) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "metadata",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+
+	var add func(md metadata) error
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(metadataHeader); err != nil {
+			return err
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		add = func(md metadata) error {
+			if err := cw.Write(md.record()); err != nil {
+				return err
+			}
+			cw.Flush()
+			return cw.Error()
+		}
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		add = func(md metadata) error { return enc.Encode(md) }
+	default:
+		return fil.hookErr(&fs.PathError{
+			Op:   "metadata",
+			Path: fil.path(),
+			Err:  fs.ErrInvalid,
+		})
+	}
+
+	rec := func(pth string, ent *File, data []byte) error {
+		md := metadata{
+			Path:  filepath.ToSlash(pth),
+			Type:  "file",
+			Size:  ent.Size(),
+			Mode:  fmt.Sprintf("%04o", ent.Mode().Perm()),
+			MTime: ent.ModTime().UTC().Format(time.RFC3339Nano),
+		}
+		if ent.IsDir() {
+			md.Type = "dir"
+		} else {
+			sum := sha256.Sum256(data)
+			md.Hash = hex.EncodeToString(sum[:])
+		}
+		return add(md)
+	}
+	return fil.exportKept("metadata", keep, nil, rec)
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_ExportMetadata(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(must.Value(open(dir, "file1")).Chmod(0755))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.ExportMetadata(buf, FormatCSV)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(csv.NewReader(buf).ReadAll())
+		assert.Len(t, 10, have)
+		want := []string{"path", "type", "size", "mode", "mtime", "hash"}
+		assert.Equal(t, want, have[0])
+		want = []string{
+			"file0", "file", "5", "0600", "0001-01-01T00:00:00Z", sha256File0,
+		}
+		assert.Equal(t, want, have[1])
+		assert.Equal(t, "0755", have[2][3])
+		want = []string{
+			"sub/sub2", "dir", "4096", "0700", "0001-01-01T00:00:00Z", "",
+		}
+		assert.Equal(t, want, have[7])
+		assert.Equal(t, "sub/sub2/file6", have[9][0])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.ExportMetadata(buf, FormatJSON)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		assert.Len(t, 9, lines)
+		var have map[string]any
+		must.Nil(json.Unmarshal([]byte(lines[0]), &have))
+		want := map[string]any{
+			"path":  "file0",
+			"type":  "file",
+			"size":  float64(5),
+			"mode":  "0600",
+			"mtime": "0001-01-01T00:00:00Z",
+			"hash":  sha256File0,
+		}
+		assert.Equal(t, want, have)
+		must.Nil(json.Unmarshal([]byte(lines[3]), &have))
+		assert.Equal(t, "sub", have["path"])
+		assert.Equal(t, "dir", have["type"])
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := NewRoot().ExportMetadata(buf, FormatCSV)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "path,type,size,mode,mtime,hash\n", buf.String())
+	})
+
+	t.Run("error - not supported format", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().ExportMetadata(buf, Format(42))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "metadata", e.Op)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := MustFile("file").ExportMetadata(buf, FormatCSV)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "metadata", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("error - writer", func(t *testing.T) {
+		for _, format := range []Format{FormatCSV, FormatJSON} {
+			// --- Given ---
+			errTst := errors.New("test error")
+
+			// --- When ---
+			err := tstDirMem().ExportMetadata(errWriter{errTst}, format)
+
+			// --- Then ---
+			assert.ErrorIs(t, errTst, err)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"syscall"
+)
+
+// MirrorTo writes the directory tree to the directory dir on the OS file
+// system, creating it if needed, and turns on the write-through mode in which
+// every following modification of the tree is applied to dir as it happens.
+// Use it when a failing test should leave behind an inspectable copy of the
+// final or crashed state of the tree.
+//
+// In the write-through mode, the modified file is rewritten as a whole after
+// every call modifying its content, files and directories added with
+// [File.AddFile] are written with their subtrees, the ones removed with
+// [File.Remove] and [File.RemoveAll] are removed from dir, [File.Rename]
+// renames the paths in dir, and [File.Exchange] exchanges the paths in dir
+// with three renames. When applying a modification to dir fails, the
+// modification is kept in memory, and the method returns the error of the
+// [os] package. Existing files in dir which are not in the tree are left
+// untouched.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, an error of the [fs.PathError] type when the
+// content of a lazily loaded file cannot be loaded, and the errors of the
+// [os] package.
+func (fil *File) MirrorTo(dir string) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "mirror",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	if err := fil.mirrorTree(dir); err != nil {
+		return err
+	}
+	fil.mirror = dir
+	return nil
+}
+
+// mirrorPath returns the path of the instance in the directory set with
+// [File.MirrorTo] on the instance or the closest of its parents. Returns an
+// empty string when the write-through mode is off.
+func (fil *File) mirrorPath() string {
+	top := fil
+	for top != nil && top.mirror == "" {
+		top = top.parent
+	}
+	if top == nil {
+		return ""
+	}
+	var elems []string
+	for f := fil; f != top; f = f.parent {
+		elems = append(elems, f.Name())
+	}
+	elems = append(elems, top.mirror)
+	slices.Reverse(elems)
+	return filepath.Join(elems...)
+}
+
+// mirrorContent rewrites the file in the write-through mode.
+func (fil *File) mirrorContent() error {
+	if pth := fil.mirrorPath(); pth != "" {
+		return os.WriteFile(pth, fil.mapCopy(), fil.Mode().Perm())
+	}
+	return nil
+}
+
+// mirrorAdd writes the file and its subtree in the write-through mode.
+func (fil *File) mirrorAdd() error {
+	if pth := fil.mirrorPath(); pth != "" {
+		return fil.mirrorTree(pth)
+	}
+	return nil
+}
+
+// mirrorTree writes the instance and its subtree to the given path on the OS
+// file system.
+func (fil *File) mirrorTree(dst string) error {
+	if fil.isSymlink() {
+		return os.Symlink(fil.link, dst)
+	}
+	if !fil.IsDir() {
+		data, err := fil.diskContent()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, fil.Mode().Perm())
+	}
+	if err := os.MkdirAll(dst, fil.Mode().Perm()); err != nil {
+		return err
+	}
+	for _, ent := range fil.entries {
+		if err := ent.mirrorTree(filepath.Join(dst, ent.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diskContent returns the copy of the file content to write to the OS file
+// system. The content of a lazily loaded file is loaded and unloaded again,
+// so writing the file doesn't change its state.
+func (fil *File) diskContent() ([]byte, error) {
+	unloaded := fil.unloaded()
+	if err := fil.load(); err != nil {
+		return nil, err
+	}
+	data := fil.mapCopy()
+	if unloaded {
+		fil.unload()
+	}
+	return data, nil
+}
+
+// WriteToDisk writes the directory tree to the directory dir on the OS file
+// system, creating it if needed, for the code which works only with the real
+// paths. Unlike [File.MirrorTo], the following modifications of the tree are
+// not applied to dir. The files and directories are written with the same
+// contents and permission bits as in the tree, regardless of the umask, and
+// the symbolic links are recreated with the same destinations. The
+// permissions of the directories are set after their entries are written, so
+// the read-only directories can be written too. Existing files in dir which
+// are not in the tree are left untouched. The progress is reported to the
+// function set with [WithProgress], and the write is shaped by the throttle
+// set with [WithThrottle].
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, an error of the [fs.PathError] type when the
+// content of a lazily loaded file cannot be loaded, an error of the
+// [fs.PathError] type with [ErrInterrupted] when the write was interrupted
+// with [Throttle.Interrupt], and the errors of the [os] package.
+func (fil *File) WriteToDisk(dir string) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "writetodisk",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	dw := &diskWrite{prog: fil.progress(fil.contentSize), th: fil.throttle()}
+	dw.th.begin()
+	return fil.diskTree(dir, ".", dw)
+}
+
+// diskWrite is the state of [File.WriteToDisk].
+type diskWrite struct {
+	prog *progress // Progress of the write.
+	th   *Throttle // Throttle of the write.
+}
+
+// diskTree writes the instance and its subtree to the given path on the OS
+// file system and sets their permission bits. The pth is the path of the
+// instance reported to the progress and kept in the checkpoint.
+func (fil *File) diskTree(dst, pth string, dw *diskWrite) error {
+	if !fil.IsDir() {
+		if dw.th.skip(pth) {
+			if fil.Mode().IsRegular() {
+				dw.prog.add(pth, int64(fil.Len()))
+			}
+			return nil
+		}
+		if err := dw.th.wait(int64(fil.Len())); err != nil {
+			return fil.hookErr(&fs.PathError{
+				Op:   "writetodisk",
+				Path: pth,
+				Err:  err,
+			})
+		}
+	}
+	if fil.isSymlink() {
+		if err := os.Symlink(fil.link, dst); err != nil {
+			return err
+		}
+		dw.th.copied(pth)
+		return nil
+	}
+	if !fil.IsDir() {
+		data, err := fil.diskContent()
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(dst, data, 0600); err != nil {
+			return err
+		}
+		if err = os.Chmod(dst, fil.Mode().Perm()); err != nil {
+			return err
+		}
+		dw.th.copied(pth)
+		dw.prog.add(pth, int64(len(data)))
+		return nil
+	}
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	for _, ent := range fil.listEntries() {
+		sub := path.Join(pth, ent.Name())
+		err := ent.diskTree(filepath.Join(dst, ent.Name()), sub, dw)
+		if err != nil {
+			return err
+		}
+	}
+	return os.Chmod(dst, fil.Mode().Perm())
+}
+
+// mirrorExchange exchanges the given paths in the write-through mode. The
+// paths must be returned by [File.mirrorPath] before the exchange.
+func mirrorExchange(a, b string) error {
+	if a == "" {
+		return nil
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(a), ".exchange-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp) }()
+
+	mid := filepath.Join(tmp, filepath.Base(a))
+	if err = os.Rename(a, mid); err != nil {
+		return err
+	}
+	if err = os.Rename(b, a); err != nil {
+		return err
+	}
+	return os.Rename(mid, b)
+}
@@ -0,0 +1,358 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// readOS returns the content of the file on the OS file system as a string.
+func readOS(t *testing.T, pth string) string {
+	t.Helper()
+	return string(must.Value(os.ReadFile(pth)))
+}
+
+func Test_File_MirrorTo(t *testing.T) {
+	t.Run("writes the tree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := filepath.Join(t.TempDir(), "mirror")
+
+		// --- When ---
+		err := dir.MirrorTo(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(list(os.DirFS(dst)))
+		assert.Equal(t, must.Value(dir.List()), have)
+		have = readOS(t, filepath.Join(dst, "sub/sub2/file5"))
+		assert.Equal(t, "file5", have)
+		assert.Equal(t, dst, dir.mirror)
+	})
+
+	t.Run("lazy file stays unloaded", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		dir := NewRoot()
+		fil := lazyFile([]byte("abc"), &loads)
+		must.Nil(dir.AddFile(fil))
+		dst := t.TempDir()
+
+		// --- When ---
+		err := dir.MirrorTo(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", readOS(t, filepath.Join(dst, "file")))
+		assert.True(t, fil.unloaded())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.MirrorTo(t.TempDir())
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "mirror", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Equal(t, "", fil.mirror)
+	})
+
+	t.Run("error - os", func(t *testing.T) {
+		// --- Given ---
+		dst := filepath.Join(t.TempDir(), "file")
+		must.Nil(os.WriteFile(dst, nil, 0600))
+
+		// --- When ---
+		err := tstDirMem().MirrorTo(dst)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+}
+
+func Test_File_MirrorTo_write_through(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		mod  func(fil *File) error
+		want string
+	}{
+		{
+			"Write",
+			func(fil *File) error {
+				_, err := fil.Write([]byte("X"))
+				return err
+			},
+			"Xile3",
+		},
+		{
+			"WriteByte",
+			func(fil *File) error { return fil.WriteByte('X') },
+			"Xile3",
+		},
+		{
+			"WriteAt",
+			func(fil *File) error {
+				_, err := fil.WriteAt([]byte("XY"), 4)
+				return err
+			},
+			"fileXY",
+		},
+		{
+			"WriteString",
+			func(fil *File) error {
+				_, err := fil.WriteString("abc")
+				return err
+			},
+			"abce3",
+		},
+		{
+			"ReadFrom",
+			func(fil *File) error {
+				_, err := fil.ReadFrom(strings.NewReader("abcdefg"))
+				return err
+			},
+			"abcdefg",
+		},
+		{
+			"Truncate",
+			func(fil *File) error { return fil.Truncate(2) },
+			"fi",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			dir := tstDirMem()
+			dst := t.TempDir()
+			must.Nil(dir.MirrorTo(dst))
+			fil := must.Value(open(dir, "sub/file3"))
+
+			// --- When ---
+			err := tc.mod(fil)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, readOS(t, filepath.Join(dst, "sub/file3")))
+		})
+	}
+
+	t.Run("AddFile", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+		sub := MustDirectory("new")
+		must.Nil(sub.AddFile(MustFileWith("file", []byte("abc"))))
+
+		// --- When ---
+		err := must.Value(open(dir, "sub")).AddFile(sub)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", readOS(t, filepath.Join(dst, "sub/new/file")))
+	})
+
+	t.Run("Exchange", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+
+		// --- When ---
+		err := dir.Exchange("sub/sub2", "file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file0", readOS(t, filepath.Join(dst, "sub/sub2")))
+		assert.Equal(t, "file5", readOS(t, filepath.Join(dst, "file0/file5")))
+		have := must.Value(list(os.DirFS(dst)))
+		assert.Equal(t, must.Value(dir.List()), have)
+	})
+
+	t.Run("rejected by the content scanner", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithContentScanner(secretScanner))
+		fil := MustFileWith("file", []byte("abc"))
+		must.Nil(dir.AddFile(fil))
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+		must.Value(fil.Write([]byte("secret")))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.ErrorIs(t, errSecret, err)
+		assert.Equal(t, "abc", readOS(t, filepath.Join(dst, "file")))
+	})
+
+	t.Run("mirror set on a subdirectory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(must.Value(open(dir, "sub")).MirrorTo(dst))
+
+		// --- When ---
+		must.Value(must.Value(open(dir, "file0")).Write([]byte("X")))
+		must.Value(must.Value(open(dir, "sub/sub2/file6")).Write([]byte("X")))
+
+		// --- Then ---
+		assert.Equal(t, "Xile6", readOS(t, filepath.Join(dst, "sub2/file6")))
+		_, err := os.Stat(filepath.Join(dst, "file0"))
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("error - os", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+		must.Nil(os.RemoveAll(filepath.Join(dst, "sub")))
+		fil := must.Value(open(dir, "sub/file3"))
+
+		// --- When ---
+		n, err := fil.Write([]byte("X"))
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, "Xile3", string(fil.buf))
+	})
+}
+
+func Test_File_WriteToDisk(t *testing.T) {
+	t.Run("writes the tree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := filepath.Join(t.TempDir(), "disk")
+
+		// --- When ---
+		err := dir.WriteToDisk(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(list(os.DirFS(dst)))
+		assert.Equal(t, must.Value(dir.List()), have)
+		have = readOS(t, filepath.Join(dst, "sub/sub2/file5"))
+		assert.Equal(t, "file5", have)
+		assert.Equal(t, "", dir.mirror)
+	})
+
+	t.Run("permissions", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.MkdirAll("ro", 0500))
+		must.Nil(dir.MkdirAll("pub", 0777))
+		fil := MustFileWith("bin", []byte("abc"), WithFileMode(0755))
+		must.Nil(must.Value(open(dir, "ro")).AddFile(fil))
+		must.Nil(dir.WriteFile("pub/file", nil, 0666))
+		dst := t.TempDir()
+		t.Cleanup(func() { _ = os.Chmod(filepath.Join(dst, "ro"), 0700) })
+
+		// --- When ---
+		err := dir.WriteToDisk(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		info := must.Value(os.Stat(filepath.Join(dst, "ro")))
+		assert.Equal(t, fs.ModeDir|0500, info.Mode())
+		info = must.Value(os.Stat(filepath.Join(dst, "ro/bin")))
+		assert.Equal(t, fs.FileMode(0755), info.Mode())
+		assert.Equal(t, "abc", readOS(t, filepath.Join(dst, "ro/bin")))
+		info = must.Value(os.Stat(filepath.Join(dst, "pub")))
+		assert.Equal(t, fs.ModeDir|0777, info.Mode())
+		info = must.Value(os.Stat(filepath.Join(dst, "pub/file")))
+		assert.Equal(t, fs.FileMode(0666), info.Mode())
+	})
+
+	t.Run("symbolic link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub/file3", "link"))
+		dst := t.TempDir()
+
+		// --- When ---
+		err := dir.WriteToDisk(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(os.Readlink(filepath.Join(dst, "link")))
+		assert.Equal(t, "sub/file3", have)
+		assert.Equal(t, "file3", readOS(t, filepath.Join(dst, "link")))
+	})
+
+	t.Run("modifications are not written", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.WriteToDisk(dst))
+
+		// --- When ---
+		err := dir.WriteFile("file0", []byte("changed"), 0644)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file0", readOS(t, filepath.Join(dst, "file0")))
+	})
+
+	t.Run("lazy file stays unloaded", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		dir := NewRoot()
+		fil := lazyFile([]byte("abc"), &loads)
+		must.Nil(dir.AddFile(fil))
+		dst := t.TempDir()
+
+		// --- When ---
+		err := dir.WriteToDisk(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", readOS(t, filepath.Join(dst, "file")))
+		assert.True(t, fil.unloaded())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.WriteToDisk(t.TempDir())
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "writetodisk", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - os", func(t *testing.T) {
+		// --- Given ---
+		dst := filepath.Join(t.TempDir(), "file")
+		must.Nil(os.WriteFile(dst, nil, 0600))
+
+		// --- When ---
+		err := tstDirMem().WriteToDisk(dst)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+}
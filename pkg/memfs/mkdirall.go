@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// MkdirAll creates the directory with the given name in the directory tree,
+// along with any missing parents, like [os.MkdirAll] does. The created
+// directories get the permission bits of the perm. When the directory
+// already exists, it does nothing and returns nil.
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.ENOTDIR] when the instance is not a directory or the name goes
+//     through a regular file,
+//   - [fs.ErrInvalid] when the name is not valid,
+//   - [syscall.EEXIST] when the name refers to a regular file,
+//
+// and the errors returned by [File.AddFile].
+func (fil *File) MkdirAll(name string, perm fs.FileMode) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "mkdir",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	name = fil.lenientPath(name)
+	if !fs.ValidPath(name) {
+		return fil.hookErr(&fs.PathError{
+			Op:   "mkdir",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		})
+	}
+
+	if ent, err := open(fil, name); err == nil {
+		if ent.IsDir() {
+			return nil
+		}
+		return fil.hookErr(&fs.PathError{
+			Op:   "mkdir",
+			Path: name,
+			Err:  syscall.EEXIST,
+		})
+	}
+	_, err := mkdirAll(fil, name, WithFileMode(perm))
+	return err
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_MkdirAll(t *testing.T) {
+	t.Run("creates hierarchy", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.MkdirAll("sub/a/b", 0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		a := must.Value(open(dir, "sub/a"))
+		assert.Equal(t, fs.ModeDir|0755, a.Mode())
+		b := must.Value(open(dir, "sub/a/b"))
+		assert.Equal(t, fs.ModeDir|0755, b.Mode())
+		assert.Same(t, a, b.parent)
+	})
+
+	t.Run("existing directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub2 := must.Value(open(dir, "sub/sub2"))
+
+		// --- When ---
+		err := dir.MkdirAll("sub/sub2", 0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, sub2, must.Value(open(dir, "sub/sub2")))
+		assert.Equal(t, fs.ModeDir|0700, sub2.Mode())
+		assert.Len(t, 2, sub2.entries)
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.MkdirAll(".", 0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 4, dir.entries)
+	})
+
+	t.Run("lenient paths", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithLenientPaths)
+
+		// --- When ---
+		err := dir.MkdirAll("./a/b/", 0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, must.Value(open(dir, "a/b")).IsDir())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").MkdirAll("dir", 0755)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "mkdir", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		for _, name := range []string{"../dir", "/dir", "dir/", ""} {
+			// --- When ---
+			err := tstDirMem().MkdirAll(name, 0755)
+
+			// --- Then ---
+			var e *fs.PathError
+			assert.ErrorAs(t, &e, err)
+			assert.Equal(t, "mkdir", e.Op)
+			assert.Equal(t, name, e.Path)
+			assert.Equal(t, fs.ErrInvalid, e.Err)
+		}
+	})
+
+	t.Run("error - file blocks the path", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().MkdirAll("sub/file3", 0755)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "mkdir", e.Op)
+		assert.Equal(t, "sub/file3", e.Path)
+		assert.Equal(t, syscall.EEXIST, e.Err)
+		assert.ErrorIs(t, fs.ErrExist, err)
+	})
+
+	t.Run("error - path through file", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().MkdirAll("file0/dir", 0755)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithReadOnly)
+
+		// --- When ---
+		err := dir.MkdirAll("a/b", 0755)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Len(t, 0, dir.entries)
+	})
+}
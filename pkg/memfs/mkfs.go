@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// MkFS returns a new root directory with the tree described by the map of
+// paths to file contents, for example:
+//
+//	root, err := MkFS(map[string][]byte{
+//	    "a/b/c.txt": []byte("content"),
+//	    "a/empty/":  nil,
+//	})
+//
+// The intermediate directories are created automatically, the paths ending
+// with a slash represent empty directories, and their contents are ignored.
+// The contents are copied. The options are applied to the root directory.
+//
+// Returns an error of the [fs.PathError] type with [fs.ErrInvalid] when a
+// path is not valid and with [syscall.ENOTDIR] when a path goes through a
+// file or is both a file and a directory.
+func MkFS(files map[string][]byte, opts ...func(*File)) (*File, error) {
+	root := NewRoot(opts...)
+	for _, key := range slices.Sorted(maps.Keys(files)) {
+		name := strings.TrimSuffix(key, "/")
+		if !fs.ValidPath(name) || name == "." {
+			return nil, &fs.PathError{
+				Op:   "mkfs",
+				Path: key,
+				Err:  fs.ErrInvalid,
+			}
+		}
+
+		if strings.HasSuffix(key, "/") {
+			if _, err := mkdirAll(root, name); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		dir, err := mkdirAll(root, path.Dir(name))
+		if err != nil {
+			return nil, err
+		}
+		fil, err := FileWith(path.Base(name), slices.Clone(files[key]))
+		if err != nil {
+			return nil, err
+		}
+		if err = dir.AddFile(fil); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_MkFS(t *testing.T) {
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		files := map[string][]byte{
+			"file0":       []byte("file0"),
+			"a/b/file1":   []byte("file1"),
+			"a/file2":     []byte("file2"),
+			"a/empty/":    nil,
+			"a/b/":        nil,
+			"c/d/e/file3": []byte("file3"),
+		}
+
+		// --- When ---
+		have, err := MkFS(files, WithLazyCache)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.keep)
+		var paths []string
+		have.walk("", func(pth string, ent *File) {
+			if ent.IsDir() {
+				pth += "/"
+			}
+			paths = append(paths, pth)
+		})
+		want := []string{
+			"a/",
+			"a/b/",
+			"a/b/file1",
+			"a/empty/",
+			"a/file2",
+			"c/",
+			"c/d/",
+			"c/d/e/",
+			"c/d/e/file3",
+			"file0",
+		}
+		assert.Equal(t, want, paths)
+		fil := must.Value(open(have, "a/b/file1"))
+		assert.Equal(t, []byte("file1"), fil.buf)
+		assert.Equal(t, fs.FileMode(0600), fil.Mode())
+	})
+
+	t.Run("content is copied", func(t *testing.T) {
+		// --- Given ---
+		content := []byte("abc")
+
+		// --- When ---
+		have, err := MkFS(map[string][]byte{"file": content})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		content[0] = 'x'
+		assert.Equal(t, []byte("abc"), must.Value(open(have, "file")).buf)
+	})
+
+	t.Run("empty map", func(t *testing.T) {
+		// --- When ---
+		have, err := MkFS(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.IsDir())
+		assert.Len(t, 0, have.entries)
+	})
+
+	t.Run("error - invalid path", func(t *testing.T) {
+		for _, key := range []string{"../file", "/file", "a//b", ".", "/"} {
+			// --- When ---
+			have, err := MkFS(map[string][]byte{key: nil})
+
+			// --- Then ---
+			var e *fs.PathError
+			assert.ErrorAs(t, &e, err)
+			assert.Equal(t, "mkfs", e.Op)
+			assert.Equal(t, key, e.Path)
+			assert.Equal(t, fs.ErrInvalid, e.Err)
+			assert.Nil(t, have)
+		}
+	})
+
+	t.Run("error - path through file", func(t *testing.T) {
+		// --- Given ---
+		files := map[string][]byte{"a": nil, "a/b": nil}
+
+		// --- When ---
+		have, err := MkFS(files)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - file and directory", func(t *testing.T) {
+		// --- Given ---
+		files := map[string][]byte{"a": nil, "a/": nil}
+
+		// --- When ---
+		have, err := MkFS(files)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Nil(t, have)
+	})
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"slices"
+	"syscall"
+)
+
+// ErrMapWrite is returned when the memory returned by [File.Mmap] in the
+// [MapReadOnly] mode was modified.
+var ErrMapWrite = errors.New("write to read-only mapping")
+
+// MapMode represents the [File.Mmap] mapping mode.
+type MapMode int
+
+const (
+	// MapShared maps the file content directly. Changes made through the
+	// mapping are visible in the file (PROT_WRITE, MAP_SHARED).
+	MapShared MapMode = iota
+
+	// MapReadOnly maps a write-protected copy of the file content. The file
+	// content cannot be changed through the mapping, and changes made to the
+	// mapping are reported when it is unmapped (PROT_READ).
+	MapReadOnly
+
+	// MapPrivate maps a copy-on-write copy of the file content. Changes made
+	// through the mapping are not visible in the file (MAP_PRIVATE).
+	MapPrivate
+)
+
+// Mmap emulates mapping the file content into memory. It returns the mapped
+// memory and the function unmapping it, which must be called when the mapping
+// is no longer used. The length of the mapped memory is equal to the file
+// size at the time of the call. Calling the unmap function more than once
+// returns an error.
+//
+// In the [MapShared] mode, the mapping shares memory with the file, so it is
+// not safe to change the file size while the mapping is in use. The unmap
+// function updates the file state after the changes made through the mapping,
+// for example, the checksum maintained in the [WithFileChecksum] mode. In the
+// [MapReadOnly] mode, the unmap function returns an error of the
+// [fs.PathError] type with [ErrMapWrite] when the mapped memory was modified.
+//
+// Returns an error of the [fs.PathError] type with [syscall.EISDIR] when the
+// file represents a directory, [syscall.EINVAL] for unknown mode and
+// [syscall.EPERM] when the [MapShared] mode is not permitted by the file
+// attribute flags.
+func (fil *File) Mmap(mode MapMode) ([]byte, func() error, error) {
+	var err error
+	switch {
+	case fil.IsDir():
+		err = syscall.EISDIR
+	case mode < MapShared || mode > MapPrivate:
+		err = syscall.EINVAL
+	}
+	if err != nil {
+		return nil, nil, fil.hookErr(&fs.PathError{
+			Op:   "mmap",
+			Path: fil.path(),
+			Err:  err,
+		})
+	}
+
+	if err = fil.load(); err != nil {
+		return nil, nil, err
+	}
+
+	var mem []byte
+	var unmap func() error
+	switch mode {
+	case MapShared:
+		if err = fil.checkAttr("mmap"); err != nil {
+			return nil, nil, err
+		}
+		if err = fil.own(); err != nil {
+			return nil, nil, err
+		}
+		fil.snapshot()
+		fil.materialize()
+		mem = fil.buf[:len(fil.buf):len(fil.buf)]
+		unmap = func() error {
+			fil.updateSum()
+			return nil
+		}
+
+	case MapReadOnly:
+		mem = fil.mapCopy()
+		orig := slices.Clone(mem)
+		unmap = func() error {
+			if !bytes.Equal(orig, mem) {
+				return fil.hookErr(&fs.PathError{
+					Op:   "munmap",
+					Path: fil.path(),
+					Err:  ErrMapWrite,
+				})
+			}
+			return nil
+		}
+
+	default:
+		mem = fil.mapCopy()
+		unmap = func() error { return nil }
+	}
+
+	var unmapped bool
+	return mem, func() error {
+		if unmapped {
+			return fil.hookErr(&fs.PathError{
+				Op:   "munmap",
+				Path: fil.path(),
+				Err:  syscall.EINVAL,
+			})
+		}
+		unmapped = true
+		return unmap()
+	}, nil
+}
+
+// mapCopy returns a copy of the file content.
+func (fil *File) mapCopy() []byte {
+	if fil.stub {
+		return makeSlice(fil.Len())
+	}
+	return slices.Clone(fil.buf[:len(fil.buf):len(fil.buf)])
+}
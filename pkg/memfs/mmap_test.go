@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Mmap(t *testing.T) {
+	t.Run("shared", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2})
+
+		// --- When ---
+		mem, unmap, err := fil.Mmap(MapShared)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2}, mem)
+		assert.Equal(t, 3, cap(mem))
+		mem[0] = 42
+		assert.NoError(t, unmap())
+		assert.Equal(t, []byte{42, 1, 2}, fil.buf)
+	})
+
+	t.Run("shared updates checksum", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2}, WithFileChecksum)
+		mem, unmap := must.Values(fil.Mmap(MapShared))
+
+		// --- When ---
+		mem[0] = 42
+		err := unmap()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.NoError(t, fil.VerifyChecksum())
+	})
+
+	t.Run("shared stub", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2})
+
+		// --- When ---
+		mem, _, err := fil.Mmap(MapShared)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 0}, mem)
+		assert.False(t, fil.stub)
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2})
+
+		// --- When ---
+		mem, unmap, err := fil.Mmap(MapReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2}, mem)
+		assert.NoError(t, unmap())
+	})
+
+	t.Run("read only stub", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2})
+
+		// --- When ---
+		mem, _, err := fil.Mmap(MapReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 0}, mem)
+		assert.True(t, fil.stub)
+	})
+
+	t.Run("read only immutable file", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0}, WithFileAttr(AttrImmutable))
+
+		// --- When ---
+		mem, _, err := fil.Mmap(MapReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0}, mem)
+	})
+
+	t.Run("error - read only write", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2})
+		mem, unmap := must.Values(fil.Mmap(MapReadOnly))
+
+		// --- When ---
+		mem[0] = 42
+		err := unmap()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "munmap", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, ErrMapWrite, e.Err)
+		assert.Equal(t, []byte{0, 1, 2}, fil.buf)
+	})
+
+	t.Run("private", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2})
+
+		// --- When ---
+		mem, unmap, err := fil.Mmap(MapPrivate)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		mem[0] = 42
+		assert.NoError(t, unmap())
+		assert.Equal(t, []byte{0, 1, 2}, fil.buf)
+	})
+
+	t.Run("error - unmap twice", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2})
+		_, unmap := must.Values(fil.Mmap(MapPrivate))
+		must.Nil(unmap())
+
+		// --- When ---
+		err := unmap()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "munmap", e.Op)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("dir")
+
+		// --- When ---
+		mem, unmap, err := dir.Mmap(MapReadOnly)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "mmap", e.Op)
+		assert.Equal(t, "dir", e.Path)
+		assert.Equal(t, syscall.EISDIR, e.Err)
+		assert.Nil(t, mem)
+		assert.Nil(t, unmap)
+	})
+
+	t.Run("error - invalid mode", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		mem, unmap, err := fil.Mmap(MapMode(42))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		assert.Nil(t, mem)
+		assert.Nil(t, unmap)
+	})
+
+	t.Run("error - shared immutable file", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0}, WithFileAttr(AttrImmutable))
+
+		// --- When ---
+		mem, unmap, err := fil.Mmap(MapShared)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "mmap", e.Op)
+		assert.Equal(t, syscall.EPERM, e.Err)
+		assert.Nil(t, mem)
+		assert.Nil(t, unmap)
+	})
+}
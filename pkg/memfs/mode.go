@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"slices"
+	"syscall"
+)
+
+// WithFileMode is a [File] constructor function option setting the
+// permission bits, for example, 0755 for executables. Other bits of the mode
+// are ignored.
+func WithFileMode(mode fs.FileMode) func(*File) {
+	return func(fil *File) {
+		fil.info.mode = fil.info.mode.Type() | mode.Perm()
+	}
+}
+
+// Chmod changes the permission bits to the permission bits of the mode. Like
+// with [os.File.Chmod] on Unix, other bits of the mode are ignored. In the
+// write-through mode (see [File.MirrorTo]), the mode is also changed in the
+// OS file system.
+//
+// Returns an error of the [fs.PathError] type with [syscall.EPERM] when the
+// attribute flags (see [Attr]) are set, with [syscall.EROFS] when the file is
+// read-only (see [WithReadOnly]), and the errors of the [os] package in the
+// write-through mode.
+func (fil *File) Chmod(mode fs.FileMode) error {
+	if err := fil.checkReadOnly("chmod"); err != nil {
+		return err
+	}
+	if fil.attr != 0 {
+		return fil.hookErr(&fs.PathError{
+			Op:   "chmod",
+			Path: fil.path(),
+			Err:  syscall.EPERM,
+		})
+	}
+	WithFileMode(mode)(fil)
+	if pth := fil.mirrorPath(); pth != "" {
+		return os.Chmod(pth, fil.Mode().Perm())
+	}
+	return nil
+}
+
+// ChmodAt changes the permission bits of the file with the given name in the
+// directory tree, like [os.Chmod] does, see [File.Chmod] for details. The
+// symbolic links are followed.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, with [fs.ErrInvalid] when the name is not
+// valid, and with [fs.ErrNotExist] when the file does not exist, and the
+// errors returned by [File.Chmod].
+func (fil *File) ChmodAt(name string, mode fs.FileMode) error {
+	ent, err := fil.target("chmod", name)
+	if err != nil {
+		return err
+	}
+	return ent.Chmod(mode)
+}
+
+// target returns the file with the given name in the directory tree,
+// following the symbolic links. The op is used as the operation name in the
+// returned errors.
+func (fil *File) target(op, name string) (*File, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   op,
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	name = fil.lenientPath(name)
+	ent, err := open(fil, name)
+	if err != nil {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   op,
+			Path: name,
+			Err:  unwrapPathErr(err),
+		})
+	}
+	return ent, nil
+}
+
+// Executables returns the sorted paths of all regular files in the directory
+// tree with any of the execute permission bits set. Paths are relative to the
+// directory.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory.
+func (fil *File) Executables() ([]string, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "executables",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	var matches []string
+	fil.walk("", func(pth string, ent *File) {
+		if ent.Mode().IsRegular() && ent.Mode().Perm()&0111 != 0 {
+			matches = append(matches, pth)
+		}
+	})
+	slices.Sort(matches)
+	return matches, nil
+}
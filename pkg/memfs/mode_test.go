@@ -0,0 +1,272 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithFileMode(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- When ---
+		have := MustFile("file", WithFileMode(0755))
+
+		// --- Then ---
+		assert.Equal(t, fs.FileMode(0755), have.Mode())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		// --- When ---
+		have := must.Value(NewDirectory("dir", WithFileMode(0750)))
+
+		// --- Then ---
+		assert.Equal(t, fs.ModeDir|0750, have.Mode())
+	})
+
+	t.Run("not permission bits are ignored", func(t *testing.T) {
+		// --- When ---
+		have := MustFile("file", WithFileMode(fs.ModeSymlink|0700))
+
+		// --- Then ---
+		assert.Equal(t, fs.FileMode(0700), have.Mode())
+	})
+}
+
+func Test_File_Chmod(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.Chmod(0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0755), fil.Mode())
+		assert.True(t, fil.Mode().IsRegular())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("dir")
+
+		// --- When ---
+		err := dir.Chmod(os.ModeSetuid | 0500)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, fs.ModeDir|0500, dir.Mode())
+	})
+
+	t.Run("write-through mode", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+
+		// --- When ---
+		err := must.Value(open(dir, "sub/file3")).Chmod(0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fi := must.Value(os.Stat(filepath.Join(dst, "sub/file3")))
+		assert.Equal(t, fs.FileMode(0755), fi.Mode())
+	})
+
+	t.Run("error - attributes", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithFileAttr(AttrAppendOnly))
+
+		// --- When ---
+		err := fil.Chmod(0755)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "chmod", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.EPERM, e.Err)
+		assert.Equal(t, fs.FileMode(0600), fil.Mode())
+	})
+}
+
+func Test_File_ChmodAt(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.ChmodAt("sub/file3", 0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fi := must.Value(fs.Stat(dir.DirFS(), "sub/file3"))
+		assert.Equal(t, fs.FileMode(0755), fi.Mode())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.ChmodAt("sub/sub2", 0500)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fi := must.Value(must.Value(open(dir, "sub/sub2")).Stat())
+		assert.Equal(t, fs.ModeDir|0500, fi.Mode())
+	})
+
+	t.Run("follows links", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("file0", "link"))
+
+		// --- When ---
+		err := dir.ChmodAt("link", 0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(open(dir, "file0"))
+		assert.Equal(t, fs.FileMode(0755), have.Mode())
+	})
+
+	t.Run("error - attributes", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(open(dir, "sub/file3")).SetAttr(AttrImmutable)
+
+		// --- When ---
+		err := dir.ChmodAt("sub/file3", 0755)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "chmod", e.Op)
+		assert.Equal(t, "sub/file3", e.Path)
+		assert.Equal(t, syscall.EPERM, e.Err)
+	})
+}
+
+func Test_File_target(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.target("op", "sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, must.Value(open(dir, "sub/file3")), have)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		have, err := MustFile("file").target("op", "name")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "op", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().target("op", "../file0")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "op", e.Op)
+		assert.Equal(t, "../file0", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().target("op", "sub/not-existing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "op", e.Op)
+		assert.Equal(t, "sub/not-existing", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_File_Executables(t *testing.T) {
+	t.Run("executables", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(must.Value(open(dir, "file1")).Chmod(0755))
+		must.Nil(must.Value(open(dir, "sub/sub2/file5")).Chmod(0710))
+		must.Nil(must.Value(open(dir, "sub/file4")).Chmod(0644))
+
+		// --- When ---
+		have, err := dir.Executables()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"file1", "sub/sub2/file5"}, have)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.Executables()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("tar round trip", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(must.Value(open(dir, "sub/file3")).Chmod(0755))
+		buf := &bytes.Buffer{}
+		must.Nil(dir.WriteTar(buf))
+		tree := must.Value(FromTar(buf))
+
+		// --- When ---
+		have, err := tree.Executables()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sub/file3"}, have)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithFileMode(0755))
+
+		// --- When ---
+		have, err := fil.Executables()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "executables", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+}
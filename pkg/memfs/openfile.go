@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"syscall"
+)
+
+// OpenFile opens the file with the given name in the directory tree, like
+// [os.OpenFile] does. With the [os.O_CREATE] flag, a missing file is created
+// with the permission bits of the perm, but its parent directory must exist.
+// With [os.O_CREATE] and [os.O_EXCL], the file must not exist. With the
+// [os.O_TRUNC] flag and the [os.O_WRONLY] or [os.O_RDWR] access mode, the
+// file is truncated.
+//
+// The returned [Handle] has its own offset, starting at zero, and flags, so
+// opening the file doesn't affect its other handles. The flags of the file
+// set with [WithFileFlag] don't apply to the handle, and the access mode is
+// enforced. The [OpenShareDelete] flag allows removing and renaming the file
+// while the handle is open (see [WithSharingViolations]).
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.ENOTDIR] when the instance is not a directory or, with
+//     [os.O_CREATE], the parent of the file is a regular file,
+//   - [fs.ErrInvalid] when the name is not valid,
+//   - [fs.ErrNotExist] when the file or, with [os.O_CREATE], its parent
+//     directory does not exist,
+//   - [syscall.EEXIST] when the file exists and the [os.O_CREATE] and
+//     [os.O_EXCL] flags are set,
+//   - [syscall.EISDIR] when the name refers to a directory and the access
+//     mode is not [os.O_RDONLY] or the [os.O_TRUNC] flag is set,
+//
+// and the errors returned by [File.AddFile] and [File.Truncate].
+func (fil *File) OpenFile(
+	name string,
+	flag int,
+	perm fs.FileMode,
+) (*Handle, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "open",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	name = fil.lenientPath(name)
+	if !fs.ValidPath(name) {
+		return nil, fil.openErr(name, fs.ErrInvalid)
+	}
+
+	ent, err := open(fil, name)
+	if err == nil && flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL {
+		return nil, fil.openErr(name, syscall.EEXIST)
+	}
+	if err != nil {
+		if flag&os.O_CREATE == 0 {
+			return nil, fil.openErr(name, unwrapPathErr(err))
+		}
+		if ent, err = fil.create(name, perm); err != nil {
+			return nil, err
+		}
+	}
+
+	write := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	if ent.IsDir() && (write || flag&os.O_TRUNC != 0) {
+		return nil, fil.openErr(name, syscall.EISDIR)
+	}
+	h := &Handle{fil: ent, name: name, flag: flag}
+	if write && flag&os.O_TRUNC != 0 {
+		if err = h.Truncate(0); err != nil {
+			return nil, err
+		}
+	}
+	ent.opened()
+	if flag&OpenShareDelete != 0 {
+		ent.shareDel++
+	}
+	return h, nil
+}
+
+// create creates a regular file with the given name and permission bits for
+// [File.OpenFile].
+func (fil *File) create(name string, perm fs.FileMode) (*File, error) {
+	dir, err := open(fil, path.Dir(name))
+	if err != nil {
+		return nil, fil.openErr(name, unwrapPathErr(err))
+	}
+	if !dir.IsDir() {
+		return nil, fil.openErr(name, syscall.ENOTDIR)
+	}
+	ent, err := NewFile(path.Base(name), WithFileMode(perm))
+	if err != nil {
+		return nil, err
+	}
+	if err = dir.AddFile(ent); err != nil {
+		return nil, err
+	}
+	return ent, nil
+}
+
+// openErr returns an error of the [fs.PathError] type for [File.OpenFile].
+func (fil *File) openErr(name string, err error) error {
+	return fil.hookErr(&fs.PathError{Op: "open", Path: name, Err: err})
+}
+
+// unwrapPathErr returns the Err field of the error when it is of the
+// [fs.PathError] type, otherwise it returns the error.
+func unwrapPathErr(err error) error {
+	var e *fs.PathError
+	if errors.As(err, &e) {
+		return e.Err
+	}
+	return err
+}
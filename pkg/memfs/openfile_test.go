@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_OpenFile(t *testing.T) {
+	t.Run("existing file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "sub/file3"))
+		fil.off = 2
+
+		// --- When ---
+		have, err := dir.OpenFile("sub/file3", os.O_RDONLY, 0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, fil, have.File())
+		assert.Equal(t, "sub/file3", have.Name())
+		assert.Equal(t, "file3", string(must.Value(io.ReadAll(have))))
+		assert.Equal(t, 2, fil.Offset())
+		assert.Equal(t, 1, fil.OpenCount())
+	})
+
+	t.Run("create", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.OpenFile("sub/new", os.O_RDWR|os.O_CREATE, 0640)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, have.File(), must.Value(open(dir, "sub/new")))
+		assert.Equal(t, fs.FileMode(0640), have.File().Mode())
+		assert.Equal(t, 0, have.File().Len())
+	})
+
+	t.Run("create existing", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file0"))
+
+		// --- When ---
+		have, err := dir.OpenFile("file0", os.O_RDWR|os.O_CREATE, 0640)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, fil, have.File())
+		assert.Equal(t, fs.FileMode(0600), fil.Mode())
+		assert.Equal(t, "file0", string(fil.buf))
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.OpenFile("file0", os.O_WRONLY|os.O_TRUNC, 0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, have.File().Len())
+	})
+
+	t.Run("truncate ignored for read only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.OpenFile("file0", os.O_RDONLY|os.O_TRUNC, 0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 5, have.File().Len())
+	})
+
+	t.Run("append", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.OpenFile("file0", os.O_WRONLY|os.O_APPEND, 0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		must.Value(have.Write([]byte("X")))
+		fil := must.Value(open(dir, "file0"))
+		assert.Equal(t, "file0X", string(fil.buf))
+		assert.Equal(t, 0, fil.flag&os.O_APPEND)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.OpenFile(".", os.O_RDONLY, 0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, dir, have.File())
+		assert.Len(t, 4, must.Value(have.ReadDir(-1)))
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		have, err := MustFile("file").OpenFile("name", os.O_RDONLY, 0)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "open", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - open", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			name string
+			flag int
+			want error
+		}{
+			{"invalid name", "../file", os.O_RDONLY, fs.ErrInvalid},
+			{"not existing", "sub/missing", os.O_RDONLY, fs.ErrNotExist},
+			{"missing parent", "missing/new", os.O_CREATE, fs.ErrNotExist},
+			{"parent is a file", "file0/new", os.O_CREATE, syscall.ENOTDIR},
+			{
+				"exclusive",
+				"file0",
+				os.O_CREATE | os.O_EXCL | os.O_WRONLY,
+				syscall.EEXIST,
+			},
+			{"directory for write", "sub", os.O_WRONLY, syscall.EISDIR},
+			{"directory truncate", "sub", os.O_TRUNC, syscall.EISDIR},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				dir := tstDirMem()
+
+				// --- When ---
+				have, err := dir.OpenFile(tc.name, tc.flag, 0600)
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "open", e.Op)
+				assert.Equal(t, tc.name, e.Path)
+				assert.Equal(t, tc.want, e.Err)
+				assert.Nil(t, have)
+			})
+		}
+	})
+
+	t.Run("error - create read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithReadOnly)
+
+		// --- When ---
+		have, err := dir.OpenFile("new", os.O_CREATE, 0600)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - truncate immutable", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(open(dir, "file0")).SetAttr(AttrImmutable)
+
+		// --- When ---
+		have, err := dir.OpenFile("file0", os.O_RDWR|os.O_TRUNC, 0)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Nil(t, have)
+	})
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Compile time checks.
+var (
+	_ fs.ReadDirFS  = &OSFS{}
+	_ fs.ReadFileFS = &OSFS{}
+	_ fs.StatFS     = &OSFS{}
+	_ WriteFS       = &OSFS{}
+	_ WriteFS       = &SyncFS{}
+)
+
+// OSFS is a writable file system backed by a directory on the OS file
+// system. Use it with [Chain] to get the tracing, fault injection, quota and
+// other middlewares of the package in integration tests which need a real
+// disk, for example:
+//
+//	fsys := Chain(WrapOS(t.TempDir()), Trace(rec), Quota(1<<20))
+type OSFS struct {
+	dir  string // The directory on the OS file system.
+	fsys fs.FS  // The file system returned by [os.DirFS] for the dir.
+}
+
+// WrapOS returns a new instance of [OSFS] for the directory dir on the OS
+// file system. The directory is not created or checked.
+func WrapOS(dir string) *OSFS {
+	return &OSFS{dir: dir, fsys: os.DirFS(dir)}
+}
+
+// Open implements [fs.FS] interface.
+func (o *OSFS) Open(name string) (fs.File, error) { return o.fsys.Open(name) }
+
+// Stat implements [fs.StatFS] interface.
+func (o *OSFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(o.fsys, name)
+}
+
+// ReadFile implements [fs.ReadFileFS] interface.
+func (o *OSFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(o.fsys, name)
+}
+
+// ReadDir implements [fs.ReadDirFS] interface.
+func (o *OSFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(o.fsys, name)
+}
+
+// WriteFile calls [os.WriteFile] for the name in the directory.
+func (o *OSFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	pth, err := o.path("writefile", name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(pth, data, perm)
+}
+
+// MkdirAll calls [os.MkdirAll] for the name in the directory.
+func (o *OSFS) MkdirAll(name string, perm fs.FileMode) error {
+	pth, err := o.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(pth, perm)
+}
+
+// Remove calls [os.Remove] for the name in the directory.
+func (o *OSFS) Remove(name string) error {
+	pth, err := o.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(pth)
+}
+
+// RemoveAll calls [os.RemoveAll] for the name in the directory.
+func (o *OSFS) RemoveAll(name string) error {
+	pth, err := o.path("removeall", name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(pth)
+}
+
+// Rename calls [os.Rename] for the paths in the directory.
+func (o *OSFS) Rename(oldpath, newpath string) error {
+	src, err := o.path("rename", oldpath)
+	if err != nil {
+		return err
+	}
+	dst, err := o.path("rename", newpath)
+	if err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
+
+// path returns the OS path for the name. Returns an error of the
+// [fs.PathError] type with [fs.ErrInvalid] when the name is not valid or is
+// "." for operations other than mkdir. The op is used as the operation name
+// in the returned error.
+func (o *OSFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) || (name == "." && op != "mkdir") {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(o.dir, filepath.FromSlash(name)), nil
+}
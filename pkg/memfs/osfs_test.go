@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WrapOS(t *testing.T) {
+	t.Run("passes fstest", func(t *testing.T) {
+		// --- Given ---
+		fsys := WrapOS(tstDirOS(t))
+
+		// --- When ---
+		err := fstest.TestFS(fsys, "file0", "sub/file3", "sub/sub2/file6")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{"fs.ReadDirFS", "fs.ReadFileFS", "fs.StatFS"}
+		assert.Equal(t, want, Capabilities(fsys))
+	})
+
+	t.Run("modifies the directory", func(t *testing.T) {
+		// --- Given ---
+		dir := t.TempDir()
+		fsys := WrapOS(dir)
+
+		// --- When ---
+		must.Nil(fsys.MkdirAll("a/b", 0700))
+		must.Nil(fsys.WriteFile("a/b/file", []byte("abc"), 0600))
+		must.Nil(fsys.Rename("a/b/file", "a/moved"))
+		must.Nil(fsys.WriteFile("a/b/other", nil, 0600))
+		must.Nil(fsys.Remove("a/b/other"))
+
+		// --- Then ---
+		assert.Equal(t, "abc", readOS(t, filepath.Join(dir, "a/moved")))
+		ets := must.Value(os.ReadDir(filepath.Join(dir, "a/b")))
+		assert.Len(t, 0, ets)
+		must.Nil(fsys.RemoveAll("a"))
+		_, err := os.Stat(filepath.Join(dir, "a"))
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("with middlewares", func(t *testing.T) {
+		// --- Given ---
+		var evs []Event
+		rec := func(ev Event) { evs = append(evs, ev) }
+		fsys := Chain(WrapOS(t.TempDir()), Trace(rec), Quota(4))
+		wfs := fsys.(WriteFS)
+
+		// --- When ---
+		err0 := wfs.WriteFile("file0", []byte("abc"), 0600)
+		err1 := wfs.WriteFile("file1", []byte("abc"), 0600)
+
+		// --- Then ---
+		assert.NoError(t, err0)
+		assert.ErrorIs(t, syscall.ENOSPC, err1)
+		assert.Len(t, 2, evs)
+		assert.Equal(t, Event{Op: "writefile", Name: "file0"}, evs[0])
+	})
+
+	t.Run("error - invalid names", func(t *testing.T) {
+		// --- Given ---
+		fsys := WrapOS(t.TempDir())
+
+		// --- When ---
+		errs := []error{
+			fsys.WriteFile("../file", nil, 0600),
+			fsys.MkdirAll("/dir", 0700),
+			fsys.Remove("."),
+			fsys.RemoveAll("."),
+			fsys.Rename("a", "../b"),
+			fsys.Rename("", "b"),
+		}
+
+		// --- Then ---
+		for _, err := range errs {
+			assert.ErrorIs(t, fs.ErrInvalid, err)
+		}
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- Given ---
+		fsys := WrapOS(t.TempDir())
+
+		// --- When ---
+		err := fsys.Remove("not-existing")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+}
@@ -0,0 +1,571 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/tar"
+	"cmp"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// Names of the whiteout files in the OCI image layers, see
+// [Overlay.WriteLayer].
+const (
+	whiteoutPrefix = ".wh."         // Prefix of the removed file names.
+	whiteoutOpaque = ".wh..wh..opq" // Marks the opaque directory.
+)
+
+// Compile time checks.
+var (
+	_ fs.ReadDirFS   = &Overlay{}
+	_ fs.ReadFileFS  = &Overlay{}
+	_ fs.StatFS      = &Overlay{}
+	_ WriteFS        = &Overlay{}
+	_ fs.ReadDirFile = &overlayDir{}
+)
+
+// Overlay is a writable file system layering an in-memory directory tree on
+// top of a read-only base file system, like the union mounts do. The reads
+// fall through to the base unless the file was written, created or removed
+// through the overlay, and the writes land in the in-memory upper layer
+// only, so the base is never modified. It's the way to patch an [embed.FS]
+// or any other read-only [fs.FS] in a test, for example:
+//
+//	fsys := NewOverlay(assets)
+//	err := fsys.WriteFile("config.yaml", data, 0644)
+//
+// The files are copied up from the base to the upper layer when they are
+// modified or renamed, along with their parent directories, which keep the
+// permission bits they have in the base. The directory listings merge both
+// layers, and the files removed from the base are remembered as whiteouts,
+// see [Overlay.Whiteouts] and [Overlay.WriteLayer]. The symbolic links are
+// resolved within the layer they are in. Like [File], the overlay is not
+// safe for concurrent use.
+type Overlay struct {
+	base  fs.FS           // The read-only base layer.
+	upper *File           // The root of the writable upper layer.
+	gone  map[string]bool // Paths hidden in the base layer.
+}
+
+// NewOverlay returns a new instance of [Overlay] with an empty upper layer
+// on top of the base. The options are applied to the root directory of the
+// upper layer, see [Overlay.Upper].
+func NewOverlay(base fs.FS, opts ...func(*File)) *Overlay {
+	return &Overlay{
+		base:  base,
+		upper: NewRoot(opts...),
+		gone:  make(map[string]bool),
+	}
+}
+
+// Upper returns the root directory of the upper layer with the files
+// written, created or copied up through the overlay, so a test can check
+// what was changed.
+func (o *Overlay) Upper() *File { return o.upper }
+
+// Whiteouts returns the sorted paths of the files and directories hidden in
+// the base layer because they were removed or replaced through the overlay.
+// See [Overlay.WriteLayer] for the way they are written to the image layers.
+func (o *Overlay) Whiteouts() []string {
+	return slices.Sorted(maps.Keys(o.gone))
+}
+
+// Open implements [fs.FS] interface. The directories are opened with the
+// merged listing of both layers.
+func (o *Overlay) Open(name string) (fs.File, error) {
+	info, err := o.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		ets, err := o.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		return &overlayDir{name: name, info: info, ets: ets}, nil
+	}
+	if o.inUpper(name) {
+		return dirFS{dir: o.upper}.Open(name)
+	}
+	return o.base.Open(name)
+}
+
+// Stat implements [fs.StatFS] interface.
+func (o *Overlay) Stat(name string) (fs.FileInfo, error) {
+	return o.stat("stat", name)
+}
+
+// ReadFile implements [fs.ReadFileFS] interface.
+func (o *Overlay) ReadFile(name string) ([]byte, error) {
+	if err := o.check("readfile", name); err != nil {
+		return nil, err
+	}
+	if o.inUpper(name) {
+		return dirFS{dir: o.upper}.ReadFile(name)
+	}
+	if o.hidden(name) {
+		return nil, o.err("open", name, fs.ErrNotExist)
+	}
+	return fs.ReadFile(o.base, name)
+}
+
+// ReadDir implements [fs.ReadDirFS] interface. The entries of both layers
+// are returned in the name order, the upper layer entries taking precedence
+// over the base layer entries with the same names.
+func (o *Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	info, err := o.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, o.err("readdir", name, syscall.ENOTDIR)
+	}
+
+	ets := make(map[string]fs.DirEntry)
+	if o.inUpper(name) {
+		ups, err := dirFS{dir: o.upper}.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ent := range ups {
+			ets[ent.Name()] = ent
+		}
+	}
+	if !o.hidden(name) {
+		bes, err := fs.ReadDir(o.base, name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, ent := range bes {
+			if _, ok := ets[ent.Name()]; ok {
+				continue
+			}
+			if !o.gone[path.Join(name, ent.Name())] {
+				ets[ent.Name()] = ent
+			}
+		}
+	}
+	have := slices.Collect(maps.Values(ets))
+	slices.SortFunc(have, func(a, b fs.DirEntry) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+	return have, nil
+}
+
+// WriteFile writes data to the file with the given name in the upper layer,
+// like [os.WriteFile] does. Unlike [File.WriteFile], the parent directory
+// must exist in one of the layers. The file existing only in the base keeps
+// its permission bits.
+func (o *Overlay) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return o.err("open", name, fs.ErrInvalid)
+	}
+	if dir := path.Dir(name); dir != "." {
+		info, err := o.stat("open", dir)
+		if err != nil {
+			return o.err("open", name, fs.ErrNotExist)
+		}
+		if !info.IsDir() {
+			return o.err("open", name, syscall.ENOTDIR)
+		}
+		if err = o.mkdirAll("open", dir, 0); err != nil {
+			return err
+		}
+	}
+	if !o.inUpper(name) {
+		if info, err := o.stat("open", name); err == nil {
+			if info.IsDir() {
+				return o.err("open", name, syscall.EISDIR)
+			}
+			perm = info.Mode().Perm()
+		}
+	}
+	return o.upper.WriteFile(name, data, perm)
+}
+
+// MkdirAll creates the directory with the given name along with any missing
+// parents in the upper layer, like [os.MkdirAll] does. Returns an error of
+// the [fs.PathError] type with [syscall.ENOTDIR] when any path element exists
+// in one of the layers and is not a directory.
+func (o *Overlay) MkdirAll(name string, perm fs.FileMode) error {
+	if err := o.check("mkdir", name); err != nil {
+		return err
+	}
+	return o.mkdirAll("mkdir", name, perm)
+}
+
+// Remove removes the file or the empty directory with the given name, like
+// [os.Remove] does. The file is removed from the upper layer and hidden in
+// the base layer. A directory is empty when the merged listing is empty.
+func (o *Overlay) Remove(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return o.err("remove", name, fs.ErrInvalid)
+	}
+	info, err := o.lstat("remove", name)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		ets, err := o.ReadDir(name)
+		if err != nil {
+			return err
+		}
+		if len(ets) > 0 {
+			return o.err("remove", name, syscall.ENOTEMPTY)
+		}
+	}
+	inBase := o.inBase(name)
+	if o.inUpper(name) {
+		if err = o.upper.Remove(name); err != nil {
+			return err
+		}
+	}
+	if inBase {
+		o.gone[name] = true
+	}
+	return nil
+}
+
+// RemoveAll removes the file or the directory with the given name, along
+// with all its children, like [os.RemoveAll] does. It returns nil when the
+// file does not exist.
+func (o *Overlay) RemoveAll(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return o.err("removeall", name, fs.ErrInvalid)
+	}
+	inBase := o.inBase(name)
+	if o.inUpper(name) {
+		if err := o.upper.RemoveAll(name); err != nil {
+			return err
+		}
+	}
+	if inBase {
+		o.gone[name] = true
+	}
+	return nil
+}
+
+// Rename moves the file or the directory, with its subtree, from the oldpath
+// to the newpath, like [File.Rename] does. The oldpath, and the newpath when
+// it exists, are copied up to the upper layer first, so renaming a large
+// base directory copies its whole tree to memory.
+func (o *Overlay) Rename(oldpath, newpath string) error {
+	for _, pth := range []string{oldpath, newpath} {
+		if !fs.ValidPath(pth) || pth == "." {
+			return o.err("rename", pth, fs.ErrInvalid)
+		}
+	}
+	if _, err := o.lstat("rename", oldpath); err != nil {
+		return err
+	}
+	if oldpath == newpath {
+		return nil
+	}
+
+	oldBase, newBase := o.inBase(oldpath), o.inBase(newpath)
+	if err := o.copyUp(oldpath); err != nil {
+		return err
+	}
+	if _, err := o.lstat("rename", newpath); err == nil {
+		if err = o.copyUp(newpath); err != nil {
+			return err
+		}
+	} else if dir := path.Dir(newpath); dir != "." {
+		if info, err := o.stat("rename", dir); err == nil && info.IsDir() {
+			if err = o.mkdirAll("rename", dir, 0); err != nil {
+				return err
+			}
+		}
+	}
+	if err := o.upper.Rename(oldpath, newpath); err != nil {
+		return err
+	}
+	if oldBase {
+		o.gone[oldpath] = true
+	}
+	if newBase {
+		o.gone[newpath] = true
+	}
+	return nil
+}
+
+// WriteLayer writes the changes made through the overlay to w as a tar
+// archive in the OCI image layer format, so the container image tooling can
+// be tested against the overlay. The files and directories of the upper
+// layer are written like [File.WriteTar] writes them, and the whiteouts (see
+// [Overlay.Whiteouts]) are written as the empty files:
+//
+//   - the file or directory removed from the base is marked with the
+//     ".wh.<name>" file in its parent directory,
+//   - the directory removed from the base and created again through the
+//     overlay is marked as opaque with the ".wh..wh..opq" file in it, so
+//     none of its base entries shows through,
+//   - the file removed from the base and created again needs no whiteout.
+//
+// The whiteouts inside the removed directories are not written. The parent
+// directories of the whiteouts which are not in the upper layer are written
+// with the permission bits they have in the base. The entries are written in
+// the path order without the modification times, so the same changes always
+// produce the same archive.
+//
+// Returns the errors returned by the base file system, by w and by loading
+// the lazily loaded files.
+func (o *Overlay) WriteLayer(w io.Writer) error {
+	hdrs := make(map[string]*tar.Header)
+	data := make(map[string][]byte)
+	add := func(pth string, ent *File, d []byte) error {
+		pth = filepath.ToSlash(pth)
+		hdrs[pth] = tarHeader(pth, ent, len(d))
+		data[pth] = d
+		return nil
+	}
+	if err := o.upper.export("layer", nil, add); err != nil {
+		return err
+	}
+	for _, pth := range o.Whiteouts() {
+		dir := path.Dir(pth)
+		if o.hidden(dir) {
+			continue // Covered by the whiteout of the parent.
+		}
+		wh := path.Join(dir, whiteoutPrefix+path.Base(pth))
+		if info, err := o.upper.Lstat(pth); err == nil {
+			if !info.IsDir() {
+				continue
+			}
+			wh, dir = path.Join(pth, whiteoutOpaque), pth
+		}
+		hdrs[wh] = &tar.Header{Name: wh, Mode: 0600, Typeflag: tar.TypeReg}
+		for ; dir != "." && hdrs[dir] == nil; dir = path.Dir(dir) {
+			info, err := fs.Stat(o.base, dir)
+			if err != nil {
+				return err
+			}
+			hdrs[dir] = &tar.Header{
+				Name:     dir + "/",
+				Mode:     int64(info.Mode().Perm()),
+				Typeflag: tar.TypeDir,
+			}
+		}
+	}
+
+	tw := tar.NewWriter(w)
+	for _, pth := range slices.Sorted(maps.Keys(hdrs)) {
+		if err := tw.WriteHeader(hdrs[pth]); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data[pth]); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// stat returns the information about the file with the given name, following
+// the symbolic links. The op is used as the operation name in the returned
+// errors.
+func (o *Overlay) stat(op, name string) (fs.FileInfo, error) {
+	if err := o.check(op, name); err != nil {
+		return nil, err
+	}
+	if o.inUpper(name) {
+		return dirFS{dir: o.upper}.Stat(name)
+	}
+	if o.hidden(name) {
+		return nil, o.err(op, name, fs.ErrNotExist)
+	}
+	return fs.Stat(o.base, name)
+}
+
+// lstat works like [Overlay.stat] but doesn't follow the symbolic link.
+func (o *Overlay) lstat(op, name string) (fs.FileInfo, error) {
+	if err := o.check(op, name); err != nil {
+		return nil, err
+	}
+	if o.inUpper(name) {
+		return o.upper.Lstat(name)
+	}
+	if o.hidden(name) {
+		return nil, o.err(op, name, fs.ErrNotExist)
+	}
+	return fs.Lstat(o.base, name)
+}
+
+// inUpper returns true when the file with the given name exists in the
+// upper layer.
+func (o *Overlay) inUpper(name string) bool {
+	_, err := lopen(o.upper, name)
+	return err == nil
+}
+
+// inBase returns true when the file with the given name exists in the base
+// layer and is not hidden.
+func (o *Overlay) inBase(name string) bool {
+	if o.hidden(name) {
+		return false
+	}
+	_, err := fs.Lstat(o.base, name)
+	return err == nil
+}
+
+// hidden returns true when the file with the given name or any of its
+// parents was hidden in the base layer.
+func (o *Overlay) hidden(name string) bool {
+	for pth := name; pth != "."; pth = path.Dir(pth) {
+		if o.gone[pth] {
+			return true
+		}
+	}
+	return false
+}
+
+// mkdirAll creates the directory with the given name and its missing parents
+// in the upper layer. The directories existing only in the base are copied
+// up with their permission bits, and the missing ones are created with the
+// perm.
+func (o *Overlay) mkdirAll(op, name string, perm fs.FileMode) error {
+	if name == "." {
+		return nil
+	}
+	var pth string
+	for _, elem := range strings.Split(name, "/") {
+		pth = path.Join(pth, elem)
+		mode := perm
+		info, err := o.stat(op, pth)
+		switch {
+		case err == nil && !info.IsDir():
+			return o.err(op, pth, syscall.ENOTDIR)
+		case err == nil:
+			mode = info.Mode().Perm()
+		case !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+		if err = o.upper.MkdirAll(pth, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyUp copies the file or the directory tree with the given name from the
+// base layer to the upper layer, skipping the files which are already there
+// and the hidden ones.
+func (o *Overlay) copyUp(name string) error {
+	info, err := o.lstat("open", name)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if err = o.mkdirAll("open", name, 0); err != nil {
+			return err
+		}
+		ets, err := o.ReadDir(name)
+		if err != nil {
+			return err
+		}
+		for _, ent := range ets {
+			if err = o.copyUp(path.Join(name, ent.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if o.inUpper(name) {
+		return nil
+	}
+	if err = o.mkdirAll("open", path.Dir(name), 0); err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		dst, err := fs.ReadLink(o.base, name)
+		if err != nil {
+			return err
+		}
+		return o.upper.Symlink(dst, name)
+	}
+	data, err := fs.ReadFile(o.base, name)
+	if err != nil {
+		return err
+	}
+	return o.upper.WriteFile(name, data, info.Mode().Perm())
+}
+
+// check returns an error of the [fs.PathError] type with [fs.ErrInvalid] when
+// the name is not valid.
+func (o *Overlay) check(op, name string) error {
+	if !fs.ValidPath(name) {
+		return o.err(op, name, fs.ErrInvalid)
+	}
+	return nil
+}
+
+// err returns an error of the [fs.PathError] type passed through the error
+// hook of the upper layer.
+func (o *Overlay) err(op, name string, err error) error {
+	return o.upper.hookErr(&fs.PathError{Op: op, Path: name, Err: err})
+}
+
+// overlayDir is the directory handle returned by [Overlay.Open].
+type overlayDir struct {
+	name   string        // Name passed to Open.
+	info   fs.FileInfo   // The directory information.
+	ets    []fs.DirEntry // The merged directory entries.
+	cursor int           // Directory entries already read.
+	closed bool          // The handle was closed.
+}
+
+// Stat implements [fs.File] interface.
+func (d *overlayDir) Stat() (fs.FileInfo, error) {
+	if d.closed {
+		return nil, d.err("stat", fs.ErrClosed)
+	}
+	return d.info, nil
+}
+
+// Read implements [fs.File] interface.
+func (d *overlayDir) Read([]byte) (int, error) {
+	if d.closed {
+		return 0, d.err("read", fs.ErrClosed)
+	}
+	return 0, d.err("read", syscall.EISDIR)
+}
+
+// ReadDir implements [fs.ReadDirFile] interface.
+func (d *overlayDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, d.err("readdir", fs.ErrClosed)
+	}
+	left := d.ets[d.cursor:]
+	if n <= 0 {
+		d.cursor = len(d.ets)
+		return left, nil
+	}
+	if len(left) == 0 {
+		return nil, io.EOF
+	}
+	left = left[:min(n, len(left))]
+	d.cursor += len(left)
+	return left, nil
+}
+
+// Close implements [fs.File] interface.
+func (d *overlayDir) Close() error {
+	if d.closed {
+		return d.err("close", fs.ErrClosed)
+	}
+	d.closed = true
+	return nil
+}
+
+// err returns an error of the [fs.PathError] type for the handle.
+func (d *overlayDir) err(op string, err error) error {
+	return &fs.PathError{Op: op, Path: d.name, Err: err}
+}
@@ -0,0 +1,676 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstOverlayBase returns a base file system for the Overlay tests.
+func tstOverlayBase() fstest.MapFS {
+	return fstest.MapFS{
+		"file":      {Data: []byte("base"), Mode: 0640},
+		"dir":       {Mode: fs.ModeDir | 0750},
+		"dir/file":  {Data: []byte("dir/file"), Mode: 0600},
+		"dir/other": {Data: []byte("dir/other"), Mode: 0600},
+	}
+}
+
+// tstLayer returns the names and the modes of the tar archive entries
+// written by [Overlay.WriteLayer].
+func tstLayer(t *testing.T, data []byte) []string {
+	t.Helper()
+	var have []string
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return have
+		}
+		must.Nil(err)
+		have = append(have, hdr.Name+" "+fs.FileMode(hdr.Mode).String())
+	}
+}
+
+func Test_NewOverlay(t *testing.T) {
+	// --- Given ---
+	base := tstOverlayBase()
+
+	// --- When ---
+	have := NewOverlay(base, WithReadOnly)
+
+	// --- Then ---
+	assert.Equal(t, base, have.base)
+	assert.True(t, have.Upper().IsDir())
+	assert.True(t, have.Upper().readOnly())
+	assert.Nil(t, have.Whiteouts())
+}
+
+func Test_Overlay(t *testing.T) {
+	t.Run("passes fstest", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.WriteFile("dir/new", []byte("new"), 0644))
+		must.Nil(fsys.Remove("dir/other"))
+
+		// --- When ---
+		err := fstest.TestFS(fsys, "file", "dir/file", "dir/new")
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("reads fall through to the base", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		have, err := fsys.ReadFile("dir/file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "dir/file", string(have))
+		assert.Len(t, 0, fsys.Upper().entries)
+	})
+
+	t.Run("open file from the base", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		fil, err := fsys.Open("file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "base", string(must.Value(io.ReadAll(fil))))
+		assert.NoError(t, fil.Close())
+	})
+
+	t.Run("open file from the upper layer", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.WriteFile("file", []byte("upper"), 0644))
+
+		// --- When ---
+		fil, err := fsys.Open("file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "upper", string(must.Value(io.ReadAll(fil))))
+		assert.NoError(t, fil.Close())
+	})
+
+	t.Run("open directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.WriteFile("dir/new", nil, 0644))
+
+		// --- When ---
+		fil, err := fsys.Open("dir")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		dir := fil.(fs.ReadDirFile)
+		ets := must.Value(dir.ReadDir(2))
+		assert.Equal(t, []string{"file", "new"}, names(ets))
+		ets = must.Value(dir.ReadDir(2))
+		assert.Equal(t, []string{"other"}, names(ets))
+		_, err = dir.ReadDir(1)
+		assert.ErrorIs(t, io.EOF, err)
+		_, err = fil.Read(nil)
+		assert.ErrorIs(t, syscall.EISDIR, err)
+		assert.NoError(t, fil.Close())
+		assert.ErrorIs(t, fs.ErrClosed, fil.Close())
+	})
+
+	t.Run("error - open not existing", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		fil, err := fsys.Open("missing")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, fil)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		have, err := fsys.ReadFile("../file")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_Overlay_ReadDir(t *testing.T) {
+	t.Run("merges the layers", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.WriteFile("dir/file", []byte("upper"), 0644))
+		must.Nil(fsys.WriteFile("dir/new", nil, 0644))
+		must.Nil(fsys.Remove("dir/other"))
+
+		// --- When ---
+		have, err := fsys.ReadDir("dir")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"file", "new"}, names(have))
+		info := must.Value(have[0].Info())
+		assert.Equal(t, int64(5), info.Size())
+	})
+
+	t.Run("root", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.MkdirAll("new", 0755))
+
+		// --- When ---
+		have, err := fsys.ReadDir(".")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"dir", "file", "new"}, names(have))
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		have, err := fsys.ReadDir("file")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_Overlay_WriteFile(t *testing.T) {
+	t.Run("new file", func(t *testing.T) {
+		// --- Given ---
+		base := tstOverlayBase()
+		fsys := NewOverlay(base)
+
+		// --- When ---
+		err := fsys.WriteFile("dir/new", []byte("new"), 0644)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "new", string(must.Value(fsys.ReadFile("dir/new"))))
+		dir := must.Value(fsys.Upper().Lstat("dir"))
+		assert.Equal(t, fs.ModeDir|0750, dir.Mode())
+		info := must.Value(fsys.Stat("dir/new"))
+		assert.Equal(t, fs.FileMode(0644), info.Mode())
+		_, ok := base["dir/new"]
+		assert.False(t, ok)
+	})
+
+	t.Run("copies up existing file", func(t *testing.T) {
+		// --- Given ---
+		base := tstOverlayBase()
+		fsys := NewOverlay(base)
+
+		// --- When ---
+		err := fsys.WriteFile("file", []byte("upper"), 0644)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "upper", string(must.Value(fsys.ReadFile("file"))))
+		info := must.Value(fsys.Stat("file"))
+		assert.Equal(t, fs.FileMode(0640), info.Mode())
+		assert.Equal(t, "base", string(base["file"].Data))
+	})
+
+	t.Run("error - parent does not exist", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.WriteFile("missing/file", nil, 0644)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("error - parent is a file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.WriteFile("file/new", nil, 0644)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.WriteFile("dir", nil, 0644)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EISDIR, err)
+	})
+
+	t.Run("error - root", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.WriteFile(".", nil, 0644)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+	})
+}
+
+func Test_Overlay_MkdirAll(t *testing.T) {
+	t.Run("creates missing directories", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.MkdirAll("dir/a/b", 0700)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		info := must.Value(fsys.Stat("dir/a/b"))
+		assert.Equal(t, fs.ModeDir|0700, info.Mode())
+		info = must.Value(fsys.Stat("dir"))
+		assert.Equal(t, fs.ModeDir|0750, info.Mode())
+	})
+
+	t.Run("existing base directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.MkdirAll("dir", 0700)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		ets := must.Value(fsys.ReadDir("dir"))
+		assert.Equal(t, []string{"file", "other"}, names(ets))
+	})
+
+	t.Run("error - path goes through a file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.MkdirAll("file/sub", 0700)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Len(t, 0, fsys.Upper().entries)
+	})
+}
+
+func Test_Overlay_Remove(t *testing.T) {
+	t.Run("base file", func(t *testing.T) {
+		// --- Given ---
+		base := tstOverlayBase()
+		fsys := NewOverlay(base)
+
+		// --- When ---
+		err := fsys.Remove("dir/other")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = fsys.Stat("dir/other")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Equal(t, []string{"dir/other"}, fsys.Whiteouts())
+		_, ok := base["dir/other"]
+		assert.True(t, ok)
+	})
+
+	t.Run("upper file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.WriteFile("new", nil, 0644))
+
+		// --- When ---
+		err := fsys.Remove("new")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = fsys.Stat("new")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, fsys.Whiteouts())
+	})
+
+	t.Run("copied up file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.WriteFile("file", []byte("upper"), 0644))
+
+		// --- When ---
+		err := fsys.Remove("file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = fsys.Stat("file")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("directory emptied in the overlay", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.Remove("dir/file"))
+		must.Nil(fsys.Remove("dir/other"))
+
+		// --- When ---
+		err := fsys.Remove("dir")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		ets := must.Value(fsys.ReadDir("."))
+		assert.Equal(t, []string{"file"}, names(ets))
+	})
+
+	t.Run("error - not empty directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.Remove("dir")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTEMPTY, err)
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.Remove("missing")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+}
+
+func Test_Overlay_RemoveAll(t *testing.T) {
+	t.Run("base directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.RemoveAll("dir")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = fsys.Stat("dir/file")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Equal(t, []string{"dir"}, fsys.Whiteouts())
+	})
+
+	t.Run("recreated directory does not show base entries", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.RemoveAll("dir"))
+
+		// --- When ---
+		err := fsys.MkdirAll("dir", 0700)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		ets := must.Value(fsys.ReadDir("dir"))
+		assert.Len(t, 0, ets)
+	})
+
+	t.Run("not existing", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.RemoveAll("missing")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, fsys.Whiteouts())
+	})
+}
+
+func Test_Overlay_Rename(t *testing.T) {
+	t.Run("base file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.Rename("file", "dir/moved")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(fsys.ReadFile("dir/moved"))
+		assert.Equal(t, "base", string(have))
+		_, err = fsys.Stat("file")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		info := must.Value(fsys.Stat("dir/moved"))
+		assert.Equal(t, fs.FileMode(0640), info.Mode())
+	})
+
+	t.Run("base directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.Remove("dir/other"))
+
+		// --- When ---
+		err := fsys.Rename("dir", "moved")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		ets := must.Value(fsys.ReadDir("moved"))
+		assert.Equal(t, []string{"file"}, names(ets))
+		_, err = fsys.Stat("dir")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("replaces base file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.Rename("dir/file", "file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(fsys.ReadFile("file"))
+		assert.Equal(t, "dir/file", string(have))
+		ets := must.Value(fsys.ReadDir("dir"))
+		assert.Equal(t, []string{"other"}, names(ets))
+	})
+
+	t.Run("error - directory replaces file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.Rename("dir", "file")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		have := must.Value(fsys.ReadFile("file"))
+		assert.Equal(t, "base", string(have))
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+
+		// --- When ---
+		err := fsys.Rename("missing", "file")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+}
+
+func Test_Overlay_WriteLayer(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := fsys.WriteLayer(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, tstLayer(t, buf.Bytes()))
+	})
+
+	t.Run("upper layer", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.WriteFile("dir/new", []byte("new"), 0644))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := fsys.WriteLayer(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{"dir/ -rwxr-x---", "dir/new -rw-r--r--"}
+		assert.Equal(t, want, tstLayer(t, buf.Bytes()))
+		have := must.Value(FromTar(buf))
+		assert.Equal(t, "new", string(must.Value(have.ReadFile("dir/new"))))
+	})
+
+	t.Run("removed file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.Remove("dir/other"))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := fsys.WriteLayer(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{"dir/ -rwxr-x---", "dir/.wh.other -rw-------"}
+		assert.Equal(t, want, tstLayer(t, buf.Bytes()))
+	})
+
+	t.Run("removed directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.Remove("dir/other"))
+		must.Nil(fsys.RemoveAll("dir"))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := fsys.WriteLayer(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{".wh.dir -rw-------"}
+		assert.Equal(t, want, tstLayer(t, buf.Bytes()))
+	})
+
+	t.Run("opaque directory", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.RemoveAll("dir"))
+		must.Nil(fsys.MkdirAll("dir", 0700))
+		must.Nil(fsys.WriteFile("dir/new", []byte("new"), 0600))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := fsys.WriteLayer(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{
+			"dir/ -rwx------",
+			"dir/.wh..wh..opq -rw-------",
+			"dir/new -rw-------",
+		}
+		assert.Equal(t, want, tstLayer(t, buf.Bytes()))
+	})
+
+	t.Run("replaced file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.Remove("file"))
+		must.Nil(fsys.WriteFile("file", []byte("new"), 0600))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := fsys.WriteLayer(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{"file -rw-------"}
+		assert.Equal(t, want, tstLayer(t, buf.Bytes()))
+	})
+
+	t.Run("renamed file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.Rename("dir/file", "moved"))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := fsys.WriteLayer(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{
+			"dir/ -rwxr-x---",
+			"dir/.wh.file -rw-------",
+			"moved -rw-------",
+		}
+		assert.Equal(t, want, tstLayer(t, buf.Bytes()))
+	})
+
+	t.Run("same changes same archive", func(t *testing.T) {
+		// --- Given ---
+		changed := func() *Overlay {
+			fsys := NewOverlay(tstOverlayBase())
+			must.Nil(fsys.Remove("dir/other"))
+			must.Nil(fsys.WriteFile("new", []byte("new"), 0600))
+			return fsys
+		}
+		buf0, buf1 := &bytes.Buffer{}, &bytes.Buffer{}
+
+		// --- When ---
+		must.Nil(changed().WriteLayer(buf0))
+		must.Nil(changed().WriteLayer(buf1))
+
+		// --- Then ---
+		assert.Equal(t, buf0.Bytes(), buf1.Bytes())
+	})
+
+	t.Run("error - writer", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewOverlay(tstOverlayBase())
+		must.Nil(fsys.Remove("file"))
+
+		// --- When ---
+		err := fsys.WriteLayer(errWriter{err: io.ErrShortWrite})
+
+		// --- Then ---
+		assert.ErrorIs(t, io.ErrShortWrite, err)
+	})
+}
@@ -0,0 +1,203 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+)
+
+// Compile time checks of the file systems returned by [PathMapper].
+var (
+	_ fs.ReadDirFS  = mapFS{}
+	_ fs.ReadFileFS = mapFS{}
+	_ fs.ReadLinkFS = mapFS{}
+	_ fs.StatFS     = mapFS{}
+	_ WriteFS       = mapWriteFS{}
+)
+
+// PathMapper returns a middleware rewriting or vetoing the names passed to
+// the file system, so the code under test can be pointed at a new layout
+// without changing it, for example:
+//
+//	legacy := func(name string) (string, error) {
+//		if strings.HasPrefix(name, "old/") {
+//			return "", fs.ErrPermission
+//		}
+//		return strings.Replace(name, "conf.d/", "config/", 1), nil
+//	}
+//	fsys := Chain(root.DirFS(), PathMapper(legacy))
+//
+// The mapper is called with every name, and both paths of "rename", passed
+// to the file system methods. The returned name is passed to the wrapped
+// file system, and the errors of the [fs.PathError] type it returns for the
+// returned name report the original name. When the mapper returns an error,
+// the operation is not called and an error of the [fs.PathError] type
+// wrapping it is returned, with the operation names listed in [Faults]. The
+// names of the opened files and the directory entries are not mapped back.
+func PathMapper(mapper func(name string) (string, error)) Middleware {
+	return func(fsys fs.FS) fs.FS {
+		m := mapFS{fsys: fsys, mapper: mapper}
+		if _, ok := fsys.(WriteFS); ok {
+			return mapWriteFS{m}
+		}
+		return m
+	}
+}
+
+// mapFS is the file system returned by the [PathMapper] middleware.
+type mapFS struct {
+	fsys   fs.FS                             // The wrapped file system.
+	mapper func(name string) (string, error) // See [PathMapper].
+}
+
+// Open implements [fs.FS] interface.
+func (m mapFS) Open(name string) (fs.File, error) {
+	pth, err := m.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := m.fsys.Open(pth)
+	if err != nil {
+		return nil, m.err(name, pth, err)
+	}
+	return f, nil
+}
+
+// Stat implements [fs.StatFS] interface.
+func (m mapFS) Stat(name string) (fs.FileInfo, error) {
+	pth, err := m.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(m.fsys, pth)
+	if err != nil {
+		return nil, m.err(name, pth, err)
+	}
+	return info, nil
+}
+
+// ReadDir implements [fs.ReadDirFS] interface.
+func (m mapFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	pth, err := m.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	ets, err := fs.ReadDir(m.fsys, pth)
+	return ets, m.err(name, pth, err)
+}
+
+// ReadFile implements [fs.ReadFileFS] interface.
+func (m mapFS) ReadFile(name string) ([]byte, error) {
+	pth, err := m.path("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fs.ReadFile(m.fsys, pth)
+	if err != nil {
+		return nil, m.err(name, pth, err)
+	}
+	return data, nil
+}
+
+// ReadLink implements [fs.ReadLinkFS] interface.
+func (m mapFS) ReadLink(name string) (string, error) {
+	pth, err := m.path("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	dst, err := fs.ReadLink(m.fsys, pth)
+	return dst, m.err(name, pth, err)
+}
+
+// Lstat implements [fs.ReadLinkFS] interface.
+func (m mapFS) Lstat(name string) (fs.FileInfo, error) {
+	pth, err := m.path("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Lstat(m.fsys, pth)
+	if err != nil {
+		return nil, m.err(name, pth, err)
+	}
+	return info, nil
+}
+
+// path returns the name returned by the mapper for the name. Returns an
+// error of the [fs.PathError] type wrapping the mapper error. The op is used
+// as the operation name in the returned error.
+func (m mapFS) path(op, name string) (string, error) {
+	pth, err := m.mapper(name)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return pth, nil
+}
+
+// err returns the error with the mapped path pth replaced by the original
+// name when it's of the [fs.PathError] type. Returns nil when err is nil.
+func (m mapFS) err(name, pth string, err error) error {
+	if pe, ok := err.(*fs.PathError); ok && pe.Path == pth {
+		return &fs.PathError{Op: pe.Op, Path: name, Err: pe.Err}
+	}
+	return err
+}
+
+// mapWriteFS is the file system returned by the [PathMapper] middleware for
+// the wrapped file systems implementing [WriteFS].
+type mapWriteFS struct{ mapFS }
+
+// WriteFile implements [WriteFS] interface.
+func (m mapWriteFS) WriteFile(
+	name string,
+	data []byte,
+	perm fs.FileMode,
+) error {
+	pth, err := m.path("writefile", name)
+	if err != nil {
+		return err
+	}
+	err = m.fsys.(WriteFS).WriteFile(pth, data, perm)
+	return m.err(name, pth, err)
+}
+
+// MkdirAll implements [WriteFS] interface.
+func (m mapWriteFS) MkdirAll(name string, perm fs.FileMode) error {
+	pth, err := m.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return m.err(name, pth, m.fsys.(WriteFS).MkdirAll(pth, perm))
+}
+
+// Remove implements [WriteFS] interface.
+func (m mapWriteFS) Remove(name string) error {
+	pth, err := m.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return m.err(name, pth, m.fsys.(WriteFS).Remove(pth))
+}
+
+// RemoveAll implements [WriteFS] interface.
+func (m mapWriteFS) RemoveAll(name string) error {
+	pth, err := m.path("removeall", name)
+	if err != nil {
+		return err
+	}
+	return m.err(name, pth, m.fsys.(WriteFS).RemoveAll(pth))
+}
+
+// Rename implements [WriteFS] interface.
+func (m mapWriteFS) Rename(oldpath, newpath string) error {
+	src, err := m.path("rename", oldpath)
+	if err != nil {
+		return err
+	}
+	dst, err := m.path("rename", newpath)
+	if err != nil {
+		return err
+	}
+	err = m.fsys.(WriteFS).Rename(src, dst)
+	return m.err(oldpath, src, m.err(newpath, dst, err))
+}
@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstLegacy is a [PathMapper] mapper moving the "old/" directory to "sub/"
+// and forbidding the "secret" paths.
+func tstLegacy(name string) (string, error) {
+	if strings.HasPrefix(name, "secret") {
+		return "", fs.ErrPermission
+	}
+	if rest, ok := strings.CutPrefix(name, "old/"); ok {
+		return "sub/" + rest, nil
+	}
+	if name == "old" {
+		return "sub", nil
+	}
+	return name, nil
+}
+
+func Test_PathMapper(t *testing.T) {
+	t.Run("passes fstest", func(t *testing.T) {
+		// --- Given ---
+		same := func(name string) (string, error) { return name, nil }
+
+		// --- When ---
+		fsys := Chain(tstDirMem().DirFS(), PathMapper(same))
+
+		// --- Then ---
+		assert.NoError(t, fstest.TestFS(fsys, "file0", "sub/sub2/file6"))
+	})
+
+	t.Run("keeps capabilities", func(t *testing.T) {
+		// --- When ---
+		ro := Chain(tstDirMem().DirFS(), PathMapper(tstLegacy))
+		rw := Chain(NewSyncFS(tstDirMem()), PathMapper(tstLegacy))
+
+		// --- Then ---
+		_, ok := ro.(WriteFS)
+		assert.False(t, ok)
+		_, ok = rw.(WriteFS)
+		assert.True(t, ok)
+	})
+
+	t.Run("rewrites read paths", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(tstDirMem().DirFS(), PathMapper(tstLegacy))
+
+		// --- When ---
+		have, err := fs.ReadFile(fsys, "old/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file3", string(have))
+		info := must.Value(fs.Stat(fsys, "old/sub2"))
+		assert.True(t, info.IsDir())
+		ets := must.Value(fs.ReadDir(fsys, "old"))
+		assert.Equal(t, []string{"file3", "file4", "sub2"}, names(ets))
+		fil := must.Value(fsys.Open("old/file4"))
+		assert.NoError(t, fil.Close())
+	})
+
+	t.Run("rewrites write paths", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fsys := Chain(NewSyncFS(dir), PathMapper(tstLegacy)).(WriteFS)
+
+		// --- When ---
+		must.Nil(fsys.MkdirAll("old/a", 0700))
+		must.Nil(fsys.WriteFile("old/a/new", []byte("new"), 0600))
+		must.Nil(fsys.Rename("old/file3", "old/a/file3"))
+		must.Nil(fsys.Remove("old/file4"))
+		must.Nil(fsys.RemoveAll("old/sub2"))
+
+		// --- Then ---
+		ets := must.Value(dir.ReadDir(-1))
+		assert.Equal(t, []string{"file0", "file1", "file2", "sub"}, names(ets))
+		sub := must.Value(open(dir, "sub/a"))
+		ets = must.Value(sub.ReadDir(-1))
+		assert.Equal(t, []string{"file3", "new"}, names(ets))
+	})
+
+	t.Run("error - vetoed path", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(NewSyncFS(tstDirMem()), PathMapper(tstLegacy))
+		wfs := fsys.(WriteFS)
+
+		// --- When ---
+		_, err := fsys.Open("secret/file")
+
+		// --- Then ---
+		var pe *fs.PathError
+		assert.True(t, errors.As(err, &pe))
+		assert.Equal(t, "open", pe.Op)
+		assert.Equal(t, "secret/file", pe.Path)
+		assert.ErrorIs(t, fs.ErrPermission, err)
+		err = wfs.Rename("file0", "secret")
+		assert.ErrorIs(t, fs.ErrPermission, err)
+		assert.NoError(t, must.Value(fsys.Open("file0")).Close())
+	})
+
+	t.Run("error - reports the original name", func(t *testing.T) {
+		// --- Given ---
+		fsys := Chain(NewSyncFS(tstDirMem()), PathMapper(tstLegacy))
+
+		// --- When ---
+		_, err := fs.Stat(fsys, "old/missing")
+
+		// --- Then ---
+		var pe *fs.PathError
+		assert.True(t, errors.As(err, &pe))
+		assert.Equal(t, "old/missing", pe.Path)
+		assert.ErrorIs(t, syscall.ENOENT, err)
+	})
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Limits used by [GenPathologicalTree].
+const (
+	// PathologicalNameMax is the length in bytes of the longest names, the
+	// NAME_MAX limit of the most common file systems.
+	PathologicalNameMax = 255
+
+	// PathologicalDepth is the number of nested directories in the "deep"
+	// directory.
+	PathologicalDepth = 64
+)
+
+// pathologicalNames maps the top level directories of the tree generated by
+// [GenPathologicalTree] to the names of the files they contain.
+var pathologicalNames = map[string][]string{
+	"combining": {
+		"\u00e9",               // Precomposed "é" (NFC).
+		"e\u0301",              // Decomposed "é" (NFD).
+		"a\u0308\u0308\u0308",  // Stacked combining characters.
+		"\u0301leading",        // Combining character without a base.
+		"\U0001F44D\U0001F3FD", // Emoji with a skin tone modifier.
+		"\u1100\u1161\u11a8",   // Conjoining Hangul jamo.
+	},
+	"bidi": {
+		"\u202etxt.exe",        // Right-to-left override.
+		"file\u200f",           // Trailing right-to-left mark.
+		"\u2067isolate\u2069",  // Right-to-left isolate.
+		"\u05e9\u05dc\u05d5.1", // Hebrew followed by a digit.
+	},
+	"long": {
+		strings.Repeat("a", PathologicalNameMax),
+		strings.Repeat("\u00e9", PathologicalNameMax/2),
+		strings.Repeat("\U0001F600", PathologicalNameMax/4),
+	},
+	"confusable": {
+		"paypal",                 // Latin.
+		"p\u0430yp\u0430l",       // Cyrillic "а".
+		"\uff50\uff41\uff59",     // Fullwidth "pay".
+		"pay\u200bpal",           // Zero width space.
+		"pay\u00adpal",           // Soft hyphen.
+		"I1l|",                   // Similar ASCII characters.
+		"\u2024\u2024",           // One dot leaders looking like "..".
+		"a\u2215b",               // Division slash looking like "/".
+		" leading and trailing ", // Spaces.
+	},
+}
+
+// GenPathologicalTree returns a new root directory with files which names are
+// known to break path handling code. Use it as a standard torture-test fixture
+// for walkers, sanitizers and encoders. The tree contains the following
+// directories:
+//
+//   - "combining" - names with combining characters, including the same
+//     character in the NFC and NFD normalization forms,
+//   - "bidi" - names with bidirectional text control characters,
+//   - "long" - names of [PathologicalNameMax] bytes or close to it using one
+//     to four bytes long UTF-8 sequences,
+//   - "confusable" - names looking the same or similar to other names or
+//     special path elements,
+//   - "deep" - [PathologicalDepth] nested "d" directories with the "file"
+//     file at the bottom.
+//
+// The content of every file is its name. Every call returns a new tree.
+func GenPathologicalTree() *File {
+	root := NewRoot()
+	for _, dir := range slices.Sorted(maps.Keys(pathologicalNames)) {
+		sub, err := mkdirAll(root, dir)
+		if err != nil {
+			panic(err)
+		}
+		for _, name := range pathologicalNames[dir] {
+			pathologicalAdd(sub, name)
+		}
+	}
+	deep, err := mkdirAll(root, "deep"+strings.Repeat("/d", PathologicalDepth))
+	if err != nil {
+		panic(err)
+	}
+	pathologicalAdd(deep, "file")
+	return root
+}
+
+// pathologicalAdd adds the file with the given name and its name as content
+// to the directory. It panics on error.
+func pathologicalAdd(dir *File, name string) {
+	fil, err := FileWith(name, []byte(name))
+	if err == nil {
+		err = dir.AddFile(fil)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_GenPathologicalTree(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		// --- When ---
+		have := GenPathologicalTree()
+
+		// --- Then ---
+		var files []string
+		have.walk("", func(pth string, ent *File) {
+			if ent.IsDir() {
+				return
+			}
+			files = append(files, pth)
+			assert.Equal(t, ent.Name(), string(ent.buf))
+			assert.True(t, utf8.ValidString(pth))
+		})
+		assert.Len(t, 23, files)
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		// --- When ---
+		have := GenPathologicalTree()
+
+		// --- Then ---
+		var dirs []string
+		for _, ent := range have.entries {
+			dirs = append(dirs, ent.Name())
+		}
+		want := []string{"bidi", "combining", "confusable", "long", "deep"}
+		assert.Equal(t, want, dirs)
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		// --- When ---
+		have := GenPathologicalTree()
+
+		// --- Then ---
+		ents := must.Value(fs.ReadDir(have.FS(), "."))
+		assert.Len(t, 5, ents)
+		for _, ent := range ents {
+			assert.True(t, ent.IsDir())
+		}
+	})
+
+	t.Run("normalization forms are different files", func(t *testing.T) {
+		// --- When ---
+		have := GenPathologicalTree()
+
+		// --- Then ---
+		nfc := must.Value(have.ReadFile("combining/\u00e9"))
+		nfd := must.Value(have.ReadFile("combining/e\u0301"))
+		assert.NotEqual(t, string(nfc), string(nfd))
+	})
+
+	t.Run("long names", func(t *testing.T) {
+		// --- When ---
+		have := GenPathologicalTree()
+
+		// --- Then ---
+		dir := must.Value(open(have, "long"))
+		for _, ent := range dir.entries {
+			assert.True(t, len(ent.Name()) <= PathologicalNameMax)
+			assert.True(t, len(ent.Name()) > PathologicalNameMax-4)
+		}
+	})
+
+	t.Run("deep nesting", func(t *testing.T) {
+		// --- Given ---
+		pth := "deep" + strings.Repeat("/d", PathologicalDepth) + "/file"
+
+		// --- When ---
+		have := GenPathologicalTree()
+
+		// --- Then ---
+		fil := must.Value(open(have, pth))
+		assert.Equal(t, pth, fil.path())
+	})
+
+	t.Run("new tree on every call", func(t *testing.T) {
+		// --- Given ---
+		tree := GenPathologicalTree()
+		must.Value(must.Value(open(tree, "bidi/file\u200f")).Write([]byte("x")))
+
+		// --- When ---
+		have := GenPathologicalTree()
+
+		// --- Then ---
+		data := must.Value(have.ReadFile("bidi/file\u200f"))
+		assert.Equal(t, "file\u200f", string(data))
+	})
+}
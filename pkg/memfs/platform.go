@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"strconv"
+	"syscall"
+)
+
+// Platform represents the operating system whose error conventions are
+// emulated by the [PlatformErrors] hook.
+type Platform int
+
+const (
+	// PlatformLinux uses the Linux conventions, which are the package
+	// defaults.
+	PlatformLinux Platform = iota
+
+	// PlatformDarwin uses the macOS conventions.
+	PlatformDarwin
+
+	// PlatformWindows uses the Windows conventions.
+	PlatformWindows
+)
+
+// WinErrno is a Windows system error code reported in the [PlatformWindows]
+// emulation. Like [syscall.Errno] on Windows, it matches the [fs] package
+// sentinel errors with [errors.Is].
+type WinErrno uintptr
+
+// Windows system error codes.
+const (
+	WinErrFileNotFound     WinErrno = 2  // ERROR_FILE_NOT_FOUND
+	WinErrAccessDenied     WinErrno = 5  // ERROR_ACCESS_DENIED
+	WinErrSharingViolation WinErrno = 32 // ERROR_SHARING_VIOLATION
+	WinErrFileExists       WinErrno = 80 // ERROR_FILE_EXISTS
+)
+
+// winMessages maps the Windows system error codes to their messages.
+var winMessages = map[WinErrno]string{
+	WinErrFileNotFound: "The system cannot find the file specified.",
+	WinErrAccessDenied: "Access is denied.",
+	WinErrSharingViolation: "The process cannot access the file because " +
+		"it is being used by another process.",
+	WinErrFileExists: "The file exists.",
+}
+
+// Error implements error interface.
+func (e WinErrno) Error() string {
+	if msg, ok := winMessages[e]; ok {
+		return msg
+	}
+	return "winapi error #" + strconv.Itoa(int(e))
+}
+
+// Is reports whether the error code matches the target.
+func (e WinErrno) Is(target error) bool {
+	switch target {
+	case fs.ErrNotExist:
+		return e == WinErrFileNotFound
+	case fs.ErrPermission:
+		return e == WinErrAccessDenied
+	case fs.ErrExist:
+		return e == WinErrFileExists
+	}
+	return false
+}
+
+// platformOps maps the operation names used by the package to the names
+// reported on the given platform.
+var platformOps = map[Platform]map[string]string{
+	PlatformDarwin: {
+		"readdirent": "fdopendir",
+	},
+	PlatformWindows: {
+		"openat":     "open",
+		"statat":     "CreateFile",
+		"readdirent": "readdir",
+	},
+}
+
+// platformErrs maps the [syscall.Errno] errors used by the package to the
+// errors reported on the given platform.
+var platformErrs = map[Platform]map[syscall.Errno]error{
+	PlatformWindows: {
+		syscall.ENOENT: WinErrFileNotFound,
+		syscall.EPERM:  WinErrAccessDenied,
+		syscall.EACCES: WinErrAccessDenied,
+		syscall.EEXIST: WinErrFileExists,
+		syscall.EBUSY:  WinErrSharingViolation,
+	},
+}
+
+// PlatformErrors returns an error hook for the [WithErrorHook] option which
+// changes the Op and Err fields of the [fs.PathError] errors to match the
+// conventions of the given platform. For example, for [PlatformWindows],
+// opening a not existing file through the [fs.FS] returned by [File.FS]
+// reports the "open" operation with [WinErrFileNotFound] instead of the
+// "openat" operation with [syscall.ENOENT]. Other errors are returned
+// unchanged.
+//
+// Use the hook directly to chain it with other hooks, or use the
+// [WithPlatform] option.
+func PlatformErrors(p Platform) func(err error) error {
+	ops, errs := platformOps[p], platformErrs[p]
+	return func(err error) error {
+		var e *fs.PathError
+		if !errors.As(err, &e) {
+			return err
+		}
+		if op, ok := ops[e.Op]; ok {
+			e.Op = op
+		}
+		if errno, ok := e.Err.(syscall.Errno); ok {
+			if pe, ok := errs[errno]; ok {
+				e.Err = pe
+			}
+		}
+		return err
+	}
+}
+
+// WithPlatform is a [File] constructor function option setting the error
+// hook returned by [PlatformErrors]. It replaces the hook set with the
+// [WithErrorHook] option.
+func WithPlatform(p Platform) func(*File) {
+	return WithErrorHook(PlatformErrors(p))
+}
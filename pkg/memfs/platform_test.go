@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WinErrno_Error(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		errno WinErrno
+		want  string
+	}{
+		{
+			"file not found",
+			WinErrFileNotFound,
+			"The system cannot find the file specified.",
+		},
+		{"access denied", WinErrAccessDenied, "Access is denied."},
+		{"file exists", WinErrFileExists, "The file exists."},
+		{"unknown", WinErrno(1234), "winapi error #1234"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have := tc.errno.Error()
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func Test_WinErrno_Is(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		errno  WinErrno
+		target error
+		want   bool
+	}{
+		{"not exist", WinErrFileNotFound, fs.ErrNotExist, true},
+		{"permission", WinErrAccessDenied, fs.ErrPermission, true},
+		{"exist", WinErrFileExists, fs.ErrExist, true},
+		{"not matching", WinErrFileNotFound, fs.ErrExist, false},
+		{"other target", WinErrAccessDenied, fs.ErrInvalid, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have := errors.Is(tc.errno, tc.target)
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func Test_PlatformErrors(t *testing.T) {
+	t.Run("linux", func(t *testing.T) {
+		// --- Given ---
+		hook := PlatformErrors(PlatformLinux)
+		err := &fs.PathError{Op: "openat", Path: "a", Err: syscall.ENOENT}
+
+		// --- When ---
+		have := hook(err)
+
+		// --- Then ---
+		assert.ErrorEqual(t, "openat a: no such file or directory", have)
+	})
+
+	t.Run("darwin", func(t *testing.T) {
+		// --- Given ---
+		hook := PlatformErrors(PlatformDarwin)
+		err := &fs.PathError{Op: "readdirent", Path: "a", Err: syscall.ENOTDIR}
+
+		// --- When ---
+		have := hook(err)
+
+		// --- Then ---
+		assert.ErrorEqual(t, "fdopendir a: not a directory", have)
+	})
+
+	t.Run("windows", func(t *testing.T) {
+		// --- Given ---
+		hook := PlatformErrors(PlatformWindows)
+		err := &fs.PathError{Op: "openat", Path: "a", Err: syscall.ENOENT}
+
+		// --- When ---
+		have := hook(err)
+
+		// --- Then ---
+		want := "open a: The system cannot find the file specified."
+		assert.ErrorEqual(t, want, have)
+		assert.ErrorIs(t, fs.ErrNotExist, have)
+	})
+
+	t.Run("wrapped error", func(t *testing.T) {
+		// --- Given ---
+		hook := PlatformErrors(PlatformWindows)
+		pe := &fs.PathError{Op: "write", Path: "a", Err: syscall.EPERM}
+		err := fmt.Errorf("wrapped: %w", pe)
+
+		// --- When ---
+		have := hook(err)
+
+		// --- Then ---
+		assert.Same(t, err, have)
+		assert.Equal(t, WinErrAccessDenied, pe.Err)
+	})
+
+	t.Run("not mapped", func(t *testing.T) {
+		// --- Given ---
+		hook := PlatformErrors(PlatformWindows)
+		err := &fs.PathError{Op: "write", Path: "a", Err: ErrChecksum}
+
+		// --- When ---
+		have := hook(err)
+
+		// --- Then ---
+		assert.ErrorEqual(t, "write a: checksum mismatch", have)
+	})
+
+	t.Run("not path error", func(t *testing.T) {
+		// --- Given ---
+		hook := PlatformErrors(PlatformWindows)
+
+		// --- When ---
+		have := hook(fs.ErrExist)
+
+		// --- Then ---
+		assert.Same(t, fs.ErrExist, have)
+	})
+}
+
+func Test_WithPlatform(t *testing.T) {
+	t.Run("FS Open", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithPlatform(PlatformWindows))
+
+		// --- When ---
+		have, err := root.FS().Open("not-existing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "open", e.Op)
+		assert.Equal(t, WinErrFileNotFound, e.Err)
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("FS Stat", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithPlatform(PlatformWindows))
+
+		// --- When ---
+		have, err := fs.Stat(root.FS(), "not-existing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "CreateFile", e.Op)
+		assert.Equal(t, WinErrFileNotFound, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("FS ReadDir", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithPlatform(PlatformDarwin))
+		must.Nil(root.AddFile(MustFile("file")))
+
+		// --- When ---
+		have, err := fs.ReadDir(root.FS(), "file")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "fdopendir", e.Op)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("file method", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithPlatform(PlatformWindows))
+		fil := MustFile("file", WithFileAttr(AttrImmutable))
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		_, err := fil.Write([]byte{0})
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, WinErrAccessDenied, e.Err)
+		assert.ErrorIs(t, fs.ErrPermission, err)
+	})
+}
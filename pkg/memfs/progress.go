@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+// WithProgress is a [File] constructor function option setting the function
+// called with the progress of the bulk operations on the directory and all
+// directories in its tree: [File.RemoveAll], [File.WriteToDisk],
+// [File.ImportTar], [File.ImportZip], [File.ImportTxtar], and, when passed
+// to them, [FromFS] and [CopyFromDisk]. The function is called after every
+// regular file is removed, written, imported or copied, with the path of the
+// file relative to the directory the operation was called on, the number of
+// content bytes processed so far, and the total number of content bytes the
+// operation processes, known before it starts, so the progress logic can be
+// tested against deterministic totals. The total is -1 when it's not known,
+// like for the tar archives, which are read as a stream. The progress
+// function set on the nearest directory applies.
+func WithProgress(fn func(pth string, done, total int64)) func(*File) {
+	return func(fil *File) { fil.progHook = fn }
+}
+
+// progress reports the progress of a bulk operation to the function set
+// with [WithProgress]. The nil instance reports nothing.
+type progress struct {
+	fn    func(pth string, done, total int64) // The progress function.
+	done  int64                               // Bytes processed so far.
+	total int64                               // Bytes to process or -1.
+}
+
+// progress returns the progress of a bulk operation on the instance, with
+// the total number of bytes returned by the total function. Returns nil,
+// without calling the total function, when no progress function was set
+// with [WithProgress] on the instance or any of its parents.
+func (fil *File) progress(total func() int64) *progress {
+	for f := fil; f != nil; f = f.parent {
+		if f.progHook != nil {
+			return &progress{fn: f.progHook, total: total()}
+		}
+	}
+	return nil
+}
+
+// add records processing n bytes of the file with the given path.
+func (p *progress) add(pth string, n int64) {
+	if p == nil {
+		return
+	}
+	p.done += n
+	p.fn(pth, p.done, p.total)
+}
+
+// contentSize returns the total size of the regular files in the tree of
+// the instance, or the size of the instance when it's a regular file.
+func (fil *File) contentSize() int64 {
+	if fil.Mode().IsRegular() {
+		return int64(fil.Len())
+	}
+	var size int64
+	fil.walk("", func(_ string, ent *File) {
+		if ent.Mode().IsRegular() {
+			size += int64(ent.Len())
+		}
+	})
+	return size
+}
@@ -0,0 +1,240 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstProgress records the calls of the function set with [WithProgress].
+type tstProgress []string
+
+// fn is the progress function recording its calls.
+func (p *tstProgress) fn(pth string, done, total int64) {
+	*p = append(*p, fmt.Sprintf("%s:%d/%d", pth, done, total))
+}
+
+func Test_WithProgress(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		fn := func(string, int64, int64) { calls++ }
+		fil := &File{}
+
+		// --- When ---
+		WithProgress(fn)(fil)
+
+		// --- Then ---
+		fil.progHook("", 0, 0)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("RemoveAll", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := tstDirMem()
+		WithProgress(have.fn)(dir)
+
+		// --- When ---
+		err := dir.RemoveAll("sub")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := tstProgress{
+			"sub/file3:5/20",
+			"sub/file4:10/20",
+			"sub/sub2/file5:15/20",
+			"sub/sub2/file6:20/20",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("RemoveAll file", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := tstDirMem()
+		WithProgress(have.fn)(dir)
+
+		// --- When ---
+		err := dir.RemoveAll("sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, tstProgress{"sub/file3:5/5"}, have)
+	})
+
+	t.Run("RemoveAll error not reported", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := tstDirMem()
+		WithProgress(have.fn)(dir)
+		WithReadOnly(must.Value(open(dir, "sub/sub2")))
+
+		// --- When ---
+		err := dir.RemoveAll("sub")
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("nearest directory applies", func(t *testing.T) {
+		// --- Given ---
+		var have0, have1 tstProgress
+		dir := tstDirMem()
+		WithProgress(have0.fn)(dir)
+		sub := must.Value(open(dir, "sub"))
+		WithProgress(have1.fn)(sub)
+
+		// --- When ---
+		err := sub.RemoveAll("sub2")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, have0)
+		want := tstProgress{"sub2/file5:5/10", "sub2/file6:10/10"}
+		assert.Equal(t, want, have1)
+	})
+
+	t.Run("WriteToDisk", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := must.Value(open(tstDirMem(), "sub"))
+		WithProgress(have.fn)(dir)
+
+		// --- When ---
+		err := dir.WriteToDisk(t.TempDir())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := tstProgress{
+			"file3:5/20",
+			"file4:10/20",
+			"sub2/file5:15/20",
+			"sub2/file6:20/20",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("ImportTar", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := NewRoot(WithProgress(have.fn))
+		data := tstTar(
+			false,
+			arcEntry{"./dir/", fs.ModeDir | 0700, ""},
+			arcEntry{"./dir/a", 0600, "a"},
+			arcEntry{"b", 0600, "bc"},
+		)
+
+		// --- When ---
+		err := dir.ImportTar(bytes.NewReader(data), ConflictError)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, tstProgress{"dir/a:1/-1", "b:3/-1"}, have)
+	})
+
+	t.Run("ImportZip", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := NewRoot(WithProgress(have.fn))
+		zr := tstZip(
+			arcEntry{"dir/", fs.ModeDir | 0700, ""},
+			arcEntry{"dir/a", 0600, "a"},
+			arcEntry{"b", 0600, "bc"},
+		)
+
+		// --- When ---
+		err := dir.ImportZip(zr, zr.Size(), ConflictError)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, tstProgress{"dir/a:1/3", "b:3/3"}, have)
+	})
+
+	t.Run("ImportTxtar skipped files", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := NewRoot(WithProgress(have.fn))
+		must.Nil(dir.WriteFile("a", []byte("x"), 0600))
+		data := "-- a --\nabc\n-- b --\nd\n"
+
+		// --- When ---
+		err := dir.ImportTxtar(data, ConflictSkip)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, tstProgress{"a:4/6", "b:6/6"}, have)
+	})
+
+	t.Run("ImportTxtar overwrite", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := NewRoot(WithProgress(have.fn))
+		must.Nil(dir.MkdirAll("a", 0700))
+		must.Nil(dir.WriteFile("a/x", []byte("x"), 0600))
+
+		// --- When ---
+		err := dir.ImportTxtar("-- a --\nabc\n", ConflictOverwrite)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, tstProgress{"a:4/4"}, have)
+	})
+
+	t.Run("FromFS", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		fsys := tstDirMem().FS()
+
+		// --- When ---
+		root, err := FromFS(fsys, WithProgress(have.fn))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 7, have)
+		assert.Equal(t, "file0:5/35", have[0])
+		assert.Equal(t, "sub/sub2/file6:35/35", have[6])
+		assert.NotNil(t, root.progHook)
+	})
+
+	t.Run("CopyFromDisk", func(t *testing.T) {
+		// --- Given ---
+		var have tstProgress
+		dir := t.TempDir()
+		must.Nil(os.WriteFile(dir+"/a", []byte("abc"), 0600))
+
+		// --- When ---
+		_, err := CopyFromDisk(dir, WithProgress(have.fn))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, tstProgress{"a:3/3"}, have)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		called := false
+		total := func() int64 {
+			called = true
+			return 0
+		}
+
+		// --- When ---
+		have := dir.progress(total)
+
+		// --- Then ---
+		assert.Nil(t, have)
+		assert.False(t, called)
+	})
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+)
+
+// errReadLimit is returned by the [ingestReader] when the source has more
+// data than the [ReadLimit] allows.
+var errReadLimit = errors.New("read limit exceeded")
+
+// ReadFromOption is an option of the [File.ReadFromContext] method.
+type ReadFromOption func(*ingestReader)
+
+// ReadLimit is a [File.ReadFromContext] option limiting the number of bytes
+// read from the source to n. The limit of zero or less means no limit.
+func ReadLimit(n int64) ReadFromOption {
+	return func(ir *ingestReader) { ir.limit = n }
+}
+
+// ReadProgress is a [File.ReadFromContext] option setting the function called
+// after every read from the source with the total number of bytes read so
+// far. The function is called synchronously, so a slow consumer can apply
+// back-pressure by blocking in it.
+func ReadProgress(fn func(total int64)) ReadFromOption {
+	return func(ir *ingestReader) { ir.progress = fn }
+}
+
+// ReadFromContext works like [File.ReadFrom], but stops reading when the
+// context is done and can limit the number of bytes read and report the
+// progress, see [ReadLimit] and [ReadProgress]. Use it to ingest large
+// uploads which must be bounded or cancellable. The context is checked before
+// every read from r, so a blocked read is not interrupted. The bytes read
+// before the reading stopped stay written.
+//
+// Returns the context error when the context is done, an error of the
+// [fs.PathError] type with [syscall.EFBIG] when r has more bytes than the
+// limit allows, in which case the bytes up to the limit are written, and the
+// errors returned by [File.ReadFrom].
+func (fil *File) ReadFromContext(
+	ctx context.Context,
+	r io.Reader,
+	opts ...ReadFromOption,
+) (int64, error) {
+	ir := &ingestReader{ctx: ctx, r: r}
+	for _, opt := range opts {
+		opt(ir)
+	}
+	n, err := fil.ReadFrom(ir)
+	if errors.Is(err, errReadLimit) {
+		err = fil.hookErr(&fs.PathError{
+			Op:   "write",
+			Path: fil.path(),
+			Err:  syscall.EFBIG,
+		})
+	}
+	return n, err
+}
+
+// ingestReader is the reader used by [File.ReadFromContext] to check the
+// context, enforce the limit and report the progress.
+type ingestReader struct {
+	ctx      context.Context   // Context checked before every read.
+	r        io.Reader         // The source.
+	limit    int64             // Read limit, see [ReadLimit].
+	progress func(total int64) // See [ReadProgress].
+	total    int64             // Number of bytes read so far.
+}
+
+// Read implements [io.Reader] interface.
+func (ir *ingestReader) Read(p []byte) (int, error) {
+	if err := ir.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if ir.limit > 0 {
+		left := ir.limit - ir.total
+		if left == 0 {
+			// Probe the source to tell the exceeded limit from the end.
+			var probe [1]byte
+			n, err := ir.r.Read(probe[:])
+			if n > 0 {
+				return 0, errReadLimit
+			}
+			return 0, err
+		}
+		p = p[:min(int64(len(p)), left)]
+	}
+	n, err := ir.r.Read(p)
+	ir.total += int64(n)
+	if n > 0 && ir.progress != nil {
+		ir.progress(ir.total)
+	}
+	return n, err
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_ReadFromContext(t *testing.T) {
+	t.Run("no options", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithFileAppend)
+
+		// --- When ---
+		n, err := fil.ReadFromContext(t.Context(), strings.NewReader("def"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, "abcdef", string(fil.buf))
+	})
+
+	t.Run("within the limit", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		src := strings.NewReader("abc")
+
+		// --- When ---
+		n, err := fil.ReadFromContext(t.Context(), src, ReadLimit(3))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, "abc", string(fil.buf))
+	})
+
+	t.Run("zero limit", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		src := strings.NewReader("abc")
+
+		// --- When ---
+		n, err := fil.ReadFromContext(t.Context(), src, ReadLimit(0))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	t.Run("error - limit exceeded", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		src := strings.NewReader("abcdef")
+
+		// --- When ---
+		n, err := fil.ReadFromContext(t.Context(), src, ReadLimit(4))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "write", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.EFBIG, e.Err)
+		assert.Equal(t, int64(4), n)
+		assert.Equal(t, "abcd", string(fil.buf))
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		src := iotest.OneByteReader(strings.NewReader("abc"))
+		var have []int64
+		progress := func(total int64) { have = append(have, total) }
+
+		// --- When ---
+		n, err := fil.ReadFromContext(t.Context(), src, ReadProgress(progress))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, []int64{1, 2, 3}, have)
+	})
+
+	t.Run("error - canceled", func(t *testing.T) {
+		// --- Given ---
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		fil := MustFileWith("file", []byte("abc"))
+
+		// --- When ---
+		n, err := fil.ReadFromContext(ctx, strings.NewReader("def"))
+
+		// --- Then ---
+		assert.ErrorIs(t, context.Canceled, err)
+		assert.Equal(t, int64(0), n)
+		assert.Equal(t, "abc", string(fil.buf))
+	})
+
+	t.Run("error - canceled while reading", func(t *testing.T) {
+		// --- Given ---
+		ctx, cancel := context.WithCancel(t.Context())
+		fil := MustFile("file")
+		src := iotest.OneByteReader(strings.NewReader("abcdef"))
+		progress := func(total int64) {
+			if total == 2 {
+				cancel()
+			}
+		}
+
+		// --- When ---
+		n, err := fil.ReadFromContext(ctx, src, ReadProgress(progress))
+
+		// --- Then ---
+		assert.ErrorIs(t, context.Canceled, err)
+		assert.Equal(t, int64(2), n)
+		assert.Equal(t, "ab", string(fil.buf))
+	})
+
+	t.Run("error - source", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("test error")
+		fil := MustFile("file")
+
+		// --- When ---
+		n, err := fil.ReadFromContext(t.Context(), iotest.ErrReader(e))
+
+		// --- Then ---
+		assert.ErrorIs(t, e, err)
+		assert.Equal(t, int64(0), n)
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- Given ---
+		dir := must.Value(NewDirectory("dir"))
+
+		// --- When ---
+		n, err := dir.ReadFromContext(t.Context(), strings.NewReader("abc"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EISDIR, err)
+		assert.Equal(t, int64(0), n)
+	})
+
+	t.Run("error hook", func(t *testing.T) {
+		// --- Given ---
+		var have error
+		hook := func(err error) error { have = err; return err }
+		fil := MustFile("file", WithErrorHook(hook))
+		src := strings.NewReader("abc")
+
+		// --- When ---
+		_, err := fil.ReadFromContext(t.Context(), src, ReadLimit(1))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EFBIG, err)
+		assert.ErrorIs(t, syscall.EFBIG, have)
+	})
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"regexp"
+)
+
+// Redaction is a rule applied to the file contents when exporting the tree
+// with [File.WriteTar] or [File.Txtar]. It returns the content to export for
+// the file with the given path relative to the exported directory. The rules
+// must not modify the data slice.
+type Redaction func(pth string, data []byte) ([]byte, error)
+
+// RedactPaths returns a [Redaction] replacing the whole content of the files
+// with paths matching the pattern with repl. The pattern syntax is the same
+// as in [File.GlobEx]. The export returns [path.ErrBadPattern] when the
+// pattern is malformed.
+//
+//	tree.Txtar(memfs.RedactPaths("**/secret*", "***"))
+func RedactPaths(pattern, repl string) Redaction {
+	pts, err := expandBraces(pattern)
+	for _, pt := range pts {
+		if err == nil {
+			err = validGlob(pt)
+		}
+	}
+	return func(pth string, data []byte) ([]byte, error) {
+		if err != nil {
+			return nil, err
+		}
+		for _, pt := range pts {
+			if ok, _ := matchGlob(pt, pth); ok {
+				return []byte(repl), nil
+			}
+		}
+		return data, nil
+	}
+}
+
+// RedactRegexp returns a [Redaction] replacing the matches of the regular
+// expression in the contents of all files with repl. Inside repl, "$" signs
+// are interpreted as in [regexp.Regexp.Expand].
+//
+//	tree.Txtar(memfs.RedactRegexp(regexp.MustCompile(`token=\w+`), "token=***"))
+func RedactRegexp(re *regexp.Regexp, repl string) Redaction {
+	return func(_ string, data []byte) ([]byte, error) {
+		return re.ReplaceAll(data, []byte(repl)), nil
+	}
+}
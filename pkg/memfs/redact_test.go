@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"path"
+	"regexp"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_RedactPaths(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pattern string
+		pth     string
+		want    string
+	}{
+		{"match", "**/secret*", "a/b/secret.txt", "***"},
+		{"match in root", "**/secret*", "secret", "***"},
+		{"alternatives", "{a,b}/*", "b/file", "***"},
+		{"no match", "**/secret*", "a/public", "data"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			rule := RedactPaths(tc.pattern, "***")
+
+			// --- When ---
+			have, err := rule(tc.pth, []byte("data"))
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, string(have))
+		})
+	}
+
+	t.Run("error - bad pattern", func(t *testing.T) {
+		// --- Given ---
+		rule := RedactPaths("a/[", "***")
+
+		// --- When ---
+		have, err := rule("a/b", []byte("data"))
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - unbalanced braces", func(t *testing.T) {
+		// --- Given ---
+		rule := RedactPaths("{a,b", "***")
+
+		// --- When ---
+		have, err := rule("a", []byte("data"))
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_RedactRegexp(t *testing.T) {
+	t.Run("replace matches", func(t *testing.T) {
+		// --- Given ---
+		re := regexp.MustCompile(`(token)=\w+`)
+		rule := RedactRegexp(re, "$1=***")
+
+		// --- When ---
+		have, err := rule("file", []byte("a token=abc b token=def"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "a token=*** b token=***", string(have))
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		// --- Given ---
+		rule := RedactRegexp(regexp.MustCompile(`token=\w+`), "***")
+
+		// --- When ---
+		have, err := rule("file", []byte("data"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "data", string(have))
+	})
+}
@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"syscall"
+)
+
+// Remove removes the file or the empty directory with the given name from the
+// directory or its subdirectories, like [os.Remove] does. The handles to the
+// removed file stay usable, but the file is no longer part of the tree. The
+// symbolic links (see [File.Symlink]) are removed, not the files they point to.
+// In the write-through mode (see [File.MirrorTo]), the file is also removed
+// from the OS file system.
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.ENOTDIR] when the instance is not a directory,
+//   - [fs.ErrInvalid] when the name is not valid or is ".",
+//   - [fs.ErrNotExist] when the file does not exist,
+//   - [syscall.ENOTEMPTY] when the directory is not empty,
+//   - [syscall.EPERM] when the file or its parent directory has attribute
+//     flags (see [Attr]) set,
+//   - [syscall.EROFS] when the file is read-only (see [WithReadOnly]),
+//   - [syscall.EBUSY] when the file is open and the sharing violations are
+//     turned on (see [WithSharingViolations]),
+//
+// and the errors of the [os] package in the write-through mode.
+func (fil *File) Remove(name string) error {
+	ent, err := fil.removeEntry(name)
+	if err != nil {
+		return err
+	}
+	if len(ent.entries) > 0 {
+		return fil.hookErr(&fs.PathError{
+			Op:   "remove",
+			Path: name,
+			Err:  syscall.ENOTEMPTY,
+		})
+	}
+	if err = ent.checkRemove(); err != nil {
+		return err
+	}
+	return ent.detach(os.Remove)
+}
+
+// RemoveAll removes the file or the directory with the given name, along
+// with all its children, from the directory or its subdirectories, like
+// [os.RemoveAll] does. It returns nil when the file does not exist. Either
+// the whole tree is removed or nothing is. In the write-through mode (see
+// [File.MirrorTo]), the tree is also removed from the OS file system. The
+// progress is reported to the function set with [WithProgress].
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.ENOTDIR] when the instance is not a directory,
+//   - [fs.ErrInvalid] when the name is not valid or is ".",
+//   - [syscall.EPERM] when any of the removed files or the parent directory
+//     has attribute flags (see [Attr]) set,
+//   - [syscall.EROFS] when the file is read-only (see [WithReadOnly]),
+//   - [syscall.EBUSY] when any of the removed files is open and the sharing
+//     violations are turned on (see [WithSharingViolations]),
+//
+// and the errors of the [os] package in the write-through mode.
+func (fil *File) RemoveAll(name string) error {
+	return fil.removeAll(name, true)
+}
+
+// removeAll implements [File.RemoveAll]. The progress is reported only when
+// report is true.
+func (fil *File) removeAll(name string, report bool) error {
+	ent, err := fil.removeEntry(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = ent.checkRemove(); err != nil {
+		return err
+	}
+	ent.walk("", func(_ string, sub *File) {
+		if err == nil {
+			err = sub.checkRemove()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	var prog *progress
+	if report {
+		prog = fil.progress(ent.contentSize)
+	}
+	if err = ent.detach(os.RemoveAll); err != nil {
+		return err
+	}
+	if prog == nil {
+		return nil
+	}
+	if ent.Mode().IsRegular() {
+		prog.add(name, int64(ent.Len()))
+	}
+	ent.walk(name, func(pth string, sub *File) {
+		if sub.Mode().IsRegular() {
+			prog.add(pth, int64(sub.Len()))
+		}
+	})
+	return nil
+}
+
+// removeEntry returns the file with the given name for [File.Remove] and
+// [File.RemoveAll].
+func (fil *File) removeEntry(name string) (*File, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "remove",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	name = fil.lenientPath(name)
+	if !fs.ValidPath(name) || name == "." {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "remove",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		})
+	}
+	ent, err := lopen(fil, name)
+	if err != nil {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "remove",
+			Path: name,
+			Err:  unwrapPathErr(err),
+		})
+	}
+	return ent, nil
+}
+
+// checkRemove returns an error of the [fs.PathError] type with
+// [syscall.EROFS] when the instance is read-only (see [WithReadOnly]), with
+// [syscall.EPERM] when the instance or its parent has attribute flags (see
+// [Attr]) set, and with [syscall.EBUSY] when the instance is open and the
+// sharing violations are turned on (see [WithSharingViolations]).
+func (fil *File) checkRemove() error {
+	if err := fil.checkReadOnly("remove"); err != nil {
+		return err
+	}
+	if err := fil.checkSharing("remove"); err != nil {
+		return err
+	}
+	for _, f := range []*File{fil, fil.parent} {
+		if f.attr != 0 {
+			return fil.hookErr(&fs.PathError{
+				Op:   "remove",
+				Path: f.path(),
+				Err:  syscall.EPERM,
+			})
+		}
+	}
+	return nil
+}
+
+// detach removes the instance from the entries of its parent. In the
+// write-through mode, it calls rm with the OS path of the instance.
+func (fil *File) detach(rm func(name string) error) error {
+	pth := fil.mirrorPath()
+	fil.unlink()
+	if pth != "" {
+		return rm(pth)
+	}
+	return nil
+}
+
+// unlink removes the instance from the entries of its parent.
+func (fil *File) unlink() {
+	par := fil.parent
+	par.entries = slices.DeleteFunc(par.entries, func(f *File) bool {
+		return f == fil
+	})
+	fil.parent = nil
+}
@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Remove(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "sub/file3"))
+
+		// --- When ---
+		err := dir.Remove("sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = open(dir, "sub/file3")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, fil.parent)
+		assert.Equal(t, "file3", fil.String())
+		assert.Len(t, 2, must.Value(open(dir, "sub")).entries)
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.MkdirAll("sub/empty", 0700))
+
+		// --- When ---
+		err := dir.Remove("sub/empty")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = open(dir, "sub/empty")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("write-through", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+
+		// --- When ---
+		err := dir.Remove("sub/sub2/file5")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(dst, "sub/sub2/file5"))
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		have := readOS(t, filepath.Join(dst, "sub/sub2/file6"))
+		assert.Equal(t, "file6", have)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").Remove("name")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "remove", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		for _, name := range []string{"../file0", "/file0", ".", ""} {
+			// --- When ---
+			err := tstDirMem().Remove(name)
+
+			// --- Then ---
+			var e *fs.PathError
+			assert.ErrorAs(t, &e, err)
+			assert.Equal(t, "remove", e.Op)
+			assert.Equal(t, name, e.Path)
+			assert.Equal(t, fs.ErrInvalid, e.Err)
+		}
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().Remove("sub/not-existing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "remove", e.Op)
+		assert.Equal(t, "sub/not-existing", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+	})
+
+	t.Run("error - directory not empty", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Remove("sub/sub2")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "remove", e.Op)
+		assert.Equal(t, "sub/sub2", e.Path)
+		assert.Equal(t, syscall.ENOTEMPTY, e.Err)
+		assert.NotNil(t, must.Value(open(dir, "sub/sub2")).parent)
+	})
+
+	t.Run("error - file attributes", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(open(dir, "sub/file3")).SetAttr(AttrImmutable)
+
+		// --- When ---
+		err := dir.Remove("sub/file3")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "remove", e.Op)
+		assert.Equal(t, "sub/file3", e.Path)
+		assert.Equal(t, syscall.EPERM, e.Err)
+	})
+
+	t.Run("error - parent attributes", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(open(dir, "sub")).SetAttr(AttrImmutable)
+
+		// --- When ---
+		err := dir.Remove("sub/file3")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "sub", e.Path)
+		assert.Equal(t, syscall.EPERM, e.Err)
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithReadOnly(dir)
+
+		// --- When ---
+		err := dir.Remove("file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Len(t, 4, dir.entries)
+	})
+}
+
+func Test_File_RemoveAll(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.RemoveAll("sub")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"file0", "file1", "file2"}, names(dir.Files()))
+		assert.Len(t, 0, dir.Dirs())
+	})
+
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.RemoveAll("file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 3, dir.entries)
+	})
+
+	t.Run("not existing", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.RemoveAll("sub/not-existing")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 4, dir.entries)
+	})
+
+	t.Run("write-through", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+
+		// --- When ---
+		err := dir.RemoveAll("sub/sub2")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(dst, "sub/sub2"))
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Equal(t, "file3", readOS(t, filepath.Join(dst, "sub/file3")))
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").RemoveAll("name")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().RemoveAll(".")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "remove", e.Op)
+		assert.Equal(t, ".", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+	})
+
+	t.Run("error - attributes in the tree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(open(dir, "sub/sub2/file6")).SetAttr(AttrAppendOnly)
+
+		// --- When ---
+		err := dir.RemoveAll("sub")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "remove", e.Op)
+		assert.Equal(t, "sub/sub2/file6", e.Path)
+		assert.Equal(t, syscall.EPERM, e.Err)
+		must.Value(open(dir, "sub/file3"))
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithReadOnly(must.Value(open(dir, "sub")))
+
+		// --- When ---
+		err := dir.RemoveAll("sub")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Len(t, 4, dir.entries)
+	})
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"syscall"
+)
+
+// Rename moves the file or the directory, with its subtree, from the oldpath to
+// the newpath in the directory or its subdirectories, like [os.Rename] does.
+// The parent directory of the newpath must exist. When the newpath exists, it's
+// replaced, but a directory can only replace an empty directory and a file can
+// only replace a file. Renaming a file to itself does nothing, except in the
+// case-insensitive mode (see [WithCaseInsensitive]), where it changes the case
+// of the name. The symbolic links (see [File.Symlink]) are renamed, not the
+// files they point to. The renames are reported to the hook set with
+// [WithRenameHook]. In the write-through mode (see [File.MirrorTo]), the file
+// is also renamed in the OS file system.
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.ENOTDIR] when the instance is not a directory, the parent of
+//     the newpath is not a directory, or a directory would replace a file,
+//   - [fs.ErrInvalid] when any of the paths is not valid or is ".",
+//   - [fs.ErrNotExist] when the oldpath or the parent of the newpath does not
+//     exist,
+//   - [syscall.EINVAL] when the newpath is inside the oldpath directory,
+//   - [syscall.EISDIR] when a file would replace a directory,
+//   - [syscall.ENOTEMPTY] when the newpath is a directory which is not empty,
+//   - [syscall.EPERM] when any of the files or their parent directories has
+//     attribute flags (see [Attr]) set,
+//   - [syscall.EROFS] when any of the files is read-only (see
+//     [WithReadOnly]),
+//   - [syscall.EBUSY] when the renamed or replaced file is open and the
+//     sharing violations are turned on (see [WithSharingViolations]),
+//   - [syscall.ENOSPC] when moving the file to the newpath exceeds any of
+//     the quotas (see [WithQuota]),
+//
+// and the errors of the [os] package in the write-through mode.
+func (fil *File) Rename(oldpath, newpath string) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "rename",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	oldpath = fil.lenientPath(oldpath)
+	newpath = fil.lenientPath(newpath)
+	for _, pth := range []string{oldpath, newpath} {
+		if !fs.ValidPath(pth) || pth == "." {
+			return fil.renameErr(pth, fs.ErrInvalid)
+		}
+	}
+	ent, err := fil.renameEntry(oldpath, oldpath, false)
+	if err != nil {
+		return err
+	}
+	dir, err := fil.renameEntry(path.Dir(newpath), newpath, true)
+	if err != nil {
+		return err
+	}
+	if !dir.IsDir() {
+		return fil.renameErr(newpath, syscall.ENOTDIR)
+	}
+	if dir == ent || IsAncestor(ent, dir) {
+		return fil.renameErr(newpath, syscall.EINVAL)
+	}
+
+	name := path.Base(newpath)
+	dst, _ := lopen(dir, name)
+	caseOnly := dst == ent && ent.Name() != name
+	if dst == ent && !caseOnly {
+		return nil
+	}
+	if caseOnly {
+		dst = nil
+	}
+	if dst != nil {
+		switch {
+		case ent.IsDir() && !dst.IsDir():
+			return fil.renameErr(newpath, syscall.ENOTDIR)
+		case !ent.IsDir() && dst.IsDir():
+			return fil.renameErr(newpath, syscall.EISDIR)
+		case len(dst.entries) > 0:
+			return fil.renameErr(newpath, syscall.ENOTEMPTY)
+		}
+	}
+
+	checks := []*File{ent, ent.parent, dir}
+	if dst != nil {
+		checks = append(checks, dst)
+	}
+	for _, f := range checks {
+		if err = f.checkReadOnly("rename"); err != nil {
+			return err
+		}
+	}
+	for _, f := range []*File{ent, dst} {
+		if f == nil {
+			continue
+		}
+		if err = f.checkSharing("rename"); err != nil {
+			return err
+		}
+	}
+	for _, f := range checks {
+		if f.attr != 0 {
+			return fil.hookErr(&fs.PathError{
+				Op:   "rename",
+				Path: f.path(),
+				Err:  syscall.EPERM,
+			})
+		}
+	}
+	if !dir.moveFits(ent, dst) {
+		return fil.renameErr(newpath, syscall.ENOSPC)
+	}
+
+	src, oldPth := ent.mirrorPath(), ent.path()
+	if dst != nil {
+		dst.unlink()
+	}
+	ent.unlink()
+	ent.info.name = name
+	dir.entries = append(dir.entries, ent)
+	ent.parent = dir
+	if hook := ent.renamesHook(); hook != nil {
+		hook(RenameEvent{
+			OldPath:  oldPth,
+			NewPath:  ent.path(),
+			CaseOnly: caseOnly,
+		})
+	}
+	return mirrorRename(ent, src)
+}
+
+// renameEntry returns the file with the given name for [File.Rename],
+// following the symbolic link named by the last element of the name when
+// follow is true. The pth is used as the path in the returned errors.
+func (fil *File) renameEntry(name, pth string, follow bool) (*File, error) {
+	ent, err := lookup(fil, name, follow)
+	if err != nil {
+		return nil, fil.renameErr(pth, unwrapPathErr(err))
+	}
+	return ent, nil
+}
+
+// renameErr returns an error of the [fs.PathError] type for [File.Rename].
+func (fil *File) renameErr(name string, err error) error {
+	return fil.hookErr(&fs.PathError{Op: "rename", Path: name, Err: err})
+}
+
+// mirrorRename applies the rename of the file in the write-through mode. The
+// src must be the path returned by [File.mirrorPath] before the rename.
+func mirrorRename(fil *File, src string) error {
+	dst := fil.mirrorPath()
+	switch {
+	case src != "" && dst != "":
+		return os.Rename(src, dst)
+	case dst != "":
+		return fil.mirrorTree(dst)
+	case src != "":
+		return os.RemoveAll(src)
+	}
+	return nil
+}
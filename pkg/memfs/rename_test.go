@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Rename(t *testing.T) {
+	t.Run("file in the same directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file0"))
+
+		// --- When ---
+		err := dir.Rename("file0", "renamed")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, fil, must.Value(open(dir, "renamed")))
+		assert.Equal(t, "renamed", fil.Name())
+		_, err = open(dir, "file0")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Len(t, 4, dir.entries)
+	})
+
+	t.Run("directory to other directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub2 := must.Value(open(dir, "sub/sub2"))
+
+		// --- When ---
+		err := dir.Rename("sub/sub2", "moved")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, dir, sub2.parent)
+		assert.Len(t, 2, must.Value(open(dir, "sub")).entries)
+		have := must.Value(open(dir, "moved/file5"))
+		assert.Same(t, sub2, have.parent)
+		assert.Equal(t, "moved/file5", have.path())
+	})
+
+	t.Run("replaces file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file0"))
+		old := must.Value(open(dir, "sub/file3"))
+
+		// --- When ---
+		err := dir.Rename("file0", "sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, fil, must.Value(open(dir, "sub/file3")))
+		assert.Nil(t, old.parent)
+		assert.Len(t, 3, must.Value(open(dir, "sub")).entries)
+	})
+
+	t.Run("replaces empty directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.MkdirAll("empty", 0700))
+
+		// --- When ---
+		err := dir.Rename("sub/sub2", "empty")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file5", must.Value(open(dir, "empty/file5")).String())
+		assert.Len(t, 5, dir.entries)
+	})
+
+	t.Run("to itself", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Rename("sub/file3", "sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		must.Value(open(dir, "sub/file3"))
+	})
+
+	t.Run("write-through", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+
+		// --- When ---
+		err := dir.Rename("sub/sub2", "moved")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(dst, "sub/sub2"))
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		have := readOS(t, filepath.Join(dst, "moved/file6"))
+		assert.Equal(t, "file6", have)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").Rename("a", "b")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "rename", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - invalid paths", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			old  string
+			new  string
+			path string
+		}{
+			{"old", "../file0", "file9", "../file0"},
+			{"old dot", ".", "file9", "."},
+			{"new", "file0", "/file9", "/file9"},
+			{"new dot", "file0", ".", "."},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- When ---
+				err := tstDirMem().Rename(tc.old, tc.new)
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "rename", e.Op)
+				assert.Equal(t, tc.path, e.Path)
+				assert.Equal(t, fs.ErrInvalid, e.Err)
+			})
+		}
+	})
+
+	t.Run("error - replacing", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			old  string
+			new  string
+			want error
+		}{
+			{"old does not exist", "not-existing", "file9", fs.ErrNotExist},
+			{"new parent missing", "file0", "missing/file0", fs.ErrNotExist},
+			{"new parent is a file", "file0", "file1/file0", syscall.ENOTDIR},
+			{"directory into itself", "sub", "sub/sub2/sub", syscall.EINVAL},
+			{"directory to itself parent", "sub", "sub/x", syscall.EINVAL},
+			{"directory over file", "sub/sub2", "file0", syscall.ENOTDIR},
+			{"file over directory", "file0", "sub/sub2", syscall.EISDIR},
+			{"over own parent", "sub/sub2", "sub", syscall.ENOTEMPTY},
+			{"over not empty", "sub/sub2", "other", syscall.ENOTEMPTY},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				dir := tstDirMem()
+				must.Nil(dir.WriteFile("other/file", nil, 0600))
+
+				// --- When ---
+				err := dir.Rename(tc.old, tc.new)
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "rename", e.Op)
+				assert.Equal(t, tc.want, e.Err)
+				must.Value(open(dir, "file0"))
+				must.Value(open(dir, "sub/sub2/file5"))
+			})
+		}
+	})
+
+	t.Run("error - directory into deep descendant", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		deep := "sub/sub2/" + strings.Repeat("d/", 50) + "d"
+		must.Nil(dir.MkdirAll(deep, 0700))
+
+		// --- When ---
+		err := dir.Rename("sub", deep+"/sub")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+		assert.Same(t, dir, must.Value(open(dir, "sub")).parent)
+		must.Value(open(dir, deep))
+	})
+
+	t.Run("error - directory into descendant through link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub/sub2", "link"))
+
+		// --- When ---
+		err := dir.Rename("sub", "link/sub")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+		must.Value(open(dir, "sub/sub2/file5"))
+	})
+
+	t.Run("error - attributes", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(open(dir, "sub/sub2")).SetAttr(AttrImmutable)
+
+		// --- When ---
+		err := dir.Rename("file0", "sub/sub2/file0")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "rename", e.Op)
+		assert.Equal(t, "sub/sub2", e.Path)
+		assert.Equal(t, syscall.EPERM, e.Err)
+		must.Value(open(dir, "file0"))
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithReadOnly(must.Value(open(dir, "sub")))
+
+		// --- When ---
+		err := dir.Rename("file0", "sub/file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		must.Value(open(dir, "file0"))
+	})
+
+	t.Run("quota - replacing", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithQuota(3))
+
+		// --- When ---
+		err := root.Rename("file", "mnt/file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), must.Value(open(root, "mnt")).usage())
+	})
+
+	t.Run("quota - inside the tree", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithQuota(3))
+		must.Nil(root.MkdirAll("mnt/dir", 0700))
+
+		// --- When ---
+		err := root.Rename("mnt/file", "mnt/dir/file")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		must.Value(open(root, "mnt/dir/file"))
+	})
+
+	t.Run("error - quota", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithQuota(5))
+
+		// --- When ---
+		err := root.Rename("file", "mnt/new")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "rename", e.Op)
+		assert.Equal(t, "mnt/new", e.Path)
+		assert.Equal(t, syscall.ENOSPC, e.Err)
+		must.Value(open(root, "file"))
+	})
+}
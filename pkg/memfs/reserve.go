@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// Reservation is the space reserved with [File.Reserve]. The copies of the
+// reservation refer to the same reserved space.
+type Reservation struct {
+	*reservation
+}
+
+// reservation is the state of the [Reservation] shared by its copies.
+type reservation struct {
+	fil  *File // The file the space was reserved in.
+	size int64 // Number of reserved bytes, zero after the release.
+}
+
+// Reserve reserves n bytes in the quotas (see [WithQuota]) of the instance
+// and its parents, the way applications pre-allocating space, like download
+// managers, do. The reserved bytes count as used by the files until the
+// reservation is released with [Reservation.Release], so the writes and
+// other reservations exceeding the quotas fail. Writing the files doesn't
+// consume the reservation. Without the quotas, the reservation always
+// succeeds.
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.EINVAL] when n is negative,
+//   - [syscall.EROFS] when the instance is read-only (see [WithReadOnly]),
+//   - [syscall.ENOSPC] when reserving n bytes exceeds any of the quotas.
+func (fil *File) Reserve(n int64) (Reservation, error) {
+	if n < 0 {
+		return Reservation{}, fil.hookErr(&fs.PathError{
+			Op:   "reserve",
+			Path: fil.path(),
+			Err:  syscall.EINVAL,
+		})
+	}
+	if err := fil.checkReadOnly("reserve"); err != nil {
+		return Reservation{}, err
+	}
+	if err := fil.checkQuota("reserve", n); err != nil {
+		return Reservation{}, err
+	}
+	fil.reserved += n
+	return Reservation{&reservation{fil: fil, size: n}}, nil
+}
+
+// Size returns the number of reserved bytes or zero when the reservation was
+// released.
+func (res Reservation) Size() int64 {
+	if res.reservation == nil {
+		return 0
+	}
+	return res.size
+}
+
+// Release releases the reserved space. Releasing the zero value or already
+// released reservation does nothing.
+func (res Reservation) Release() {
+	if res.reservation == nil {
+		return
+	}
+	res.fil.reserved -= res.size
+	res.size = 0
+}
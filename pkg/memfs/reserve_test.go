@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Reserve(t *testing.T) {
+	t.Run("reserve", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithQuota(6)), "mnt"))
+
+		// --- When ---
+		have, err := mnt.Reserve(2)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), have.Size())
+		assert.Equal(t, int64(5), mnt.usage())
+	})
+
+	t.Run("counted by parent quota", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount()
+		WithQuota(10)(root)
+		mnt := must.Value(open(root, "mnt"))
+
+		// --- When ---
+		_, err := mnt.Reserve(4)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), root.usage())
+		_, err = root.Reserve(1)
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+	})
+
+	t.Run("writes fail when reserved", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithQuota(6)), "mnt"))
+		must.Value(mnt.Reserve(3))
+		fil := must.Value(open(mnt, "file"))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte("d"), 3)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithQuota(6)), "mnt"))
+		res := must.Value(mnt.Reserve(3))
+		fil := must.Value(open(mnt, "file"))
+
+		// --- When ---
+		res.Release()
+
+		// --- Then ---
+		assert.Equal(t, int64(0), res.Size())
+		assert.Equal(t, int64(3), mnt.usage())
+		assert.Equal(t, 3, must.Value(fil.WriteAt([]byte("def"), 3)))
+	})
+
+	t.Run("release twice", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithQuota(6)), "mnt"))
+		res0 := must.Value(mnt.Reserve(1))
+		res1 := must.Value(mnt.Reserve(2))
+		res0.Release()
+
+		// --- When ---
+		res0.Release()
+
+		// --- Then ---
+		assert.Equal(t, int64(2), res1.Size())
+		assert.Equal(t, int64(5), mnt.usage())
+	})
+
+	t.Run("released copy", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithQuota(6)), "mnt"))
+		res := must.Value(mnt.Reserve(3))
+		cp := res
+
+		// --- When ---
+		cp.Release()
+
+		// --- Then ---
+		assert.Equal(t, int64(0), res.Size())
+		assert.Equal(t, int64(3), mnt.usage())
+	})
+
+	t.Run("release zero value", func(t *testing.T) {
+		// --- Given ---
+		var res Reservation
+
+		// --- When ---
+		res.Release()
+
+		// --- Then ---
+		assert.Equal(t, int64(0), res.Size())
+	})
+
+	t.Run("no quota", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.Reserve(1 << 40)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1<<40), have.Size())
+	})
+
+	t.Run("error - quota exhausted", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithQuota(6)), "mnt"))
+		must.Value(mnt.Reserve(2))
+
+		// --- When ---
+		have, err := mnt.Reserve(2)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "reserve", e.Op)
+		assert.Equal(t, "mnt", e.Path)
+		assert.Equal(t, syscall.ENOSPC, e.Err)
+		assert.Equal(t, int64(0), have.Size())
+		assert.Equal(t, int64(5), mnt.usage())
+	})
+
+	t.Run("error - negative", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		_, err := dir.Reserve(-1)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EINVAL, err)
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithReadOnly), "mnt"))
+
+		// --- When ---
+		_, err := mnt.Reserve(1)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+	})
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+)
+
+// WithContentScanner is a [File] constructor function option setting a
+// content scanner. The scanner is called by [File.Close] with the file path
+// and its content when the file was modified since it was created or last
+// closed. When the scanner returns an error, the modifications are rolled
+// back, and Close returns an error of the [fs.PathError] type wrapping the
+// scanner error. The scanner must not modify or retain the data slice.
+//
+// The scanner set on a directory is used for all files in its tree unless a
+// file or a directory closer to them in the hierarchy has its own scanner.
+func WithContentScanner(scan func(pth string, data []byte) error) func(*File) {
+	return func(fil *File) { fil.scan = scan }
+}
+
+// scanner returns the content scanner set with [WithContentScanner] on the
+// instance or the closest of its parents. Returns nil when no scanner was set.
+func (fil *File) scanner() func(pth string, data []byte) error {
+	for f := fil; f != nil; f = f.parent {
+		if f.scan != nil {
+			return f.scan
+		}
+	}
+	return nil
+}
+
+// snapshot records the modification of the instance and keeps a copy of the
+// file content before the first modification, so it can be restored when the
+// content scanner rejects the modifications. It doesn't copy the content when
+// the snapshot was already taken, or there is no content scanner.
+func (fil *File) snapshot() {
+	fil.wrote = true
+	if fil.dirty || fil.scanner() == nil {
+		return
+	}
+	fil.materialize()
+	fil.prev = slices.Clone(fil.buf)
+	fil.dirty = true
+}
+
+// scanContent runs the content scanner on modified content. When the scanner
+// returns an error, it restores the content from before the modifications.
+func (fil *File) scanContent() error {
+	if !fil.dirty {
+		return nil
+	}
+	prev := fil.prev
+	fil.prev = nil
+	fil.dirty = false
+
+	scan := fil.scanner()
+	if scan == nil {
+		return nil
+	}
+	if err := scan(fil.path(), fil.buf); err != nil {
+		zeroOutSlice(fil.buf)
+		fil.buf = prev
+		fil.updateSum()
+		return errors.Join(fil.hookErr(&fs.PathError{
+			Op:   "close",
+			Path: fil.path(),
+			Err:  err,
+		}), fil.mirrorContent())
+	}
+	return nil
+}
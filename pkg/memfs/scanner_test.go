@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// errSecret is returned by the secretScanner.
+var errSecret = errors.New("secret found")
+
+// secretScanner is a content scanner rejecting content with the "secret"
+// word.
+func secretScanner(_ string, data []byte) error {
+	if bytes.Contains(data, []byte("secret")) {
+		return errSecret
+	}
+	return nil
+}
+
+func Test_WithContentScanner(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+
+	// --- When ---
+	WithContentScanner(secretScanner)(fil)
+
+	// --- Then ---
+	assert.NotNil(t, fil.scan)
+}
+
+func Test_File_scanner(t *testing.T) {
+	t.Run("no scanner", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have := fil.scanner()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("parent scanner", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithContentScanner(secretScanner))
+		fil := MustFile("file")
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		have := fil.scanner()
+
+		// --- Then ---
+		assert.NotNil(t, have)
+	})
+}
+
+func Test_File_contentScanner(t *testing.T) {
+	t.Run("accepted content", func(t *testing.T) {
+		// --- Given ---
+		var paths []string
+		scan := func(pth string, data []byte) error {
+			paths = append(paths, pth+":"+string(data))
+			return nil
+		}
+		root := NewRoot(WithContentScanner(scan))
+		sub := MustDirectory("sub")
+		must.Nil(root.AddFile(sub))
+		fil := MustFileWith("file", []byte("abc"))
+		must.Nil(sub.AddFile(fil))
+		must.Value(fil.Write([]byte("x")))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sub/file:xbc"}, paths)
+		assert.False(t, fil.dirty)
+		assert.Nil(t, fil.prev)
+	})
+
+	t.Run("not modified content is not scanned", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		scan := func(string, []byte) error { calls++; return nil }
+		fil := MustFileWith("file", []byte("abc"), WithContentScanner(scan))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("error - rejected Write", func(t *testing.T) {
+		// --- Given ---
+		opt := WithContentScanner(secretScanner)
+		fil := MustFileWith("file", []byte("abc"), opt)
+		fil.SeekEnd()
+		must.Value(fil.Write([]byte(" secret")))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "close", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.ErrorIs(t, errSecret, e.Err)
+		assert.Equal(t, []byte("abc"), fil.buf)
+		assert.Equal(t, 0, fil.off)
+	})
+
+	t.Run("error - rejected WriteAt", func(t *testing.T) {
+		// --- Given ---
+		opt := WithContentScanner(secretScanner)
+		fil := MustFileWith("file", []byte("abc"), opt)
+		must.Value(fil.WriteAt([]byte("secret"), 10))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.ErrorIs(t, errSecret, err)
+		assert.Equal(t, []byte("abc"), fil.buf)
+	})
+
+	t.Run("error - rejected ReadFrom", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithContentScanner(secretScanner))
+		must.Value(fil.ReadFrom(bytes.NewReader([]byte("secret"))))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.ErrorIs(t, errSecret, err)
+		assert.Len(t, 0, fil.buf)
+	})
+
+	t.Run("error - rejected Truncate", func(t *testing.T) {
+		// --- Given ---
+		scan := func(_ string, data []byte) error {
+			if len(data) == 0 {
+				return errors.New("empty")
+			}
+			return nil
+		}
+		fil := MustFileWith("file", []byte("abc"), WithContentScanner(scan))
+		must.Nil(fil.Truncate(0))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.Equal(t, []byte("abc"), fil.buf)
+	})
+
+	t.Run("the next modification is scanned again", func(t *testing.T) {
+		// --- Given ---
+		opt := WithContentScanner(secretScanner)
+		fil := MustFileWith("file", []byte("abc"), opt)
+		must.Value(fil.Write([]byte("secret")))
+		assert.Error(t, fil.Close())
+		must.Value(fil.Write([]byte("x")))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("xbc"), fil.buf)
+	})
+}
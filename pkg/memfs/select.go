@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+)
+
+// Selector reports whether the file or directory with the given
+// slash-separated path and info is selected by [File.ExportSelected] or
+// [Tolerance.Select], so the exports and comparisons can be narrowed down
+// using the metadata, for example, to audit the permission handling of the
+// provisioning tools by looking only at the executable files. Selectors are
+// composed with [SelectAll], [SelectAny] and [SelectNot].
+type Selector func(pth string, info fs.FileInfo) bool
+
+// SelectPerm returns a [Selector] selecting the files and directories with
+// any of the permission bits in the mask set, for example, 0111 selects the
+// executable files and 0002 the world-writable ones.
+func SelectPerm(mask fs.FileMode) Selector {
+	mask &= fs.ModePerm
+	return func(_ string, info fs.FileInfo) bool {
+		return info.Mode()&mask != 0
+	}
+}
+
+// SelectType returns a [Selector] selecting the files of the given type:
+// [fs.ModeDir] for directories, [fs.ModeSymlink] for symbolic links or zero
+// for regular files. Other bits of the typ are ignored.
+func SelectType(typ fs.FileMode) Selector {
+	typ &= fs.ModeType
+	return func(_ string, info fs.FileInfo) bool {
+		return info.Mode().Type() == typ
+	}
+}
+
+// SelectAttr returns a [Selector] selecting the files and directories with
+// all the given attribute flags set (see [File.SetAttr]). The flags are read
+// from the [SysInfo] returned by the [fs.FileInfo.Sys] method, so the files
+// of other file systems, like [os.DirFS], are never selected.
+func SelectAttr(attr Attr) Selector {
+	return func(_ string, info fs.FileInfo) bool {
+		si, ok := info.Sys().(*SysInfo)
+		return ok && si.Attr&attr == attr
+	}
+}
+
+// SelectAll returns a [Selector] selecting the files and directories selected
+// by all the given selectors. With no selectors, it selects everything.
+func SelectAll(sels ...Selector) Selector {
+	return func(pth string, info fs.FileInfo) bool {
+		for _, sel := range sels {
+			if !sel(pth, info) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// SelectAny returns a [Selector] selecting the files and directories selected
+// by any of the given selectors. With no selectors, it selects nothing.
+func SelectAny(sels ...Selector) Selector {
+	return func(pth string, info fs.FileInfo) bool {
+		for _, sel := range sels {
+			if sel(pth, info) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SelectNot returns a [Selector] selecting the files and directories not
+// selected by the given selector.
+func SelectNot(sel Selector) Selector {
+	return func(pth string, info fs.FileInfo) bool {
+		return !sel(pth, info)
+	}
+}
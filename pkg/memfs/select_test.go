@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstInfo returns the [fs.FileInfo] of a file with the given mode.
+func tstInfo(mode fs.FileMode) fs.FileInfo {
+	return FileInfo{name: "file", mode: mode}
+}
+
+func Test_SelectPerm(t *testing.T) {
+	t.Run("any of the bits", func(t *testing.T) {
+		// --- Given ---
+		sel := SelectPerm(0111)
+
+		// --- Then ---
+		assert.True(t, sel("file", tstInfo(0100)))
+		assert.True(t, sel("file", tstInfo(0755)))
+		assert.False(t, sel("file", tstInfo(0644)))
+	})
+
+	t.Run("type bits are ignored", func(t *testing.T) {
+		// --- Given ---
+		sel := SelectPerm(fs.ModeDir | 0002)
+
+		// --- Then ---
+		assert.False(t, sel("dir", tstInfo(fs.ModeDir|0755)))
+		assert.True(t, sel("dir", tstInfo(fs.ModeDir|0777)))
+	})
+}
+
+func Test_SelectType(t *testing.T) {
+	t.Run("regular files", func(t *testing.T) {
+		// --- Given ---
+		sel := SelectType(0)
+
+		// --- Then ---
+		assert.True(t, sel("file", tstInfo(0644)))
+		assert.False(t, sel("dir", tstInfo(fs.ModeDir|0755)))
+		assert.False(t, sel("link", tstInfo(fs.ModeSymlink|0777)))
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		// --- Given ---
+		sel := SelectType(fs.ModeDir | 0755)
+
+		// --- Then ---
+		assert.True(t, sel("dir", tstInfo(fs.ModeDir|0700)))
+		assert.False(t, sel("file", tstInfo(0755)))
+	})
+}
+
+func Test_SelectAttr(t *testing.T) {
+	t.Run("all flags set", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithFileAttr(AttrImmutable|AttrAppendOnly))
+		sel := SelectAttr(AttrImmutable)
+
+		// --- Then ---
+		assert.True(t, sel("file", must.Value(fil.Stat())))
+	})
+
+	t.Run("not all flags set", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithFileAttr(AttrImmutable))
+		sel := SelectAttr(AttrImmutable | AttrAppendOnly)
+
+		// --- Then ---
+		assert.False(t, sel("file", must.Value(fil.Stat())))
+	})
+
+	t.Run("other file system", func(t *testing.T) {
+		// --- Given ---
+		sel := SelectAttr(AttrImmutable)
+
+		// --- Then ---
+		assert.False(t, sel("file", tstInfo(0644)))
+	})
+}
+
+func Test_SelectAll(t *testing.T) {
+	t.Run("all selected", func(t *testing.T) {
+		// --- Given ---
+		sel := SelectAll(SelectType(0), SelectPerm(0100))
+
+		// --- Then ---
+		assert.True(t, sel("file", tstInfo(0700)))
+		assert.False(t, sel("file", tstInfo(0600)))
+		assert.False(t, sel("dir", tstInfo(fs.ModeDir|0700)))
+	})
+
+	t.Run("no selectors", func(t *testing.T) {
+		// --- Then ---
+		assert.True(t, SelectAll()("file", tstInfo(0600)))
+	})
+}
+
+func Test_SelectAny(t *testing.T) {
+	t.Run("any selected", func(t *testing.T) {
+		// --- Given ---
+		sel := SelectAny(SelectType(fs.ModeDir), SelectPerm(0100))
+
+		// --- Then ---
+		assert.True(t, sel("file", tstInfo(0700)))
+		assert.True(t, sel("dir", tstInfo(fs.ModeDir|0600)))
+		assert.False(t, sel("file", tstInfo(0600)))
+	})
+
+	t.Run("no selectors", func(t *testing.T) {
+		// --- Then ---
+		assert.False(t, SelectAny()("file", tstInfo(0700)))
+	})
+}
+
+func Test_SelectNot(t *testing.T) {
+	// --- Given ---
+	sel := SelectNot(SelectPerm(0002))
+
+	// --- Then ---
+	assert.True(t, sel("file", tstInfo(0644)))
+	assert.False(t, sel("file", tstInfo(0666)))
+}
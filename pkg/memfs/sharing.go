@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// OpenShareDelete is a [File.OpenFile] flag allowing the file to be removed
+// or renamed while the returned handle is open, when the sharing violations
+// are turned on with [WithSharingViolations]. It's the equivalent of the
+// FILE_SHARE_DELETE share mode on Windows. The flag doesn't collide with
+// the flags of the [os] package.
+const OpenShareDelete = 0x40000000
+
+// WithSharingViolations is a [File] constructor function option making the
+// instance and, when used on a directory, all files in its tree behave like
+// the files on Windows, which can't be removed or renamed while they are
+// open. The [File.Remove], [File.RemoveAll] and [File.Rename] methods
+// return an error of the [fs.PathError] type with [syscall.EBUSY] when any
+// of the files they would remove, rename or replace has open handles (see
+// [File.OpenCount]), unless all of them were opened with [File.OpenFile]
+// and the [OpenShareDelete] flag. With the hook set with [WithPlatform] for
+// [PlatformWindows], the error is [WinErrSharingViolation]. Use it to test
+// the cross-platform code replacing the files which may be open.
+func WithSharingViolations(fil *File) { fil.sharing = true }
+
+// sharingViolations returns true when the [WithSharingViolations] option was
+// used on the instance or any of its parents.
+func (fil *File) sharingViolations() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.sharing {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSharing returns an error of the [fs.PathError] type with
+// [syscall.EBUSY] when the sharing violations are turned on (see
+// [WithSharingViolations]) and the instance has open handles which were not
+// opened with the [OpenShareDelete] flag. The op is used as the operation
+// name in the returned error.
+func (fil *File) checkSharing(op string) error {
+	if fil.refs-fil.shareDel <= 0 || !fil.sharingViolations() {
+		return nil
+	}
+	return fil.hookErr(&fs.PathError{
+		Op:   op,
+		Path: fil.path(),
+		Err:  syscall.EBUSY,
+	})
+}
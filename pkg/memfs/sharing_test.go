@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithSharingViolations(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		// --- Given ---
+		fil := &File{}
+
+		// --- When ---
+		WithSharingViolations(fil)
+
+		// --- Then ---
+		assert.True(t, fil.sharing)
+	})
+
+	t.Run("remove open file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		h := must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+
+		// --- When ---
+		err := dir.Remove("file0")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "remove", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EBUSY, e.Err)
+		assert.Same(t, h.File(), must.Value(open(dir, "file0")))
+	})
+
+	t.Run("remove all with open file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		must.Value(dir.Open("sub/sub2/file5"))
+
+		// --- When ---
+		err := dir.RemoveAll("sub")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EBUSY, err)
+		assert.Equal(t, "sub/sub2/file5", err.(*fs.PathError).Path)
+		assert.Len(t, 3, must.Value(open(dir, "sub")).entries)
+	})
+
+	t.Run("rename open file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		must.Value(dir.OpenFile("file0", os.O_RDWR, 0))
+
+		// --- When ---
+		err := dir.Rename("file0", "new")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "rename", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EBUSY, e.Err)
+	})
+
+	t.Run("rename replacing open file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		must.Value(dir.OpenFile("file1", os.O_RDONLY, 0))
+
+		// --- When ---
+		err := dir.Rename("file0", "file1")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EBUSY, err)
+		assert.Equal(t, "file1", err.(*fs.PathError).Path)
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("file0"))))
+	})
+
+	t.Run("share delete", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		flag := os.O_RDONLY | OpenShareDelete
+		h0 := must.Value(dir.OpenFile("file0", flag, 0))
+		h1 := must.Value(dir.OpenFile("file1", flag, 0))
+
+		// --- When ---
+		err0 := dir.Rename("file0", "new")
+		err1 := dir.Remove("file1")
+
+		// --- Then ---
+		assert.NoError(t, err0)
+		assert.NoError(t, err1)
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("new"))))
+		assert.Equal(t, "file1", h1.File().String())
+		assert.Equal(t, 1, h0.File().shareDel)
+	})
+
+	t.Run("share delete with other handle", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		flag := os.O_RDONLY | OpenShareDelete
+		must.Value(dir.OpenFile("file0", flag, 0))
+		must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+
+		// --- When ---
+		err := dir.Remove("file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EBUSY, err)
+	})
+
+	t.Run("closed handles", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		h := must.Value(dir.OpenFile("file0", os.O_RDONLY|OpenShareDelete, 0))
+		must.Nil(h.Close())
+		must.Nil(must.Value(dir.OpenFile("file0", os.O_RDONLY, 0)).Close())
+
+		// --- When ---
+		err := dir.Remove("file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, h.File().shareDel)
+	})
+
+	t.Run("turned off", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+
+		// --- When ---
+		err := dir.Remove("file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("windows error", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSharingViolations(dir)
+		WithPlatform(PlatformWindows)(dir)
+		must.Value(dir.OpenFile("file0", os.O_RDONLY, 0))
+
+		// --- When ---
+		err := dir.Remove("file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, WinErrSharingViolation, err)
+		wMsg := "remove file0: The process cannot access the file because " +
+			"it is being used by another process."
+		assert.ErrorEqual(t, wMsg, err)
+	})
+}
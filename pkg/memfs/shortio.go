@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+// WithMaxReadChunk is a [File] constructor function option limiting the
+// number of bytes returned by a single [File.Read] call of the instance and,
+// when used on a directory, of all files in its tree to n, like a pipe or a
+// network connection, so the code can be tested against the readers which
+// don't fill the buffers in one go. The reads return fewer bytes than
+// requested without an error, as allowed by [io.Reader]. Other read methods,
+// like [File.ReadAt] and [File.WriteTo], are not affected. The limit set on
+// the nearest directory applies. It panics when n is less than one.
+func WithMaxReadChunk(n int) func(*File) {
+	if n < 1 {
+		panic("memfs.WithMaxReadChunk: chunk less than one")
+	}
+	return func(fil *File) { fil.rchunk = n }
+}
+
+// WithMaxWriteChunk is a [File] constructor function option limiting the
+// number of bytes written by a single [File.Write] or [File.WriteString]
+// call of the instance and, when used on a directory, of all files in its
+// tree to n, so the code can be tested against the writers which don't
+// write the whole buffers. The writes of more bytes write the first n and
+// return [io.ErrShortWrite], as required by [io.Writer]. Other write
+// methods, like [File.WriteAt], are not affected. The limit set on the
+// nearest directory applies. It panics when n is less than one.
+func WithMaxWriteChunk(n int) func(*File) {
+	if n < 1 {
+		panic("memfs.WithMaxWriteChunk: chunk less than one")
+	}
+	return func(fil *File) { fil.wchunk = n }
+}
+
+// readChunk returns the read chunk set with [WithMaxReadChunk] on the
+// instance or the nearest of its parents. Returns zero when there is no
+// limit.
+func (fil *File) readChunk() int {
+	for f := fil; f != nil; f = f.parent {
+		if f.rchunk > 0 {
+			return f.rchunk
+		}
+	}
+	return 0
+}
+
+// writeChunk returns the write chunk set with [WithMaxWriteChunk] on the
+// instance or the nearest of its parents. Returns zero when there is no
+// limit.
+func (fil *File) writeChunk() int {
+	for f := fil; f != nil; f = f.parent {
+		if f.wchunk > 0 {
+			return f.wchunk
+		}
+	}
+	return 0
+}
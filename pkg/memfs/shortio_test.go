@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithMaxReadChunk(t *testing.T) {
+	t.Run("short reads", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abcdefg"), WithMaxReadChunk(3))
+		buf := make([]byte, 10)
+
+		// --- When ---
+		n1, err1 := fil.Read(buf)
+		n2, err2 := fil.Read(buf[n1:])
+		n3, err3 := fil.Read(buf[n1+n2:])
+		n4, err4 := fil.Read(buf[n1+n2+n3:])
+
+		// --- Then ---
+		assert.NoError(t, err1)
+		assert.Equal(t, 3, n1)
+		assert.NoError(t, err2)
+		assert.Equal(t, 3, n2)
+		assert.NoError(t, err3)
+		assert.Equal(t, 1, n3)
+		assert.ErrorIs(t, io.EOF, err4)
+		assert.Equal(t, 0, n4)
+		assert.Equal(t, "abcdefg", string(buf[:7]))
+	})
+
+	t.Run("ReadAll reads everything", func(t *testing.T) {
+		// --- Given ---
+		data := bytes.Repeat([]byte("abc"), 100)
+		fil := MustFileWith("file", data, WithMaxReadChunk(7))
+
+		// --- When ---
+		have, err := io.ReadAll(fil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, data, have)
+	})
+
+	t.Run("inherited from the directory", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithMaxReadChunk(2))
+		must.Nil(root.WriteFile("file", []byte("abcdef"), 0600))
+		fil := must.Value(root.Open("file"))
+
+		// --- When ---
+		n, err := fil.Read(make([]byte, 6))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("ReadAt is not affected", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abcdef"), WithMaxReadChunk(2))
+
+		// --- When ---
+		n, err := fil.ReadAt(make([]byte, 6), 0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 6, n)
+	})
+
+	t.Run("panic - chunk less than one", func(t *testing.T) {
+		// --- Given ---
+		fn := func() { WithMaxReadChunk(0) }
+
+		// --- When ---
+		msg := assert.PanicMsg(t, fn)
+
+		// --- Then ---
+		assert.Equal(t, "memfs.WithMaxReadChunk: chunk less than one", *msg)
+	})
+}
+
+func Test_WithMaxWriteChunk(t *testing.T) {
+	t.Run("short write", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithMaxWriteChunk(3))
+
+		// --- When ---
+		n, err := fil.Write([]byte("abcdefg"))
+
+		// --- Then ---
+		assert.ErrorIs(t, io.ErrShortWrite, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, 3, fil.Len())
+		assert.Equal(t, int64(3), must.Value(fil.Seek(0, io.SeekCurrent)))
+	})
+
+	t.Run("write within the chunk", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithMaxWriteChunk(3))
+
+		// --- When ---
+		n, err := fil.WriteString("abc")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("retrying writer writes everything", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithMaxWriteChunk(4))
+		data := []byte("abcdefghij")
+
+		// --- When ---
+		for len(data) > 0 {
+			n, err := fil.Write(data)
+			if err != nil && !errors.Is(err, io.ErrShortWrite) {
+				t.Fatal(err)
+			}
+			data = data[n:]
+		}
+
+		// --- Then ---
+		must.Value(fil.Seek(0, io.SeekStart))
+		assert.Equal(t, "abcdefghij", string(must.Value(io.ReadAll(fil))))
+	})
+
+	t.Run("inherited from the directory", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithMaxWriteChunk(2))
+		fil := MustFile("file")
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		n, err := fil.Write([]byte("abc"))
+
+		// --- Then ---
+		assert.ErrorIs(t, io.ErrShortWrite, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("error hook", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("test error")
+		hook := WithErrorHook(func(error) error { return e })
+		fil := MustFile("file", WithMaxWriteChunk(2), hook)
+
+		// --- When ---
+		n, err := fil.Write([]byte("abc"))
+
+		// --- Then ---
+		assert.Same(t, e, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("WriteAt is not affected", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithMaxWriteChunk(2))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte("abcdef"), 0)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 6, n)
+	})
+
+	t.Run("panic - chunk less than one", func(t *testing.T) {
+		// --- Given ---
+		fn := func() { WithMaxWriteChunk(0) }
+
+		// --- When ---
+		msg := assert.PanicMsg(t, fn)
+
+		// --- Then ---
+		assert.Equal(t, "memfs.WithMaxWriteChunk: chunk less than one", *msg)
+	})
+}
+
+func Test_File_readChunk(t *testing.T) {
+	t.Run("no limit", func(t *testing.T) {
+		// --- Then ---
+		assert.Equal(t, 0, MustFile("file").readChunk())
+	})
+
+	t.Run("nearest directory", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithMaxReadChunk(8))
+		sub := MustDirectory("sub")
+		WithMaxReadChunk(4)(sub)
+		must.Nil(root.AddFile(sub))
+		fil := MustFile("file")
+		must.Nil(sub.AddFile(fil))
+
+		// --- Then ---
+		assert.Equal(t, 4, fil.readChunk())
+	})
+}
+
+func Test_File_writeChunk(t *testing.T) {
+	t.Run("no limit", func(t *testing.T) {
+		// --- Then ---
+		assert.Equal(t, 0, MustFile("file").writeChunk())
+	})
+
+	t.Run("nearest directory", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithMaxWriteChunk(8))
+		sub := MustDirectory("sub")
+		WithMaxWriteChunk(4)(sub)
+		must.Nil(root.AddFile(sub))
+		fil := MustFile("file")
+		must.Nil(sub.AddFile(fil))
+
+		// --- Then ---
+		assert.Equal(t, 4, fil.writeChunk())
+	})
+}
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+)
+
+// Skeleton returns a new root directory with a structure-only copy of the
+// directory tree. The directories and files keep their names and permission
+// bits, and the files are stubs (see [File.StripContents]) keeping only their
+// sizes, so the copy uses almost no memory regardless of the content size.
+// The options are applied to the root directory after the tree is built, so
+// options like [WithReadOnly] can be used.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory.
+func (fil *File) Skeleton(opts ...func(*File)) (*File, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "skeleton",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+
+	root := NewRoot()
+	var err error
+	fil.walk("", func(pth string, ent *File) {
+		if err != nil {
+			return
+		}
+		err = addSkeleton(root, filepath.ToSlash(pth), ent.IsDir(),
+			ent.Size(), ent.Mode().Perm())
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(root)
+	}
+	return root, nil
+}
+
+// LoadSkeleton returns a new root directory with the structure-only tree
+// described by the records read from r in the given format, as written by
+// [File.ExportMetadata]. Use it to replay in tests the layout of directories
+// captured elsewhere, without shipping their content. The files are stubs
+// (see [File.StripContents]) with the sizes and the permission bits from the
+// records, the directories get the permission bits from the records, and the
+// other fields are ignored. The options are applied to the root directory
+// after the tree is built.
+//
+// Returns an error of the [fs.PathError] type with [fs.ErrInvalid] when the
+// format is not supported, or a record is malformed or has an invalid path,
+// the errors returned by [File.AddFile] when a path is repeated, and the
+// errors of the [encoding/csv] and [encoding/json] packages.
+func LoadSkeleton(
+	r io.Reader,
+	format Format,
+	opts ...func(*File),
+) (*File, error) {
+	var next func() (metadata, error)
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		hdr, err := cr.Read()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if err == nil && !slices.Equal(metadataHeader, hdr) {
+			return nil, &fs.PathError{
+				Op:   "skeleton",
+				Path: "",
+				Err:  fs.ErrInvalid,
+			}
+		}
+		next = func() (metadata, error) {
+			rec, err := cr.Read()
+			if err != nil {
+				return metadata{}, err
+			}
+			md := metadata{Path: rec[0], Type: rec[1], Mode: rec[3]}
+			if md.Size, err = strconv.ParseInt(rec[2], 10, 64); err != nil {
+				return md, &fs.PathError{
+					Op:   "skeleton",
+					Path: md.Path,
+					Err:  fs.ErrInvalid,
+				}
+			}
+			return md, nil
+		}
+	case FormatJSON:
+		dec := json.NewDecoder(r)
+		next = func() (metadata, error) {
+			var md metadata
+			err := dec.Decode(&md)
+			return md, err
+		}
+	default:
+		return nil, &fs.PathError{
+			Op:   "skeleton",
+			Path: "",
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	root := NewRoot()
+	for {
+		md, err := next()
+		if errors.Is(err, io.EOF) {
+			for _, opt := range opts {
+				opt(root)
+			}
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		perm, err := strconv.ParseUint(md.Mode, 8, 32)
+		if err != nil || perm > uint64(fs.ModePerm) || md.Size < 0 ||
+			(md.Type != "file" && md.Type != "dir") {
+			return nil, &fs.PathError{
+				Op:   "skeleton",
+				Path: md.Path,
+				Err:  fs.ErrInvalid,
+			}
+		}
+		err = addSkeleton(root, md.Path, md.Type == "dir", md.Size,
+			fs.FileMode(perm))
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// addSkeleton adds to the root directory the directory or the stub file with
+// the given path, size and permission bits. The missing parent directories
+// are created.
+func addSkeleton(
+	root *File,
+	pth string,
+	isDir bool,
+	size int64,
+	perm fs.FileMode,
+) error {
+	if !fs.ValidPath(pth) || pth == "." {
+		return &fs.PathError{Op: "skeleton", Path: pth, Err: fs.ErrInvalid}
+	}
+
+	if isDir {
+		dir, err := mkdirAll(root, pth)
+		if err != nil {
+			return err
+		}
+		dir.info.mode = fs.ModeDir | perm
+		return nil
+	}
+
+	dir, err := mkdirAll(root, path.Dir(pth))
+	if err != nil {
+		return err
+	}
+	fil, err := FileWith(path.Base(pth), nil)
+	if err != nil {
+		return err
+	}
+	fil.info.mode = perm
+	fil.info.size = size
+	fil.stub = true
+	return dir.AddFile(fil)
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Skeleton(t *testing.T) {
+	t.Run("structure only copy", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(must.Value(open(dir, "file1")).Chmod(0755))
+		must.Nil(must.Value(open(dir, "sub/sub2")).Chmod(0750))
+
+		// --- When ---
+		have, err := dir.Skeleton()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		var want, got []string
+		dir.walk("", func(pth string, _ *File) { want = append(want, pth) })
+		have.walk("", func(pth string, _ *File) { got = append(got, pth) })
+		assert.Equal(t, want, got)
+
+		fil := must.Value(open(have, "file1"))
+		assert.True(t, fil.stub)
+		assert.Nil(t, fil.buf)
+		assert.Equal(t, int64(5), fil.Size())
+		assert.Equal(t, fs.FileMode(0755), fil.Mode())
+		assert.Equal(t, "\x00\x00\x00\x00\x00", fil.String())
+		sub2 := must.Value(open(have, "sub/sub2"))
+		assert.Equal(t, fs.ModeDir|0750, sub2.Mode())
+		assert.Equal(t, "file1", string(must.Value(open(dir, "file1")).buf))
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().Skeleton(WithReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.readOnly())
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.MkdirAll("a/b", 0755))
+
+		// --- When ---
+		have, err := dir.Skeleton()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, fs.ModeDir|0755, must.Value(open(have, "a/b")).Mode())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		have, err := MustFile("file").Skeleton()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "skeleton", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_LoadSkeleton(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		for _, format := range []Format{FormatCSV, FormatJSON} {
+			// --- Given ---
+			dir := tstDirMem()
+			must.Nil(must.Value(open(dir, "sub/file3")).Chmod(0755))
+			must.Nil(dir.MkdirAll("empty", 0750))
+			buf := &bytes.Buffer{}
+			must.Nil(dir.ExportMetadata(buf, format))
+
+			// --- When ---
+			have, err := LoadSkeleton(buf, format)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			var want, got []string
+			dir.walk("", func(pth string, _ *File) { want = append(want, pth) })
+			have.walk("", func(pth string, _ *File) { got = append(got, pth) })
+			assert.Equal(t, want, got)
+			fil := must.Value(open(have, "sub/file3"))
+			assert.True(t, fil.stub)
+			assert.Equal(t, int64(5), fil.Size())
+			assert.Equal(t, fs.FileMode(0755), fil.Mode())
+			empty := must.Value(open(have, "empty"))
+			assert.Equal(t, fs.ModeDir|0750, empty.Mode())
+		}
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		// --- Given ---
+		r := strings.NewReader("")
+
+		// --- When ---
+		have, err := LoadSkeleton(r, FormatJSON, WithReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.readOnly())
+		assert.Len(t, 0, have.entries)
+	})
+
+	t.Run("empty csv", func(t *testing.T) {
+		// --- When ---
+		have, err := LoadSkeleton(strings.NewReader(""), FormatCSV)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, have.entries)
+	})
+
+	t.Run("error - invalid records", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			format Format
+			data   string
+			path   string
+		}{
+			{"csv header", FormatCSV, "a,b,c,d,e,f\n", ""},
+			{"csv size", FormatCSV, "path,type,size,mode,mtime,hash\n" +
+				"a,file,x,0644,,\n", "a"},
+			{"csv mode", FormatCSV, "path,type,size,mode,mtime,hash\n" +
+				"a,file,1,0999,,\n", "a"},
+			{"type", FormatJSON, `{"path":"a","type":"link","mode":"0644"}`,
+				"a"},
+			{"negative size", FormatJSON,
+				`{"path":"a","type":"file","size":-1,"mode":"0644"}`, "a"},
+			{"mode bits", FormatJSON,
+				`{"path":"a","type":"file","mode":"1777"}`, "a"},
+			{"path", FormatJSON,
+				`{"path":"../a","type":"file","mode":"0644"}`, "../a"},
+			{"dot", FormatJSON, `{"path":".","type":"dir","mode":"0755"}`,
+				"."},
+			{"format", Format(42), "", ""},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- When ---
+				have, err := LoadSkeleton(strings.NewReader(tc.data), tc.format)
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "skeleton", e.Op)
+				assert.Equal(t, tc.path, e.Path)
+				assert.Equal(t, fs.ErrInvalid, e.Err)
+				assert.Nil(t, have)
+			})
+		}
+	})
+
+	t.Run("error - repeated path", func(t *testing.T) {
+		// --- Given ---
+		data := `{"path":"a","type":"file","mode":"0644"}` + "\n" +
+			`{"path":"a","type":"file","mode":"0644"}`
+
+		// --- When ---
+		have, err := LoadSkeleton(strings.NewReader(data), FormatJSON)
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - malformed json", func(t *testing.T) {
+		// --- When ---
+		have, err := LoadSkeleton(strings.NewReader("{"), FormatJSON)
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.Nil(t, have)
+	})
+}
@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"syscall"
+)
+
+// Version is the version of the [File.Snapshot] document format.
+type Version int
+
+// SnapshotVersion is the version of the documents written by
+// [File.Snapshot]. It changes only when the documents can no longer be
+// restored by the older versions of the package; the new optional fields
+// don't change it, because [Restore] ignores the fields it doesn't know.
+const SnapshotVersion Version = 1
+
+// snapDoc represents the [File.Snapshot] document.
+type snapDoc struct {
+	Version Version `json:"version,omitempty"`
+	snapNode
+}
+
+// snapHead represents the fields of the [File.Snapshot] document needed to
+// check if it can be restored.
+type snapHead struct {
+	Version Version `json:"version,omitempty"`
+	Type    string  `json:"type"`
+}
+
+// snapNode represents a file or a directory in the [File.Snapshot] document.
+type snapNode struct {
+	Name    string      `json:"name,omitempty"`
+	Type    string      `json:"type"`
+	Mode    string      `json:"mode"`
+	Content []byte      `json:"content,omitempty"`
+	Link    string      `json:"link,omitempty"`
+	Entries []*snapNode `json:"entries,omitempty"`
+}
+
+// Snapshot writes the directory tree to w as an indented JSON document, so
+// the state of the tree can be compared with a golden file and reconstructed
+// later with [Restore]. The document is the directory object with the
+// "version" field set to [SnapshotVersion], and every object has the
+// following fields:
+//
+//   - name - the name of the file, omitted for the directory itself,
+//   - type - "file", "dir" or "symlink",
+//   - mode - the permission bits as an octal number, for example, "0644",
+//   - content - the base64-encoded file content, omitted when empty,
+//   - link - the symbolic link destination,
+//   - entries - the directory entries in the name order.
+//
+// The modification times are not written, so the snapshots of equal trees
+// are equal.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, an error of the [fs.PathError] type when the
+// content of a lazily loaded file cannot be loaded, and the errors returned
+// by w.
+func (fil *File) Snapshot(w io.Writer) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "snapshot",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	node, err := fil.snapNode()
+	if err != nil {
+		return err
+	}
+	node.Name = ""
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snapDoc{Version: SnapshotVersion, snapNode: *node})
+}
+
+// snapNode returns the [File.Snapshot] node for the instance and its
+// subtree.
+func (fil *File) snapNode() (*snapNode, error) {
+	node := &snapNode{
+		Name: fil.Name(),
+		Type: "file",
+		Mode: fmt.Sprintf("%04o", fil.Mode().Perm()),
+	}
+	switch {
+	case fil.isSymlink():
+		node.Type = "symlink"
+		node.Link = fil.link
+
+	case fil.IsDir():
+		node.Type = "dir"
+		for _, ent := range fil.listEntries() {
+			sub, err := ent.snapNode()
+			if err != nil {
+				return nil, err
+			}
+			node.Entries = append(node.Entries, sub)
+		}
+
+	default:
+		data, err := fil.diskContent()
+		if err != nil {
+			return nil, err
+		}
+		node.Content = data
+	}
+	return node, nil
+}
+
+// Restore returns a new root directory with the tree read from r, as written
+// by [File.Snapshot]. The options are applied to the root directory after
+// the tree is built, so options like [WithReadOnly] can be used. The fields
+// it doesn't know are ignored, so the documents with the optional fields
+// added by the newer versions of the package can be restored. The documents
+// without the version are treated as version 1.
+//
+// Returns an error of the [fs.PathError] type with [errors.ErrUnsupported]
+// when the document version is newer than [SnapshotVersion], with
+// [fs.ErrInvalid] when the version is invalid or an object has an invalid
+// name, type or mode, or is not a directory at the top, the errors returned
+// by [File.AddFile] when a name is repeated, and the errors of the
+// [encoding/json] package.
+func Restore(r io.Reader, opts ...func(*File)) (*File, error) {
+	var doc snapDoc
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	ver := snapVersion(doc.Version)
+	if ver > SnapshotVersion {
+		return nil, &fs.PathError{
+			Op:   "restore",
+			Path: ".",
+			Err:  errors.ErrUnsupported,
+		}
+	}
+	perm, err := snapPerm(&doc.snapNode)
+	if err != nil || doc.Type != "dir" || ver < 1 {
+		return nil, &fs.PathError{Op: "restore", Path: ".", Err: fs.ErrInvalid}
+	}
+	root := NewRoot()
+	root.info.mode = fs.ModeDir | perm
+	if err = restoreEntries(root, ".", doc.Entries); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(root)
+	}
+	return root, nil
+}
+
+// CanLoad reads the [File.Snapshot] document from r and returns its version
+// and true when it can be restored with [Restore], so the cached fixtures
+// written by other versions of the package can be checked before use. The
+// documents without the version are reported as version 1. Returns zero and
+// false when r doesn't contain a snapshot document. It reads r to the end
+// of the document.
+func CanLoad(r io.Reader) (Version, bool) {
+	var head snapHead
+	if err := json.NewDecoder(r).Decode(&head); err != nil {
+		return 0, false
+	}
+	if head.Type != "dir" {
+		return 0, false
+	}
+	ver := snapVersion(head.Version)
+	return ver, ver > 0 && ver <= SnapshotVersion
+}
+
+// snapVersion returns the version of the [File.Snapshot] document with the
+// given "version" field value.
+func snapVersion(ver Version) Version {
+	if ver == 0 {
+		return 1
+	}
+	return ver
+}
+
+// restoreEntries adds the entries from the [File.Snapshot] nodes to the
+// directory with the given path.
+func restoreEntries(dir *File, dirPth string, nodes []*snapNode) error {
+	for _, node := range nodes {
+		pth := node.Name
+		if dirPth != "." {
+			pth = dirPth + "/" + node.Name
+		}
+		perm, err := snapPerm(node)
+		if err != nil || !fs.ValidPath(node.Name) || node.Name == "." ||
+			path.Base(node.Name) != node.Name {
+			return &fs.PathError{Op: "restore", Path: pth, Err: fs.ErrInvalid}
+		}
+
+		var ent *File
+		switch node.Type {
+		case "file":
+			ent, err = FileWith(node.Name, node.Content, WithFileMode(perm))
+		case "dir":
+			ent, err = NewDirectory(node.Name, WithFileMode(perm))
+		case "symlink":
+			ent = &File{
+				info: FileInfo{name: node.Name, mode: fs.ModeSymlink | 0777},
+				link: node.Link,
+			}
+		default:
+			err = fs.ErrInvalid
+		}
+		if err != nil {
+			return &fs.PathError{Op: "restore", Path: pth, Err: fs.ErrInvalid}
+		}
+		if err = dir.AddFile(ent); err != nil {
+			return err
+		}
+		if node.Type == "dir" {
+			if err = restoreEntries(ent, pth, node.Entries); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// snapPerm returns the permission bits of the [File.Snapshot] node.
+func snapPerm(node *snapNode) (fs.FileMode, error) {
+	perm, err := strconv.ParseUint(node.Mode, 8, 32)
+	if err != nil || perm > uint64(fs.ModePerm) {
+		return 0, fs.ErrInvalid
+	}
+	return fs.FileMode(perm), nil
+}
@@ -0,0 +1,327 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Snapshot(t *testing.T) {
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.MkdirAll("sub", 0755))
+		must.Nil(dir.WriteFile("sub/b", []byte("bbb"), 0644))
+		must.Nil(dir.WriteFile("a", nil, 0600))
+		must.Nil(dir.Symlink("sub/b", "link"))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.Snapshot(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := `{
+  "version": 1,
+  "type": "dir",
+  "mode": "0700",
+  "entries": [
+    {
+      "name": "a",
+      "type": "file",
+      "mode": "0600"
+    },
+    {
+      "name": "link",
+      "type": "symlink",
+      "mode": "0777",
+      "link": "sub/b"
+    },
+    {
+      "name": "sub",
+      "type": "dir",
+      "mode": "0755",
+      "entries": [
+        {
+          "name": "b",
+          "type": "file",
+          "mode": "0644",
+          "content": "YmJi"
+        }
+      ]
+    }
+  ]
+}
+`
+		assert.Equal(t, want, buf.String())
+	})
+
+	t.Run("lazy file stays unloaded", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		dir := NewRoot()
+		fil := lazyFile([]byte("abc"), &loads)
+		must.Nil(dir.AddFile(fil))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.Snapshot(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Contain(t, `"content": "YWJj"`, buf.String())
+		assert.True(t, fil.unloaded())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.Snapshot(&bytes.Buffer{})
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "snapshot", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+}
+
+func Test_Restore(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Chmod(0755))
+		must.Nil(must.Value(open(dir, "sub")).Chmod(0500))
+		must.Nil(dir.Symlink("sub/file3", "link"))
+		buf := &bytes.Buffer{}
+		must.Nil(dir.Snapshot(buf))
+
+		// --- When ---
+		have, err := Restore(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, fs.ModeDir|0755, have.Mode())
+		tol := Tolerance{IgnoreModTime: true}
+		assert.Nil(t, must.Value(Diff(dir.DirFS(), have.DirFS(), tol)))
+	})
+
+	t.Run("options are applied", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+		must.Nil(tstDirMem().Snapshot(buf))
+
+		// --- When ---
+		have, err := Restore(buf, WithReadOnly)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		err = have.WriteFile("file0", nil, 0600)
+		assert.ErrorIs(t, syscall.EROFS, err)
+	})
+
+	t.Run("without version", func(t *testing.T) {
+		// --- Given ---
+		data := `{"type": "dir", "mode": "0700", "entries": [
+			{"name": "a", "type": "file", "mode": "0600", "content": "YWJj"}]}`
+
+		// --- When ---
+		have, err := Restore(strings.NewReader(data))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", string(must.Value(have.ReadFile("a"))))
+	})
+
+	t.Run("unknown optional fields are ignored", func(t *testing.T) {
+		// --- Given ---
+		data := `{"version": 1, "type": "dir", "mode": "0700",
+			"labels": {"ci": "cache"}, "entries": [
+			{"name": "a", "type": "file", "mode": "0600", "xattrs": {}}]}`
+
+		// --- When ---
+		have, err := Restore(strings.NewReader(data))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a"}, names(must.Value(have.ReadDir(-1))))
+	})
+
+	t.Run("error - newer version", func(t *testing.T) {
+		// --- Given ---
+		data := `{"version": 2, "type": "dir", "mode": "0700"}`
+
+		// --- When ---
+		have, err := Restore(strings.NewReader(data))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "restore", e.Op)
+		assert.Equal(t, ".", e.Path)
+		assert.ErrorIs(t, errors.ErrUnsupported, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - invalid", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			data string
+			pth  string
+		}{
+			{"top type", `{"type": "file", "mode": "0600"}`, "."},
+			{"version", `{"version": -1, "type": "dir", "mode": "0700"}`, "."},
+			{"top mode", `{"type": "dir", "mode": "9"}`, "."},
+			{
+				"type",
+				`{"type": "dir", "mode": "0700", "entries": [
+					{"name": "a", "type": "fifo", "mode": "0600"}]}`,
+				"a",
+			},
+			{
+				"mode",
+				`{"type": "dir", "mode": "0700", "entries": [
+					{"name": "a", "type": "file", "mode": "1777"}]}`,
+				"a",
+			},
+			{
+				"name",
+				`{"type": "dir", "mode": "0700", "entries": [
+					{"name": "a/b", "type": "file", "mode": "0600"}]}`,
+				"a/b",
+			},
+			{
+				"nested",
+				`{"type": "dir", "mode": "0700", "entries": [
+					{"name": "a", "type": "dir", "mode": "0700", "entries": [
+						{"name": "..", "type": "file", "mode": "0600"}]}]}`,
+				"a/..",
+			},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- When ---
+				have, err := Restore(strings.NewReader(tc.data))
+
+				// --- Then ---
+				var e *fs.PathError
+				assert.ErrorAs(t, &e, err)
+				assert.Equal(t, "restore", e.Op)
+				assert.Equal(t, tc.pth, e.Path)
+				assert.ErrorIs(t, fs.ErrInvalid, err)
+				assert.Nil(t, have)
+			})
+		}
+	})
+
+	t.Run("error - repeated name", func(t *testing.T) {
+		// --- Given ---
+		data := `{"type": "dir", "mode": "0700", "entries": [
+			{"name": "a", "type": "file", "mode": "0600"},
+			{"name": "a", "type": "dir", "mode": "0700"}]}`
+
+		// --- When ---
+		have, err := Restore(strings.NewReader(data))
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrExist, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - json", func(t *testing.T) {
+		// --- When ---
+		have, err := Restore(strings.NewReader("{"))
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, fs.ErrInvalid))
+		assert.Nil(t, have)
+	})
+}
+
+func Test_CanLoad(t *testing.T) {
+	t.Run("current version", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+		must.Nil(tstDirMem().Snapshot(buf))
+
+		// --- When ---
+		ver, ok := CanLoad(buf)
+
+		// --- Then ---
+		assert.True(t, ok)
+		assert.Equal(t, SnapshotVersion, ver)
+	})
+
+	t.Run("without version", func(t *testing.T) {
+		// --- Given ---
+		data := `{"type": "dir", "mode": "0700"}`
+
+		// --- When ---
+		ver, ok := CanLoad(strings.NewReader(data))
+
+		// --- Then ---
+		assert.True(t, ok)
+		assert.Equal(t, Version(1), ver)
+	})
+
+	t.Run("newer version", func(t *testing.T) {
+		// --- Given ---
+		data := `{"version": 2, "type": "dir", "mode": "0700"}`
+
+		// --- When ---
+		ver, ok := CanLoad(strings.NewReader(data))
+
+		// --- Then ---
+		assert.False(t, ok)
+		assert.Equal(t, Version(2), ver)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		// --- Given ---
+		data := `{"version": -1, "type": "dir", "mode": "0700"}`
+
+		// --- When ---
+		_, ok := CanLoad(strings.NewReader(data))
+
+		// --- Then ---
+		assert.False(t, ok)
+	})
+
+	t.Run("not a snapshot", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			data string
+		}{
+			{"json", "{"},
+			{"not a directory", `{"type": "file", "mode": "0600"}`},
+			{"empty", ""},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- When ---
+				ver, ok := CanLoad(strings.NewReader(tc.data))
+
+				// --- Then ---
+				assert.False(t, ok)
+				assert.Equal(t, Version(0), ver)
+			})
+		}
+	})
+}
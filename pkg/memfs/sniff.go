@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"net/http"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// sniffLen is the number of bytes considered by [http.DetectContentType].
+const sniffLen = 512
+
+// DetectContentType returns the MIME type of the file content detected with
+// [http.DetectContentType], for example, "image/png" or "text/plain;
+// charset=utf-8". It does not change the offset. The content of a lazily
+// loaded file is loaded and dropped again unless it was already in memory.
+//
+// Returns an error of the [fs.PathError] type with [syscall.EISDIR] when the
+// instance is a directory, and an error of the [fs.PathError] type when the
+// content of a lazily loaded file cannot be loaded.
+func (fil *File) DetectContentType() (string, error) {
+	if fil.IsDir() {
+		return "", fil.hookErr(&fs.PathError{
+			Op:   "read",
+			Path: fil.path(),
+			Err:  syscall.EISDIR,
+		})
+	}
+	unloaded := fil.unloaded()
+	if err := fil.load(); err != nil {
+		return "", err
+	}
+	data := fil.buf
+	if fil.stub {
+		data = make([]byte, min(fil.Len(), sniffLen))
+	}
+	typ := http.DetectContentType(data[:min(len(data), sniffLen)])
+	if unloaded {
+		fil.unload()
+	}
+	return typ, nil
+}
+
+// ByContentType returns the sorted paths of all files in the directory tree
+// which content type detected with [File.DetectContentType] matches the
+// given one. Paths are relative to the directory. The parameters like
+// "charset" are ignored, so "text/plain" matches "text/plain;
+// charset=utf-8", and the "*" subtype matches any subtype, for example,
+// "image/*" matches all images.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory and the first error returned by
+// [File.DetectContentType].
+func (fil *File) ByContentType(typ string) ([]string, error) {
+	if !fil.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "sniff",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+
+	var err error
+	var matches []string
+	fil.walk("", func(pth string, ent *File) {
+		if err != nil || ent.IsDir() {
+			return
+		}
+		var have string
+		if have, err = ent.DetectContentType(); err != nil {
+			return
+		}
+		if matchContentType(typ, have) {
+			matches = append(matches, pth)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// matchContentType returns true when the detected content type matches the
+// wanted one ignoring the parameters. The wanted type may use the "*"
+// subtype.
+func matchContentType(want, have string) bool {
+	want = mediaType(want)
+	have = mediaType(have)
+	if main, ok := strings.CutSuffix(want, "/*"); ok {
+		return strings.HasPrefix(have, main+"/")
+	}
+	return want == have
+}
+
+// mediaType returns the lowercase content type without the parameters.
+func mediaType(typ string) string {
+	typ, _, _ = strings.Cut(typ, ";")
+	return strings.ToLower(strings.TrimSpace(typ))
+}
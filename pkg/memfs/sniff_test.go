@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// pngHeader is the PNG file signature.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+// tstDirSniff returns a directory with files of different content types.
+func tstDirSniff() *File {
+	dir := NewRoot()
+	img := must.Value(mkdirAll(dir, "img"))
+	must.Nil(img.AddFile(MustFileWith("a.png", pngHeader)))
+	must.Nil(img.AddFile(MustFileWith("b.gif", []byte("GIF89a"))))
+	must.Nil(dir.AddFile(MustFileWith("c.png", pngHeader)))
+	must.Nil(dir.AddFile(MustFileWith("d.txt", []byte("text"))))
+	must.Nil(dir.AddFile(MustFileWith("e.html", []byte("<html>"))))
+	return dir
+}
+
+func Test_File_DetectContentType(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		content []byte
+		want    string
+	}{
+		{"png", pngHeader, "image/png"},
+		{"text", []byte("text"), "text/plain; charset=utf-8"},
+		{"html", []byte("<html>"), "text/html; charset=utf-8"},
+		{"binary", []byte{0, 1, 2}, "application/octet-stream"},
+		{"empty", nil, "text/plain; charset=utf-8"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			off := WithFileOffset(len(tc.content))
+			fil := MustFileWith("file", tc.content, off)
+
+			// --- When ---
+			have, err := fil.DetectContentType()
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, have)
+			assert.Equal(t, len(tc.content), fil.Offset())
+		})
+	}
+
+	t.Run("stub", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		fil := MustFileWith("file", pngHeader)
+		must.Nil(dir.AddFile(fil))
+		must.Nil(dir.StripContents(nil))
+
+		// --- When ---
+		have, err := fil.DetectContentType()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "application/octet-stream", have)
+	})
+
+	t.Run("lazy file is unloaded", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile(pngHeader, &loads)
+
+		// --- When ---
+		have, err := fil.DetectContentType()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", have)
+		assert.Equal(t, 1, loads)
+		assert.True(t, fil.unloaded())
+	})
+
+	t.Run("loaded lazy file is kept", func(t *testing.T) {
+		// --- Given ---
+		var loads int
+		fil := lazyFile(pngHeader, &loads)
+		must.Nil(fil.load())
+
+		// --- When ---
+		have, err := fil.DetectContentType()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", have)
+		assert.Equal(t, 1, loads)
+		assert.False(t, fil.unloaded())
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- Given ---
+		dir := MustDirectory("dir")
+
+		// --- When ---
+		have, err := dir.DetectContentType()
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "dir", e.Path)
+		assert.Equal(t, syscall.EISDIR, e.Err)
+		assert.Equal(t, "", have)
+	})
+
+	t.Run("error - load", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+		fil := &File{
+			info: FileInfo{name: "file", size: 3, mode: 0600},
+			lazy: func() ([]byte, error) { return nil, errTst },
+		}
+
+		// --- When ---
+		have, err := fil.DetectContentType()
+
+		// --- Then ---
+		assert.ErrorIs(t, errTst, err)
+		assert.Equal(t, "", have)
+	})
+}
+
+func Test_File_ByContentType(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		typ  string
+		want []string
+	}{
+		{"exact", "image/png", []string{"c.png", "img/a.png"}},
+		{"wildcard", "image/*", []string{"c.png", "img/a.png", "img/b.gif"}},
+		{"without parameters", "text/plain", []string{"d.txt"}},
+		{"with parameters", "text/html; charset=utf-8", []string{"e.html"}},
+		{"case insensitive", "Image/GIF", []string{"img/b.gif"}},
+		{"wildcard text", "text/*", []string{"d.txt", "e.html"}},
+		{"not matching", "video/webm", nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			dir := tstDirSniff()
+
+			// --- When ---
+			have, err := dir.ByContentType(tc.typ)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have, err := fil.ByContentType("image/png")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "sniff", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - load", func(t *testing.T) {
+		// --- Given ---
+		errTst := errors.New("test error")
+		dir := tstDirSniff()
+		must.Nil(dir.AddFile(&File{
+			info: FileInfo{name: "lazy", size: 3, mode: 0600},
+			lazy: func() ([]byte, error) { return nil, errTst },
+		}))
+
+		// --- When ---
+		have, err := dir.ByContentType("image/png")
+
+		// --- Then ---
+		assert.ErrorIs(t, errTst, err)
+		assert.Nil(t, have)
+	})
+}
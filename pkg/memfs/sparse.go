@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+// Hole is a range of a file which was never written, created by writing
+// beyond its end or by extending it with [File.Truncate].
+type Hole struct {
+	Off int64 // Offset of the first byte of the range.
+	Len int64 // Length of the range.
+}
+
+// HoleEvent describes a hole created in a file, reported to the hook set with
+// [WithHoleHook].
+type HoleEvent struct {
+	Hole
+
+	// The hole was materialized by filling it with zeros. It's false in the
+	// sparse mode (see [WithSparse]).
+	Zeroed bool
+}
+
+// WithSparse is a [File] constructor function option enabling the sparse
+// mode. By default, the holes created by writing beyond the end of a file,
+// for example with [File.WriteAt] or after seeking past the end, or by
+// extending it with [File.Truncate], are eagerly filled with zeros and
+// become indistinguishable from the written data. In the sparse mode, the
+// file keeps track of them, like a sparse file on disk, and reports them with
+// [File.Holes] until they are written to. Either way, reading a hole returns
+// zeros. When used on a directory, it applies to all files in its tree.
+func WithSparse(fil *File) { fil.sparse = true }
+
+// WithHoleHook is a [File] constructor function option setting a hook which
+// is called with the file path and the description of every hole created in
+// the file. It allows writing assertions like "the writer didn't leave gaps"
+// or "the gap was kept sparse". The hook set on a directory is used for all
+// files in its tree unless a file or a directory closer to them in the
+// hierarchy has its own hook.
+func WithHoleHook(hook func(pth string, ev HoleEvent)) func(*File) {
+	return func(fil *File) { fil.holeHook = hook }
+}
+
+// Holes returns the holes in the file in the offset order. It returns nil
+// when the file has no holes or is not in the sparse mode (see [WithSparse]).
+func (fil *File) Holes() []Hole {
+	var have []Hole
+	size := int64(len(fil.buf))
+	for _, h := range fil.holes {
+		if h.Off >= size {
+			break
+		}
+		h.Len = min(h.Len, size-h.Off)
+		have = append(have, h)
+	}
+	return have
+}
+
+// isSparse returns true when the sparse mode is set on the instance or any
+// of its parents.
+func (fil *File) isSparse() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.sparse {
+			return true
+		}
+	}
+	return false
+}
+
+// holesHook returns the hook set with [WithHoleHook] on the instance or the
+// closest of its parents. Returns nil when no hook was set.
+func (fil *File) holesHook() func(pth string, ev HoleEvent) {
+	for f := fil; f != nil; f = f.parent {
+		if f.holeHook != nil {
+			return f.holeHook
+		}
+	}
+	return nil
+}
+
+// gap records the hole between the offsets from and to created when the
+// content is extended from the length from without writing to it. It does
+// nothing when to is not greater than from.
+func (fil *File) gap(from, to int) {
+	if to <= from {
+		return
+	}
+	h := Hole{Off: int64(from), Len: int64(to - from)}
+	sparse := fil.isSparse()
+	if sparse {
+		n := len(fil.holes)
+		if n > 0 && fil.holes[n-1].Off+fil.holes[n-1].Len == h.Off {
+			fil.holes[n-1].Len += h.Len
+		} else {
+			fil.holes = append(fil.holes, h)
+		}
+	}
+	if hook := fil.holesHook(); hook != nil {
+		hook(fil.path(), HoleEvent{Hole: h, Zeroed: !sparse})
+	}
+}
+
+// fill removes the range of n bytes starting at the offset off from the
+// holes after it was written to.
+func (fil *File) fill(off, n int) {
+	if len(fil.holes) == 0 || n <= 0 {
+		return
+	}
+	start, end := int64(off), int64(off+n)
+	var holes []Hole
+	for _, h := range fil.holes {
+		hEnd := h.Off + h.Len
+		if hEnd <= start || h.Off >= end {
+			holes = append(holes, h)
+			continue
+		}
+		if h.Off < start {
+			holes = append(holes, Hole{Off: h.Off, Len: start - h.Off})
+		}
+		if hEnd > end {
+			holes = append(holes, Hole{Off: end, Len: hEnd - end})
+		}
+	}
+	fil.holes = holes
+}
+
+// cut removes the holes, or their parts, beyond the size.
+func (fil *File) cut(size int) {
+	if len(fil.holes) == 0 {
+		return
+	}
+	last := fil.holes[len(fil.holes)-1]
+	fil.fill(size, int(last.Off+last.Len)-size)
+}
@@ -0,0 +1,306 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithSparse(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+
+	// --- When ---
+	WithSparse(fil)
+
+	// --- Then ---
+	assert.True(t, fil.sparse)
+}
+
+func Test_WithHoleHook(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+
+	// --- When ---
+	WithHoleHook(func(string, HoleEvent) {})(fil)
+
+	// --- Then ---
+	assert.NotNil(t, fil.holeHook)
+}
+
+func Test_File_isSparse(t *testing.T) {
+	t.Run("not set", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have := fil.isSparse()
+
+		// --- Then ---
+		assert.False(t, have)
+	})
+
+	t.Run("set on parent", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithSparse)
+		fil := MustFile("file")
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		have := fil.isSparse()
+
+		// --- Then ---
+		assert.True(t, have)
+	})
+}
+
+func Test_File_holesHook(t *testing.T) {
+	t.Run("no hook", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have := fil.holesHook()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("parent hook", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithHoleHook(func(string, HoleEvent) {}))
+		fil := MustFile("file")
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		have := fil.holesHook()
+
+		// --- Then ---
+		assert.NotNil(t, have)
+	})
+}
+
+func Test_File_Holes(t *testing.T) {
+	t.Run("WriteAt beyond capacity", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("xyz"), 10))
+
+		// --- Then ---
+		assert.Equal(t, []Hole{{Off: 3, Len: 7}}, fil.Holes())
+		want := "abc" + strings.Repeat("\x00", 7) + "xyz"
+		assert.Equal(t, want, string(fil.buf))
+	})
+
+	t.Run("WriteAt within capacity", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithSparse)
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("x"), 5))
+
+		// --- Then ---
+		assert.Equal(t, []Hole{{Off: 0, Len: 5}}, fil.Holes())
+		assert.Equal(t, "\x00\x00\x00\x00\x00x", string(fil.buf))
+	})
+
+	t.Run("Write after seeking past the end", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		must.Value(fil.Seek(5, io.SeekStart))
+
+		// --- When ---
+		must.Value(fil.Write([]byte("x")))
+
+		// --- Then ---
+		assert.Equal(t, []Hole{{Off: 3, Len: 2}}, fil.Holes())
+	})
+
+	t.Run("ReadFrom after seeking past the end", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		must.Value(fil.Seek(5, io.SeekStart))
+
+		// --- When ---
+		must.Value(fil.ReadFrom(strings.NewReader("xy")))
+
+		// --- Then ---
+		assert.Equal(t, []Hole{{Off: 3, Len: 2}}, fil.Holes())
+		assert.Equal(t, "abc\x00\x00xy", string(fil.buf))
+	})
+
+	t.Run("ReadFrom reading nothing", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		must.Value(fil.Seek(5, io.SeekStart))
+
+		// --- When ---
+		must.Value(fil.ReadFrom(strings.NewReader("")))
+
+		// --- Then ---
+		assert.Nil(t, fil.Holes())
+	})
+
+	t.Run("Truncate extending", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+
+		// --- When ---
+		must.Nil(fil.Truncate(1000))
+
+		// --- Then ---
+		assert.Equal(t, []Hole{{Off: 3, Len: 997}}, fil.Holes())
+	})
+
+	t.Run("Truncate shrinking", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		must.Nil(fil.Truncate(10))
+
+		// --- When ---
+		must.Nil(fil.Truncate(5))
+
+		// --- Then ---
+		assert.Equal(t, []Hole{{Off: 3, Len: 2}}, fil.Holes())
+	})
+
+	t.Run("adjacent holes are merged", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		must.Nil(fil.Truncate(5))
+
+		// --- When ---
+		must.Nil(fil.Truncate(8))
+
+		// --- Then ---
+		assert.Equal(t, []Hole{{Off: 3, Len: 5}}, fil.Holes())
+	})
+
+	t.Run("writes fill holes", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		must.Nil(fil.Truncate(10))
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("xy"), 5))
+
+		// --- Then ---
+		want := []Hole{{Off: 3, Len: 2}, {Off: 7, Len: 3}}
+		assert.Equal(t, want, fil.Holes())
+	})
+
+	t.Run("writes fill holes completely", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		must.Nil(fil.Truncate(5))
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("xyz"), 2))
+
+		// --- Then ---
+		assert.Nil(t, fil.Holes())
+		assert.Equal(t, "abxyz", string(fil.buf))
+	})
+
+	t.Run("Release drops holes", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		must.Nil(fil.Truncate(5))
+
+		// --- When ---
+		fil.Release()
+
+		// --- Then ---
+		assert.Nil(t, fil.Holes())
+	})
+
+	t.Run("sparse mode inherited", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithSparse)
+		must.Nil(root.WriteFile("dir/file", []byte("abc"), 0600))
+		fil := must.Value(open(root, "dir/file"))
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("x"), 4))
+
+		// --- Then ---
+		assert.Equal(t, []Hole{{Off: 3, Len: 1}}, fil.Holes())
+	})
+
+	t.Run("not sparse", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"))
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("x"), 100))
+
+		// --- Then ---
+		assert.Nil(t, fil.Holes())
+		want := append([]byte("abc"), bytes.Repeat([]byte{0}, 97)...)
+		assert.Equal(t, append(want, 'x'), fil.buf)
+	})
+}
+
+func Test_File_holeEvents(t *testing.T) {
+	t.Run("zeroed", func(t *testing.T) {
+		// --- Given ---
+		var have []HoleEvent
+		hook := func(pth string, ev HoleEvent) {
+			assert.Equal(t, "dir/file", pth)
+			have = append(have, ev)
+		}
+		root := NewRoot(WithHoleHook(hook))
+		must.Nil(root.WriteFile("dir/file", []byte("abc"), 0600))
+		fil := must.Value(open(root, "dir/file"))
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("x"), 5))
+		must.Nil(fil.Truncate(8))
+
+		// --- Then ---
+		want := []HoleEvent{
+			{Hole: Hole{Off: 3, Len: 2}, Zeroed: true},
+			{Hole: Hole{Off: 6, Len: 2}, Zeroed: true},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("sparse", func(t *testing.T) {
+		// --- Given ---
+		var have []HoleEvent
+		hook := func(_ string, ev HoleEvent) { have = append(have, ev) }
+		fil := MustFileWith("file", []byte("abc"), WithSparse)
+		WithHoleHook(hook)(fil)
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("x"), 5))
+
+		// --- Then ---
+		want := []HoleEvent{{Hole: Hole{Off: 3, Len: 2}, Zeroed: false}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("no hole", func(t *testing.T) {
+		// --- Given ---
+		var have []HoleEvent
+		hook := func(_ string, ev HoleEvent) { have = append(have, ev) }
+		fil := MustFileWith("file", []byte("abc"), WithHoleHook(hook))
+
+		// --- When ---
+		must.Value(fil.WriteAt([]byte("x"), 3))
+		must.Value(fil.Write([]byte("y")))
+		must.Nil(fil.Truncate(2))
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"time"
+)
+
+// Stats is a summary of the I/O operations on a file between opening and
+// closing it, reported to the hook set with [WithStatsHook].
+type Stats struct {
+	// Number of read calls, including the ones returning [io.EOF]. Calls
+	// like [File.ReadAt] and [File.WriteTo] are counted as a single read.
+	Reads int
+
+	// Number of write calls. Calls like [File.ReadFrom] are counted as a
+	// single write.
+	Writes int
+
+	BytesRead    int64         // Number of bytes read.
+	BytesWritten int64         // Number of bytes written.
+	Duration     time.Duration // Time from opening to closing the file.
+}
+
+// WithStatsHook is a [File] constructor function option setting a hook which
+// is called by [File.Close] with the file path and the summary of the I/O
+// operations since the file was opened or last closed. It allows writing
+// assertions like "the file was read in a single pass". The duration is
+// measured from opening the file with [File.Open], or the Open method of the
+// file systems returned by [File.FS] and [File.DirFS], or from the first
+// operation when the file was used directly.
+//
+// The hook set on a directory is used for all files in its tree unless a file
+// or a directory closer to them in the hierarchy has its own hook.
+func WithStatsHook(hook func(pth string, st Stats)) func(*File) {
+	return func(fil *File) { fil.statHook = hook }
+}
+
+// WithNoStats is a [File] constructor function option turning off the I/O
+// statistics of the instance and, when used on a directory, of all files in
+// its tree, for the workloads which care only about the content throughput,
+// like benchmarks using the tree as a scratch space. The read and write
+// calls are not counted, the duration is not measured, and the hooks set
+// with [WithStatsHook] are not called.
+func WithNoStats(fil *File) { fil.nostats = true }
+
+// statsHook returns the hook set with [WithStatsHook] on the instance or the
+// closest of its parents. Returns nil when no hook was set.
+func (fil *File) statsHook() func(pth string, st Stats) {
+	for f := fil; f != nil; f = f.parent {
+		if f.statHook != nil {
+			return f.statHook
+		}
+	}
+	return nil
+}
+
+// opened records opening a handle to the file.
+func (fil *File) opened() {
+	fil.refs++
+	fil.start = time.Now()
+}
+
+// countRead records a read call which read n bytes and delays it by the
+// latency (see [WithLatency]).
+func (fil *File) countRead(n int) {
+	fil.delay()
+	if !fil.statsOn() {
+		return
+	}
+	fil.begin()
+	fil.stats.Reads++
+	fil.stats.BytesRead += int64(n)
+}
+
+// countWrite records a write call which wrote n bytes and delays it by the
+// latency (see [WithLatency]).
+func (fil *File) countWrite(n int) {
+	fil.delay()
+	if !fil.statsOn() {
+		return
+	}
+	fil.begin()
+	fil.stats.Writes++
+	fil.stats.BytesWritten += int64(n)
+}
+
+// begin starts measuring the duration when the file was not opened.
+func (fil *File) begin() {
+	if fil.start.IsZero() {
+		fil.start = time.Now()
+	}
+}
+
+// reportStats calls the hook set with [WithStatsHook] and resets the
+// statistics.
+func (fil *File) reportStats() {
+	if !fil.statsOn() {
+		return
+	}
+	st := fil.stats
+	if !fil.start.IsZero() {
+		st.Duration = time.Since(fil.start)
+	}
+	fil.stats, fil.start = Stats{}, time.Time{}
+	if hook := fil.statsHook(); hook != nil {
+		hook(fil.path(), st)
+	}
+}
+
+// statsOn returns false when the [WithNoStats] option was used on the
+// instance or any of its parents.
+func (fil *File) statsOn() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.nostats {
+			return false
+		}
+	}
+	return true
+}
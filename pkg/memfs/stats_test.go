@@ -0,0 +1,247 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// statsRecorder returns a stats hook recording the reported statistics.
+func statsRecorder(have map[string][]Stats) func(pth string, st Stats) {
+	return func(pth string, st Stats) {
+		st.Duration = 0 // Not deterministic.
+		have[pth] = append(have[pth], st)
+	}
+}
+
+func Test_WithStatsHook(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+
+	// --- When ---
+	WithStatsHook(func(string, Stats) {})(fil)
+
+	// --- Then ---
+	assert.NotNil(t, fil.statHook)
+}
+
+func Test_WithNoStats(t *testing.T) {
+	t.Run("hook is not called", func(t *testing.T) {
+		// --- Given ---
+		have := map[string][]Stats{}
+		hook := WithStatsHook(statsRecorder(have))
+		root := NewRoot(hook, WithNoStats)
+		must.Nil(root.MkdirAll("sub", 0700))
+		must.Nil(root.WriteFile("sub/file", []byte("abc"), 0600))
+		fil := must.Value(open(root, "sub/file"))
+
+		// --- When ---
+		must.Value(io.ReadAll(fil))
+		must.Value(fil.Write([]byte("def")))
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, have)
+		assert.Equal(t, Stats{}, fil.stats)
+		assert.True(t, fil.start.IsZero())
+	})
+
+	t.Run("content and size are kept", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithNoStats)
+		fil := MustFile("file")
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		must.Value(fil.Write([]byte("abc")))
+		must.Value(fil.WriteAt([]byte("de"), 3))
+
+		// --- Then ---
+		assert.Equal(t, int64(5), fil.Size())
+		buf := make([]byte, 5)
+		must.Value(fil.ReadAt(buf, 0))
+		assert.Equal(t, "abcde", string(buf))
+	})
+
+	t.Run("latency is kept", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithNoStats, WithLatency(10*time.Millisecond))
+		fil := MustFile("file")
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		start := time.Now()
+		must.Value(fil.Write([]byte("abc")))
+
+		// --- Then ---
+		assert.True(t, time.Since(start) >= 10*time.Millisecond)
+	})
+}
+
+func Test_File_statsHook(t *testing.T) {
+	t.Run("no hook", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		have := fil.statsHook()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("parent hook", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot(WithStatsHook(func(string, Stats) {}))
+		fil := MustFile("file")
+		must.Nil(root.AddFile(fil))
+
+		// --- When ---
+		have := fil.statsHook()
+
+		// --- Then ---
+		assert.NotNil(t, have)
+	})
+}
+
+func Test_File_reportStats(t *testing.T) {
+	t.Run("single pass read", func(t *testing.T) {
+		// --- Given ---
+		have := map[string][]Stats{}
+		root := NewRoot(WithStatsHook(statsRecorder(have)))
+		must.Nil(root.AddFile(MustFileWith("file", []byte("abcdef"))))
+		fil := must.Value(root.FS().Open("file"))
+
+		// --- When ---
+		must.Value(io.ReadAll(fil))
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []Stats{{Reads: 2, BytesRead: 6}}
+		assert.Equal(t, want, have["file"])
+	})
+
+	t.Run("read calls", func(t *testing.T) {
+		// --- Given ---
+		have := map[string][]Stats{}
+		root := NewRoot(WithStatsHook(statsRecorder(have)))
+		must.Nil(root.AddFile(MustFileWith("file", []byte("abcdef"))))
+		fil := must.Value(open(root, "file"))
+
+		// --- When ---
+		must.Value(fil.Read(make([]byte, 2)))
+		must.Value(fil.ReadByte())
+		must.Value(fil.ReadAt(make([]byte, 2), 4))
+		must.Value(fil.WriteTo(&bytes.Buffer{}))
+		_ = fil.String()
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []Stats{{Reads: 5, BytesRead: 8}}
+		assert.Equal(t, want, have["file"])
+	})
+
+	t.Run("write calls", func(t *testing.T) {
+		// --- Given ---
+		have := map[string][]Stats{}
+		root := NewRoot(WithStatsHook(statsRecorder(have)))
+		must.Nil(root.AddFile(MustFile("file")))
+		fil := must.Value(open(root, "file"))
+
+		// --- When ---
+		must.Value(fil.Write([]byte("ab")))
+		must.Nil(fil.WriteByte('c'))
+		must.Value(fil.WriteAt([]byte("de"), 3))
+		must.Value(fil.WriteString("x"))
+		must.Value(fil.ReadFrom(strings.NewReader("yz")))
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []Stats{{Writes: 5, BytesWritten: 8}}
+		assert.Equal(t, want, have["file"])
+	})
+
+	t.Run("reset on close", func(t *testing.T) {
+		// --- Given ---
+		have := map[string][]Stats{}
+		root := NewRoot(WithStatsHook(statsRecorder(have)))
+		sub := MustDirectory("sub")
+		must.Nil(root.AddFile(sub))
+		must.Nil(sub.AddFile(MustFileWith("file", []byte("abc"))))
+		fil := must.Value(root.Open("sub/file"))
+		must.Value(io.ReadAll(fil))
+		must.Nil(fil.Close())
+		fil = must.Value(root.Open("sub/file"))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []Stats{{Reads: 2, BytesRead: 3}, {}}
+		assert.Equal(t, want, have["sub/file"])
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		// --- Given ---
+		var have []Stats
+		hook := func(_ string, st Stats) { have = append(have, st) }
+		root := NewRoot(WithStatsHook(hook))
+		must.Nil(root.AddFile(MustFileWith("file", []byte("abc"))))
+		fil := must.Value(root.DirFS().Open("file"))
+		start := fil.(*File).start
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 1, have)
+		assert.True(t, have[0].Duration > 0)
+		assert.False(t, start.IsZero())
+		assert.True(t, fil.(*File).start.IsZero())
+	})
+
+	t.Run("no hook", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"))
+		must.Value(io.ReadAll(fil))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, Stats{}, fil.stats)
+	})
+
+	t.Run("failed operations are not counted", func(t *testing.T) {
+		// --- Given ---
+		have := map[string][]Stats{}
+		root := NewRoot(WithStatsHook(statsRecorder(have)))
+		fil := MustFile("file", WithFileAttr(AttrImmutable))
+		must.Nil(root.AddFile(fil))
+		_, err := fil.Write([]byte("abc"))
+		assert.ErrorIs(t, fs.ErrPermission, err)
+
+		// --- When ---
+		err = fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []Stats{{}}, have["file"])
+	})
+}
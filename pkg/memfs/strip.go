@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"syscall"
+)
+
+// stubChunkSize is the maximum size of a zero-filled chunk written by
+// [File.WriteTo] for files with stripped content.
+const stubChunkSize = 32 * 1024
+
+// StripContents recursively releases buffers of regular files in the
+// directory for which the predicate returns true, replacing them with stubs.
+// When pred is nil, all regular files are stripped. The path passed to the
+// predicate is relative to the directory.
+//
+// Stripped files preserve their size and read as zeros. The first operation
+// modifying the content of a stripped file allocates a zeroed buffer of the
+// preserved size. Use it to reduce memory usage when a test needs only the
+// structure and sizes of the tree, not the file contents.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory.
+func (fil *File) StripContents(pred func(pth string, ent *File) bool) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "strip",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	fil.walk("", func(pth string, ent *File) {
+		if ent.IsDir() || (pred != nil && !pred(pth, ent)) {
+			return
+		}
+		ent.info.size = int64(ent.Len())
+		ent.buf = nil
+		ent.stub = true
+	})
+	return nil
+}
+
+// materialize replaces the stub created by [File.StripContents] with a zeroed
+// buffer of the preserved size. It is a no-op for files which are not stubs.
+func (fil *File) materialize() {
+	if !fil.stub {
+		return
+	}
+	fil.buf = makeSlice(int(fil.info.size))
+	fil.stub = false
+	fil.updateSum()
+}
+
+// writeStubTo writes zeros to w starting at the current offset up to the
+// preserved size of the stripped file.
+func (fil *File) writeStubTo(w io.Writer) (int64, error) {
+	var total int64
+	chunk := make([]byte, min(max(fil.Len()-fil.off, 0), stubChunkSize))
+	for fil.off < fil.Len() {
+		n, err := w.Write(chunk[:min(fil.Len()-fil.off, len(chunk))])
+		fil.off += n
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
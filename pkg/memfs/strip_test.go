@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_StripContents(t *testing.T) {
+	t.Run("strip all", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.StripContents(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := must.Value(open(dir, "sub/sub2/file5"))
+		assert.True(t, fil.stub)
+		assert.Nil(t, fil.buf)
+		assert.Equal(t, int64(5), fil.Size())
+		assert.Equal(t, []byte{0, 0, 0, 0, 0}, must.Value(io.ReadAll(fil)))
+	})
+
+	t.Run("strip matching", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		var paths []string
+		pred := func(pth string, ent *File) bool {
+			paths = append(paths, pth)
+			return strings.HasPrefix(pth, "sub/")
+		}
+
+		// --- When ---
+		err := dir.StripContents(pred)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 7, paths)
+		assert.True(t, must.Value(open(dir, "sub/file3")).stub)
+		assert.True(t, must.Value(open(dir, "sub/sub2/file6")).stub)
+		assert.False(t, must.Value(open(dir, "file0")).stub)
+		assert.Equal(t, "file0", must.Value(open(dir, "file0")).String())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+
+		// --- When ---
+		err := fil.StripContents(nil)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "strip", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+}
+
+// stubFile returns a file with content stripped by [File.StripContents].
+func stubFile(content []byte) *File {
+	dir := MustDirectory("dir")
+	fil := MustFileWith("file", content)
+	must.Nil(dir.AddFile(fil))
+	must.Nil(dir.StripContents(nil))
+	return fil
+}
+
+func Test_File_stub(t *testing.T) {
+	t.Run("Stat", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2, 3})
+
+		// --- When ---
+		have, err := fil.Stat()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), have.Size())
+		assert.Equal(t, 3, fil.Len())
+		assert.Equal(t, 0, fil.Cap())
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2, 3})
+		buf := []byte{9, 9, 9, 9}
+
+		// --- When ---
+		n, err := fil.Read(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []byte{0, 0, 0, 9}, buf)
+		assert.Equal(t, 3, fil.off)
+
+		n, err = fil.Read(buf)
+		assert.ErrorIs(t, io.EOF, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("ReadAt", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2, 3})
+		buf := []byte{9, 9}
+
+		// --- When ---
+		n, err := fil.ReadAt(buf, 2)
+
+		// --- Then ---
+		assert.ErrorIs(t, io.EOF, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []byte{0, 9}, buf)
+		assert.Equal(t, 0, fil.off)
+	})
+
+	t.Run("ReadByte", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1})
+
+		// --- When ---
+		have, err := fil.ReadByte()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, byte(0), have)
+
+		_, err = fil.ReadByte()
+		assert.ErrorIs(t, io.EOF, err)
+	})
+
+	t.Run("WriteTo", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile(bytes.Repeat([]byte{1}, stubChunkSize+10))
+		dst := &bytes.Buffer{}
+
+		// --- When ---
+		n, err := fil.WriteTo(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(stubChunkSize+10), n)
+		assert.Equal(t, make([]byte, stubChunkSize+10), dst.Bytes())
+		assert.True(t, fil.stub)
+	})
+
+	t.Run("String", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2})
+
+		// --- When ---
+		have := fil.String()
+
+		// --- Then ---
+		assert.Equal(t, "\x00\x00", have)
+		assert.Equal(t, 2, fil.off)
+	})
+
+	t.Run("Seek end", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2, 3})
+
+		// --- When ---
+		have, err := fil.Seek(-1, io.SeekEnd)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), have)
+	})
+
+	t.Run("Write materializes content", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2, 3})
+		fil.off = 1
+
+		// --- When ---
+		n, err := fil.Write([]byte{4})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.False(t, fil.stub)
+		assert.Equal(t, []byte{0, 4, 0}, fil.buf)
+	})
+
+	t.Run("WriteAt materializes content", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2, 3})
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte{4, 5}, 2)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.False(t, fil.stub)
+		assert.Equal(t, []byte{0, 0, 4, 5}, fil.buf)
+	})
+
+	t.Run("ReadFrom materializes content", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2, 3})
+		fil.off = 3
+
+		// --- When ---
+		n, err := fil.ReadFrom(bytes.NewReader([]byte{4}))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		assert.False(t, fil.stub)
+		assert.Equal(t, []byte{0, 0, 0, 4}, fil.buf)
+	})
+
+	t.Run("Truncate keeps the stub", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2, 3})
+
+		// --- When ---
+		err := fil.Truncate(10)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, fil.stub)
+		assert.Equal(t, int64(10), fil.Size())
+	})
+
+	t.Run("Release materializes content", func(t *testing.T) {
+		// --- Given ---
+		fil := stubFile([]byte{1, 2})
+
+		// --- When ---
+		have := fil.Release()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 0}, have)
+		assert.False(t, fil.stub)
+		assert.Nil(t, fil.buf)
+	})
+}
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// WithReadOnly is a [File] constructor function option making the instance
+// and, when used on a directory, its whole tree read-only, like a file system
+// mounted read-only. Modifying the content of the files, adding entries to
+// the directories, changing the modes and exchanging the entries fail with
+// an error of the [fs.PathError] type with [syscall.EROFS].
+func WithReadOnly(fil *File) { fil.rdonly = true }
+
+// WithWriteOnce is a [File] constructor function option making the regular
+// file and, when used on a directory, all regular files in its tree
+// read-only (see [WithReadOnly]) after their first successful [File.Close]
+// following a modification, or [File.WriteFile], like an artifact store
+// with immutability guarantees. Closing the files only read, for example,
+// with [fs.ReadFile], doesn't seal them.
+// Any following attempt to overwrite, truncate, remove or rename the file
+// fails with an error of the [fs.PathError] type with [syscall.EROFS].
+func WithWriteOnce(fil *File) { fil.once = true }
+
+// WithQuota is a [File] constructor function option limiting the total size
+// of the files in the directory tree to the given number of bytes, like a
+// file system mounted with a size limit. Writes, truncations and additions
+// which would exceed the limit fail with an error of the [fs.PathError] type
+// with [syscall.ENOSPC], the [File.ReadFrom] writes the data up to the limit
+// before failing. When quotas are set on nested directories, all of them
+// apply. The space reserved with [File.Reserve] counts as used. The quota of
+// zero means no limit. The files moved into the tree with [File.Rename] or
+// [File.Exchange] count the same way as the added ones. Checking the quota
+// walks the directory tree, so it's meant for test fixtures rather than
+// large trees.
+func WithQuota(size int64) func(*File) {
+	return func(fil *File) { fil.quota = size }
+}
+
+// WithLatency is a [File] constructor function option delaying every read and
+// write call of the instance and, when used on a directory, of all files in
+// its tree by the given duration, like a slow network mount. The latency set
+// on the nearest directory applies.
+func WithLatency(d time.Duration) func(*File) {
+	return func(fil *File) { fil.latency = d }
+}
+
+// readOnly returns true when the [WithReadOnly] option was used on the
+// instance or any of its parents.
+func (fil *File) readOnly() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.rdonly {
+			return true
+		}
+	}
+	return false
+}
+
+// writeOnce returns true when the [WithWriteOnce] option was used on the
+// instance or any of its parents.
+func (fil *File) writeOnce() bool {
+	for f := fil; f != nil; f = f.parent {
+		if f.once {
+			return true
+		}
+	}
+	return false
+}
+
+// seal makes the regular file read-only when the [WithWriteOnce] option was
+// used on the instance or any of its parents.
+func (fil *File) seal() {
+	if fil.Mode().IsRegular() && fil.writeOnce() {
+		fil.rdonly = true
+	}
+}
+
+// checkReadOnly returns an error of the [fs.PathError] type with
+// [syscall.EROFS] when the instance is read-only (see [WithReadOnly]). The op
+// is used as the operation name in the returned error.
+func (fil *File) checkReadOnly(op string) error {
+	if !fil.readOnly() {
+		return nil
+	}
+	return fil.hookErr(&fs.PathError{
+		Op:   op,
+		Path: fil.path(),
+		Err:  syscall.EROFS,
+	})
+}
+
+// quotaLeft returns the number of bytes the size of the instance can grow by
+// without exceeding any of the quotas set with [WithQuota] on the instance or
+// its parents. It returns false when there are no quotas.
+func (fil *File) quotaLeft() (int64, bool) {
+	var left int64
+	var limited bool
+	for f := fil; f != nil; f = f.parent {
+		if f.quota <= 0 {
+			continue
+		}
+		l := max(f.quota-f.usage(), 0)
+		if !limited || l < left {
+			left, limited = l, true
+		}
+	}
+	return left, limited
+}
+
+// checkQuota returns an error of the [fs.PathError] type with
+// [syscall.ENOSPC] when growing the size of the instance by n bytes exceeds
+// any of the quotas (see [WithQuota]). The op is used as the operation name
+// in the returned error.
+func (fil *File) checkQuota(op string, n int64) error {
+	if n <= 0 {
+		return nil
+	}
+	if left, ok := fil.quotaLeft(); !ok || n <= left {
+		return nil
+	}
+	return fil.hookErr(&fs.PathError{
+		Op:   op,
+		Path: fil.path(),
+		Err:  syscall.ENOSPC,
+	})
+}
+
+// moveFits returns true when moving the file ent into the directory,
+// replacing the file dst when it's not nil, doesn't exceed any of the quotas
+// (see [WithQuota]) set on the directory or its parents. The quotas of the
+// directories which already contain ent are not checked, because the move
+// doesn't grow them.
+func (fil *File) moveFits(ent, dst *File) bool {
+	grow := ent.usage()
+	if dst != nil {
+		grow -= dst.usage()
+	}
+	if grow <= 0 {
+		return true
+	}
+	for f := fil; f != nil; f = f.parent {
+		if f.quota <= 0 || f.isAncestorOf(ent) {
+			continue
+		}
+		if f.usage()+grow > f.quota {
+			return false
+		}
+	}
+	return true
+}
+
+// growsBy returns the number of bytes writing n bytes at the offset off
+// grows the file by.
+func (fil *File) growsBy(off int64, n int) int64 {
+	if n == 0 {
+		return 0
+	}
+	return max(off+int64(n)-int64(fil.Len()), 0)
+}
+
+// usage returns the size of the file or the total size of the files in the
+// directory tree, including the space reserved with [File.Reserve].
+func (fil *File) usage() int64 {
+	if !fil.IsDir() {
+		return int64(fil.Len()) + fil.reserved
+	}
+	size := fil.reserved
+	fil.walk("", func(_ string, ent *File) {
+		size += ent.reserved
+		if !ent.IsDir() {
+			size += int64(ent.Len())
+		}
+	})
+	return size
+}
+
+// delay sleeps for the latency set with [WithLatency] on the instance or the
+// nearest of its parents, unless the [SyncFS] handle calling the method has
+// already slept for it.
+func (fil *File) delay() {
+	if fil.nodelay {
+		return
+	}
+	if d := fil.latencyDur(); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// latencyDur returns the latency set with [WithLatency] on the instance or
+// the nearest of its parents.
+func (fil *File) latencyDur() time.Duration {
+	for f := fil; f != nil; f = f.parent {
+		if f.latency > 0 {
+			return f.latency
+		}
+	}
+	return 0
+}
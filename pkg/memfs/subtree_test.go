@@ -0,0 +1,514 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstMount returns a root directory with the "mnt" subdirectory created with
+// the given options. Both directories have the "file" file with the content
+// "abc".
+func tstMount(opts ...func(*File)) *File {
+	root := NewRoot()
+	must.Nil(root.AddFile(MustFileWith("file", []byte("abc"))))
+	mnt := must.Value(NewDirectory("mnt"))
+	must.Nil(mnt.AddFile(MustFileWith("file", []byte("abc"))))
+	for _, opt := range opts {
+		opt(mnt)
+	}
+	must.Nil(root.AddFile(mnt))
+	return root
+}
+
+func Test_WithReadOnly(t *testing.T) {
+	t.Run("write", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithReadOnly), "mnt/file"))
+
+		// --- When ---
+		n, err := fil.Write([]byte("def"))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "write", e.Op)
+		assert.Equal(t, "mnt/file", e.Path)
+		assert.Equal(t, syscall.EROFS, e.Err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, []byte("abc"), fil.buf)
+	})
+
+	t.Run("outside of the subtree", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithReadOnly), "file"))
+
+		// --- When ---
+		n, err := fil.Write([]byte("def"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithReadOnly), "mnt/file"))
+
+		// --- When ---
+		err := fil.Truncate(0)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "truncate", e.Op)
+		assert.Equal(t, syscall.EROFS, e.Err)
+		assert.Equal(t, 3, fil.Len())
+	})
+
+	t.Run("AddFile", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithReadOnly), "mnt"))
+
+		// --- When ---
+		err := mnt.AddFile(MustFile("new"))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "AddFile", e.Op)
+		assert.Equal(t, "mnt/new", e.Path)
+		assert.Equal(t, syscall.EROFS, e.Err)
+		assert.Len(t, 1, mnt.entries)
+	})
+
+	t.Run("Chmod", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithReadOnly), "mnt/file"))
+
+		// --- When ---
+		err := fil.Chmod(0755)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Equal(t, fs.FileMode(0600), fil.Mode())
+	})
+
+	t.Run("Exchange", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithReadOnly)
+
+		// --- When ---
+		err := root.Exchange("file", "mnt/file")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "exchange", e.Op)
+		assert.Equal(t, "mnt/file", e.Path)
+		assert.Equal(t, syscall.EROFS, e.Err)
+	})
+
+	t.Run("read", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithReadOnly), "mnt/file"))
+
+		// --- When ---
+		have := fil.String()
+
+		// --- Then ---
+		assert.Equal(t, "abc", have)
+	})
+}
+
+func Test_WithWriteOnce(t *testing.T) {
+	t.Run("writable before close", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithWriteOnce)
+
+		// --- When ---
+		n, err := fil.Write([]byte("abc"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.False(t, fil.rdonly)
+	})
+
+	t.Run("read-only after close", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithWriteOnce)
+		must.Value(fil.Write([]byte("abc")))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		n, err := fil.Write([]byte("def"))
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "write", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.EROFS, e.Err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, "abc", fil.String())
+	})
+
+	t.Run("directory tree", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithWriteOnce)
+		fil := must.Value(open(root, "mnt/file"))
+		must.Value(fil.Write([]byte("abc")))
+		must.Nil(fil.Close())
+
+		// --- When ---
+		err := root.Remove("mnt/file")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.ErrorIs(t, syscall.EROFS, fil.Truncate(0))
+		assert.ErrorIs(t, syscall.EROFS, root.Rename("mnt/file", "other"))
+		assert.ErrorIs(t, syscall.EROFS, fil.Chmod(0644))
+		assert.Equal(t, "abc", fil.String())
+	})
+
+	t.Run("not sealed when only read", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte("abc"), WithWriteOnce)
+		must.Value(io.ReadAll(fil))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.False(t, fil.rdonly)
+	})
+
+	t.Run("not sealed when opened for reading", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithWriteOnce)
+		fil := must.Value(root.FS().Open("mnt/file"))
+		must.Value(io.ReadAll(fil))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.False(t, must.Value(open(root, "mnt/file")).rdonly)
+	})
+
+	t.Run("new files in the directory", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithWriteOnce)
+		must.Nil(root.WriteFile("mnt/new", []byte("abc"), 0600))
+
+		// --- When ---
+		err := root.WriteFile("mnt/new", []byte("def"), 0600)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Equal(t, "abc", must.Value(open(root, "mnt/new")).String())
+	})
+
+	t.Run("directory stays writable", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithWriteOnce)
+		mnt := must.Value(open(root, "mnt"))
+		must.Nil(mnt.Close())
+
+		// --- When ---
+		err := mnt.AddFile(MustFile("new"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("outside of the subtree", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithWriteOnce)
+		fil := must.Value(open(root, "file"))
+		must.Nil(fil.Close())
+
+		// --- When ---
+		n, err := fil.Write([]byte("def"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("failed close", func(t *testing.T) {
+		// --- Given ---
+		scan := func(string, []byte) error { return syscall.EACCES }
+		fil := MustFile("file", WithWriteOnce, WithContentScanner(scan))
+		must.Value(fil.Write([]byte("abc")))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EACCES, err)
+		assert.False(t, fil.rdonly)
+	})
+}
+
+func Test_WithQuota(t *testing.T) {
+	t.Run("write within quota", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(6)), "mnt/file"))
+		fil.off = 3
+
+		// --- When ---
+		n, err := fil.Write([]byte("def"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []byte("abcdef"), fil.buf)
+	})
+
+	t.Run("overwrite does not count", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(3)), "mnt/file"))
+
+		// --- When ---
+		n, err := fil.Write([]byte("def"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []byte("def"), fil.buf)
+	})
+
+	t.Run("error - write", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(6)), "mnt/file"))
+		fil.off = 3
+
+		// --- When ---
+		n, err := fil.Write([]byte("defg"))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "write", e.Op)
+		assert.Equal(t, "mnt/file", e.Path)
+		assert.Equal(t, syscall.ENOSPC, e.Err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, []byte("abc"), fil.buf)
+	})
+
+	t.Run("error - WriteByte", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(3)), "mnt/file"))
+		fil.off = 3
+
+		// --- When ---
+		err := fil.WriteByte('d')
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, []byte("abc"), fil.buf)
+	})
+
+	t.Run("error - WriteAt", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(6)), "mnt/file"))
+
+		// --- When ---
+		n, err := fil.WriteAt([]byte("d"), 6)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, []byte("abc"), fil.buf)
+	})
+
+	t.Run("error - Truncate", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(6)), "mnt/file"))
+
+		// --- When ---
+		err := fil.Truncate(7)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "truncate", e.Op)
+		assert.Equal(t, syscall.ENOSPC, e.Err)
+		assert.Equal(t, 3, fil.Len())
+	})
+
+	t.Run("error - AddFile", func(t *testing.T) {
+		// --- Given ---
+		mnt := must.Value(open(tstMount(WithQuota(6)), "mnt"))
+
+		// --- When ---
+		err := mnt.AddFile(MustFileWith("new", []byte("defg")))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "AddFile", e.Op)
+		assert.Equal(t, "mnt/new", e.Path)
+		assert.Equal(t, syscall.ENOSPC, e.Err)
+		assert.Len(t, 1, mnt.entries)
+	})
+
+	t.Run("error - ReadFrom writes up to the quota", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(6)), "mnt/file"))
+		fil.off = 3
+
+		// --- When ---
+		n, err := fil.ReadFrom(strings.NewReader("defgh"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, []byte("abcdef"), fil.buf)
+		assert.Equal(t, 6, fil.Offset())
+		assert.Equal(t, make([]byte, 2), fil.buf[6:8])
+	})
+
+	t.Run("error - ReadFrom file", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(6)), "mnt/file"))
+		fil.off = 3
+		src := MustFileWith("src", []byte("defgh"))
+
+		// --- When ---
+		n, err := fil.ReadFrom(src)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, []byte("abcdef"), fil.buf)
+	})
+
+	t.Run("error - nested quotas", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithQuota(100))
+		root.quota = 6
+		fil := must.Value(open(root, "mnt/file"))
+		fil.off = 3
+
+		// --- When ---
+		n, err := fil.Write([]byte("d"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOSPC, err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func Test_WithLatency(t *testing.T) {
+	t.Run("delays reads and writes", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithLatency(20 * time.Millisecond))
+		fil := must.Value(open(root, "mnt/file"))
+		start := time.Now()
+
+		// --- When ---
+		must.Value(fil.Read(make([]byte, 3)))
+		must.Value(fil.Write([]byte("def")))
+
+		// --- Then ---
+		assert.True(t, time.Since(start) >= 40*time.Millisecond)
+	})
+
+	t.Run("outside of the subtree", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithLatency(time.Hour)), "file"))
+
+		// --- When ---
+		n, err := fil.Write([]byte("def"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+	})
+}
+
+func Test_File_quotaLeft(t *testing.T) {
+	t.Run("no quota", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(), "mnt/file"))
+
+		// --- When ---
+		have, ok := fil.quotaLeft()
+
+		// --- Then ---
+		assert.False(t, ok)
+		assert.Equal(t, int64(0), have)
+	})
+
+	t.Run("smallest of nested quotas", func(t *testing.T) {
+		// --- Given ---
+		root := tstMount(WithQuota(5))
+		root.quota = 100
+		fil := must.Value(open(root, "mnt/file"))
+
+		// --- When ---
+		have, ok := fil.quotaLeft()
+
+		// --- Then ---
+		assert.True(t, ok)
+		assert.Equal(t, int64(2), have)
+	})
+
+	t.Run("exceeded quota", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(open(tstMount(WithQuota(1)), "mnt/file"))
+
+		// --- When ---
+		have, ok := fil.quotaLeft()
+
+		// --- Then ---
+		assert.True(t, ok)
+		assert.Equal(t, int64(0), have)
+	})
+}
+
+func Test_File_usage(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		// --- When ---
+		have := tstDirMem().usage()
+
+		// --- Then ---
+		assert.Equal(t, int64(35), have)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		// --- When ---
+		have := MustFileWith("file", []byte("abc")).usage()
+
+		// --- Then ---
+		assert.Equal(t, int64(3), have)
+	})
+	t.Run("reserved", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+		must.Value(dir.Reserve(1))
+		must.Value(sub.Reserve(2))
+
+		// --- When ---
+		have := dir.usage()
+
+		// --- Then ---
+		assert.Equal(t, int64(38), have)
+	})
+}
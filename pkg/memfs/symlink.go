@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"path"
+	"syscall"
+)
+
+// maxSymlinks is the default maximum number of symbolic links followed when
+// opening a file, the same as on Linux.
+const maxSymlinks = 40
+
+// WithMaxSymlinks is a [File] constructor function option setting the maximum
+// number of symbolic links followed when opening a name in the directory
+// tree. Opening a name which needs more links followed returns an error with
+// [syscall.ELOOP]. The default is 40, the same as on Linux. Lower limits make
+// the loops created by the code under test fail fast, and the higher ones
+// allow long chains of links. When used on a directory, it applies to all
+// names opened in its tree unless a subdirectory sets its own limit. It
+// panics when the limit is less than one.
+func WithMaxSymlinks(n int) func(*File) {
+	if n < 1 {
+		panic("memfs.WithMaxSymlinks: limit less than one")
+	}
+	return func(fil *File) { fil.hops = n }
+}
+
+// symlinkLimit returns the maximum number of symbolic links followed when
+// opening names in the directory, as set on the instance or the nearest of
+// its parents with [WithMaxSymlinks].
+func (fil *File) symlinkLimit() int {
+	for f := fil; f != nil; f = f.parent {
+		if f.hops > 0 {
+			return f.hops
+		}
+	}
+	return maxSymlinks
+}
+
+// Symlink creates the symbolic link with the given name (newname) in the
+// directory tree pointing to the oldname, like [os.Symlink] does. The
+// oldname is not checked, so the link may be dangling. Relative oldnames are
+// resolved from the directory containing the link and may have ".."
+// elements, and the ones starting with "/" from the root of the tree. In the
+// write-through mode (see [File.MirrorTo]), the link is created with the
+// oldname as is.
+//
+// The links are followed by the Open method, the file systems returned by
+// [File.FS] and [File.DirFS], and the methods taking names, except
+// [File.ReadLink], [File.Lstat], [File.Remove], [File.RemoveAll] and
+// [File.Rename], which work on the link itself. Opening a name which needs
+// more than 40 links followed, or the limit set with [WithMaxSymlinks],
+// returns an error with [syscall.ELOOP].
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.ENOTDIR] when the instance or the parent of the link is not a
+//     directory,
+//   - [fs.ErrInvalid] when the newname is not valid,
+//   - [fs.ErrNotExist] when the parent of the link does not exist,
+//   - [syscall.EEXIST] when the newname exists,
+//
+// and the errors returned by [File.AddFile].
+func (fil *File) Symlink(oldname, newname string) error {
+	if !fil.IsDir() {
+		return fil.linkErr("symlink", fil.path(), syscall.ENOTDIR)
+	}
+	newname = fil.lenientPath(newname)
+	if !fs.ValidPath(newname) || newname == "." {
+		return fil.linkErr("symlink", newname, fs.ErrInvalid)
+	}
+
+	dir, err := open(fil, path.Dir(newname))
+	if err != nil {
+		return fil.linkErr("symlink", newname, unwrapPathErr(err))
+	}
+	if !dir.IsDir() {
+		return fil.linkErr("symlink", newname, syscall.ENOTDIR)
+	}
+	if _, err = lopen(dir, path.Base(newname)); err == nil {
+		return fil.linkErr("symlink", newname, syscall.EEXIST)
+	}
+	link := &File{
+		info: FileInfo{
+			name: path.Base(newname),
+			mode: fs.ModeSymlink | 0777,
+		},
+		link: oldname,
+	}
+	return dir.AddFile(link)
+}
+
+// ReadLink returns the destination of the symbolic link with the given name
+// in the directory tree (see [File.Symlink]), like [os.Readlink] does. It
+// implements [fs.ReadLinkFS] interface.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, with [fs.ErrInvalid] when the name is not
+// valid, with [fs.ErrNotExist] when the file does not exist, and with
+// [syscall.EINVAL] when it's not a symbolic link.
+func (fil *File) ReadLink(name string) (string, error) {
+	ent, err := fil.lstat("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	if !ent.isSymlink() {
+		return "", fil.linkErr("readlink", name, syscall.EINVAL)
+	}
+	return ent.link, nil
+}
+
+// Lstat returns the [fs.FileInfo] describing the file with the given name in
+// the directory tree, like [os.Lstat] does. When the file is a symbolic link
+// (see [File.Symlink]), the returned [fs.FileInfo] describes the link. It
+// implements [fs.ReadLinkFS] interface.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, with [fs.ErrInvalid] when the name is not
+// valid, and with [fs.ErrNotExist] when the file does not exist.
+func (fil *File) Lstat(name string) (fs.FileInfo, error) {
+	ent, err := fil.lstat("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return ent.Stat()
+}
+
+// lstat returns the file with the given name without following the symbolic
+// link named by the last element of the name. The op is used as the
+// operation name in the returned errors.
+func (fil *File) lstat(op, name string) (*File, error) {
+	if !fil.IsDir() {
+		return nil, fil.linkErr(op, fil.path(), syscall.ENOTDIR)
+	}
+	name = fil.lenientPath(name)
+	ent, err := lopen(fil, name)
+	if err != nil {
+		return nil, fil.linkErr(op, name, unwrapPathErr(err))
+	}
+	return ent, nil
+}
+
+// isSymlink returns true when the instance is a symbolic link.
+func (fil *File) isSymlink() bool { return fil.Type() == fs.ModeSymlink }
+
+// linkErr returns an error of the [fs.PathError] type for the symbolic link
+// methods.
+func (fil *File) linkErr(op, name string, err error) error {
+	return fil.hookErr(&fs.PathError{Op: op, Path: name, Err: err})
+}
+
+// linkHandle is the handle of a regular file opened through a symbolic link
+// by the file systems returned by [File.FS] and [File.DirFS]. Its Stat
+// method reports the name of the link, like the files opened with [os.Open].
+type linkHandle struct {
+	*File
+	name string // Name of the symbolic link.
+}
+
+// Stat implements [fs.File] interface.
+func (h linkHandle) Stat() (fs.FileInfo, error) {
+	return h.statAs(h.name), nil
+}
+
+// openedAs returns the handle of the file opened with the given name, which
+// is a [linkHandle] when the name is a symbolic link in the directory tree.
+func openedAs(dir, fil *File, name string) fs.File {
+	fil.opened()
+	if ent, _ := lopen(dir, name); ent != fil {
+		return linkHandle{File: fil, name: path.Base(name)}
+	}
+	return fil
+}
@@ -0,0 +1,613 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithMaxSymlinks(t *testing.T) {
+	t.Run("chain within the limit", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithMaxSymlinks(2))
+		must.Nil(dir.WriteFile("file", []byte("abc"), 0644))
+		must.Nil(dir.Symlink("file", "link0"))
+		must.Nil(dir.Symlink("link0", "link1"))
+
+		// --- When ---
+		have, err := dir.ReadFile("link1")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", string(have))
+	})
+
+	t.Run("error - chain longer than the limit", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithMaxSymlinks(2))
+		must.Nil(dir.WriteFile("file", []byte("abc"), 0644))
+		must.Nil(dir.Symlink("file", "link0"))
+		must.Nil(dir.Symlink("link0", "link1"))
+		must.Nil(dir.Symlink("link1", "link2"))
+
+		// --- When ---
+		_, err := dir.ReadFile("link2")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, syscall.ELOOP, e.Err)
+	})
+
+	t.Run("chain longer than the default", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithMaxSymlinks(100))
+		must.Nil(dir.WriteFile("link0", []byte("abc"), 0644))
+		for i := 1; i <= 50; i++ {
+			old, link := fmt.Sprintf("link%d", i-1), fmt.Sprintf("link%d", i)
+			must.Nil(dir.Symlink(old, link))
+		}
+
+		// --- When ---
+		have, err := dir.ReadFile("link50")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", string(have))
+	})
+
+	t.Run("inherited by subdirectories", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithMaxSymlinks(1))
+		must.Nil(dir.MkdirAll("sub", 0755))
+		must.Nil(dir.WriteFile("sub/file", []byte("abc"), 0644))
+		must.Nil(dir.Symlink("file", "sub/link0"))
+		must.Nil(dir.Symlink("link0", "sub/link1"))
+		sub := must.Value(open(dir, "sub"))
+
+		// --- When ---
+		_, err := sub.ReadFile("link1")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ELOOP, err)
+	})
+
+	t.Run("self loop fails fast", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithMaxSymlinks(3))
+		must.Nil(dir.Symlink(".", "self"))
+
+		// --- When ---
+		_, err := open(dir, "self/self/self/self")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ELOOP, err)
+	})
+
+	t.Run("panic - limit less than one", func(t *testing.T) {
+		// --- Given ---
+		fn := func() { WithMaxSymlinks(0) }
+
+		// --- When ---
+		msg := assert.PanicMsg(t, fn)
+
+		// --- Then ---
+		assert.Equal(t, "memfs.WithMaxSymlinks: limit less than one", *msg)
+	})
+}
+
+func Test_File_Symlink(t *testing.T) {
+	t.Run("relative", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Symlink("sub2/file5", "sub/link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(dir.ReadFile("sub/link"))
+		assert.Equal(t, "file5", string(have))
+		ent := must.Value(lopen(dir, "sub/link"))
+		assert.Equal(t, fs.ModeSymlink|0777, ent.Mode())
+		assert.Equal(t, int64(10), ent.Size())
+	})
+
+	t.Run("parent elements", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Symlink("../../file0", "sub/sub2/link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(dir.ReadFile("sub/sub2/link"))
+		assert.Equal(t, "file0", string(have))
+	})
+
+	t.Run("absolute", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+
+		// --- When ---
+		err := sub.Symlink("/file1", "link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file1", string(must.Value(sub.ReadFile("link"))))
+	})
+
+	t.Run("directory in the path", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Symlink("sub/sub2", "link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(dir.ReadFile("link/file6"))
+		assert.Equal(t, "file6", string(have))
+		sub2 := must.Value(open(dir, "sub/sub2"))
+		assert.Same(t, sub2, must.Value(open(dir, "link")))
+	})
+
+	t.Run("link to link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("file2", "link0"))
+
+		// --- When ---
+		err := dir.Symlink("link0", "link1")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file2", string(must.Value(dir.ReadFile("link1"))))
+	})
+
+	t.Run("dangling", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Symlink("not-existing", "link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = open(dir, "link")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Equal(t, "not-existing", must.Value(dir.ReadLink("link")))
+	})
+
+	t.Run("write-through", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+
+		// --- When ---
+		err := dir.Symlink("sub/file3", "link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(os.Readlink(filepath.Join(dst, "link")))
+		assert.Equal(t, "sub/file3", have)
+		assert.Equal(t, "file3", readOS(t, filepath.Join(dst, "link")))
+	})
+
+	t.Run("error - loop", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("link1", "link0"))
+		must.Nil(dir.Symlink("link0", "link1"))
+
+		// --- When ---
+		_, err := dir.ReadFile("link0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ELOOP, err)
+	})
+
+	t.Run("error - self loop through directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink(".", "sub/self"))
+
+		// --- When ---
+		_, err := open(dir, "sub/self/self/self/file3")
+		_, errLoop := open(dir, "sub/self/self/self/self/self/self/self/"+
+			"self/self/self/self/self/self/self/self/self/self/self/self/"+
+			"self/self/self/self/self/self/self/self/self/self/self/self/"+
+			"self/self/self/self/self/self/self/self/self/self/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, errLoop)
+		assert.Equal(t, syscall.ELOOP, e.Err)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").Symlink("a", "b")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "symlink", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		for _, name := range []string{"../link", "/link", ".", ""} {
+			// --- When ---
+			err := tstDirMem().Symlink("file0", name)
+
+			// --- Then ---
+			var e *fs.PathError
+			assert.ErrorAs(t, &e, err)
+			assert.Equal(t, "symlink", e.Op)
+			assert.Equal(t, name, e.Path)
+			assert.Equal(t, fs.ErrInvalid, e.Err)
+		}
+	})
+
+	t.Run("error - parent not existing", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().Symlink("file0", "missing/link")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "missing/link", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+	})
+
+	t.Run("error - parent is a file", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().Symlink("file0", "file1/link")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+
+	t.Run("error - exists", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("not-existing", "link"))
+
+		// --- When ---
+		errFile := dir.Symlink("sub", "file0")
+		errLink := dir.Symlink("sub", "link")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EEXIST, errFile)
+		assert.ErrorIs(t, syscall.EEXIST, errLink)
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithReadOnly(dir)
+
+		// --- When ---
+		err := dir.Symlink("file0", "link")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Len(t, 4, dir.entries)
+	})
+}
+
+func Test_File_ReadLink(t *testing.T) {
+	t.Run("link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("../file0", "sub/link"))
+
+		// --- When ---
+		have, err := dir.ReadLink("sub/link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "../file0", have)
+	})
+
+	t.Run("error - not a link", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().ReadLink("file0")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "readlink", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EINVAL, e.Err)
+		assert.Empty(t, have)
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().ReadLink("sub/missing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "readlink", e.Op)
+		assert.Equal(t, "sub/missing", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+		assert.Empty(t, have)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		_, err := MustFile("file").ReadLink("link")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+}
+
+func Test_File_Lstat(t *testing.T) {
+	t.Run("link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub", "link"))
+
+		// --- When ---
+		have, err := dir.Lstat("link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "link", have.Name())
+		assert.Equal(t, fs.ModeSymlink|0777, have.Mode())
+		assert.Equal(t, int64(3), have.Size())
+		assert.False(t, have.IsDir())
+	})
+
+	t.Run("link in linked directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub", "link"))
+		must.Nil(dir.Symlink("file3", "sub/link3"))
+
+		// --- When ---
+		have, err := dir.Lstat("link/link3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, fs.ModeSymlink, have.Mode().Type())
+	})
+
+	t.Run("file", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().Lstat("sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file3", have.Name())
+		assert.Equal(t, int64(5), have.Size())
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().Lstat("missing")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "lstat", e.Op)
+		assert.Equal(t, "missing", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_Symlink_tree_operations(t *testing.T) {
+	t.Run("Remove removes link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub", "link"))
+
+		// --- When ---
+		err := dir.Remove("link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		_, err = lopen(dir, "link")
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Len(t, 3, must.Value(open(dir, "sub")).entries)
+	})
+
+	t.Run("Rename renames link", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("file0", "link"))
+
+		// --- When ---
+		err := dir.Rename("link", "sub/link")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "file0", must.Value(dir.ReadLink("sub/link")))
+		assert.Equal(t, "file0", string(must.Value(dir.ReadFile("file0"))))
+	})
+
+	t.Run("Rename into linked directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub/sub2", "link"))
+
+		// --- When ---
+		err := dir.Rename("file0", "link/file0")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(dir.ReadFile("sub/sub2/file0"))
+		assert.Equal(t, "file0", string(have))
+	})
+
+	t.Run("WriteFile writes destination", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub/file3", "link"))
+
+		// --- When ---
+		err := dir.WriteFile("link", []byte("new"), 0600)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "new", string(must.Value(dir.ReadFile("sub/file3"))))
+	})
+
+	t.Run("FS", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("sub", "link"))
+		fsys := dir.FS()
+
+		// --- When ---
+		info := must.Value(fs.Stat(fsys, "link"))
+		linfo := must.Value(fs.Lstat(fsys, "link"))
+		dst := must.Value(fs.ReadLink(fsys, "link"))
+
+		// --- Then ---
+		assert.Equal(t, "link", info.Name())
+		assert.True(t, info.IsDir())
+		assert.Equal(t, fs.ModeSymlink, linfo.Mode().Type())
+		assert.Equal(t, "sub", dst)
+		ents := must.Value(fs.ReadDir(fsys, "link"))
+		assert.Equal(t, []string{"file3", "file4", "sub2"}, names(ents))
+	})
+
+	t.Run("FS dangling", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("missing", "link"))
+
+		// --- When ---
+		_, err := fs.Stat(dir.FS(), "link")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOENT, err)
+	})
+
+	t.Run("DirFS", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("../file0", "sub/link"))
+		fsys := dir.DirFS()
+
+		// --- When ---
+		data := must.Value(fs.ReadFile(fsys, "sub/link"))
+		dst := must.Value(fs.ReadLink(fsys, "sub/link"))
+
+		// --- Then ---
+		assert.Equal(t, "file0", string(data))
+		assert.Equal(t, "../file0", dst)
+	})
+
+	t.Run("Faults", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("file0", "link"))
+		plan := func(op, _ string) error {
+			if op == "readlink" {
+				return syscall.EIO
+			}
+			return nil
+		}
+		fsys := Chain(dir.FS(), Faults(plan))
+
+		// --- When ---
+		_, err := fs.ReadLink(fsys, "link")
+		info, errL := fs.Lstat(fsys, "link")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EIO, err)
+		assert.NoError(t, errL)
+		assert.Equal(t, fs.ModeSymlink, info.Mode().Type())
+	})
+
+	t.Run("WriteTar", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.WriteFile("file", []byte("abc"), 0600))
+		must.Nil(dir.Symlink("file", "link"))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteTar(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		tr := tar.NewReader(buf)
+		must.Value(tr.Next())
+		hdr := must.Value(tr.Next())
+		assert.Equal(t, "link", hdr.Name)
+		assert.Equal(t, byte(tar.TypeSymlink), hdr.Typeflag)
+		assert.Equal(t, "file", hdr.Linkname)
+	})
+
+	t.Run("WriteZip", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.Symlink("file", "link"))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteZip(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		rdr := bytes.NewReader(buf.Bytes())
+		zr := must.Value(zip.NewReader(rdr, rdr.Size()))
+		assert.Len(t, 1, zr.File)
+		assert.Equal(t, fs.ModeSymlink, zr.File[0].Mode().Type())
+		rc := must.Value(zr.File[0].Open())
+		assert.Equal(t, "file", string(must.Value(io.ReadAll(rc))))
+	})
+
+	t.Run("Txtar skips links", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.WriteFile("file", []byte("abc"), 0600))
+		must.Nil(dir.Symlink("file", "link"))
+
+		// --- When ---
+		have, err := dir.Txtar()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "-- file --\nabc\n", have)
+	})
+
+	t.Run("walk does not follow links", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.Symlink(".", "sub/self"))
+		var have []string
+
+		// --- When ---
+		dir.walk("", func(pth string, _ *File) { have = append(have, pth) })
+
+		// --- Then ---
+		assert.Len(t, 10, have)
+	})
+}
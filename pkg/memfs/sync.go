@@ -0,0 +1,379 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Compile time checks.
+var (
+	_ fs.ReadDirFS   = &SyncFS{}
+	_ fs.ReadFileFS  = &SyncFS{}
+	_ fs.StatFS      = &SyncFS{}
+	_ fs.ReadDirFile = &syncFile{}
+	_ io.ReaderAt    = &syncFile{}
+	_ io.Seeker      = &syncFile{}
+	_ io.Writer      = &syncFile{}
+)
+
+// SyncFS is a file system backed by a directory tree which is safe for
+// concurrent use by multiple goroutines, for example, parallel tests or HTTP
+// handlers sharing a tree. All access to the tree must go through the same
+// instance while it's in use.
+//
+// The [File] methods change the state of the instance even when reading, for
+// example, the offset, the I/O statistics and the lazily loaded content, so
+// the calls are serialized with a single mutex per tree. The files returned
+// by Open are handles with their own offsets, and the [fs.FileInfo] and
+// [fs.DirEntry] values returned by the methods are snapshots which are safe
+// to use after the tree changes. The handles sleep for the latency (see
+// [WithLatency]) of the reads and writes before taking the mutex, so a slow
+// file doesn't hold up the handles of other files.
+type SyncFS struct {
+	mu  sync.Mutex // Guards the tree.
+	dir *File      // The root of the tree.
+	seq uint64     // Modifications completed, see [SyncFS.Barrier].
+}
+
+// NewSyncFS returns a new instance of [SyncFS] for the directory tree.
+// Returns nil if the file is not a directory.
+func NewSyncFS(dir *File) *SyncFS {
+	if !dir.IsDir() {
+		return nil
+	}
+	return &SyncFS{dir: dir}
+}
+
+// Open implements [fs.FS] interface. The returned file implements
+// [fs.ReadDirFile], [io.ReaderAt], [io.Seeker] and [io.Writer] with the
+// offset starting at zero, independent of other handles.
+func (s *SyncFS) Open(name string) (fs.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fil, err := dirFS{dir: s.dir}.open(name, "open", "open")
+	if err != nil {
+		return nil, err
+	}
+	if fil.bcast != nil {
+		return fil.bcast.file(fil), nil
+	}
+	fil.opened()
+	return &syncFile{fs: s, fil: fil, name: name}, nil
+}
+
+// Stat implements [fs.StatFS] interface.
+func (s *SyncFS) Stat(name string) (fs.FileInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fil, err := dirFS{dir: s.dir}.open(name, "stat", "stat")
+	if err != nil {
+		return nil, err
+	}
+	if fil == s.dir {
+		return fil.statAs("."), nil
+	}
+	return fil.Stat()
+}
+
+// ReadFile implements [fs.ReadFileFS] interface.
+func (s *SyncFS) ReadFile(name string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dirFS{dir: s.dir}.ReadFile(name)
+}
+
+// ReadDir implements [fs.ReadDirFS] interface.
+func (s *SyncFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ets, err := dirFS{dir: s.dir}.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	return entryInfos(ets), nil
+}
+
+// AddFile calls [File.AddFile] on the directory with the given name in the
+// tree. The directory is looked up and the file is added under the same lock,
+// so when many goroutines add files with the same name to the same directory,
+// exactly one of them succeeds and all others get [fs.ErrExist], and the
+// concurrent [SyncFS.Remove] of the name happens either before or after the
+// file is added. The error precedence is the same as in [File.AddFile], for
+// example, adding a file to a read-only directory fails with
+// [syscall.EROFS] even when the name exists. The file must not be used
+// directly after it was added.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOENT] when the
+// directory does not exist or with [syscall.ENOTDIR] when the path goes
+// through a regular file, and the errors returned by [File.AddFile].
+func (s *SyncFS) AddFile(dir string, fil *File) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ent, err := dirFS{dir: s.dir}.open(dir, "open", "open")
+	if err != nil {
+		return err
+	}
+	return s.done(ent.AddFile(fil))
+}
+
+// WriteFile calls [File.WriteFile] on the directory.
+func (s *SyncFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done(s.dir.WriteFile(name, data, perm))
+}
+
+// MkdirAll calls [File.MkdirAll] on the directory.
+func (s *SyncFS) MkdirAll(name string, perm fs.FileMode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done(s.dir.MkdirAll(name, perm))
+}
+
+// Remove calls [File.Remove] on the directory.
+func (s *SyncFS) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done(s.dir.Remove(name))
+}
+
+// RemoveAll calls [File.RemoveAll] on the directory.
+func (s *SyncFS) RemoveAll(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done(s.dir.RemoveAll(name))
+}
+
+// Rename calls [File.Rename] on the directory.
+func (s *SyncFS) Rename(oldpath, newpath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done(s.dir.Rename(oldpath, newpath))
+}
+
+// Barrier waits for the operation which holds the lock of the tree to
+// complete and returns the number of the successful modifications of the
+// tree made through the instance so far: the calls to [SyncFS.AddFile],
+// [SyncFS.WriteFile], [SyncFS.MkdirAll], [SyncFS.Remove],
+// [SyncFS.RemoveAll], [SyncFS.Rename] and the writes through the handles.
+// All modifications counted by the returned number are visible to the calls
+// made after it returns, so the tests can make precise assertions about what
+// was written by a given point. The operations which haven't taken the lock
+// yet, for example, the handles sleeping for the latency (see
+// [WithLatency]), are not waited for.
+func (s *SyncFS) Barrier() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
+// done counts the modification of the tree when err is nil. Returns err.
+// Must be called with the lock held.
+func (s *SyncFS) done(err error) error {
+	if err == nil {
+		s.seq++
+	}
+	return err
+}
+
+// syncFile is the handle returned by [SyncFS.Open].
+type syncFile struct {
+	fs      *SyncFS
+	fil     *File
+	name    string        // Name passed to Open.
+	off     int64         // Offset of the handle.
+	listing []fs.DirEntry // Snapshot of the directory entries.
+	cursor  int           // Directory entries already read.
+	read    bool          // The directory listing was taken.
+	wrote   bool          // The file was modified through the handle.
+	closed  bool          // The handle was closed.
+}
+
+// Stat implements [fs.File] interface.
+func (f *syncFile) Stat() (fs.FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("stat"); err != nil {
+		return nil, err
+	}
+	if f.fil == f.fs.dir {
+		return f.fil.statAs("."), nil
+	}
+	return f.fil.Stat()
+}
+
+// Read implements [fs.File] interface.
+func (f *syncFile) Read(p []byte) (int, error) {
+	f.delay()
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("read"); err != nil {
+		return 0, err
+	}
+	f.fil.nodelay = true
+	n, err := f.fil.ReadAt(p, f.off)
+	f.fil.nodelay = false
+	f.off += int64(n)
+	if n > 0 && errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt implements [io.ReaderAt] interface.
+func (f *syncFile) ReadAt(p []byte, off int64) (int, error) {
+	f.delay()
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("read"); err != nil {
+		return 0, err
+	}
+	f.fil.nodelay = true
+	defer func() { f.fil.nodelay = false }()
+	return f.fil.ReadAt(p, off)
+}
+
+// Write implements [io.Writer] interface. When the file has the
+// [os.O_APPEND] flag, the data is appended to the file, and the offset of
+// the handle is moved to its end.
+func (f *syncFile) Write(p []byte) (int, error) {
+	f.delay()
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("write"); err != nil {
+		return 0, err
+	}
+	f.fil.nodelay = true
+	var n int
+	var err error
+	if f.fil.flag&os.O_APPEND != 0 {
+		n, err = f.fil.Write(p)
+		f.off = int64(f.fil.Len())
+	} else {
+		n, err = f.fil.WriteAt(p, f.off)
+		f.off += int64(n)
+	}
+	f.fil.nodelay = false
+	f.wrote = f.wrote || err == nil
+	return n, f.fs.done(err)
+}
+
+// delay sleeps for the latency of the file (see [WithLatency]) without
+// holding the mutex of the tree. The [File] methods called by the handle
+// don't sleep again.
+func (f *syncFile) delay() {
+	f.fs.mu.Lock()
+	d := f.fil.latencyDur()
+	f.fs.mu.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// Seek implements [io.Seeker] interface.
+func (f *syncFile) Seek(offset int64, whence int) (int64, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("seek"); err != nil {
+		return 0, err
+	}
+	if f.fil.IsDir() {
+		return 0, f.err("seek", syscall.EISDIR)
+	}
+	off := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		off += f.off
+	case io.SeekEnd:
+		off += f.fil.Size()
+	default:
+		return 0, f.err("seek", fs.ErrInvalid)
+	}
+	if off < 0 {
+		return 0, f.err("seek", fs.ErrInvalid)
+	}
+	f.off = off
+	return off, nil
+}
+
+// ReadDir implements [fs.ReadDirFile] interface. The first call takes a
+// snapshot of the directory entries in the name order.
+func (f *syncFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("readdir"); err != nil {
+		return nil, err
+	}
+	if !f.fil.IsDir() {
+		return nil, f.err("readdir", syscall.ENOTDIR)
+	}
+	if !f.read {
+		ets, err := dirFS{dir: f.fil}.ReadDir(".")
+		if err != nil {
+			return nil, err
+		}
+		f.listing = entryInfos(ets)
+		f.read = true
+	}
+	left := f.listing[f.cursor:]
+	if n <= 0 {
+		f.cursor = len(f.listing)
+		return left, nil
+	}
+	if len(left) == 0 {
+		return nil, io.EOF
+	}
+	left = left[:min(n, len(left))]
+	f.cursor += len(left)
+	return left, nil
+}
+
+// Close implements [fs.File] interface. It releases the entries snapshot of
+// the directories and the file like [File.Close] does, without resetting its
+// offset, so the content scanner, the checksum verification and the
+// statistics hook run, and the write-once files modified through the handle
+// are sealed (see [WithWriteOnce]).
+func (f *syncFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("close"); err != nil {
+		return err
+	}
+	f.closed = true
+	f.listing, f.cursor, f.read = nil, 0, false
+	return f.fil.release(f.wrote)
+}
+
+// check returns an error of the [fs.PathError] type with [fs.ErrClosed] when
+// the handle was closed.
+func (f *syncFile) check(op string) error {
+	if f.closed {
+		return f.err(op, fs.ErrClosed)
+	}
+	return nil
+}
+
+// err returns an error of the [fs.PathError] type for the handle.
+func (f *syncFile) err(op string, err error) error {
+	return f.fil.hookErr(&fs.PathError{Op: op, Path: f.name, Err: err})
+}
+
+// entryInfos returns the snapshots of the directory entries which are safe
+// to use after the tree changes.
+func entryInfos(ets []fs.DirEntry) []fs.DirEntry {
+	have := make([]fs.DirEntry, 0, len(ets))
+	for _, ent := range ets {
+		info, _ := ent.Info()
+		have = append(have, info.(FileInfo))
+	}
+	return have
+}
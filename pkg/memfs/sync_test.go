@@ -0,0 +1,615 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_NewSyncFS(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have := NewSyncFS(dir)
+
+		// --- Then ---
+		assert.NotNil(t, have)
+		assert.Same(t, dir, have.dir)
+	})
+
+	t.Run("not a directory", func(t *testing.T) {
+		// --- When ---
+		have := NewSyncFS(MustFile("file"))
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+}
+
+func Test_SyncFS(t *testing.T) {
+	t.Run("passes fstest", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+
+		// --- When ---
+		err := fstest.TestFS(fsys, "file0", "sub/file3", "sub/sub2/file6")
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent readers and writers", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		var wg sync.WaitGroup
+
+		// --- When ---
+		for i := range 8 {
+			wg.Go(func() {
+				name := fmt.Sprintf("dir%d/file", i)
+				must.Nil(fsys.WriteFile(name, []byte("abc"), 0600))
+				must.Value(fs.ReadDir(fsys, "."))
+				must.Value(fs.ReadFile(fsys, "sub/file3"))
+				fil := must.Value(fsys.Open("file0"))
+				must.Value(io.ReadAll(fil))
+				must.Nil(fil.Close())
+			})
+		}
+		wg.Wait()
+
+		// --- Then ---
+		ets := must.Value(fsys.ReadDir("."))
+		assert.Len(t, 12, ets)
+	})
+
+	t.Run("Stat returns snapshot", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+
+		// --- When ---
+		have, err := fsys.Stat("sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		must.Nil(fsys.WriteFile("sub/file3", []byte("a"), 0600))
+		assert.Equal(t, int64(5), have.Size())
+	})
+
+	t.Run("Stat root", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(must.Value(NewDirectory("dir")))
+
+		// --- When ---
+		have, err := fsys.Stat(".")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, ".", have.Name())
+		h := must.Value(fsys.Open("."))
+		assert.Equal(t, ".", must.Value(h.Stat()).Name())
+	})
+
+	t.Run("ReadDir returns snapshots", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+
+		// --- When ---
+		have, err := fsys.ReadDir("sub")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"file3", "file4", "sub2"}, names(have))
+		_, ok := have[0].(FileInfo)
+		assert.True(t, ok)
+	})
+
+	t.Run("mutating methods", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+
+		// --- When ---
+		must.Nil(fsys.MkdirAll("a/b", 0700))
+		must.Nil(fsys.Rename("file0", "a/b/file0"))
+		must.Nil(fsys.Remove("file1"))
+		must.Nil(fsys.RemoveAll("sub"))
+
+		// --- Then ---
+		ets := must.Value(fsys.ReadDir("."))
+		assert.Equal(t, []string{"a", "file2"}, names(ets))
+		data := must.Value(fsys.ReadFile("a/b/file0"))
+		assert.Equal(t, "file0", string(data))
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+
+		// --- When ---
+		have, err := fsys.Open("not-existing")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_SyncFS_AddFile(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+
+		// --- When ---
+		err := fsys.AddFile("sub/sub2", MustFileWith("new", []byte("abc")))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		data := must.Value(fsys.ReadFile("sub/sub2/new"))
+		assert.Equal(t, "abc", string(data))
+	})
+
+	t.Run("same name concurrently", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		var added, exist atomic.Int32
+		var wg sync.WaitGroup
+
+		// --- When ---
+		for i := range 32 {
+			wg.Go(func() {
+				data := []byte(strconv.Itoa(i))
+				err := fsys.AddFile("sub", MustFileWith("new", data))
+				switch {
+				case err == nil:
+					added.Add(1)
+				case errors.Is(err, fs.ErrExist):
+					exist.Add(1)
+				}
+			})
+		}
+		wg.Wait()
+
+		// --- Then ---
+		assert.Equal(t, int32(1), added.Load())
+		assert.Equal(t, int32(31), exist.Load())
+		ets := must.Value(fsys.ReadDir("sub"))
+		assert.Equal(t, []string{"file3", "file4", "new", "sub2"}, names(ets))
+	})
+
+	t.Run("add and remove concurrently", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		var added, removed atomic.Int32
+		var wg sync.WaitGroup
+
+		// --- When ---
+		for range 16 {
+			wg.Go(func() {
+				for range 100 {
+					if fsys.AddFile("sub", MustFile("new")) == nil {
+						added.Add(1)
+					}
+				}
+			})
+			wg.Go(func() {
+				for range 100 {
+					if fsys.Remove("sub/new") == nil {
+						removed.Add(1)
+					}
+				}
+			})
+		}
+		wg.Wait()
+
+		// --- Then ---
+		_, err := fsys.Stat("sub/new")
+		exists := int32(0)
+		if err == nil {
+			exists = 1
+		}
+		assert.Equal(t, added.Load(), removed.Load()+exists)
+	})
+
+	t.Run("error - read-only directory takes precedence", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.AddFile(MustFile("new")))
+		WithReadOnly(dir)
+		fsys := NewSyncFS(dir)
+
+		// --- When ---
+		err := fsys.AddFile(".", MustFile("new"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+	})
+
+	t.Run("error - directory does not exist", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+
+		// --- When ---
+		err := fsys.AddFile("missing", MustFile("new"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOENT, err)
+	})
+
+	t.Run("error - path goes through a file", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+
+		// --- When ---
+		err := fsys.AddFile("file0/sub", MustFile("new"))
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+}
+
+func Test_SyncFS_Barrier(t *testing.T) {
+	t.Run("counts successful modifications", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		must.Nil(fsys.WriteFile("new", []byte("abc"), 0600))
+		must.Nil(fsys.MkdirAll("a/b", 0700))
+		_ = fsys.Remove("not-existing")
+		must.Value(fsys.ReadFile("file0"))
+		fil := must.Value(fsys.Open("file0"))
+		must.Value(fil.(io.Writer).Write([]byte("x")))
+
+		// --- When ---
+		have := fsys.Barrier()
+
+		// --- Then ---
+		assert.Equal(t, uint64(3), have)
+	})
+
+	t.Run("concurrent writers", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Go(func() {
+				name := fmt.Sprintf("file%d", i)
+				must.Nil(fsys.WriteFile(name, []byte("abc"), 0600))
+			})
+		}
+		wg.Wait()
+
+		// --- When ---
+		have := fsys.Barrier()
+
+		// --- Then ---
+		assert.Equal(t, uint64(8), have)
+	})
+
+	t.Run("waits for operation in flight", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		fsys.mu.Lock()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			must.Nil(fsys.done(fsys.dir.WriteFile("new", nil, 0600)))
+			fsys.mu.Unlock()
+		}()
+
+		// --- When ---
+		have := fsys.Barrier()
+
+		// --- Then ---
+		assert.Equal(t, uint64(1), have)
+		_, err := fsys.Stat("new")
+		assert.NoError(t, err)
+	})
+}
+
+func Test_syncFile(t *testing.T) {
+	t.Run("independent offsets", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fsys := NewSyncFS(dir)
+		fa := must.Value(fsys.Open("file0"))
+		fb := must.Value(fsys.Open("file0"))
+		buf := make([]byte, 2)
+
+		// --- When ---
+		must.Value(fa.Read(buf))
+		n, err := fb.Read(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, "fi", string(buf))
+		assert.Equal(t, 0, must.Value(open(dir, "file0")).Offset())
+	})
+
+	t.Run("Read to EOF", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewSyncFS(tstDirMem()).Open("file0"))
+		buf := make([]byte, 10)
+
+		// --- When ---
+		n0, err0 := fil.Read(buf)
+		n1, err1 := fil.Read(buf)
+
+		// --- Then ---
+		assert.NoError(t, err0)
+		assert.Equal(t, 5, n0)
+		assert.ErrorIs(t, io.EOF, err1)
+		assert.Equal(t, 0, n1)
+	})
+
+	t.Run("Write and Seek", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(NewSyncFS(dir).Open("file0")).(*syncFile)
+
+		// --- When ---
+		off, err := fil.Seek(-1, io.SeekEnd)
+		n, wErr := fil.Write([]byte("XY"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), off)
+		assert.NoError(t, wErr)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, int64(6), fil.off)
+		assert.Equal(t, "fileXY", string(must.Value(open(dir, "file0")).buf))
+	})
+
+	t.Run("Write append", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		must.Nil(dir.AddFile(must.Value(NewLog("app.log"))))
+		fsys := NewSyncFS(dir)
+		fa := must.Value(fsys.Open("app.log")).(*syncFile)
+		fb := must.Value(fsys.Open("app.log")).(*syncFile)
+
+		// --- When ---
+		na, errA := fa.Write([]byte("abc"))
+		nb, errB := fb.Write([]byte("de"))
+
+		// --- Then ---
+		assert.NoError(t, errA)
+		assert.NoError(t, errB)
+		assert.Equal(t, 3, na)
+		assert.Equal(t, 2, nb)
+		assert.Equal(t, int64(3), fa.off)
+		assert.Equal(t, int64(5), fb.off)
+		assert.Equal(t, "abcde", string(must.Value(fsys.ReadFile("app.log"))))
+	})
+
+	t.Run("latency outside the lock", func(t *testing.T) {
+		// --- Given ---
+		lat := 100 * time.Millisecond
+		fsys := NewSyncFS(NewRoot(WithLatency(lat)))
+		var fils []fs.File
+		for i := range 4 {
+			name := "file" + strconv.Itoa(i)
+			must.Nil(fsys.WriteFile(name, []byte("abc"), 0600))
+			fils = append(fils, must.Value(fsys.Open(name)))
+		}
+		var wg sync.WaitGroup
+		start := time.Now()
+
+		// --- When ---
+		for _, fil := range fils {
+			wg.Go(func() { must.Value(fil.Read(make([]byte, 1))) })
+		}
+		wg.Wait()
+
+		// --- Then ---
+		have := time.Since(start)
+		assert.True(t, have >= lat)
+		assert.True(t, have < 3*lat)
+	})
+
+	t.Run("Seek current", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewSyncFS(tstDirMem()).Open("file0")).(*syncFile)
+		fil.off = 2
+
+		// --- When ---
+		off, err := fil.Seek(1, io.SeekCurrent)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), off)
+	})
+
+	t.Run("ReadAt", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewSyncFS(tstDirMem()).Open("file0")).(*syncFile)
+		buf := make([]byte, 3)
+
+		// --- When ---
+		n, err := fil.ReadAt(buf, 2)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, "le0", string(buf))
+		assert.Equal(t, int64(0), fil.off)
+	})
+
+	t.Run("ReadDir in batches", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		dir := must.Value(fsys.Open("sub")).(fs.ReadDirFile)
+
+		// --- When ---
+		have0, err0 := dir.ReadDir(2)
+		must.Nil(fsys.WriteFile("sub/added", nil, 0600))
+		have1, err1 := dir.ReadDir(2)
+		have2, err2 := dir.ReadDir(2)
+
+		// --- Then ---
+		assert.NoError(t, err0)
+		assert.Equal(t, []string{"file3", "file4"}, names(have0))
+		assert.NoError(t, err1)
+		assert.Equal(t, []string{"sub2"}, names(have1))
+		assert.ErrorIs(t, io.EOF, err2)
+		assert.Nil(t, have2)
+	})
+
+	t.Run("Close releases snapshot", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		dir := must.Value(fsys.Open("sub")).(*syncFile)
+		must.Value(dir.ReadDir(1))
+
+		// --- When ---
+		err := dir.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, dir.listing)
+		assert.Equal(t, 0, dir.cursor)
+		assert.False(t, dir.read)
+	})
+
+	t.Run("Close releases the file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(NewSyncFS(dir).Open("file0"))
+
+		// --- When ---
+		err := fil.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 0, must.Value(open(dir, "file0")).OpenCount())
+	})
+
+	t.Run("Close reports statistics", func(t *testing.T) {
+		// --- Given ---
+		var have Stats
+		hook := func(_ string, st Stats) { have = st }
+		dir := NewRoot(WithStatsHook(hook))
+		must.Nil(dir.WriteFile("file", nil, 0600))
+		fil := must.Value(NewSyncFS(dir).Open("file")).(io.Writer)
+		must.Value(fil.Write([]byte("abc")))
+
+		// --- When ---
+		err := fil.(fs.File).Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 1, have.Writes)
+		assert.Equal(t, int64(3), have.BytesWritten)
+	})
+
+	t.Run("Close runs the content scanner", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("test error")
+		scan := func(string, []byte) error { return e }
+		dir := NewRoot(WithContentScanner(scan))
+		must.Nil(dir.WriteFile("file", []byte("abc"), 0600))
+		fil := must.Value(NewSyncFS(dir).Open("file")).(io.Writer)
+		must.Value(fil.Write([]byte("X")))
+
+		// --- When ---
+		err := fil.(fs.File).Close()
+
+		// --- Then ---
+		assert.ErrorIs(t, e, err)
+		assert.Equal(t, "abc", string(must.Value(open(dir, "file")).buf))
+	})
+
+	t.Run("Close seals written write-once file", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithWriteOnce)
+		must.Nil(dir.AddFile(MustFile("file")))
+		fsys := NewSyncFS(dir)
+		fil := must.Value(fsys.Open("file")).(io.Writer)
+		must.Value(fil.Write([]byte("abc")))
+
+		// --- When ---
+		err := fil.(fs.File).Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		err = fsys.WriteFile("file", []byte("X"), 0600)
+		assert.ErrorIs(t, syscall.EROFS, err)
+	})
+
+	t.Run("Close does not seal read write-once file", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithWriteOnce)
+		fil := MustFile("file")
+		must.Nil(dir.AddFile(fil))
+		fsys := NewSyncFS(dir)
+		h := must.Value(fsys.Open("file"))
+		must.Value(io.ReadAll(h))
+
+		// --- When ---
+		err := h.Close()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.False(t, fil.rdonly)
+	})
+
+	t.Run("error - closed", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewSyncFS(tstDirMem()).Open("file0"))
+		must.Nil(fil.Close())
+
+		// --- When ---
+		n, err := fil.Read(make([]byte, 1))
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, fs.ErrClosed, e.Err)
+		assert.Equal(t, 0, n)
+		assert.ErrorIs(t, fs.ErrClosed, fil.Close())
+	})
+
+	t.Run("error - ReadDir on file", func(t *testing.T) {
+		// --- Given ---
+		fil := must.Value(NewSyncFS(tstDirMem()).Open("file0"))
+
+		// --- When ---
+		have, err := fil.(fs.ReadDirFile).ReadDir(-1)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - Seek", func(t *testing.T) {
+		// --- Given ---
+		fsys := NewSyncFS(tstDirMem())
+		fil := must.Value(fsys.Open("file0")).(io.Seeker)
+		dir := must.Value(fsys.Open("sub")).(io.Seeker)
+
+		// --- When ---
+		_, errN := fil.Seek(-1, io.SeekStart)
+		_, errW := fil.Seek(0, 42)
+		_, errD := dir.Seek(0, io.SeekStart)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, errN)
+		assert.ErrorIs(t, fs.ErrInvalid, errW)
+		assert.ErrorIs(t, syscall.EISDIR, errD)
+	})
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"context"
+	"io/fs"
+	"slices"
+	"syscall"
+)
+
+// tail represents a single [File.Tail] subscription.
+type tail struct {
+	ch   chan []byte   // Channel receiving appended bytes.
+	wake chan struct{} // Signals the pending bytes to the sending goroutine.
+	pend []byte        // Appended bytes not sent yet, guarded by tailMu.
+	pos  int           // File length already added to the pending bytes.
+}
+
+// Tail streams bytes appended to the file with the given name relative to the
+// directory, the same way "tail -f" does. Every modification growing the file
+// queues a copy of the bytes beyond the previously queued length, and a
+// goroutine sends them to the returned channel. The queued bytes are buffered
+// for every subscription, so the modifying calls never wait for the receiver,
+// and the bytes queued before the receiver is ready are sent together as one
+// slice. When the file is truncated, streaming continues from the new end of
+// the file. The channel is closed when the context is done, and the bytes not
+// received by then are dropped.
+//
+// Tail must be called before the file is modified from other goroutines.
+// Returns an error of the [fs.PathError] type when the file does not exist
+// and with [syscall.EISDIR] when the name represents a directory.
+func (fil *File) Tail(ctx context.Context, name string) (<-chan []byte, error) {
+	f, err := open(fil, name)
+	if err != nil {
+		return nil, fil.hookErr(err)
+	}
+	if f.IsDir() {
+		return nil, fil.hookErr(&fs.PathError{
+			Op:   "tail",
+			Path: f.path(),
+			Err:  syscall.EISDIR,
+		})
+	}
+
+	tl := &tail{
+		ch:   make(chan []byte),
+		wake: make(chan struct{}, 1),
+		pos:  f.Len(),
+	}
+	f.tailMu.Lock()
+	f.tails = append(f.tails, tl)
+	f.ntail.Add(1)
+	f.tailMu.Unlock()
+
+	go f.sendTail(ctx, tl)
+	return tl.ch, nil
+}
+
+// sendTail sends the pending bytes of the [File.Tail] subscription to its
+// channel until the context is done, then removes the subscription and
+// closes the channel.
+func (fil *File) sendTail(ctx context.Context, tl *tail) {
+	defer func() {
+		fil.tailMu.Lock()
+		fil.tails = slices.DeleteFunc(fil.tails, func(t *tail) bool {
+			return t == tl
+		})
+		fil.ntail.Add(-1)
+		fil.tailMu.Unlock()
+		close(tl.ch)
+	}()
+
+	for {
+		select {
+		case <-tl.wake:
+		case <-ctx.Done():
+			return
+		}
+		fil.tailMu.Lock()
+		data := tl.pend
+		tl.pend = nil
+		fil.tailMu.Unlock()
+		if len(data) == 0 {
+			continue
+		}
+		select {
+		case tl.ch <- data:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notifyTails queues bytes appended since the last notification for the
+// [File.Tail] subscribers. It never blocks.
+func (fil *File) notifyTails() {
+	if fil.ntail.Load() == 0 {
+		return
+	}
+	fil.tailMu.Lock()
+	defer fil.tailMu.Unlock()
+
+	l := fil.Len()
+	for _, tl := range fil.tails {
+		if l <= tl.pos {
+			tl.pos = l // Truncated or not changed.
+			continue
+		}
+		if fil.stub {
+			tl.pend = append(tl.pend, make([]byte, l-tl.pos)...)
+		} else {
+			tl.pend = append(tl.pend, fil.buf[tl.pos:l]...)
+		}
+		tl.pos = l
+		select {
+		case tl.wake <- struct{}{}:
+		default:
+		}
+	}
+}
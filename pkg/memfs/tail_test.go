@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Tail(t *testing.T) {
+	t.Run("streams appended bytes", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "sub/file3"))
+		fil.flag |= os.O_APPEND
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// --- When ---
+		ch, err := dir.Tail(ctx, "sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		go func() {
+			must.Value(fil.WriteString("abc"))
+			must.Nil(fil.WriteByte('d'))
+		}()
+		var have []byte
+		for len(have) < 4 {
+			have = append(have, <-ch...)
+		}
+		assert.Equal(t, []byte("abcd"), have)
+	})
+
+	t.Run("write and receive on the same goroutine", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ch := must.Value(fil.Tail(ctx, "."))
+
+		// --- When ---
+		must.Value(fil.Write([]byte("abc")))
+		must.Value(fil.Write([]byte("def")))
+
+		// --- Then ---
+		assert.Equal(t, []byte("abcdef"), <-ch)
+	})
+
+	t.Run("stub is streamed as zeros", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ch := must.Value(fil.Tail(ctx, "."))
+		fil.info.size = 2
+		fil.stub = true
+
+		// --- When ---
+		fil.notifyTails()
+
+		// --- Then ---
+		assert.Equal(t, []byte{0, 0}, <-ch)
+	})
+
+	t.Run("overwrite is not streamed", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := must.Value(fil.Tail(ctx, "."))
+
+		// --- When ---
+		go func() {
+			must.Value(fil.WriteAt([]byte{4, 5}, 2))
+		}()
+
+		// --- Then ---
+		assert.Equal(t, []byte{5}, <-ch)
+	})
+
+	t.Run("continues after truncation", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFileWith("file", []byte{0, 1, 2})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := must.Value(fil.Tail(ctx, "."))
+
+		// --- When ---
+		go func() {
+			must.Nil(fil.Truncate(1))
+			must.Value(fil.WriteAt([]byte{7, 8}, 1))
+		}()
+
+		// --- Then ---
+		assert.Equal(t, []byte{7, 8}, <-ch)
+	})
+
+	t.Run("channel closed when context is done", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := must.Value(fil.Tail(ctx, "."))
+
+		// --- When ---
+		cancel()
+
+		// --- Then ---
+		_, ok := <-ch
+		assert.False(t, ok)
+		must.Value(fil.Write([]byte{0}))
+		assert.Len(t, 0, fil.tails)
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		ch, err := dir.Tail(context.Background(), "sub/missing")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, ch)
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		ch, err := dir.Tail(context.Background(), "sub")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "tail", e.Op)
+		assert.Equal(t, "sub", e.Path)
+		assert.Equal(t, syscall.EISDIR, e.Err)
+		assert.Nil(t, ch)
+	})
+}
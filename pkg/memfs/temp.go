@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// tempTries is the number of names tried by [File.CreateTemp] and
+// [File.MkdirTemp] before giving up, the same as in the [os] package.
+const tempTries = 10000
+
+// WithTempSeed is a [File] constructor function option making the names
+// generated by [File.CreateTemp] and [File.MkdirTemp] for the directory and
+// all directories in its tree come from a pseudo-random sequence seeded with
+// the seed. Building the same tree the same way gives the same names on
+// every run and every machine, which keeps the golden files stable. Without
+// the option, the names are random like the ones generated by the [os]
+// package.
+func WithTempSeed(seed uint64) func(*File) {
+	return func(fil *File) { fil.rnd = rand.NewPCG(seed, 0) }
+}
+
+// CreateTemp creates a new regular file with the permission bits 0600 in the
+// directory with the given name in the directory tree, or in the directory
+// itself when the name is empty, like [os.CreateTemp] does. The file name is
+// the pattern with its last "*" replaced by a random string, or with the
+// random string appended when there is no "*". The returned [Handle] is
+// opened for reading and writing (see [File.OpenFile]), and its name is the
+// path of the file relative to the instance. Use [WithTempSeed] to make the
+// names reproducible.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, with [fs.ErrInvalid] when the pattern
+// contains a path separator, with [fs.ErrExist] when no unused name was
+// found, and the errors returned by [File.OpenFile].
+func (fil *File) CreateTemp(dir, pattern string) (*Handle, error) {
+	prefix, suffix, err := fil.tempPattern("createtemp", pattern)
+	if err != nil {
+		return nil, err
+	}
+	for range tempTries {
+		name := path.Join(dir, prefix+fil.tempName()+suffix)
+		flag := os.O_RDWR | os.O_CREATE | os.O_EXCL
+		ent, err := fil.OpenFile(name, flag, 0600)
+		if errors.Is(err, syscall.EEXIST) {
+			continue
+		}
+		return ent, err
+	}
+	pth := path.Join(dir, prefix+"*"+suffix)
+	return nil, fil.tempErr("createtemp", pth, fs.ErrExist)
+}
+
+// MkdirTemp creates a new directory with the permission bits 0700 in the
+// directory with the given name in the directory tree, or in the directory
+// itself when the name is empty, like [os.MkdirTemp] does, and returns its
+// path relative to the instance. The new directory name is generated the
+// same way as by [File.CreateTemp].
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance, or the directory with the given name, is not a directory, with
+// [fs.ErrInvalid] when the dir is not valid or the pattern contains a path
+// separator, with [fs.ErrNotExist] when the directory with the given name
+// does not exist, with [fs.ErrExist] when no unused name was found, and the
+// errors returned by [File.AddFile].
+func (fil *File) MkdirTemp(dir, pattern string) (string, error) {
+	prefix, suffix, err := fil.tempPattern("mkdirtemp", pattern)
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		dir = "."
+	}
+	dir = fil.lenientPath(dir)
+	if !fs.ValidPath(dir) {
+		return "", fil.tempErr("mkdirtemp", dir, fs.ErrInvalid)
+	}
+	parent, err := open(fil, dir)
+	if err != nil {
+		return "", fil.tempErr("mkdirtemp", dir, unwrapPathErr(err))
+	}
+	if !parent.IsDir() {
+		return "", fil.tempErr("mkdirtemp", dir, syscall.ENOTDIR)
+	}
+	for range tempTries {
+		name := prefix + fil.tempName() + suffix
+		if _, err = open(parent, name); err == nil {
+			continue
+		}
+		sub, err := NewDirectory(name)
+		if err != nil {
+			return "", err
+		}
+		if err = parent.AddFile(sub); err != nil {
+			return "", err
+		}
+		return path.Join(dir, name), nil
+	}
+	pth := path.Join(dir, prefix+"*"+suffix)
+	return "", fil.tempErr("mkdirtemp", pth, fs.ErrExist)
+}
+
+// tempPattern returns the prefix and the suffix of the pattern for
+// [File.CreateTemp] and [File.MkdirTemp].
+func (fil *File) tempPattern(op, pattern string) (string, string, error) {
+	if !fil.IsDir() {
+		return "", "", fil.tempErr(op, fil.path(), syscall.ENOTDIR)
+	}
+	if strings.Contains(pattern, "/") {
+		return "", "", fil.tempErr(op, pattern, fs.ErrInvalid)
+	}
+	prefix, suffix := pattern, ""
+	if i := strings.LastIndex(pattern, "*"); i >= 0 {
+		prefix, suffix = pattern[:i], pattern[i+1:]
+	}
+	return prefix, suffix, nil
+}
+
+// tempName returns the random part of the names generated by
+// [File.CreateTemp] and [File.MkdirTemp], using the sequence set with
+// [WithTempSeed] on the instance or the closest of its parents.
+func (fil *File) tempName() string {
+	for f := fil; f != nil; f = f.parent {
+		if f.rnd != nil {
+			return strconv.FormatUint(f.rnd.Uint64()>>32, 10)
+		}
+	}
+	return strconv.FormatUint(uint64(rand.Uint32()), 10)
+}
+
+// tempErr returns an error of the [fs.PathError] type for [File.CreateTemp]
+// and [File.MkdirTemp].
+func (fil *File) tempErr(op, name string, err error) error {
+	return fil.hookErr(&fs.PathError{Op: op, Path: name, Err: err})
+}
@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithTempSeed(t *testing.T) {
+	// --- Given ---
+	fil := &File{}
+
+	// --- When ---
+	WithTempSeed(42)(fil)
+
+	// --- Then ---
+	assert.NotNil(t, fil.rnd)
+}
+
+func Test_File_CreateTemp(t *testing.T) {
+	t.Run("pattern with star", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.CreateTemp("sub", "pre-*.txt")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(have.Name(), "sub/pre-"))
+		assert.True(t, strings.HasSuffix(have.Name(), ".txt"))
+		assert.Equal(t, fs.FileMode(0600), have.File().Mode())
+		assert.Same(t, have.File(), must.Value(open(dir, have.Name())))
+		assert.Equal(t, 1, have.File().OpenCount())
+	})
+
+	t.Run("pattern without star", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.CreateTemp("", "pre")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(have.Name(), "pre"))
+		assert.Same(t, have.File(), must.Value(open(dir, have.Name())))
+	})
+
+	t.Run("seeded names are reproducible", func(t *testing.T) {
+		// --- Given ---
+		dir0 := NewRoot(WithTempSeed(42))
+		dir1 := NewRoot(WithTempSeed(42))
+
+		// --- When ---
+		have0 := must.Value(dir0.CreateTemp("", "*"))
+		have1 := must.Value(dir1.CreateTemp("", "*"))
+
+		// --- Then ---
+		assert.Equal(t, have0.Name(), have1.Name())
+		assert.Equal(t, "3683130226", have0.Name())
+	})
+
+	t.Run("seed is inherited", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithTempSeed(42))
+		must.Nil(dir.MkdirAll("sub", 0700))
+		sub := must.Value(open(dir, "sub"))
+
+		// --- When ---
+		have := must.Value(sub.CreateTemp("", "*"))
+
+		// --- Then ---
+		assert.Equal(t, "3683130226", have.Name())
+	})
+
+	t.Run("skips existing names", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithTempSeed(42))
+		first := must.Value(dir.CreateTemp("", "*")).Name()
+		WithTempSeed(42)(dir)
+
+		// --- When ---
+		have, err := dir.CreateTemp("", "*")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.NotEqual(t, first, have.Name())
+		assert.Len(t, 2, dir.entries)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		have, err := MustFile("file").CreateTemp("", "*")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "createtemp", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - pattern with separator", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().CreateTemp("", "sub/*")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "createtemp", e.Op)
+		assert.Equal(t, "sub/*", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - directory not existing", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().CreateTemp("not-existing", "*")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_File_MkdirTemp(t *testing.T) {
+	t.Run("in directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have, err := dir.MkdirTemp("sub", "tmp-*")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(have, "sub/tmp-"))
+		ent := must.Value(open(dir, have))
+		assert.Equal(t, fs.ModeDir|0700, ent.Mode())
+	})
+
+	t.Run("in instance", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithTempSeed(42))
+
+		// --- When ---
+		have, err := dir.MkdirTemp("", "tmp-*")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "tmp-3683130226", have)
+		assert.True(t, must.Value(open(dir, have)).IsDir())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().MkdirTemp("file0", "*")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "mkdirtemp", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+		assert.Empty(t, have)
+	})
+
+	t.Run("error - invalid directory", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().MkdirTemp("../sub", "*")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+		assert.Empty(t, have)
+	})
+
+	t.Run("error - directory not existing", func(t *testing.T) {
+		// --- When ---
+		have, err := tstDirMem().MkdirTemp("not-existing", "*")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "not-existing", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+		assert.Empty(t, have)
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithReadOnly(dir)
+
+		// --- When ---
+		have, err := dir.MkdirTemp("", "*")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Empty(t, have)
+		assert.Len(t, 4, dir.entries)
+	})
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrInterrupted is returned by the bulk copies interrupted with
+// [Throttle.Interrupt].
+var ErrInterrupted = errors.New("copy interrupted")
+
+// Throttle shapes the bulk copies of the directory trees, so the migration
+// logic can be tested at a small scale with a realistic control flow:
+//
+//	th := NewThrottle(1 << 10)
+//	root := NewRoot(WithThrottle(th))
+//	// ...
+//	err := root.WriteToDisk(dir) // Interrupted by another goroutine.
+//	th.Resume()
+//	err = root.WriteToDisk(dir) // Skips the files written already.
+//
+// It limits the copy rate, pauses, resumes and interrupts the copies, and
+// keeps the checkpoint: the paths of the files and symbolic links copied so
+// far. The state is checked before every file and symbolic link is copied,
+// so the file in progress is always copied as a whole. It's safe for
+// concurrent use, so the copy can be controlled from another goroutine.
+type Throttle struct {
+	mu     sync.Mutex
+	cond   *sync.Cond      // Signals the changes of the state.
+	rate   int64           // Bytes per second, zero for no limit.
+	paused bool            // The copies wait for Resume.
+	intr   bool            // The copies fail until Resume.
+	start  time.Time       // Start of the rate window.
+	sent   int64           // Bytes copied in the rate window.
+	done   map[string]bool // The checkpoint.
+}
+
+// NewThrottle returns a new instance of [Throttle] limiting the copies to
+// rate bytes per second. Zero rate means no limit.
+func NewThrottle(rate int64) *Throttle {
+	th := &Throttle{rate: rate, done: make(map[string]bool)}
+	th.cond = sync.NewCond(&th.mu)
+	return th
+}
+
+// WithThrottle is a [File] constructor function option setting the throttle
+// of the bulk copies of the directory and all directories in its tree:
+// [File.WriteToDisk] and, when passed to them, [FromFS] and [CopyFromDisk].
+// [File.WriteToDisk] adds the paths relative to the directory it was called
+// on to the checkpoint and skips the ones already there, so the interrupted
+// write is resumed by calling it again. The skipped files are reported to
+// the function set with [WithProgress] like the written ones. [FromFS] and
+// [CopyFromDisk] return a new tree, so they use the rate limit, the pause and
+// the interrupt, but don't use the checkpoint. The throttle set on the
+// nearest directory applies.
+func WithThrottle(th *Throttle) func(*File) {
+	return func(fil *File) { fil.throt = th }
+}
+
+// Pause makes the copies wait before the next file until [Throttle.Resume]
+// or [Throttle.Interrupt] is called.
+func (th *Throttle) Pause() {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	th.paused = true
+}
+
+// Interrupt makes the copies fail with [ErrInterrupted] before the next file
+// until [Throttle.Resume] is called. The paused copies fail immediately.
+func (th *Throttle) Interrupt() {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	th.intr = true
+	th.cond.Broadcast()
+}
+
+// Resume resumes the paused copies and lets the next copies run after
+// [Throttle.Interrupt]. The rate limit doesn't count the time of the pause.
+func (th *Throttle) Resume() {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	th.paused, th.intr, th.sent = false, false, 0
+	th.cond.Broadcast()
+}
+
+// Checkpoint returns the sorted paths of the files and symbolic links copied
+// so far.
+func (th *Throttle) Checkpoint() []string {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	pths := make([]string, 0, len(th.done))
+	for pth := range th.done {
+		pths = append(pths, pth)
+	}
+	slices.Sort(pths)
+	return pths
+}
+
+// throttle returns the throttle set with [WithThrottle] on the instance or
+// the closest of its parents. Returns nil when none was set.
+func (fil *File) throttle() *Throttle {
+	for f := fil; f != nil; f = f.parent {
+		if f.throt != nil {
+			return f.throt
+		}
+	}
+	return nil
+}
+
+// begin starts a new rate window for a copy. The nil instance does nothing.
+func (th *Throttle) begin() {
+	if th == nil {
+		return
+	}
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	th.sent = 0
+}
+
+// wait waits while the copies are paused and sleeps, without holding the
+// mutex, until copying n more bytes keeps the rate. Returns [ErrInterrupted]
+// when the copies are interrupted. The nil instance returns nil.
+func (th *Throttle) wait(n int64) error {
+	if th == nil {
+		return nil
+	}
+	th.mu.Lock()
+	for th.paused && !th.intr {
+		th.cond.Wait()
+	}
+	if th.intr {
+		th.mu.Unlock()
+		return ErrInterrupted
+	}
+	var d time.Duration
+	if th.rate > 0 {
+		if th.sent == 0 {
+			th.start = time.Now()
+		}
+		th.sent += n
+		due := float64(th.sent) / float64(th.rate) * float64(time.Second)
+		d = time.Until(th.start.Add(time.Duration(due)))
+	}
+	th.mu.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+	return nil
+}
+
+// copied adds the path to the checkpoint. The nil instance does nothing.
+func (th *Throttle) copied(pth string) {
+	if th == nil {
+		return
+	}
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	th.done[pth] = true
+}
+
+// skip reports whether the path is in the checkpoint. The nil instance
+// reports false.
+func (th *Throttle) skip(pth string) bool {
+	if th == nil {
+		return false
+	}
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	return th.done[pth]
+}
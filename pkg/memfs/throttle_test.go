@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_NewThrottle(t *testing.T) {
+	// --- When ---
+	have := NewThrottle(10)
+
+	// --- Then ---
+	assert.Equal(t, int64(10), have.rate)
+	assert.False(t, have.paused)
+	assert.False(t, have.intr)
+	assert.Len(t, 0, have.done)
+	assert.NotNil(t, have.cond)
+}
+
+func Test_WithThrottle(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		// --- Given ---
+		th := NewThrottle(0)
+		fil := &File{}
+
+		// --- When ---
+		WithThrottle(th)(fil)
+
+		// --- Then ---
+		assert.Same(t, th, fil.throt)
+	})
+
+	t.Run("nearest directory applies", func(t *testing.T) {
+		// --- Given ---
+		th0, th1 := NewThrottle(0), NewThrottle(0)
+		dir := tstDirMem()
+		WithThrottle(th0)(dir)
+		WithThrottle(th1)(must.Value(open(dir, "sub")))
+
+		// --- When ---
+		have := must.Value(open(dir, "sub/sub2/file5")).throttle()
+
+		// --- Then ---
+		assert.Same(t, th1, have)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		have := dir.throttle()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("WriteToDisk rate", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithThrottle(NewThrottle(350))(dir)
+		start := time.Now()
+
+		// --- When ---
+		err := dir.WriteToDisk(t.TempDir())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, time.Since(start) >= 100*time.Millisecond)
+	})
+
+	t.Run("WriteToDisk interrupted", func(t *testing.T) {
+		// --- Given ---
+		th := NewThrottle(0)
+		dir := tstDirMem()
+		WithThrottle(th)(dir)
+		WithProgress(func(pth string, _, _ int64) {
+			if pth == "file1" {
+				th.Interrupt()
+			}
+		})(dir)
+		dst := t.TempDir()
+
+		// --- When ---
+		err := dir.WriteToDisk(dst)
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrInterrupted, err)
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "writetodisk", e.Op)
+		assert.Equal(t, "file2", e.Path)
+		assert.Equal(t, []string{"file0", "file1"}, th.Checkpoint())
+		_, err = os.Stat(filepath.Join(dst, "file2"))
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+	})
+
+	t.Run("WriteToDisk resumed", func(t *testing.T) {
+		// --- Given ---
+		th := NewThrottle(0)
+		dir := tstDirMem()
+		must.Nil(dir.Symlink("file0", "link"))
+		WithThrottle(th)(dir)
+		WithProgress(func(pth string, _, _ int64) {
+			if pth == "file1" {
+				th.Interrupt()
+			}
+		})(dir)
+		dst := t.TempDir()
+		_ = dir.WriteToDisk(dst)
+		must.Nil(os.WriteFile(filepath.Join(dst, "file0"), nil, 0600))
+		var have tstProgress
+		WithProgress(have.fn)(dir)
+		th.Resume()
+
+		// --- When ---
+		err := dir.WriteToDisk(dst)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{
+			"file0",
+			"file1",
+			"file2",
+			"link",
+			"sub/file3",
+			"sub/file4",
+			"sub/sub2/file5",
+			"sub/sub2/file6",
+		}
+		assert.Equal(t, want, th.Checkpoint())
+		assert.Len(t, 7, have)
+		assert.Equal(t, "file0:5/35", have[0])
+		assert.Equal(t, "sub/sub2/file6:35/35", have[6])
+		data := must.Value(os.ReadFile(filepath.Join(dst, "file0")))
+		assert.Equal(t, "", string(data))
+		data = must.Value(os.ReadFile(filepath.Join(dst, "sub/sub2/file6")))
+		assert.Equal(t, "file6", string(data))
+		lnk := must.Value(os.Readlink(filepath.Join(dst, "link")))
+		assert.Equal(t, "file0", lnk)
+	})
+
+	t.Run("FromFS rate", func(t *testing.T) {
+		// --- Given ---
+		th := NewThrottle(350)
+		start := time.Now()
+
+		// --- When ---
+		root, err := FromFS(tstDirMem().FS(), WithThrottle(th))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, time.Since(start) >= 100*time.Millisecond)
+		assert.Same(t, th, root.throt)
+		assert.Len(t, 0, th.Checkpoint())
+	})
+
+	t.Run("FromFS interrupted", func(t *testing.T) {
+		// --- Given ---
+		th := NewThrottle(0)
+		th.Interrupt()
+
+		// --- When ---
+		root, err := FromFS(tstDirMem().FS(), WithThrottle(th))
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrInterrupted, err)
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "fromfs", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Nil(t, root)
+	})
+}
+
+func Test_Throttle_Pause(t *testing.T) {
+	t.Run("waits for Resume", func(t *testing.T) {
+		// --- Given ---
+		th := NewThrottle(0)
+		th.Pause()
+		dir := NewRoot(WithThrottle(th))
+		must.Nil(dir.WriteFile("a", []byte("abc"), 0600))
+		done := make(chan error)
+		go func() { done <- dir.WriteToDisk(t.TempDir()) }()
+		time.Sleep(50 * time.Millisecond)
+
+		// --- When ---
+		have := th.Checkpoint()
+		th.Resume()
+
+		// --- Then ---
+		assert.Len(t, 0, have)
+		assert.NoError(t, <-done)
+		assert.Equal(t, []string{"a"}, th.Checkpoint())
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		// --- Given ---
+		th := NewThrottle(0)
+		th.Pause()
+		dir := NewRoot(WithThrottle(th))
+		must.Nil(dir.WriteFile("a", []byte("abc"), 0600))
+		done := make(chan error)
+		go func() { done <- dir.WriteToDisk(t.TempDir()) }()
+
+		// --- When ---
+		th.Interrupt()
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrInterrupted, <-done)
+		assert.Len(t, 0, th.Checkpoint())
+	})
+}
+
+func Test_Throttle_Interrupt(t *testing.T) {
+	// --- Given ---
+	th := NewThrottle(0)
+
+	// --- When ---
+	th.Interrupt()
+
+	// --- Then ---
+	assert.ErrorIs(t, ErrInterrupted, th.wait(1))
+	assert.ErrorIs(t, ErrInterrupted, th.wait(1))
+}
+
+func Test_Throttle_Resume(t *testing.T) {
+	// --- Given ---
+	th := NewThrottle(0)
+	th.Pause()
+	th.Interrupt()
+	th.sent = 10
+
+	// --- When ---
+	th.Resume()
+
+	// --- Then ---
+	assert.False(t, th.paused)
+	assert.False(t, th.intr)
+	assert.Equal(t, int64(0), th.sent)
+	assert.NoError(t, th.wait(1))
+}
+
+func Test_Throttle_Checkpoint(t *testing.T) {
+	// --- Given ---
+	th := NewThrottle(0)
+	th.copied("b")
+	th.copied("a")
+	th.copied("b")
+
+	// --- When ---
+	have := th.Checkpoint()
+
+	// --- Then ---
+	assert.Equal(t, []string{"a", "b"}, have)
+	assert.True(t, th.skip("a"))
+	assert.False(t, th.skip("c"))
+}
+
+func Test_Throttle_nil(t *testing.T) {
+	// --- Given ---
+	var th *Throttle
+
+	// --- When ---
+	th.begin()
+	th.copied("a")
+
+	// --- Then ---
+	assert.NoError(t, th.wait(1))
+	assert.False(t, th.skip("a"))
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// timeSkew is the skew of the modification times set with
+// [WithSetTimeSkew].
+type timeSkew struct {
+	mu     sync.Mutex    // Guards rnd.
+	skew   time.Duration // Constant offset.
+	jitter time.Duration // Maximal random offset.
+	rnd    *rand.PCG     // Source of the random offsets.
+}
+
+// WithSetTimeSkew is a [File] constructor function option shifting the
+// modification times set explicitly with [File.Chtimes], [File.ChtimesAt]
+// and [File.Touch] on the instance and, when used on a directory, on all
+// files in its tree, by the skew and a random offset between -jitter and
+// jitter, different for every set time, like on a network file system with
+// a server clock drifting away from the client clock. The reads and writes
+// don't store the modification times, so they are not affected. The random
+// offsets are generated from the given seed, so they are the same in every
+// run, and the generator is safe for concurrent use. Use it to test the
+// build systems which must tolerate the modification times slightly in the
+// future or out of order across the files. The time skew set on the nearest
+// directory applies. It panics when the jitter is negative.
+func WithSetTimeSkew(skew, jitter time.Duration, seed uint64) func(*File) {
+	if jitter < 0 {
+		panic("memfs.WithSetTimeSkew: negative jitter")
+	}
+	return func(fil *File) {
+		fil.skew = &timeSkew{
+			skew:   skew,
+			jitter: jitter,
+			rnd:    rand.NewPCG(seed, 0),
+		}
+	}
+}
+
+// Chtimes changes the access and modification times of the file, like
+// [os.Chtimes] does. A zero [time.Time] value leaves the corresponding time
+// unchanged. The modification time is returned by [File.ModTime] and the
+// [fs.FileInfo] values describing the file, and the access time by the
+// [SysInfo] returned by [File.Sys]. The times are not updated by reads and
+// writes, so they change only when set explicitly. The modification time is
+// shifted by the time skew set with [WithSetTimeSkew]. In the write-through
+// mode (see [File.MirrorTo]), the times are also changed in the OS file
+// system.
+//
+// Returns an error of the [fs.PathError] type with [syscall.EPERM] when the
+// attribute flags (see [Attr]) are set, with [syscall.EROFS] when the file is
+// read-only (see [WithReadOnly]), and the errors of the [os] package in the
+// write-through mode.
+func (fil *File) Chtimes(atime, mtime time.Time) error {
+	if err := fil.checkReadOnly("chtimes"); err != nil {
+		return err
+	}
+	if fil.attr != 0 {
+		return fil.hookErr(&fs.PathError{
+			Op:   "chtimes",
+			Path: fil.path(),
+			Err:  syscall.EPERM,
+		})
+	}
+	return fil.setTimes(atime, mtime)
+}
+
+// setTimes sets the access and modification times of the file for
+// [File.Chtimes], without checking whether they can be changed.
+func (fil *File) setTimes(atime, mtime time.Time) error {
+	if !atime.IsZero() {
+		fil.atime = atime
+	}
+	if !mtime.IsZero() {
+		mtime = fil.skewed(mtime)
+		fil.info.mtime = mtime
+	}
+	if pth := fil.mirrorPath(); pth != "" {
+		return os.Chtimes(pth, atime, mtime)
+	}
+	return nil
+}
+
+// ChtimesAt changes the access and modification times of the file with the
+// given name in the directory tree, like [os.Chtimes] does, see
+// [File.Chtimes] for details. The symbolic links are followed.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory, with [fs.ErrInvalid] when the name is not
+// valid, and with [fs.ErrNotExist] when the file does not exist, and the
+// errors returned by [File.Chtimes].
+func (fil *File) ChtimesAt(name string, atime, mtime time.Time) error {
+	ent, err := fil.target("chtimes", name)
+	if err != nil {
+		return err
+	}
+	return ent.Chtimes(atime, mtime)
+}
+
+// skewed returns the time shifted by the time skew set with
+// [WithSetTimeSkew] on the instance or the nearest of its parents. Returns
+// the time unchanged when no time skew was set.
+func (fil *File) skewed(t time.Time) time.Time {
+	for f := fil; f != nil; f = f.parent {
+		if ts := f.skew; ts != nil {
+			return t.Add(ts.offset())
+		}
+	}
+	return t
+}
+
+// offset returns the next offset of the time.
+func (ts *timeSkew) offset() time.Duration {
+	if ts.jitter == 0 {
+		return ts.skew
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	n := rand.New(ts.rnd).Int64N(2*int64(ts.jitter) + 1)
+	return ts.skew + time.Duration(n) - ts.jitter
+}
+
+// clone returns a copy of the time skew continuing from the same offset.
+func (ts *timeSkew) clone() *timeSkew {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	rnd := *ts.rnd
+	return &timeSkew{skew: ts.skew, jitter: ts.jitter, rnd: &rnd}
+}
@@ -0,0 +1,318 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_WithSetTimeSkew(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		// --- Given ---
+		fil := &File{}
+
+		// --- When ---
+		WithSetTimeSkew(time.Second, time.Minute, 42)(fil)
+
+		// --- Then ---
+		assert.Equal(t, time.Second, fil.skew.skew)
+		assert.Equal(t, time.Minute, fil.skew.jitter)
+		assert.NotNil(t, fil.skew.rnd)
+	})
+
+	t.Run("skew", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSetTimeSkew(time.Hour, 0, 42)(dir)
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// --- When ---
+		err := dir.ChtimesAt("sub/file3", time.Time{}, mtime)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(open(dir, "sub/file3")).ModTime()
+		assert.Equal(t, mtime.Add(time.Hour), have)
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSetTimeSkew(-time.Minute, time.Second, 42)(dir)
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		names := []string{"file0", "file1", "file2", "sub/file3"}
+
+		// --- When ---
+		var have []time.Time
+		for _, name := range names {
+			must.Nil(dir.ChtimesAt(name, time.Time{}, mtime))
+			have = append(have, must.Value(open(dir, name)).ModTime())
+		}
+
+		// --- Then ---
+		for _, tm := range have {
+			off := tm.Sub(mtime)
+			assert.True(t, off >= -time.Minute-time.Second)
+			assert.True(t, off <= -time.Minute+time.Second)
+		}
+		assert.NotEqual(t, have[0], have[1])
+	})
+
+	t.Run("same seed same times", func(t *testing.T) {
+		// --- Given ---
+		opt := WithSetTimeSkew(0, time.Hour, 42)
+		fil0, fil1 := MustFile("file"), MustFile("file")
+		opt(fil0)
+		opt(fil1)
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// --- When ---
+		must.Nil(fil0.Chtimes(time.Time{}, mtime))
+		must.Nil(fil1.Chtimes(time.Time{}, mtime))
+
+		// --- Then ---
+		assert.Equal(t, fil0.ModTime(), fil1.ModTime())
+	})
+
+	t.Run("nearest directory applies", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSetTimeSkew(time.Hour, 0, 42)(dir)
+		WithSetTimeSkew(time.Second, 0, 42)(must.Value(open(dir, "sub")))
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// --- When ---
+		err := dir.ChtimesAt("sub/sub2/file5", time.Time{}, mtime)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(open(dir, "sub/sub2/file5")).ModTime()
+		assert.Equal(t, mtime.Add(time.Second), have)
+	})
+
+	t.Run("access time not skewed", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		WithSetTimeSkew(time.Hour, 0, 42)(fil)
+		atime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// --- When ---
+		err := fil.Chtimes(atime, time.Time{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, atime, fil.atime)
+		assert.True(t, fil.ModTime().IsZero())
+	})
+
+	t.Run("Touch", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSetTimeSkew(time.Hour, 0, 42)(dir)
+		start := time.Now()
+
+		// --- When ---
+		err := dir.Touch("new")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(open(dir, "new")).ModTime()
+		assert.True(t, have.Sub(start) >= time.Hour)
+	})
+
+	t.Run("writes not skewed", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		WithSetTimeSkew(time.Hour, 0, 42)(fil)
+
+		// --- When ---
+		must.Value(fil.Write([]byte("abc")))
+
+		// --- Then ---
+		assert.True(t, fil.ModTime().IsZero())
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithSetTimeSkew(0, time.Hour, 42)(dir)
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		fils := []*File{
+			must.Value(open(dir, "file0")),
+			must.Value(open(dir, "file1")),
+			must.Value(open(dir, "file2")),
+		}
+		var wg sync.WaitGroup
+
+		// --- When ---
+		for _, fil := range fils {
+			wg.Go(func() {
+				for range 100 {
+					must.Nil(fil.Chtimes(time.Time{}, mtime))
+				}
+			})
+		}
+		wg.Wait()
+
+		// --- Then ---
+		have := fils[0].ModTime()
+		assert.True(t, have.Sub(mtime) <= time.Hour)
+	})
+
+	t.Run("panic - negative jitter", func(t *testing.T) {
+		// --- Given ---
+		fn := func() { WithSetTimeSkew(0, -1, 42) }
+
+		// --- When ---
+		msg := assert.PanicMsg(t, fn)
+
+		// --- Then ---
+		assert.Equal(t, "memfs.WithSetTimeSkew: negative jitter", *msg)
+	})
+}
+
+func Test_File_Chtimes(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		atime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// --- When ---
+		err := fil.Chtimes(atime, mtime)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, mtime, fil.ModTime())
+		fi := must.Value(fil.Stat())
+		assert.Equal(t, mtime, fi.ModTime())
+		assert.Equal(t, atime, fi.Sys().(*SysInfo).Atime)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// --- When ---
+		err := dir.Chtimes(time.Time{}, mtime)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fi := must.Value(fs.Stat(dir.DirFS(), "."))
+		assert.Equal(t, mtime, fi.ModTime())
+	})
+
+	t.Run("zero times are not changed", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		atime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		must.Nil(fil.Chtimes(atime, mtime))
+
+		// --- When ---
+		err := fil.Chtimes(time.Time{}, time.Time{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, mtime, fil.ModTime())
+		assert.Equal(t, atime, fil.Sys().(*SysInfo).Atime)
+	})
+
+	t.Run("not changed by writes", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file")
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		must.Nil(fil.Chtimes(time.Time{}, mtime))
+
+		// --- When ---
+		must.Value(fil.Write([]byte("abc")))
+
+		// --- Then ---
+		assert.Equal(t, mtime, fil.ModTime())
+	})
+
+	t.Run("write-through mode", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		dst := t.TempDir()
+		must.Nil(dir.MirrorTo(dst))
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// --- When ---
+		err := must.Value(open(dir, "sub/file3")).Chtimes(mtime, mtime)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fi := must.Value(os.Stat(filepath.Join(dst, "sub/file3")))
+		assert.True(t, mtime.Equal(fi.ModTime()))
+	})
+
+	t.Run("error - attributes", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithFileAttr(AttrImmutable))
+
+		// --- When ---
+		err := fil.Chtimes(time.Now(), time.Now())
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "chtimes", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.EPERM, e.Err)
+		assert.True(t, fil.ModTime().IsZero())
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		fil := MustFile("file", WithReadOnly)
+
+		// --- When ---
+		err := fil.Chtimes(time.Now(), time.Now())
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.True(t, fil.ModTime().IsZero())
+	})
+}
+
+func Test_File_ChtimesAt(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// --- When ---
+		err := dir.ChtimesAt("sub/file3", time.Time{}, mtime)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fi := must.Value(fs.Stat(dir.DirFS(), "sub/file3"))
+		assert.Equal(t, mtime, fi.ModTime())
+		ets := must.Value(fs.ReadDir(dir.DirFS(), "sub"))
+		assert.Equal(t, mtime, must.Value(ets[0].Info()).ModTime())
+	})
+
+	t.Run("error - not existing", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().ChtimesAt("not-existing", time.Now(), time.Now())
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "chtimes", e.Op)
+		assert.Equal(t, "not-existing", e.Path)
+		assert.Equal(t, fs.ErrNotExist, e.Err)
+	})
+}
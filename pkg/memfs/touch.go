@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import "time"
+
+// Touch creates an empty file with the given name in the directory tree,
+// like touch(1) does, along with any missing intermediate directories. The
+// new file gets the default permission bits (0600). When the file or a
+// directory with the name already exists, its content is left untouched.
+// In both cases, the access and modification times of the file are set to
+// the current time (see [File.Chtimes]).
+//
+// Returns the errors returned by [File.WriteFile] when the file is created,
+// and by [File.Chtimes] when it exists.
+func (fil *File) Touch(name string) error {
+	now := time.Now()
+	if fil.IsDir() {
+		if ent, err := open(fil, name); err == nil {
+			return ent.Chtimes(now, now)
+		}
+	}
+	if err := fil.WriteFile(name, nil, 0600); err != nil {
+		return err
+	}
+	ent, err := open(fil, fil.lenientPath(name))
+	if err != nil {
+		return err
+	}
+	return ent.setTimes(now, now)
+}
+
+// EnsureDir creates the directory with the given name in the directory tree,
+// like "mkdir -p" does, along with any missing parents. The new directories
+// get the default permission bits (0700). It does nothing when the directory
+// already exists.
+//
+// Returns the errors returned by [File.MkdirAll].
+func (fil *File) EnsureDir(name string) error {
+	return fil.MkdirAll(name, 0700)
+}
@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Touch(t *testing.T) {
+	t.Run("creates empty file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.Touch("sub/a/new")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := must.Value(open(dir, "sub/a/new"))
+		assert.False(t, fil.IsDir())
+		assert.Equal(t, int64(0), fil.Size())
+		assert.Equal(t, fs.FileMode(0600), fil.Mode())
+	})
+
+	t.Run("new file times", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		before := time.Now()
+
+		// --- When ---
+		err := dir.Touch("new")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := must.Value(open(dir, "new"))
+		assert.False(t, fil.ModTime().Before(before))
+		assert.Equal(t, fil.ModTime(), fil.atime)
+	})
+
+	t.Run("existing file content is untouched", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "sub/file3"))
+
+		// --- When ---
+		err := dir.Touch("sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, fil, must.Value(open(dir, "sub/file3")))
+		assert.Equal(t, []byte("file3"), fil.buf)
+	})
+
+	t.Run("existing file times", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "sub/file3"))
+		old := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		must.Nil(fil.Chtimes(old, old))
+		before := time.Now()
+
+		// --- When ---
+		err := dir.Touch("sub/file3")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.False(t, fil.ModTime().Before(before))
+		assert.False(t, fil.atime.Before(before))
+	})
+
+	t.Run("existing directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		before := time.Now()
+
+		// --- When ---
+		err := dir.Touch("sub/sub2")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		sub := must.Value(open(dir, "sub/sub2"))
+		assert.True(t, sub.IsDir())
+		assert.False(t, sub.ModTime().Before(before))
+	})
+
+	t.Run("new file in write-once tree", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithWriteOnce)
+
+		// --- When ---
+		err := dir.Touch("new")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.False(t, must.Value(open(dir, "new")).ModTime().IsZero())
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").Touch("new")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().Touch("../new")
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+	})
+
+	t.Run("error - read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithReadOnly)
+
+		// --- When ---
+		err := dir.Touch("new")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+	})
+
+	t.Run("error - existing file read-only", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		WithReadOnly(dir)
+
+		// --- When ---
+		err := dir.Touch("file0")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "chtimes", e.Op)
+		assert.Equal(t, "file0", e.Path)
+		assert.Equal(t, syscall.EROFS, e.Err)
+	})
+}
+
+func Test_File_EnsureDir(t *testing.T) {
+	t.Run("creates directories", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.EnsureDir("sub/a/b")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have := must.Value(open(dir, "sub/a/b"))
+		assert.Equal(t, fs.ModeDir|0700, have.Mode())
+	})
+
+	t.Run("existing directory", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.EnsureDir("sub/sub2")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 2, must.Value(open(dir, "sub/sub2")).entries)
+	})
+
+	t.Run("error - file exists", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().EnsureDir("file0")
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EEXIST, err)
+	})
+}
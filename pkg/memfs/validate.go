@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// Violation rules reported by [Validate].
+const (
+	// RuleRequired is reported for the required paths which don't exist.
+	RuleRequired = "required"
+
+	// RuleAllowed is reported for the paths not matching any of the allowed
+	// patterns.
+	RuleAllowed = "allowed"
+
+	// RuleSize is reported for the files larger than allowed.
+	RuleSize = "size"
+
+	// RuleMode is reported for the files and directories with the permission
+	// bits not meeting the constraints.
+	RuleMode = "mode"
+)
+
+// Spec is a declarative specification of a directory tree layout checked by
+// [Validate]. The patterns have the [File.GlobEx] syntax and are matched
+// against the slash-separated paths relative to the root of the file system.
+type Spec struct {
+	// Paths which must exist in the tree.
+	Required []string
+
+	// Patterns all paths in the tree must match. When empty, all paths are
+	// allowed. The parent directories of the allowed paths are allowed too.
+	Allowed []string
+
+	// Constraints for the paths matching their patterns.
+	Rules []SpecRule
+}
+
+// SpecRule is a constraint for the files and directories matching the
+// pattern, see [Spec].
+type SpecRule struct {
+	// Pattern in the [File.GlobEx] syntax.
+	Pattern string
+
+	// Maximum size of the matching regular files in bytes. When zero, the
+	// size is not limited.
+	MaxSize int64
+
+	// Permission bits the matching files must have set.
+	Perm fs.FileMode
+
+	// Permission bits the matching files must not have set, for example,
+	// 0002 for the world-writable files.
+	DenyPerm fs.FileMode
+}
+
+// Violation describes a path not meeting the [Spec].
+type Violation struct {
+	Path string // Slash-separated path relative to the root.
+	Rule string // One of the Rule constants, like [RuleRequired].
+	Msg  string // Human-readable description.
+}
+
+// String implements [fmt.Stringer] interface.
+func (v Violation) String() string {
+	return v.Path + ": " + v.Rule + ": " + v.Msg
+}
+
+// Validate checks the tree in the file system against the specification
+// and returns the violations, so the layout and packaging conventions can
+// be enforced by tests. The violations of the required paths are returned
+// first, in the specification order, followed by the others in the path
+// order. Returns nil when the tree meets the specification.
+//
+// Returns [path.ErrBadPattern] when a pattern is malformed and the errors
+// returned by the file system.
+func Validate(fsys fs.FS, spec Spec) ([]Violation, error) {
+	allowed, err := compileGlobs(spec.Allowed...)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([][]string, 0, len(spec.Rules))
+	for _, rule := range spec.Rules {
+		pts, err := compileGlobs(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, pts)
+	}
+
+	var vs []Violation
+	for _, pth := range spec.Required {
+		_, err := fs.Stat(fsys, pth)
+		if errors.Is(err, fs.ErrNotExist) {
+			vs = append(vs, Violation{
+				Path: pth,
+				Rule: RuleRequired,
+				Msg:  "does not exist",
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	walk := func(pth string, d fs.DirEntry, err error) error {
+		if err != nil || pth == "." {
+			return err
+		}
+		if len(allowed) > 0 && !matchAny(allowed, pth, d.IsDir()) {
+			vs = append(vs, Violation{
+				Path: pth,
+				Rule: RuleAllowed,
+				Msg:  "does not match any allowed pattern",
+			})
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		for i, rule := range spec.Rules {
+			if !matchAny(rules[i], pth, false) {
+				continue
+			}
+			vs = append(vs, checkRule(pth, info, rule)...)
+		}
+		return nil
+	}
+	if err = fs.WalkDir(fsys, ".", walk); err != nil {
+		return nil, err
+	}
+	return vs, nil
+}
+
+// checkRule returns the violations of the rule by the file.
+func checkRule(pth string, info fs.FileInfo, rule SpecRule) []Violation {
+	var vs []Violation
+	size := info.Size()
+	if rule.MaxSize > 0 && info.Mode().IsRegular() && size > rule.MaxSize {
+		vs = append(vs, Violation{
+			Path: pth,
+			Rule: RuleSize,
+			Msg:  fmt.Sprintf("size %d exceeds %d bytes", size, rule.MaxSize),
+		})
+	}
+	perm := info.Mode().Perm()
+	if want := rule.Perm.Perm(); perm&want != want {
+		vs = append(vs, Violation{
+			Path: pth,
+			Rule: RuleMode,
+			Msg:  fmt.Sprintf("mode %04o misses bits %04o", perm, want&^perm),
+		})
+	}
+	if deny := perm & rule.DenyPerm.Perm(); deny != 0 {
+		vs = append(vs, Violation{
+			Path: pth,
+			Rule: RuleMode,
+			Msg:  fmt.Sprintf("mode %04o has denied bits %04o", perm, deny),
+		})
+	}
+	return vs
+}
+
+// compileGlobs returns the patterns in the [File.GlobEx] syntax with the
+// brace alternatives expanded. Returns [path.ErrBadPattern] when any of the
+// patterns is malformed.
+func compileGlobs(patterns ...string) ([]string, error) {
+	var pts []string
+	for _, pattern := range patterns {
+		exp, err := expandBraces(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, pt := range exp {
+			if err = validGlob(pt); err != nil {
+				return nil, err
+			}
+		}
+		pts = append(pts, exp...)
+	}
+	return pts, nil
+}
+
+// matchAny reports whether the path matches any of the compiled patterns.
+// When dir is true, the path also matches when it's a parent directory of a
+// path matching a pattern.
+func matchAny(pts []string, pth string, dir bool) bool {
+	for _, pt := range pts {
+		if ok, _ := matchGlob(pt, pth); ok {
+			return true
+		}
+		if !dir {
+			continue
+		}
+		els := strings.Split(pt, "/")
+		for i := 1; i < len(els); i++ {
+			if ok, _ := matchGlob(strings.Join(els[:i], "/"), pth); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		// --- Given ---
+		spec := Spec{
+			Required: []string{"file0", "sub/sub2"},
+			Allowed:  []string{"file*", "sub/**"},
+			Rules: []SpecRule{
+				{Pattern: "**", MaxSize: 5, DenyPerm: 0077},
+				{Pattern: "sub/**", Perm: 0600},
+			},
+		}
+
+		// --- When ---
+		have, err := Validate(tstDirMem().DirFS(), spec)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("empty spec", func(t *testing.T) {
+		// --- When ---
+		have, err := Validate(tstDirMem().DirFS(), Spec{})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("required", func(t *testing.T) {
+		// --- Given ---
+		spec := Spec{Required: []string{"sub/missing", "file0", "LICENSE"}}
+
+		// --- When ---
+		have, err := Validate(tstDirMem().DirFS(), spec)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []Violation{
+			{Path: "sub/missing", Rule: RuleRequired, Msg: "does not exist"},
+			{Path: "LICENSE", Rule: RuleRequired, Msg: "does not exist"},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		// --- Given ---
+		spec := Spec{Allowed: []string{"file{0,1}", "sub/sub2/*"}}
+
+		// --- When ---
+		have, err := Validate(tstDirMem().DirFS(), spec)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		msg := "does not match any allowed pattern"
+		want := []Violation{
+			{Path: "file2", Rule: RuleAllowed, Msg: msg},
+			{Path: "sub/file3", Rule: RuleAllowed, Msg: msg},
+			{Path: "sub/file4", Rule: RuleAllowed, Msg: msg},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		// --- Given ---
+		fsys := fstest.MapFS{
+			"big":      {Data: []byte("0123456789")},
+			"dir/big":  {Data: []byte("0123456789")},
+			"dir/tiny": {Data: []byte("0")},
+		}
+		spec := Spec{Rules: []SpecRule{{Pattern: "dir/*", MaxSize: 4}}}
+
+		// --- When ---
+		have, err := Validate(fsys, spec)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []Violation{
+			{Path: "dir/big", Rule: RuleSize, Msg: "size 10 exceeds 4 bytes"},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("mode", func(t *testing.T) {
+		// --- Given ---
+		fsys := fstest.MapFS{
+			"bin/ok":   {Mode: 0755},
+			"bin/nox":  {Mode: 0644},
+			"bin/open": {Mode: 0777},
+		}
+		spec := Spec{
+			Rules: []SpecRule{
+				{Pattern: "bin/*", Perm: 0111, DenyPerm: 0002},
+			},
+		}
+
+		// --- When ---
+		have, err := Validate(fsys, spec)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []Violation{
+			{
+				Path: "bin/nox",
+				Rule: RuleMode,
+				Msg:  "mode 0644 misses bits 0111",
+			},
+			{
+				Path: "bin/open",
+				Rule: RuleMode,
+				Msg:  "mode 0777 has denied bits 0002",
+			},
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("error - bad pattern", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			spec Spec
+		}{
+			{"allowed", Spec{Allowed: []string{"a{b"}}},
+			{"rule", Spec{Rules: []SpecRule{{Pattern: "[a"}}}},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- When ---
+				have, err := Validate(tstDirMem().DirFS(), tc.spec)
+
+				// --- Then ---
+				assert.ErrorIs(t, path.ErrBadPattern, err)
+				assert.Nil(t, have)
+			})
+		}
+	})
+
+	t.Run("error - file system", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("test error")
+		fsys := Chain(tstDirMem().DirFS(), Faults(func(op, _ string) error {
+			if op == "readdir" {
+				return e
+			}
+			return nil
+		}))
+
+		// --- When ---
+		have, err := Validate(fsys, Spec{})
+
+		// --- Then ---
+		assert.ErrorIs(t, e, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_Violation_String(t *testing.T) {
+	// --- Given ---
+	v := Violation{Path: "a/b", Rule: RuleRequired, Msg: "does not exist"}
+
+	// --- When ---
+	have := v.String()
+
+	// --- Then ---
+	assert.Equal(t, "a/b: required: does not exist", have)
+}
+
+func Test_matchAny(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pts  []string
+		pth  string
+		dir  bool
+		want bool
+	}{
+		{"match", []string{"a/*.go"}, "a/b.go", false, true},
+		{"no match", []string{"a/*.go"}, "a/b.txt", false, false},
+		{"parent", []string{"a/b/*.go"}, "a/b", true, true},
+		{"grandparent", []string{"a/b/*.go"}, "a", true, true},
+		{"parent not a dir", []string{"a/b/*.go"}, "a", false, false},
+		{"other dir", []string{"a/b/*.go"}, "c", true, false},
+		{"double star", []string{"**/x"}, "a/b", true, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have := matchAny(tc.pts, tc.pth, tc.dir)
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func Test_compileGlobs(t *testing.T) {
+	t.Run("expands braces", func(t *testing.T) {
+		// --- When ---
+		have, err := compileGlobs("a/{b,c}", "d")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a/b", "a/c", "d"}, have)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		// --- When ---
+		have, err := compileGlobs("a/[")
+
+		// --- Then ---
+		assert.ErrorIs(t, path.ErrBadPattern, err)
+		assert.Nil(t, have)
+	})
+}
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"syscall"
+)
+
+// WalkOrder is the order [File.Walk] visits the directory tree in.
+type WalkOrder int
+
+const (
+	// WalkPreOrder visits every directory before its entries, depth-first,
+	// the same as [fs.WalkDir].
+	WalkPreOrder WalkOrder = iota
+
+	// WalkPostOrder visits every directory after its entries, depth-first,
+	// so the tree can be removed bottom-up.
+	WalkPostOrder
+
+	// WalkBreadthFirst visits all entries at the given depth before the
+	// entries deeper in the tree.
+	WalkBreadthFirst
+)
+
+// Walk calls fn for every file and directory in the directory tree, not
+// including the directory itself, in the given order. The pth is the path of
+// the entry relative to the directory. The entries of every directory are
+// visited in the name order, so the order doesn't depend on the order they
+// were added. The symbolic links are not followed.
+//
+// The entries of a directory are listed before they are visited, so fn may
+// remove the entries it is called with, for example, in the
+// [WalkPostOrder] order. When fn returns [fs.SkipDir] for a directory in the
+// [WalkPreOrder] or [WalkBreadthFirst] order, its entries are not visited; in
+// other cases it's ignored. When fn returns [fs.SkipAll], the walk stops and
+// Walk returns nil. Other errors stop the walk and are returned.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory and with [fs.ErrInvalid] when the order is not
+// known.
+func (fil *File) Walk(
+	order WalkOrder,
+	fn func(pth string, ent *File) error,
+) error {
+	var err error
+	switch {
+	case !fil.IsDir():
+		err = syscall.ENOTDIR
+	case order < WalkPreOrder || order > WalkBreadthFirst:
+		err = fs.ErrInvalid
+	}
+	if err != nil {
+		return fil.hookErr(&fs.PathError{
+			Op:   "walk",
+			Path: fil.path(),
+			Err:  err,
+		})
+	}
+
+	if order == WalkBreadthFirst {
+		err = fil.walkBreadth(fn)
+	} else {
+		err = fil.walkDepth("", order == WalkPostOrder, fn)
+	}
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkDepth walks the directory tree depth-first calling fn for every entry
+// before its entries or, when post is true, after them. The tree is walked
+// with a stack of the directories being visited instead of recursion, so the
+// very deep trees don't grow the goroutine stack.
+func (fil *File) walkDepth(
+	pth string,
+	post bool,
+	fn func(pth string, ent *File) error,
+) error {
+	type frame struct {
+		pth  string  // Path of the directory.
+		dir  *File   // The directory.
+		ents []*File // Entries of the directory not visited yet.
+	}
+	stack := []*frame{{pth: pth, dir: fil, ents: fil.listEntries()}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if len(top.ents) == 0 {
+			stack = stack[:len(stack)-1]
+			if post && len(stack) > 0 {
+				err := fn(top.pth, top.dir)
+				if err != nil && !errors.Is(err, fs.SkipDir) {
+					return err
+				}
+			}
+			continue
+		}
+
+		ent := top.ents[0]
+		top.ents = top.ents[1:]
+		entPth := path.Join(top.pth, ent.Name())
+		if !ent.IsDir() || !post {
+			err := fn(entPth, ent)
+			if errors.Is(err, fs.SkipDir) && ent.IsDir() {
+				continue
+			}
+			if err != nil && !errors.Is(err, fs.SkipDir) {
+				return err
+			}
+		}
+		if ent.IsDir() {
+			sub := &frame{pth: entPth, dir: ent, ents: ent.listEntries()}
+			stack = append(stack, sub)
+		}
+	}
+	return nil
+}
+
+// walkBreadth walks the directory tree breadth-first calling fn for every
+// entry.
+func (fil *File) walkBreadth(fn func(pth string, ent *File) error) error {
+	type item struct {
+		pth string
+		dir *File
+	}
+	queue := []item{{dir: fil}}
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		for _, ent := range it.dir.listEntries() {
+			entPth := path.Join(it.pth, ent.Name())
+			err := fn(entPth, ent)
+			if errors.Is(err, fs.SkipDir) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if ent.IsDir() {
+				queue = append(queue, item{pth: entPth, dir: ent})
+			}
+		}
+	}
+	return nil
+}
+
+// listEntries returns the copy of the directory entries in the name order.
+func (fil *File) listEntries() []*File {
+	fil.sortEntries()
+	return slices.Clone(fil.entries)
+}
@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_Walk(t *testing.T) {
+	t.Run("orders", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			order WalkOrder
+			want  []string
+		}{
+			{
+				"pre-order",
+				WalkPreOrder,
+				[]string{
+					"file0", "file1", "file2", "sub", "sub/file3",
+					"sub/file4", "sub/sub2", "sub/sub2/file5",
+					"sub/sub2/file6",
+				},
+			},
+			{
+				"post-order",
+				WalkPostOrder,
+				[]string{
+					"file0", "file1", "file2", "sub/file3", "sub/file4",
+					"sub/sub2/file5", "sub/sub2/file6", "sub/sub2", "sub",
+				},
+			},
+			{
+				"breadth-first",
+				WalkBreadthFirst,
+				[]string{
+					"file0", "file1", "file2", "sub", "sub/file3",
+					"sub/file4", "sub/sub2", "sub/sub2/file5",
+					"sub/sub2/file6",
+				},
+			},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				var have []string
+				fn := func(pth string, _ *File) error {
+					have = append(have, pth)
+					return nil
+				}
+
+				// --- When ---
+				err := tstDirMem().Walk(tc.order, fn)
+
+				// --- Then ---
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, have)
+			})
+		}
+	})
+
+	t.Run("breadth-first visits shallow entries first", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(dir.WriteFile("a/b/c", nil, 0600))
+		must.Nil(dir.WriteFile("z", nil, 0600))
+		var have []string
+		fn := func(pth string, _ *File) error {
+			have = append(have, pth)
+			return nil
+		}
+
+		// --- When ---
+		err := dir.Walk(WalkBreadthFirst, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{
+			"a", "file0", "file1", "file2", "sub", "z",
+			"a/b", "sub/file3", "sub/file4", "sub/sub2",
+			"a/b/c", "sub/sub2/file5", "sub/sub2/file6",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("post-order removes bottom-up", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		sub := must.Value(open(dir, "sub"))
+		fn := func(pth string, _ *File) error {
+			return sub.Remove(pth)
+		}
+
+		// --- When ---
+		err := sub.Walk(WalkPostOrder, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, sub.entries)
+		assert.Len(t, 4, dir.entries)
+	})
+
+	t.Run("SkipDir", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			order WalkOrder
+			want  []string
+		}{
+			{
+				"pre-order",
+				WalkPreOrder,
+				[]string{
+					"file0", "file1", "file2", "sub", "sub/file3",
+					"sub/file4", "sub/sub2",
+				},
+			},
+			{
+				"post-order",
+				WalkPostOrder,
+				[]string{
+					"file0", "file1", "file2", "sub/file3", "sub/file4",
+					"sub/sub2/file5", "sub/sub2/file6", "sub/sub2", "sub",
+				},
+			},
+			{
+				"breadth-first",
+				WalkBreadthFirst,
+				[]string{
+					"file0", "file1", "file2", "sub", "sub/file3",
+					"sub/file4", "sub/sub2",
+				},
+			},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				var have []string
+				fn := func(pth string, ent *File) error {
+					have = append(have, pth)
+					if ent.IsDir() && path.Base(pth) == "sub2" {
+						return fs.SkipDir
+					}
+					return nil
+				}
+
+				// --- When ---
+				err := tstDirMem().Walk(tc.order, fn)
+
+				// --- Then ---
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, have)
+			})
+		}
+	})
+
+	t.Run("SkipDir for file is ignored", func(t *testing.T) {
+		// --- Given ---
+		var have []string
+		fn := func(pth string, _ *File) error {
+			have = append(have, pth)
+			if pth == "sub/file3" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		sub := must.Value(open(tstDirMem(), "sub"))
+
+		// --- When ---
+		err := sub.Walk(WalkPreOrder, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{"file3", "file4", "sub2", "sub2/file5", "sub2/file6"}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("SkipAll", func(t *testing.T) {
+		for _, order := range []WalkOrder{
+			WalkPreOrder, WalkPostOrder, WalkBreadthFirst,
+		} {
+			// --- Given ---
+			var have []string
+			fn := func(pth string, _ *File) error {
+				have = append(have, pth)
+				if pth == "file1" {
+					return fs.SkipAll
+				}
+				return nil
+			}
+
+			// --- When ---
+			err := tstDirMem().Walk(order, fn)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"file0", "file1"}, have)
+		}
+	})
+
+	t.Run("error - returned by fn", func(t *testing.T) {
+		for _, order := range []WalkOrder{
+			WalkPreOrder, WalkPostOrder, WalkBreadthFirst,
+		} {
+			// --- Given ---
+			e := errors.New("test error")
+			var have []string
+			fn := func(pth string, _ *File) error {
+				have = append(have, pth)
+				if pth == "sub/file4" {
+					return e
+				}
+				return nil
+			}
+
+			// --- When ---
+			err := tstDirMem().Walk(order, fn)
+
+			// --- Then ---
+			assert.Same(t, e, err)
+			assert.Equal(t, "sub/file4", have[len(have)-1])
+		}
+	})
+
+	t.Run("very deep tree", func(t *testing.T) {
+		// --- Given ---
+		const depth = 5000
+		root := NewRoot()
+		cur := root
+		for range depth {
+			sub := MustDirectory("d")
+			must.Nil(cur.AddFile(sub))
+			cur = sub
+		}
+		must.Nil(cur.AddFile(MustFile("file")))
+		deepest := strings.Repeat("d/", depth) + "file"
+
+		for _, order := range []WalkOrder{WalkPreOrder, WalkPostOrder} {
+			var have []string
+			fn := func(pth string, _ *File) error {
+				have = append(have, pth)
+				return nil
+			}
+
+			// --- When ---
+			err := root.Walk(order, fn)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Len(t, depth+1, have)
+			if order == WalkPreOrder {
+				assert.Equal(t, "d", have[0])
+				assert.Equal(t, deepest, have[depth])
+			} else {
+				assert.Equal(t, deepest, have[0])
+				assert.Equal(t, "d", have[depth])
+			}
+		}
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").Walk(WalkPreOrder, nil)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "walk", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - unknown order", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().Walk(WalkOrder(42), nil)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+	})
+}
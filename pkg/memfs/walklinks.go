@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"syscall"
+)
+
+// WalkLinks are the flags changing how [File.WalkSymlinks] treats the
+// symbolic links.
+type WalkLinks int
+
+const (
+	// FollowLinks makes [File.WalkSymlinks] follow the symbolic links, so
+	// the function is called with the files they point to, and the entries
+	// of the directories they point to are visited under the link paths.
+	FollowLinks WalkLinks = 1 << iota
+
+	// ReportCycles makes [File.WalkSymlinks] call the function with an error
+	// with [syscall.ELOOP] for the symbolic links pointing to a directory
+	// containing them, instead of visiting them without their entries.
+	ReportCycles
+)
+
+// WalkSymlinks calls fn for every file and directory in the directory tree,
+// not including the directory itself, depth-first, visiting every directory
+// before its entries in the name order, like [File.Walk] does in the
+// [WalkPreOrder] order. It's meant for validating the tools which must
+// detect the symbolic link cycles, like backup software, against
+// adversarial layouts.
+//
+// Without [FollowLinks], the symbolic links are visited like other files and
+// fn is called with the links themselves. With [FollowLinks], fn is called
+// with the files the links point to, and the directories they point to are
+// walked, unless one of them contains the link, which would make the walk
+// never end. Such cycles are visited without their entries or, with
+// [ReportCycles], fn is called with an error of the [fs.PathError] type with
+// [syscall.ELOOP]. The links which can't be followed, like the dangling
+// ones, are always reported to fn with the error returned when opening them,
+// and with the link itself as the ent.
+//
+// When fn returns [fs.SkipDir] for a directory, its entries are not visited.
+// When fn returns [fs.SkipAll], the walk stops and WalkSymlinks returns nil.
+// Other errors stop the walk and are returned, so fn decides whether the
+// errors it's called with stop the walk.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory.
+func (fil *File) WalkSymlinks(
+	flags WalkLinks,
+	fn func(pth string, ent *File, err error) error,
+) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "walk",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	err := fil.walkLinks(flags, fn)
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkLinks implements [File.WalkSymlinks] with a stack of the directories
+// being visited instead of recursion. The directories on the stack are the
+// ones containing the visited entry, which is how the cycles are detected.
+func (fil *File) walkLinks(
+	flags WalkLinks,
+	fn func(pth string, ent *File, err error) error,
+) error {
+	type frame struct {
+		pth  string  // Path of the directory.
+		dir  *File   // The directory.
+		ents []*File // Entries of the directory not visited yet.
+	}
+	stack := []*frame{{dir: fil, ents: fil.listEntries()}}
+	active := map[*File]int{fil: 1}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if len(top.ents) == 0 {
+			stack = stack[:len(stack)-1]
+			active[top.dir]--
+			continue
+		}
+
+		ent := top.ents[0]
+		top.ents = top.ents[1:]
+		entPth := path.Join(top.pth, ent.Name())
+
+		var err error
+		var cycle bool
+		if ent.isSymlink() && flags&FollowLinks != 0 {
+			var dst *File
+			if dst, err = lookup(top.dir, ent.Name(), true); err != nil {
+				err = &fs.PathError{
+					Op:   "walk",
+					Path: entPth,
+					Err:  unwrapPathErr(err),
+				}
+			} else {
+				ent = dst
+				cycle = dst.IsDir() && active[dst] > 0
+			}
+			if cycle && flags&ReportCycles != 0 {
+				err = &fs.PathError{
+					Op:   "walk",
+					Path: entPth,
+					Err:  syscall.ELOOP,
+				}
+			}
+		}
+
+		ferr := fn(entPth, ent, err)
+		if errors.Is(ferr, fs.SkipDir) {
+			continue
+		}
+		if ferr != nil {
+			return ferr
+		}
+		if err == nil && !cycle && ent.IsDir() {
+			sub := &frame{pth: entPth, dir: ent, ents: ent.listEntries()}
+			stack = append(stack, sub)
+			active[ent]++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// tstLinkTree returns a directory tree with symbolic links:
+//
+//	dir/a
+//	dir/up -> ..
+//	file
+//	ln -> dir
+func tstLinkTree() *File {
+	root := NewRoot()
+	must.Nil(root.MkdirAll("dir", 0700))
+	must.Nil(root.WriteFile("dir/a", []byte("a"), 0600))
+	must.Nil(root.WriteFile("file", []byte("file"), 0600))
+	must.Nil(root.Symlink("..", "dir/up"))
+	must.Nil(root.Symlink("dir", "ln"))
+	return root
+}
+
+func Test_File_WalkSymlinks(t *testing.T) {
+	t.Run("links not followed", func(t *testing.T) {
+		// --- Given ---
+		var have []string
+		fn := func(pth string, ent *File, err error) error {
+			must.Nil(err)
+			have = append(have, pth+":"+ent.Type().String())
+			return nil
+		}
+
+		// --- When ---
+		err := tstLinkTree().WalkSymlinks(0, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{
+			"dir:d---------", "dir/a:----------", "dir/up:L---------",
+			"file:----------", "ln:L---------",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("links followed", func(t *testing.T) {
+		// --- Given ---
+		var have []string
+		fn := func(pth string, ent *File, err error) error {
+			must.Nil(err)
+			have = append(have, pth)
+			return nil
+		}
+
+		// --- When ---
+		err := tstLinkTree().WalkSymlinks(FollowLinks, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{
+			"dir", "dir/a", "dir/up", "file", "ln", "ln/a", "ln/up",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("followed link passes the destination", func(t *testing.T) {
+		// --- Given ---
+		root := tstLinkTree()
+		var have *File
+		fn := func(pth string, ent *File, _ error) error {
+			if pth == "ln" {
+				have = ent
+			}
+			return nil
+		}
+
+		// --- When ---
+		err := root.WalkSymlinks(FollowLinks, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Same(t, must.Value(open(root, "dir")), have)
+	})
+
+	t.Run("report cycles", func(t *testing.T) {
+		// --- Given ---
+		var have []string
+		fn := func(pth string, _ *File, err error) error {
+			if err != nil {
+				have = append(have, err.Error())
+			}
+			return nil
+		}
+
+		// --- When ---
+		err := tstLinkTree().WalkSymlinks(FollowLinks|ReportCycles, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := []string{
+			"walk dir/up: too many levels of symbolic links",
+			"walk ln/up: too many levels of symbolic links",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("dangling link", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot()
+		must.Nil(root.Symlink("missing", "link"))
+		var have error
+		var haveEnt *File
+		fn := func(_ string, ent *File, err error) error {
+			have, haveEnt = err, ent
+			return nil
+		}
+
+		// --- When ---
+		err := root.WalkSymlinks(FollowLinks, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, have)
+		assert.Equal(t, "walk", e.Op)
+		assert.Equal(t, "link", e.Path)
+		assert.ErrorIs(t, fs.ErrNotExist, e.Err)
+		assert.True(t, haveEnt.isSymlink())
+	})
+
+	t.Run("link loop", func(t *testing.T) {
+		// --- Given ---
+		root := NewRoot()
+		must.Nil(root.Symlink("b", "a"))
+		must.Nil(root.Symlink("a", "b"))
+		var have []error
+		fn := func(_ string, _ *File, err error) error {
+			have = append(have, err)
+			return nil
+		}
+
+		// --- When ---
+		err := root.WalkSymlinks(FollowLinks, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 2, have)
+		assert.ErrorIs(t, syscall.ELOOP, have[0])
+		assert.ErrorIs(t, syscall.ELOOP, have[1])
+	})
+
+	t.Run("skip dir", func(t *testing.T) {
+		// --- Given ---
+		var have []string
+		fn := func(pth string, _ *File, _ error) error {
+			have = append(have, pth)
+			if pth == "ln" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		// --- When ---
+		err := tstLinkTree().WalkSymlinks(FollowLinks, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"dir", "dir/a", "dir/up", "file", "ln"}, have)
+	})
+
+	t.Run("skip all", func(t *testing.T) {
+		// --- Given ---
+		var have []string
+		fn := func(pth string, _ *File, _ error) error {
+			have = append(have, pth)
+			return fs.SkipAll
+		}
+
+		// --- When ---
+		err := tstLinkTree().WalkSymlinks(FollowLinks, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"dir"}, have)
+	})
+
+	t.Run("error - returned by fn", func(t *testing.T) {
+		// --- Given ---
+		var have []string
+		fn := func(pth string, _ *File, err error) error {
+			have = append(have, pth)
+			return err
+		}
+		flags := FollowLinks | ReportCycles
+
+		// --- When ---
+		err := tstLinkTree().WalkSymlinks(flags, fn)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ELOOP, err)
+		assert.Equal(t, []string{"dir", "dir/a", "dir/up"}, have)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").WalkSymlinks(0, nil)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "walk", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+	"syscall"
+)
+
+// WriteFile writes data to the file with the given name in the directory
+// tree, like [os.WriteFile] does. When the file does not exist, it's created
+// with the permission bits of the perm and the missing intermediate
+// directories are created too. Otherwise, it's truncated before writing and
+// the perm is ignored. The data is copied, and the offset of an existing file
+// is not changed. With the [WithWriteOnce] option, the written file becomes
+// read-only, as if it was closed.
+//
+// Returns an error of the [fs.PathError] type with:
+//   - [syscall.ENOTDIR] when the instance is not a directory or the name goes
+//     through a regular file,
+//   - [fs.ErrInvalid] when the name is not valid,
+//   - [syscall.EISDIR] when the name refers to a directory,
+//
+// and the errors returned by [File.AddFile], [File.Truncate] and
+// [File.Write].
+func (fil *File) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fil.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "open",
+			Path: fil.path(),
+			Err:  syscall.ENOTDIR,
+		})
+	}
+	name = fil.lenientPath(name)
+	if !fs.ValidPath(name) || name == "." {
+		return fil.hookErr(&fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		})
+	}
+
+	dir, err := mkdirAll(fil, path.Dir(name))
+	if err != nil {
+		return err
+	}
+	ent, err := open(dir, path.Base(name))
+	if err != nil {
+		ent, err = FileWith(
+			path.Base(name),
+			slices.Clone(data),
+			WithFileMode(perm),
+		)
+		if err != nil {
+			return err
+		}
+		if err = dir.AddFile(ent); err != nil {
+			return err
+		}
+		ent.seal()
+		return nil
+	}
+	if ent.IsDir() {
+		return fil.hookErr(&fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  syscall.EISDIR,
+		})
+	}
+
+	if err = ent.Truncate(0); err != nil {
+		return err
+	}
+	prev := ent.off
+	ent.off = 0
+	_, err = ent.Write(data)
+	ent.off = prev
+	if err != nil {
+		return err
+	}
+	ent.seal()
+	return nil
+}
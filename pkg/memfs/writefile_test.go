@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_File_WriteFile(t *testing.T) {
+	t.Run("new file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		data := []byte("abc")
+
+		// --- When ---
+		err := dir.WriteFile("new", data, 0644)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := must.Value(open(dir, "new"))
+		assert.Equal(t, []byte("abc"), fil.buf)
+		assert.Equal(t, fs.FileMode(0644), fil.Mode())
+		data[0] = 'x'
+		assert.Equal(t, []byte("abc"), fil.buf)
+	})
+
+	t.Run("creates intermediate directories", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+
+		// --- When ---
+		err := dir.WriteFile("sub/a/b/new", []byte("abc"), 0600)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", string(must.Value(dir.ReadFile("sub/a/b/new"))))
+		assert.True(t, must.Value(open(dir, "sub/a")).IsDir())
+	})
+
+	t.Run("truncates existing file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "sub/file3"))
+		fil.off = 2
+
+		// --- When ---
+		err := dir.WriteFile("sub/file3", []byte("ab"), 0755)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ab"), fil.buf)
+		assert.Equal(t, fs.FileMode(0600), fil.Mode())
+		assert.Equal(t, 2, fil.Offset())
+	})
+
+	t.Run("existing file in append mode", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot()
+		fil := MustFileWith("file", []byte("abc"), WithFileAppend)
+		must.Nil(dir.AddFile(fil))
+
+		// --- When ---
+		err := dir.WriteFile("file", []byte("de"), 0600)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("de"), fil.buf)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").WriteFile("new", nil, 0600)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "open", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		for _, name := range []string{"../new", "/new", ".", ""} {
+			// --- When ---
+			err := tstDirMem().WriteFile(name, nil, 0600)
+
+			// --- Then ---
+			var e *fs.PathError
+			assert.ErrorAs(t, &e, err)
+			assert.Equal(t, "open", e.Op)
+			assert.Equal(t, name, e.Path)
+			assert.Equal(t, fs.ErrInvalid, e.Err)
+		}
+	})
+
+	t.Run("error - path through file", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().WriteFile("file0/new", nil, 0600)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+	})
+
+	t.Run("error - directory", func(t *testing.T) {
+		// --- When ---
+		err := tstDirMem().WriteFile("sub/sub2", nil, 0600)
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "open", e.Op)
+		assert.Equal(t, "sub/sub2", e.Path)
+		assert.Equal(t, syscall.EISDIR, e.Err)
+	})
+
+	t.Run("error - immutable file", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		fil := must.Value(open(dir, "file0"))
+		fil.SetAttr(AttrImmutable)
+
+		// --- When ---
+		err := dir.WriteFile("file0", []byte("abc"), 0600)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EPERM, err)
+		assert.Equal(t, []byte("file0"), fil.buf)
+	})
+
+	t.Run("error - read-only directory", func(t *testing.T) {
+		// --- Given ---
+		dir := NewRoot(WithReadOnly)
+
+		// --- When ---
+		err := dir.WriteFile("new", []byte("abc"), 0600)
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.EROFS, err)
+		assert.Len(t, 0, dir.entries)
+	})
+}
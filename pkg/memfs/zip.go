@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// WriteZip writes all files and directories in the directory tree to w as a
+// zip archive with the deflated file contents. Paths in the archive are
+// relative to the directory, and the file contents pass through the
+// redaction rules in the given order. The entries are written in the path
+// order, and the modification times are not set, so exporting the same tree
+// always produces the same archive. The symbolic links (see [File.Symlink])
+// are stored with their destinations as the content, like the zip tools do.
+//
+// Returns an error of the [fs.PathError] type with [syscall.ENOTDIR] when the
+// instance is not a directory.
+func (fil *File) WriteZip(w io.Writer, rules ...Redaction) error {
+	return fil.writeZip(w, nil, rules)
+}
+
+// writeZip writes the files and directories in the directory tree for which
+// keep returns true to w as a zip archive. When keep is nil, all of them are
+// written. See [File.WriteZip] for details.
+func (fil *File) writeZip(
+	w io.Writer,
+	keep func(pth string) bool,
+	rules []Redaction,
+) error {
+	zw := zip.NewWriter(w)
+	add := func(pth string, ent *File, data []byte) error {
+		hdr := &zip.FileHeader{
+			Name:   filepath.ToSlash(pth),
+			Method: zip.Deflate,
+		}
+		hdr.SetMode(ent.Mode())
+		if ent.IsDir() {
+			hdr.Name += "/"
+			hdr.Method = zip.Store
+		}
+		if ent.isSymlink() {
+			data = []byte(ent.link)
+		}
+		zf, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		_, err = zf.Write(data)
+		return err
+	}
+	if err := fil.exportKept("zip", keep, rules, add); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// LazyFromZip returns a new root directory with the tree stored in the zip
+// archive read from r, which has the given size. The options are applied to
+// the root directory. The file contents are decompressed on the first read
+// and dropped on [File.Close] unless the [WithLazyCache] option was used, so
+// files never read don't cost any decompression. All the files and
+// directories in the tree have the [AttrImmutable] attribute flag set.
+//
+// Returns an error of the [fs.PathError] type with [fs.ErrInvalid] when the
+// archive contains an entry with an invalid path (see [File.ImportTar]) or an
+// entry which is neither a regular file nor a directory, and wrapping
+// [ErrImportLimit] when the archive exceeds the limits (see
+// [WithImportLimits]). The decompression errors are returned by the first
+// read.
+func LazyFromZip(r io.ReaderAt, size int64, opts ...func(*File)) (*File, error) {
+	root, err := fromZip(r, size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	root.walk("", func(_ string, ent *File) { ent.attr = AttrImmutable })
+	root.attr = AttrImmutable
+	return root, nil
+}
+
+// fromZip returns a new root directory with the tree stored in the zip
+// archive read from r, which has the given size. The file contents are loaded
+// lazily. The options are applied to the root directory.
+func fromZip(r io.ReaderAt, size int64, opts ...func(*File)) (*File, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return nil, err
+	}
+
+	root := NewRoot(opts...)
+	lim := newImportLimiter(root, "zip")
+	for _, zf := range zr.File {
+		name := strings.TrimSuffix(zf.Name, "/")
+		mode := zf.Mode()
+		if !validArchivePath(name) || name == "." ||
+			!(mode.IsDir() || mode.IsRegular()) {
+			return nil, &fs.PathError{
+				Op:   "zip",
+				Path: zf.Name,
+				Err:  fs.ErrInvalid,
+			}
+		}
+		err = lim.add(zf.Name, zipSize(zf), int64(zf.CompressedSize64))
+		if err != nil {
+			return nil, err
+		}
+
+		if mode.IsDir() {
+			dir, err := mkdirAll(root, name)
+			if err != nil {
+				return nil, err
+			}
+			dir.info.mode = fs.ModeDir | mode.Perm()
+			continue
+		}
+
+		dir, err := mkdirAll(root, path.Dir(name))
+		if err != nil {
+			return nil, err
+		}
+		fil := &File{
+			info: FileInfo{
+				name: path.Base(name),
+				size: zipSize(zf),
+				mode: mode.Perm(),
+			},
+			lazy: zipContent(zf),
+		}
+		if err = dir.AddFile(fil); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// zipSize returns the uncompressed size of the zip archive file.
+func zipSize(zf *zip.File) int64 {
+	return int64(min(zf.UncompressedSize64, math.MaxInt64))
+}
+
+// zipContent returns a function decompressing the zip archive file.
+func zipContent(zf *zip.File) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rc.Close() }()
+		return io.ReadAll(rc)
+	}
+}
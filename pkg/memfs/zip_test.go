@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+// arcEntry represents an entry of the archive created by tstZip or tstTar.
+type arcEntry struct {
+	name    string
+	mode    fs.FileMode
+	content string
+}
+
+// tstZip returns a zip archive with the given entries.
+func tstZip(entries ...arcEntry) *bytes.Reader {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, ent := range entries {
+		hdr := &zip.FileHeader{Name: ent.name, Method: zip.Deflate}
+		hdr.SetMode(ent.mode)
+		w := must.Value(zw.CreateHeader(hdr))
+		must.Value(w.Write([]byte(ent.content)))
+	}
+	must.Nil(zw.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+// tstZipDir returns a zip archive matching the structure created by tstDirMem.
+func tstZipDir() *bytes.Reader {
+	return tstZip(
+		arcEntry{"file0", 0600, "file0"},
+		arcEntry{"file1", 0600, "file1"},
+		arcEntry{"file2", 0600, "file2"},
+		arcEntry{"sub/", fs.ModeDir | 0700, ""},
+		arcEntry{"sub/file3", 0600, "file3"},
+		arcEntry{"sub/file4", 0600, "file4"},
+		arcEntry{"sub/sub2/file5", 0600, "file5"},
+		arcEntry{"sub/sub2/file6", 0600, "file6"},
+	)
+}
+
+func Test_LazyFromZip(t *testing.T) {
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZipDir()
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		want := must.Value(tstDirMem().List())
+		assert.Equal(t, want, must.Value(have.List()))
+		fil := must.Value(open(have, "sub/sub2/file5"))
+		assert.Equal(t, fs.FileMode(0600), fil.Mode())
+		content := must.Value(have.ReadFile("sub/sub2/file5"))
+		assert.Equal(t, "file5", string(content))
+		sub := must.Value(open(have, "sub"))
+		assert.Equal(t, fs.ModeDir|0700, sub.Mode())
+	})
+
+	t.Run("content is not loaded", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZipDir()
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		fil := must.Value(open(have, "sub/file3"))
+		assert.True(t, fil.unloaded())
+		assert.Nil(t, fil.buf)
+		assert.Equal(t, int64(5), fil.Size())
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZipDir()
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, AttrImmutable, have.Attr())
+		fil := must.Value(open(have, "sub/file3"))
+		assert.Equal(t, AttrImmutable, fil.Attr())
+		_, err = fil.Write([]byte{0})
+		assert.ErrorIs(t, syscall.EPERM, err)
+		err = have.AddFile(MustFile("new"))
+		assert.ErrorIs(t, syscall.EPERM, err)
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZipDir()
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size(), WithLazyCache)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.True(t, have.keep)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZip()
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, have.entries)
+	})
+
+	t.Run("error - not a zip archive", func(t *testing.T) {
+		// --- Given ---
+		zr := bytes.NewReader([]byte("abc"))
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		assert.ErrorIs(t, zip.ErrFormat, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - invalid path", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZip(arcEntry{"../file", 0600, "abc"})
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "zip", e.Op)
+		assert.Equal(t, "../file", e.Path)
+		assert.Equal(t, fs.ErrInvalid, e.Err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - symbolic link", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZip(arcEntry{"link", fs.ModeSymlink | 0777, "file"})
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrInvalid, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - file used as a directory", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZip(
+			arcEntry{"file", 0600, "abc"},
+			arcEntry{"file/sub", 0600, "abc"},
+		)
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		assert.ErrorIs(t, syscall.ENOTDIR, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - duplicate file", func(t *testing.T) {
+		// --- Given ---
+		zr := tstZip(
+			arcEntry{"file", 0600, "abc"},
+			arcEntry{"file", 0600, "def"},
+		)
+
+		// --- When ---
+		have, err := LazyFromZip(zr, zr.Size())
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrExist, err)
+		assert.Nil(t, have)
+	})
+
+	t.Run("error - corrupted content", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+		zw := zip.NewWriter(buf)
+		hdr := &zip.FileHeader{Name: "file", Method: zip.Store}
+		must.Value(must.Value(zw.CreateHeader(hdr)).Write([]byte("abc")))
+		must.Nil(zw.Close())
+		data := buf.Bytes()
+		data[bytes.Index(data, []byte("abc"))] = 'x'
+		zr := bytes.NewReader(data)
+		root := must.Value(LazyFromZip(zr, zr.Size()))
+
+		// --- When ---
+		have, err := root.ReadFile("file")
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "read", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.ErrorIs(t, zip.ErrChecksum, err)
+		assert.Len(t, 0, have)
+	})
+}
+
+func Test_File_WriteZip(t *testing.T) {
+	t.Run("tree", func(t *testing.T) {
+		// --- Given ---
+		dir := tstDirMem()
+		must.Nil(must.Value(open(dir, "file1")).Chmod(0755))
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := dir.WriteZip(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		r := bytes.NewReader(buf.Bytes())
+		have := must.Value(LazyFromZip(r, r.Size()))
+		assert.Equal(t, must.Value(dir.List()), must.Value(have.List()))
+		content := must.Value(have.ReadFile("sub/sub2/file5"))
+		assert.Equal(t, "file5", string(content))
+		fil := must.Value(open(have, "file1"))
+		assert.Equal(t, fs.FileMode(0755), fil.Mode())
+	})
+
+	t.Run("entries in path order", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().WriteZip(buf)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		r := bytes.NewReader(buf.Bytes())
+		zr := must.Value(zip.NewReader(r, r.Size()))
+		var have []string
+		for _, zf := range zr.File {
+			have = append(have, zf.Name)
+		}
+		want := []string{
+			"file0", "file1", "file2", "sub/", "sub/file3", "sub/file4",
+			"sub/sub2/", "sub/sub2/file5", "sub/sub2/file6",
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("same tree same archive", func(t *testing.T) {
+		// --- Given ---
+		buf0, buf1 := &bytes.Buffer{}, &bytes.Buffer{}
+
+		// --- When ---
+		must.Nil(tstDirMem().WriteZip(buf0))
+		must.Nil(tstDirMem().WriteZip(buf1))
+
+		// --- Then ---
+		assert.Equal(t, buf0.Bytes(), buf1.Bytes())
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+
+		// --- When ---
+		err := tstDirMem().WriteZip(buf, RedactPaths("file0", "***"))
+
+		// --- Then ---
+		assert.NoError(t, err)
+		r := bytes.NewReader(buf.Bytes())
+		have := must.Value(LazyFromZip(r, r.Size()))
+		assert.Equal(t, "***", string(must.Value(have.ReadFile("file0"))))
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- When ---
+		err := MustFile("file").WriteZip(&bytes.Buffer{})
+
+		// --- Then ---
+		var e *fs.PathError
+		assert.ErrorAs(t, &e, err)
+		assert.Equal(t, "zip", e.Op)
+		assert.Equal(t, "file", e.Path)
+		assert.Equal(t, syscall.ENOTDIR, e.Err)
+	})
+}
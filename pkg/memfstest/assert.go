@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+)
+
+// AssertFileEqual asserts the file with the name in the file system has the
+// want content. On failure, it marks the test as failed, reports the unified
+// diff of the want and have contents, and returns false.
+func AssertFileEqual(t testing.TB, fsys fs.FS, name string, want []byte) bool {
+	t.Helper()
+	have, ok := readFile(t, fsys, name)
+	if !ok {
+		return false
+	}
+	if bytes.Equal(want, have) {
+		return true
+	}
+	t.Errorf(
+		"expected file %q content to be equal:\n%s",
+		name, unifiedDiff(want, have),
+	)
+	return false
+}
+
+// AssertFileContains asserts the file with the name in the file system
+// contains the sub content. On failure, it marks the test as failed, reports
+// both the sub and the file contents, and returns false.
+func AssertFileContains(
+	t testing.TB,
+	fsys fs.FS,
+	name string,
+	sub []byte,
+) bool {
+	t.Helper()
+	have, ok := readFile(t, fsys, name)
+	if !ok {
+		return false
+	}
+	if bytes.Contains(have, sub) {
+		return true
+	}
+	t.Errorf(
+		"expected file %q to contain:\n  want: %q\n  have: %q",
+		name, sub, have,
+	)
+	return false
+}
+
+// readFile reads the file with the name in the file system. On failure, it
+// marks the test as failed, reports the error, and returns false.
+func readFile(t testing.TB, fsys fs.FS, name string) ([]byte, bool) {
+	t.Helper()
+	have, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		t.Errorf("expected file %q to be readable:\n  error: %v", name, err)
+		return nil, false
+	}
+	return have, true
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+// spyT is a [testing.TB] recording the reported errors, the logs and the
+// cleanup functions.
+type spyT struct {
+	testing.TB
+	msg      string
+	log      string
+	failed   bool
+	cleanups []func()
+	dir      string
+}
+
+func (spy *spyT) Helper()             {}
+func (spy *spyT) Failed() bool        { return spy.failed }
+func (spy *spyT) Cleanup(f func())    { spy.cleanups = append(spy.cleanups, f) }
+func (spy *spyT) ArtifactDir() string { return spy.dir }
+
+func (spy *spyT) Errorf(format string, args ...any) {
+	spy.msg = fmt.Sprintf(format, args...)
+	spy.failed = true
+}
+
+func (spy *spyT) Logf(format string, args ...any) {
+	spy.log = fmt.Sprintf(format, args...)
+}
+
+// cleanup calls the registered cleanup functions in the reverse order.
+func (spy *spyT) cleanup() {
+	for i := len(spy.cleanups) - 1; i >= 0; i-- {
+		spy.cleanups[i]()
+	}
+}
+
+// tstFS returns a file system with the "file" file with three lines.
+func tstFS() fstest.MapFS {
+	return fstest.MapFS{"file": {Data: []byte("line1\nline2\nline3\n")}}
+}
+
+func Test_AssertFileEqual(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+		want := []byte("line1\nline2\nline3\n")
+
+		// --- When ---
+		have := AssertFileEqual(spy, tstFS(), "file", want)
+
+		// --- Then ---
+		assert.True(t, have)
+		assert.Equal(t, "", spy.msg)
+	})
+
+	t.Run("not equal", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+
+		// --- When ---
+		have := AssertFileEqual(spy, tstFS(), "file", []byte("line1\nline3\n"))
+
+		// --- Then ---
+		assert.False(t, have)
+		want := "expected file \"file\" content to be equal:\n" +
+			"--- want\n" +
+			"+++ have\n" +
+			"@@ -1,2 +1,3 @@\n" +
+			" line1\n" +
+			"+line2\n" +
+			" line3\n"
+		assert.Equal(t, want, spy.msg)
+	})
+
+	t.Run("not existing file", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+
+		// --- When ---
+		have := AssertFileEqual(spy, tstFS(), "missing", nil)
+
+		// --- Then ---
+		assert.False(t, have)
+		want := "expected file \"missing\" to be readable:\n" +
+			"  error: open missing: file does not exist"
+		assert.Equal(t, want, spy.msg)
+	})
+}
+
+func Test_AssertFileContains(t *testing.T) {
+	t.Run("contains", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+
+		// --- When ---
+		have := AssertFileContains(spy, tstFS(), "file", []byte("line2\n"))
+
+		// --- Then ---
+		assert.True(t, have)
+		assert.Equal(t, "", spy.msg)
+	})
+
+	t.Run("does not contain", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+
+		// --- When ---
+		have := AssertFileContains(spy, tstFS(), "file", []byte("line4"))
+
+		// --- Then ---
+		assert.False(t, have)
+		want := "expected file \"file\" to contain:\n" +
+			"  want: \"line4\"\n" +
+			"  have: \"line1\\nline2\\nline3\\n\""
+		assert.Equal(t, want, spy.msg)
+	})
+
+	t.Run("not existing file", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+
+		// --- When ---
+		have := AssertFileContains(spy, tstFS(), "missing", nil)
+
+		// --- Then ---
+		assert.False(t, have)
+		assert.Contain(t, "to be readable", spy.msg)
+	})
+}
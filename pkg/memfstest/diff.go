@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// diffContext is the number of unchanged lines around the changes in the
+// unified diff hunks.
+const diffContext = 3
+
+// edit represents a single line of the unified diff.
+type edit struct {
+	kind byte   // One of ' ', '-' or '+'.
+	a, b int    // Zero based line numbers in want and have.
+	line string // Line including the line ending.
+}
+
+// unifiedDiff returns the unified diff of the want and have contents with the
+// "want" and "have" file labels. For contents which are not valid UTF-8, it
+// returns a description of the first difference instead.
+func unifiedDiff(want, have []byte) string {
+	if !utf8.Valid(want) || !utf8.Valid(have) {
+		i := 0
+		for i < len(want) && i < len(have) && want[i] == have[i] {
+			i++
+		}
+		return fmt.Sprintf(
+			"binary contents differ at offset %d "+
+				"(want %d bytes, have %d bytes)",
+			i, len(want), len(have),
+		)
+	}
+
+	eds := diffLines(splitLines(want), splitLines(have))
+	var out strings.Builder
+	out.WriteString("--- want\n+++ have\n")
+	for k := 0; k < len(eds); {
+		if eds[k].kind == ' ' {
+			k++
+			continue
+		}
+		start := max(k-diffContext, 0)
+		end := k
+		for {
+			for end < len(eds) && eds[end].kind != ' ' {
+				end++
+			}
+			run := end
+			for run < len(eds) && eds[run].kind == ' ' {
+				run++
+			}
+			if run == len(eds) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(eds))
+				break
+			}
+			end = run
+		}
+		writeHunk(&out, eds[start:end])
+		k = end
+	}
+	return out.String()
+}
+
+// writeHunk writes the unified diff hunk with the edits to out.
+func writeHunk(out *strings.Builder, eds []edit) {
+	var aCnt, bCnt int
+	for _, ed := range eds {
+		if ed.kind != '+' {
+			aCnt++
+		}
+		if ed.kind != '-' {
+			bCnt++
+		}
+	}
+	fmt.Fprintf(
+		out,
+		"@@ -%s +%s @@\n",
+		hunkRange(eds[0].a, aCnt),
+		hunkRange(eds[0].b, bCnt),
+	)
+	for _, ed := range eds {
+		out.WriteByte(ed.kind)
+		out.WriteString(ed.line)
+		if !strings.HasSuffix(ed.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange returns the range of the hunk lines starting at the zero based
+// line number.
+func hunkRange(start, cnt int) string {
+	switch cnt {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, cnt)
+	}
+}
+
+// splitLines splits the content into lines keeping the line endings.
+func splitLines(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n') + 1
+		if i == 0 {
+			i = len(data)
+		}
+		lines = append(lines, string(data[:i]))
+		data = data[i:]
+	}
+	return lines
+}
+
+// diffLines returns the edits transforming the lines a to the lines b based
+// on their longest common subsequence.
+func diffLines(a, b []string) []edit {
+	// The lcs[i][j] is the length of the longest common subsequence of the
+	// a[i:] and b[j:] lines.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var eds []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			eds = append(eds, edit{' ', i, j, a[i]})
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			eds = append(eds, edit{'-', i, j, a[i]})
+			i++
+		default:
+			eds = append(eds, edit{'+', i, j, b[j]})
+			j++
+		}
+	}
+	return eds
+}
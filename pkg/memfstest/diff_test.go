@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_unifiedDiff(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		want string
+		have string
+		diff string
+	}{
+		{
+			"changed line",
+			"a\nb\nc\n",
+			"a\nx\nc\n",
+			"@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			"removed line",
+			"a\nb\n",
+			"a\n",
+			"@@ -1,2 +1 @@\n a\n-b\n",
+		},
+		{
+			"empty want",
+			"",
+			"a\n",
+			"@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			"no newline at end of file",
+			"a\n",
+			"a",
+			"@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+		{
+			"merged hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"x\n2\n3\n4\n5\n6\n7\ny\n",
+			"@@ -1,8 +1,8 @@\n-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have := unifiedDiff([]byte(tc.want), []byte(tc.have))
+
+			// --- Then ---
+			assert.Equal(t, "--- want\n+++ have\n"+tc.diff, have)
+		})
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		// --- When ---
+		have := unifiedDiff([]byte{0, 1, 0xff}, []byte{0, 2})
+
+		// --- Then ---
+		want := "binary contents differ at offset 1 " +
+			"(want 3 bytes, have 2 bytes)"
+		assert.Equal(t, want, have)
+	})
+}
+
+func Test_splitLines(t *testing.T) {
+	// --- When ---
+	have := splitLines([]byte("a\n\nb"))
+
+	// --- Then ---
+	assert.Equal(t, []string{"a\n", "\n", "b"}, have)
+	assert.Nil(t, splitLines(nil))
+	assert.Equal(t, "a\n\nb", strings.Join(have, ""))
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"bytes"
+	"crypto"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ctx42/memfs/pkg/memfs"
+)
+
+// dumpExts maps the formats to the extensions of the dump files.
+var dumpExts = map[memfs.Format]string{
+	memfs.FormatCSV:   ".csv",
+	memfs.FormatJSON:  ".jsonl",
+	memfs.FormatTar:   ".tar",
+	memfs.FormatZip:   ".zip",
+	memfs.FormatTxtar: ".txtar",
+}
+
+// DumpOnFailure registers a cleanup function dumping the final state of the
+// directory tree when the test fails, so the fixture state can be examined
+// after the fact. When the tests run with the -artifacts flag, the tree is
+// exported in the given format (see [memfs.File.Export]) to the "memfs" file
+// with the format extension, for example, "memfs.tar", in the test artifact
+// directory (see [testing.T.ArtifactDir]). Otherwise, the artifact directory
+// is removed after the test, so the SHA-256 manifest of the tree (see
+// [memfs.File.WriteManifest]) is logged instead.
+func DumpOnFailure(t testing.TB, dir *memfs.File, format memfs.Format) {
+	t.Helper()
+	t.Cleanup(func() {
+		t.Helper()
+		if t.Failed() {
+			dumpTree(t, dir, format, artifacts())
+		}
+	})
+}
+
+// dumpTree writes the directory tree in the format to the test artifact
+// directory when toDir is true, or logs its manifest otherwise.
+func dumpTree(t testing.TB, dir *memfs.File, format memfs.Format, toDir bool) {
+	t.Helper()
+	ext, ok := dumpExts[format]
+	if !toDir || !ok {
+		buf := &bytes.Buffer{}
+		if err := dir.WriteManifest(buf, crypto.SHA256); err != nil {
+			t.Errorf("memfs dump: %v", err)
+			return
+		}
+		t.Logf("memfs tree:\n%s", buf.String())
+		return
+	}
+	buf := &bytes.Buffer{}
+	if err := dir.Export(buf, format); err != nil {
+		t.Errorf("memfs dump: %v", err)
+		return
+	}
+	pth := filepath.Join(t.ArtifactDir(), "memfs"+ext)
+	if err := os.WriteFile(pth, buf.Bytes(), 0600); err != nil {
+		t.Errorf("memfs dump: %v", err)
+		return
+	}
+	t.Logf("memfs tree written to %s", pth)
+}
+
+// artifacts returns true when the tests run with the -artifacts flag.
+func artifacts() bool {
+	f := flag.Lookup("test.artifacts")
+	return f != nil && f.Value.String() == "true"
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+
+	"github.com/ctx42/memfs/pkg/memfs"
+)
+
+// tstTree returns a tree with the "dir/file" file.
+func tstTree() *memfs.File {
+	root := memfs.NewRoot()
+	must.Nil(root.WriteFile("dir/file", []byte("abc"), 0600))
+	return root
+}
+
+// wantManifest is the log of the tree returned by [tstTree].
+const wantManifest = "memfs tree:\n" +
+	"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" +
+	"  dir/file\n"
+
+func Test_DumpOnFailure(t *testing.T) {
+	t.Run("passed", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+		DumpOnFailure(spy, tstTree(), memfs.FormatTar)
+
+		// --- When ---
+		spy.cleanup()
+
+		// --- Then ---
+		assert.Len(t, 1, spy.cleanups)
+		assert.Equal(t, "", spy.log)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{failed: true}
+		DumpOnFailure(spy, tstTree(), memfs.FormatTar)
+
+		// --- When ---
+		spy.cleanup()
+
+		// --- Then ---
+		assert.Equal(t, wantManifest, spy.log)
+	})
+}
+
+func Test_dumpTree(t *testing.T) {
+	t.Run("log", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+
+		// --- When ---
+		dumpTree(spy, tstTree(), memfs.FormatTxtar, false)
+
+		// --- Then ---
+		assert.Equal(t, wantManifest, spy.log)
+		assert.Equal(t, "", spy.msg)
+	})
+
+	t.Run("artifact", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{dir: t.TempDir()}
+
+		// --- When ---
+		dumpTree(spy, tstTree(), memfs.FormatTxtar, true)
+
+		// --- Then ---
+		pth := filepath.Join(spy.dir, "memfs.txtar")
+		assert.Equal(t, "memfs tree written to "+pth, spy.log)
+		have := must.Value(os.ReadFile(pth))
+		assert.Equal(t, "-- dir/file --\nabc\n", string(have))
+	})
+
+	t.Run("artifact tar", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{dir: t.TempDir()}
+
+		// --- When ---
+		dumpTree(spy, tstTree(), memfs.FormatTar, true)
+
+		// --- Then ---
+		have := must.Value(memfs.FromTar(must.Value(
+			os.Open(filepath.Join(spy.dir, "memfs.tar")),
+		)))
+		data := must.Value(have.ReadFile("dir/file"))
+		assert.Equal(t, "abc", string(data))
+	})
+
+	t.Run("unknown format is logged", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{dir: t.TempDir()}
+
+		// --- When ---
+		dumpTree(spy, tstTree(), memfs.Format(42), true)
+
+		// --- Then ---
+		assert.Equal(t, wantManifest, spy.log)
+	})
+
+	t.Run("error - not a directory", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+
+		// --- When ---
+		dumpTree(spy, must.Value(memfs.NewFile("file")), memfs.FormatTar, false)
+
+		// --- Then ---
+		assert.True(t, spy.failed)
+		assert.Equal(t, "", spy.log)
+	})
+
+	t.Run("error - writing artifact", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{dir: filepath.Join(t.TempDir(), "missing")}
+
+		// --- When ---
+		dumpTree(spy, tstTree(), memfs.FormatTar, true)
+
+		// --- Then ---
+		assert.True(t, spy.failed)
+		assert.Equal(t, "", spy.log)
+	})
+}
+
+func Test_artifacts(t *testing.T) {
+	// --- When ---
+	have := artifacts()
+
+	// --- Then ---
+	assert.False(t, have)
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+// Package memfstest provides a differential testing harness running the same
+// sequence of file operations against two file implementations, for example
+// [os.File] and [memfs.File], and comparing the results byte-for-byte. It
+// also provides assertions on the contents of files in any [fs.FS].
+package memfstest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/ctx42/memfs/pkg/memfs"
+)
+
+// ErrMismatch is returned by [Compare] when the compared implementations
+// behave differently.
+var ErrMismatch = errors.New("implementations differ")
+
+// File is an interface common to [os.File] and [memfs.File] the operations
+// are performed on.
+type File interface {
+	io.Seeker
+	io.Reader
+	io.ReaderAt
+	io.ReaderFrom
+	io.Writer
+	io.WriterAt
+	io.StringWriter
+	io.WriterTo
+
+	Stat() (fs.FileInfo, error)
+	Truncate(size int64) error
+}
+
+// Op represents a single operation performed on a file.
+type Op struct {
+	// Name of the operation used in error messages.
+	Name string
+
+	// Run performs the operation on the file and returns its results, which
+	// are compared between implementations.
+	Run func(fil File) []any
+}
+
+// Compare performs operations on both files in order and returns an error
+// wrapping [ErrMismatch] describing the first difference. After every
+// operation, it compares the operation results, the file offsets and the file
+// contents. Errors of the [fs.PathError] type are compared by the operation
+// name and the underlying error only, since paths usually differ between
+// implementations.
+func Compare(want, have File, ops ...Op) error {
+	for i, op := range ops {
+		wRes, hRes := op.Run(want), op.Run(have)
+		if err := diff(i, op.Name, "result", wRes, hRes); err != nil {
+			return err
+		}
+		err := diff(i, op.Name, "state", state(want), state(have))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OSFile creates a file named "file" in the directory dir with the content,
+// and opens it with the flag. The caller is responsible for closing the file.
+func OSFile(dir string, flag int, content []byte) (*os.File, error) {
+	pth := filepath.Join(dir, "file")
+	if err := os.WriteFile(pth, content, 0600); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(pth, flag, 0600)
+}
+
+// MemFile returns a [memfs.File] named "file" with the content and the flag.
+// The file takes ownership of the content slice.
+func MemFile(flag int, content []byte) (*memfs.File, error) {
+	return memfs.FileWith("file", content, memfs.WithFileFlag(flag))
+}
+
+// state returns the file offset and content.
+func state(fil File) []any {
+	off, err := fil.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return []any{off, nil, err}
+	}
+	info, err := fil.Stat()
+	if err != nil {
+		return []any{off, nil, err}
+	}
+	buf := make([]byte, info.Size())
+	n, err := fil.ReadAt(buf, 0)
+	if errors.Is(err, io.EOF) && n == len(buf) {
+		err = nil
+	}
+	return []any{off, buf[:n], err}
+}
+
+// diff compares the want and have values and returns an error wrapping
+// [ErrMismatch] when they are not equal.
+func diff(step int, name, what string, want, have []any) error {
+	for i := range want {
+		w, h := normalize(want[i]), normalize(have[i])
+		if !reflect.DeepEqual(w, h) {
+			return fmt.Errorf(
+				"%w: step %d (%s): %s %d: want %v, have %v",
+				ErrMismatch, step, name, what, i, w, h,
+			)
+		}
+	}
+	return nil
+}
+
+// normalize returns a value suitable for comparison between implementations.
+func normalize(v any) any {
+	if b, ok := v.([]byte); ok && len(b) == 0 {
+		return []byte{}
+	}
+	err, ok := v.(error)
+	if !ok {
+		return v
+	}
+	var e *fs.PathError
+	if errors.As(err, &e) {
+		return e.Op + ": " + e.Err.Error()
+	}
+	return err.Error()
+}
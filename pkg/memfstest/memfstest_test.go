@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_Compare(t *testing.T) {
+	t.Run("os and memfs", func(t *testing.T) {
+		// --- Given ---
+		want := must.Value(OSFile(t.TempDir(), os.O_RDWR, []byte{0, 1, 2}))
+		t.Cleanup(func() { _ = want.Close() })
+		have := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2}))
+
+		// --- When ---
+		err := Compare(
+			want,
+			have,
+			Seek(1, io.SeekStart),
+			Write([]byte{3, 4, 5}),
+			WriteAt([]byte{6}, 10),
+			Read(2),
+			ReadAt(20, 2),
+			Seek(-1, io.SeekStart),
+			Truncate(2),
+			ReadFrom([]byte{7, 8}),
+			WriteString("abc"),
+			Seek(0, io.SeekStart),
+			WriteTo(),
+			Stat(),
+		)
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("no operations", func(t *testing.T) {
+		// --- Given ---
+		want := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2}))
+		have := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2}))
+
+		// --- When ---
+		err := Compare(want, have)
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("error - different results", func(t *testing.T) {
+		// --- Given ---
+		want := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2}))
+		have := must.Value(MemFile(os.O_RDWR|os.O_APPEND, []byte{0, 1, 2}))
+
+		// --- When ---
+		err := Compare(want, have, Read(1), WriteAt([]byte{3}, 0))
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrMismatch, err)
+		wMsg := "implementations differ: step 1 (WriteAt([3], 0)): " +
+			"result 0: want 1, have 0"
+		assert.ErrorEqual(t, wMsg, err)
+	})
+
+	t.Run("error - different content", func(t *testing.T) {
+		// --- Given ---
+		want := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2}))
+		have := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2, 3}))
+
+		// --- When ---
+		err := Compare(want, have, Read(1))
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrMismatch, err)
+		wMsg := "implementations differ: step 0 (Read(1)): " +
+			"state 1: want [0 1 2], have [0 1 2 3]"
+		assert.ErrorEqual(t, wMsg, err)
+	})
+
+	t.Run("error - different offset", func(t *testing.T) {
+		// --- Given ---
+		want := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2}))
+		have := must.Value(MemFile(os.O_RDWR|os.O_APPEND, []byte{0, 1, 2}))
+
+		// --- When ---
+		err := Compare(want, have, Write([]byte{3}))
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrMismatch, err)
+		wMsg := "implementations differ: step 0 (Write([3])): " +
+			"state 0: want 1, have 4"
+		assert.ErrorEqual(t, wMsg, err)
+	})
+}
+
+func Test_OSFile(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		// --- Given ---
+		dir := t.TempDir()
+
+		// --- When ---
+		have, err := OSFile(dir, os.O_RDWR, []byte{0, 1, 2})
+
+		// --- Then ---
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = have.Close() })
+		assert.Equal(t, filepath.Join(dir, "file"), have.Name())
+		assert.Equal(t, []byte{0, 1, 2}, must.Value(io.ReadAll(have)))
+	})
+
+	t.Run("error - directory does not exist", func(t *testing.T) {
+		// --- Given ---
+		dir := filepath.Join(t.TempDir(), "missing")
+
+		// --- When ---
+		have, err := OSFile(dir, os.O_RDWR, nil)
+
+		// --- Then ---
+		assert.ErrorIs(t, fs.ErrNotExist, err)
+		assert.Nil(t, have)
+	})
+}
+
+func Test_MemFile(t *testing.T) {
+	// --- When ---
+	have, err := MemFile(os.O_APPEND, []byte{0, 1, 2})
+
+	// --- Then ---
+	assert.NoError(t, err)
+	assert.Equal(t, "file", have.Name())
+	assert.Equal(t, []byte{0, 1, 2}, must.Value(io.ReadAll(have)))
+	must.Value(have.Write([]byte{3}))
+	assert.Equal(t, int64(4), have.Size())
+}
+
+func Test_state(t *testing.T) {
+	// --- Given ---
+	fil := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2}))
+	must.Value(fil.Seek(1, io.SeekStart))
+
+	// --- When ---
+	have := state(fil)
+
+	// --- Then ---
+	assert.Equal(t, []any{int64(1), []byte{0, 1, 2}, nil}, have)
+}
+
+func Test_normalize(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		v    any
+		want any
+	}{
+		{"nil", nil, nil},
+		{"int", 1, 1},
+		{"nil bytes", []byte(nil), []byte{}},
+		{"empty bytes", []byte{}, []byte{}},
+		{"bytes", []byte{1}, []byte{1}},
+		{"error", io.EOF, "EOF"},
+		{
+			"path error",
+			&fs.PathError{Op: "seek", Path: "/a", Err: syscall.EINVAL},
+			"seek: invalid argument",
+		},
+		{
+			"wrapped path error",
+			errors.Join(&fs.PathError{Op: "read", Err: io.EOF}),
+			"read: EOF",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			have := normalize(tc.v)
+
+			// --- Then ---
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Write returns an operation calling [io.Writer.Write] with p.
+func Write(p []byte) Op {
+	return Op{
+		Name: fmt.Sprintf("Write(%v)", p),
+		Run: func(fil File) []any {
+			n, err := fil.Write(p)
+			return []any{n, err}
+		},
+	}
+}
+
+// WriteAt returns an operation calling [io.WriterAt.WriteAt] with p and off.
+func WriteAt(p []byte, off int64) Op {
+	return Op{
+		Name: fmt.Sprintf("WriteAt(%v, %d)", p, off),
+		Run: func(fil File) []any {
+			n, err := fil.WriteAt(p, off)
+			return []any{n, err}
+		},
+	}
+}
+
+// WriteString returns an operation calling [io.StringWriter.WriteString]
+// with s.
+func WriteString(s string) Op {
+	return Op{
+		Name: fmt.Sprintf("WriteString(%q)", s),
+		Run: func(fil File) []any {
+			n, err := fil.WriteString(s)
+			return []any{n, err}
+		},
+	}
+}
+
+// WriteTo returns an operation calling [io.WriterTo.WriteTo].
+func WriteTo() Op {
+	return Op{
+		Name: "WriteTo()",
+		Run: func(fil File) []any {
+			dst := &bytes.Buffer{}
+			n, err := fil.WriteTo(dst)
+			return []any{dst.Bytes(), n, err}
+		},
+	}
+}
+
+// Read returns an operation calling [io.Reader.Read] with a buffer of
+// length n.
+func Read(n int) Op {
+	return Op{
+		Name: fmt.Sprintf("Read(%d)", n),
+		Run: func(fil File) []any {
+			buf := make([]byte, n)
+			n, err := fil.Read(buf)
+			return []any{buf[:n], n, err}
+		},
+	}
+}
+
+// ReadAt returns an operation calling [io.ReaderAt.ReadAt] with a buffer of
+// length n and off.
+func ReadAt(n int, off int64) Op {
+	return Op{
+		Name: fmt.Sprintf("ReadAt(%d, %d)", n, off),
+		Run: func(fil File) []any {
+			buf := make([]byte, n)
+			n, err := fil.ReadAt(buf, off)
+			return []any{buf[:n], n, err}
+		},
+	}
+}
+
+// ReadFrom returns an operation calling [io.ReaderFrom.ReadFrom] with a
+// reader returning p.
+func ReadFrom(p []byte) Op {
+	return Op{
+		Name: fmt.Sprintf("ReadFrom(%v)", p),
+		Run: func(fil File) []any {
+			n, err := fil.ReadFrom(bytes.NewReader(p))
+			return []any{n, err}
+		},
+	}
+}
+
+// Seek returns an operation calling [io.Seeker.Seek] with off and whence.
+func Seek(off int64, whence int) Op {
+	return Op{
+		Name: fmt.Sprintf("Seek(%d, %d)", off, whence),
+		Run: func(fil File) []any {
+			n, err := fil.Seek(off, whence)
+			return []any{n, err}
+		},
+	}
+}
+
+// Truncate returns an operation calling Truncate with size.
+func Truncate(size int64) Op {
+	return Op{
+		Name: fmt.Sprintf("Truncate(%d)", size),
+		Run: func(fil File) []any {
+			return []any{fil.Truncate(size)}
+		},
+	}
+}
+
+// Stat returns an operation calling Stat and comparing the file size and the
+// directory flag.
+func Stat() Op {
+	return Op{
+		Name: "Stat()",
+		Run: func(fil File) []any {
+			info, err := fil.Stat()
+			if err != nil {
+				return []any{int64(0), false, err}
+			}
+			return []any{info.Size(), info.IsDir(), nil}
+		},
+	}
+}
+
+// Decode decodes a sequence of operations from data, so it can be used with
+// fuzz tests. Every operation is decoded from an opcode byte followed by
+// argument bytes. Decoding stops at the first incomplete operation.
+func Decode(data []byte) []Op {
+	var ops []Op
+	for len(data) > 0 {
+		op, n := decode(data)
+		if n == 0 {
+			break
+		}
+		ops = append(ops, op)
+		data = data[n:]
+	}
+	return ops
+}
+
+// decode decodes a single operation from data. Returns the operation and the
+// number of consumed bytes, or zero when data is too short.
+func decode(data []byte) (Op, int) {
+	code := data[0] % 10
+	args := data[1:]
+
+	// payload returns the payload of length encoded in the first argument
+	// byte, or false when the arguments are too short.
+	payload := func() ([]byte, bool) {
+		if len(args) < 1 {
+			return nil, false
+		}
+		n := int(args[0] % 16)
+		if len(args) < n+1 {
+			return nil, false
+		}
+		return args[1 : n+1], true
+	}
+
+	switch code {
+	case 0:
+		if p, ok := payload(); ok {
+			return Write(p), len(p) + 2
+		}
+	case 1:
+		if p, ok := payload(); ok && len(args) > len(p)+1 {
+			return WriteAt(p, int64(args[len(p)+1])), len(p) + 3
+		}
+	case 2:
+		if p, ok := payload(); ok {
+			return WriteString(string(p)), len(p) + 2
+		}
+	case 3:
+		return WriteTo(), 1
+	case 4:
+		if len(args) > 0 {
+			return Read(int(args[0])), 2
+		}
+	case 5:
+		if len(args) > 1 {
+			return ReadAt(int(args[0]), int64(args[1])), 3
+		}
+	case 6:
+		if p, ok := payload(); ok {
+			return ReadFrom(p), len(p) + 2
+		}
+	case 7:
+		if len(args) > 1 {
+			return Seek(int64(int8(args[0])), int(args[1]%3)), 3
+		}
+	case 8:
+		if len(args) > 0 {
+			return Truncate(int64(args[0])), 2
+		}
+	case 9:
+		return Stat(), 1
+	}
+	return Op{}, 0
+}
@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+)
+
+func Test_ops(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		op    Op
+		name  string
+		want  []any
+		state []any
+	}{
+		{
+			"Write",
+			Write([]byte{3}),
+			"Write([3])",
+			[]any{1, nil},
+			[]any{int64(2), []byte{0, 3, 2}, nil},
+		},
+		{
+			"WriteAt",
+			WriteAt([]byte{3}, 4),
+			"WriteAt([3], 4)",
+			[]any{1, nil},
+			[]any{int64(1), []byte{0, 1, 2, 0, 3}, nil},
+		},
+		{
+			"WriteString",
+			WriteString("a"),
+			`WriteString("a")`,
+			[]any{1, nil},
+			[]any{int64(2), []byte{0, 97, 2}, nil},
+		},
+		{
+			"WriteTo",
+			WriteTo(),
+			"WriteTo()",
+			[]any{[]byte{1, 2}, int64(2), nil},
+			[]any{int64(3), []byte{0, 1, 2}, nil},
+		},
+		{
+			"Read",
+			Read(4),
+			"Read(4)",
+			[]any{[]byte{1, 2}, 2, nil},
+			[]any{int64(3), []byte{0, 1, 2}, nil},
+		},
+		{
+			"ReadAt",
+			ReadAt(2, 2),
+			"ReadAt(2, 2)",
+			[]any{[]byte{2}, 1, io.EOF},
+			[]any{int64(1), []byte{0, 1, 2}, nil},
+		},
+		{
+			"ReadFrom",
+			ReadFrom([]byte{3, 4, 5}),
+			"ReadFrom([3 4 5])",
+			[]any{int64(3), nil},
+			[]any{int64(4), []byte{0, 3, 4, 5}, nil},
+		},
+		{
+			"Seek",
+			Seek(-1, io.SeekEnd),
+			"Seek(-1, 2)",
+			[]any{int64(2), nil},
+			[]any{int64(2), []byte{0, 1, 2}, nil},
+		},
+		{
+			"Truncate",
+			Truncate(1),
+			"Truncate(1)",
+			[]any{nil},
+			[]any{int64(1), []byte{0}, nil},
+		},
+		{
+			"Stat",
+			Stat(),
+			"Stat()",
+			[]any{int64(3), false, nil},
+			[]any{int64(1), []byte{0, 1, 2}, nil},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			fil := must.Value(MemFile(os.O_RDWR, []byte{0, 1, 2}))
+			must.Value(fil.Seek(1, io.SeekStart))
+
+			// --- When ---
+			have := tc.op.Run(fil)
+
+			// --- Then ---
+			assert.Equal(t, tc.name, tc.op.Name)
+			assert.Equal(t, tc.want, have)
+			assert.Equal(t, tc.state, state(fil))
+		})
+	}
+}
+
+func Test_Decode(t *testing.T) {
+	t.Run("all operations", func(t *testing.T) {
+		// --- Given ---
+		data := []byte{
+			0, 2, 1, 2, // Write([1 2])
+			1, 1, 3, 4, // WriteAt([3], 4)
+			2, 1, 'a', // WriteString("a")
+			3,    // WriteTo()
+			4, 5, // Read(5)
+			5, 6, 7, // ReadAt(6, 7)
+			6, 0, // ReadFrom([])
+			7, 0xff, 4, // Seek(-1, 1)
+			8, 9, // Truncate(9)
+			9,  // Stat()
+			19, // Stat()
+		}
+
+		// --- When ---
+		have := Decode(data)
+
+		// --- Then ---
+		var names []string
+		for _, op := range have {
+			names = append(names, op.Name)
+		}
+		want := []string{
+			"Write([1 2])",
+			"WriteAt([3], 4)",
+			`WriteString("a")`,
+			"WriteTo()",
+			"Read(5)",
+			"ReadAt(6, 7)",
+			"ReadFrom([])",
+			"Seek(-1, 1)",
+			"Truncate(9)",
+			"Stat()",
+			"Stat()",
+		}
+		assert.Equal(t, want, names)
+	})
+
+	t.Run("incomplete operations", func(t *testing.T) {
+		tt := []struct {
+			testN string
+
+			data []byte
+		}{
+			{"Write without length", []byte{0}},
+			{"Write short payload", []byte{0, 2, 1}},
+			{"WriteAt without offset", []byte{1, 1, 3}},
+			{"WriteString short payload", []byte{2, 3}},
+			{"Read without length", []byte{4}},
+			{"ReadAt without offset", []byte{5, 1}},
+			{"ReadFrom without length", []byte{6}},
+			{"Seek without whence", []byte{7, 1}},
+			{"Truncate without size", []byte{8}},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.testN, func(t *testing.T) {
+				// --- Given ---
+				data := append([]byte{9}, tc.data...)
+
+				// --- When ---
+				have := Decode(data)
+
+				// --- Then ---
+				assert.Len(t, 1, have)
+				assert.Equal(t, "Stat()", have[0].Name)
+			})
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		// --- When ---
+		have := Decode(nil)
+
+		// --- Then ---
+		assert.Len(t, 0, have)
+	})
+}
+
+func FuzzCompare(f *testing.F) {
+	f.Add([]byte{0, 2, 1, 2, 7, 0, 0, 4, 10})
+	f.Add([]byte{7, 20, 0, 0, 0, 6, 2, 1, 2, 3, 8, 40, 5, 50, 0})
+	f.Add([]byte{6, 4, 9, 9, 9, 9, 8, 48, 1, 0, 100, 3, 9})
+	f.Add([]byte{7, 0xfb, 2, 2, 3, 'a', 'b', 'c', 7, 0, 0, 3})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := Decode(data)
+		for _, flag := range []int{os.O_RDWR, os.O_RDWR | os.O_APPEND} {
+			want := must.Value(OSFile(t.TempDir(), flag, []byte{0, 1, 2}))
+			have := must.Value(MemFile(flag, []byte{0, 1, 2}))
+
+			err := Compare(want, have, ops...)
+
+			assert.NoError(t, err)
+			assert.NoError(t, want.Close())
+		}
+	})
+}
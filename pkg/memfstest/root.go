@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"testing"
+
+	"github.com/ctx42/memfs/pkg/memfs"
+)
+
+// NewTestRoot returns a new root directory created with [memfs.NewRoot] and
+// the options, which lifecycle is bound to the test, the same way the
+// [testing.T.TempDir] directories are. When the test and all its subtests
+// complete, the cleanup function marks the test as failed when any file or
+// directory in the tree has open handles (see [memfs.File.OpenCount]), and
+// logs the tree listing when the test failed, so the fixture state can be
+// examined.
+func NewTestRoot(t testing.TB, opts ...func(*memfs.File)) *memfs.File {
+	t.Helper()
+	root := memfs.NewRoot(opts...)
+	t.Cleanup(func() {
+		t.Helper()
+		checkHandles(t, root)
+		if !t.Failed() {
+			return
+		}
+		if lst, err := root.List(); err == nil {
+			t.Logf("memfs tree:\n%s", lst)
+		}
+	})
+	return root
+}
+
+// checkHandles marks the test as failed when any file or directory in the
+// tree has open handles.
+func checkHandles(t testing.TB, root *memfs.File) {
+	t.Helper()
+	const msg = "expected no open handles:\n  path: %s\n  open: %d"
+	if n := root.OpenCount(); n > 0 {
+		t.Errorf(msg, ".", n)
+	}
+	_ = root.Walk(memfs.WalkPreOrder, func(pth string, ent *memfs.File) error {
+		if n := ent.OpenCount(); n > 0 {
+			t.Errorf(msg, pth, n)
+		}
+		return nil
+	})
+}
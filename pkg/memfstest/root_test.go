@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: (c) 2025 Rafal Zajac <rzajac@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package memfstest
+
+import (
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+	"github.com/ctx42/testing/pkg/must"
+
+	"github.com/ctx42/memfs/pkg/memfs"
+)
+
+func Test_NewTestRoot(t *testing.T) {
+	t.Run("root", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+
+		// --- When ---
+		have := NewTestRoot(spy, memfs.WithReadOnly)
+
+		// --- Then ---
+		assert.NotNil(t, have)
+		assert.True(t, have.IsDir())
+		assert.Len(t, 1, spy.cleanups)
+		assert.Error(t, have.WriteFile("file", nil, 0600))
+	})
+
+	t.Run("cleanup without open handles", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+		root := NewTestRoot(spy)
+		must.Nil(root.WriteFile("dir/file", []byte("abc"), 0600))
+		fil := must.Value(root.Open("dir/file"))
+		must.Nil(fil.Close())
+
+		// --- When ---
+		spy.cleanup()
+
+		// --- Then ---
+		assert.False(t, spy.failed)
+		assert.Equal(t, "", spy.msg)
+		assert.Equal(t, "", spy.log)
+	})
+
+	t.Run("cleanup with open handles", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+		root := NewTestRoot(spy)
+		must.Nil(root.WriteFile("dir/file", []byte("abc"), 0600))
+		must.Value(root.Open("dir/file"))
+
+		// --- When ---
+		spy.cleanup()
+
+		// --- Then ---
+		assert.True(t, spy.failed)
+		want := "expected no open handles:\n  path: dir/file\n  open: 1"
+		assert.Equal(t, want, spy.msg)
+		assert.Equal(t, "memfs tree:\n.\ndir\ndir/file\n", spy.log)
+	})
+
+	t.Run("cleanup with open root", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+		root := NewTestRoot(spy)
+		must.Value(root.DirFS().Open("."))
+
+		// --- When ---
+		spy.cleanup()
+
+		// --- Then ---
+		want := "expected no open handles:\n  path: .\n  open: 1"
+		assert.Equal(t, want, spy.msg)
+	})
+
+	t.Run("cleanup logs tree of failed test", func(t *testing.T) {
+		// --- Given ---
+		spy := &spyT{}
+		root := NewTestRoot(spy)
+		must.Nil(root.WriteFile("file", nil, 0600))
+		spy.failed = true
+
+		// --- When ---
+		spy.cleanup()
+
+		// --- Then ---
+		assert.Equal(t, "", spy.msg)
+		assert.Equal(t, "memfs tree:\n.\nfile\n", spy.log)
+	})
+}